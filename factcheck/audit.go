@@ -0,0 +1,58 @@
+package factcheck
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	ActionAudit string
+	TypeTarget  string
+)
+
+const (
+	ActionAuditTopicCreate            ActionAudit = "topic.create"
+	ActionAuditTopicDelete            ActionAudit = "topic.delete"
+	ActionAuditTopicResolve           ActionAudit = "topic.resolve"
+	ActionAuditTopicUpdateStatus      ActionAudit = "topic.update_status"
+	ActionAuditTopicUpdateName        ActionAudit = "topic.update_name"
+	ActionAuditTopicUpdateDescription ActionAudit = "topic.update_description"
+	ActionAuditTopicMerge             ActionAudit = "topic.merge"
+	ActionAuditTopicSplit             ActionAudit = "topic.split"
+	ActionAuditTopicReopen            ActionAudit = "topic.reopen"
+	ActionAuditAnswerDraft            ActionAudit = "answer.draft"
+	ActionAuditAnswerUpdateDraft      ActionAudit = "answer.update_draft"
+	ActionAuditSourceCreate           ActionAudit = "source.create"
+	ActionAuditSourceUpdate           ActionAudit = "source.update"
+	ActionAuditSourceDelete           ActionAudit = "source.delete"
+	ActionAuditGroupAssignTopic       ActionAudit = "group.assign_topic"
+	ActionAuditGroupDelete            ActionAudit = "group.delete"
+	ActionAuditGroupApprove           ActionAudit = "group.approve"
+	ActionAuditGroupReject            ActionAudit = "group.reject"
+	ActionAuditMessageAssignGroup     ActionAudit = "message.assign_group"
+	ActionAuditMessageDelete          ActionAudit = "message.delete"
+	ActionAuditRoleGrant              ActionAudit = "role.grant"
+	ActionAuditRoleRevoke             ActionAudit = "role.revoke"
+
+	TypeTargetTopic   TypeTarget = "topic"
+	TypeTargetGroup   TypeTarget = "message_group"
+	TypeTargetMessage TypeTarget = "message"
+	TypeTargetRole    TypeTarget = "user_role"
+	TypeTargetAnswer  TypeTarget = "answer"
+	TypeTargetSource  TypeTarget = "answer_source"
+)
+
+// AuditEvent records an admin action. TargetIDs lists every affected entity,
+// starting with the entity of TargetType, e.g. a group and then the topic it was assigned to.
+// Before and After are JSON snapshots of the main target, and are null for creations and deletions respectively.
+type AuditEvent struct {
+	ID         string          `json:"id"`
+	Actor      UserInfo        `json:"actor"`
+	Action     ActionAudit     `json:"action"`
+	TargetType TypeTarget      `json:"target_type"`
+	TargetIDs  []string        `json:"target_ids"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -7,13 +7,20 @@ import (
 
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
 )
 
 // ProviderSet provides everything cmd/api needs
 var ProviderSet = wire.NewSet(
 	wire.Bind(new(server.Server), new(*http.Server)),
 	di.ProviderSet,
+	auth.New,
+	auth.NewAuthorizer,
+	health.New,
+	stream.New,
 	handler.New,
 	server.New,
 	wire.Struct(new(Container), "*"),
@@ -24,6 +31,10 @@ var ProviderSet = wire.NewSet(
 var ProviderSetTest = wire.NewSet(
 	wire.Bind(new(server.Server), new(*http.Server)),
 	di.ProviderSetTest,
+	auth.New,
+	auth.NewAuthorizer,
+	health.New,
+	stream.New,
 	handler.New,
 	server.New,
 	wire.Struct(new(Container), "*"),
@@ -9,11 +9,19 @@ package di
 import (
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
 )
 
 // Injectors from inject.go:
@@ -28,12 +36,29 @@ func InitializeServer() (server.Server, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	queries := postgres.New(pool)
+	queries := postgres.NewQueries(pool)
 	repository := repo.New(queries, pool)
-	serviceFactcheck := core.New(repository)
-	handlerHandler := handler.New(repository, serviceFactcheck)
-	httpServer, cleanup2 := server.New(configConfig, handlerHandler)
+	resolver := links.NewResolver(configConfig)
+	fetcher := links.NewFetcher(configConfig)
+	detectorScript := language.NewDetectorScript()
+	policy, err := ratelimit.New(configConfig, repository)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	serviceFactcheck := core.New(configConfig, repository, resolver, fetcher, detectorScript, policy)
+	handlerHandler := handler.New(configConfig, repository, serviceFactcheck)
+	authenticator, err := auth.New(configConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	authorizer := auth.NewAuthorizer(configConfig, repository)
+	checker := health.New(configConfig, pool)
+	broker, cleanup2 := stream.New(repository, pool)
+	httpServer, cleanup3 := server.New(configConfig, handlerHandler, authenticator, authorizer, checker, broker)
 	return httpServer, func() {
+		cleanup3()
 		cleanup2()
 		cleanup()
 	}, nil
@@ -50,24 +75,47 @@ func InitializeContainer() (Container, func(), error) {
 	if err != nil {
 		return Container{}, nil, err
 	}
-	queries := postgres.New(pool)
+	queries := postgres.NewQueries(pool)
 	repository := repo.New(queries, pool)
-	serviceFactcheck := core.New(repository)
+	resolver := links.NewResolver(configConfig)
+	fetcher := links.NewFetcher(configConfig)
+	detectorScript := language.NewDetectorScript()
+	policy, err := ratelimit.New(configConfig, repository)
+	if err != nil {
+		cleanup()
+		return Container{}, nil, err
+	}
+	serviceFactcheck := core.New(configConfig, repository, resolver, fetcher, detectorScript, policy)
+	senderLINE := notify.NewSenderLINE(configConfig)
+	notifier := notify.New(repository, senderLINE)
+	v := outbox.NewSinks(configConfig, notifier)
+	relay := outbox.NewRelay(configConfig, repository, v, policy)
 	container := di.Container{
 		Config:          configConfig,
 		PostgresConn:    pool,
 		PostgresQuerier: queries,
 		Repository:      repository,
 		Service:         serviceFactcheck,
+		Notifier:        notifier,
+		Relay:           relay,
 	}
-	handlerHandler := handler.New(repository, serviceFactcheck)
-	httpServer, cleanup2 := server.New(configConfig, handlerHandler)
+	handlerHandler := handler.New(configConfig, repository, serviceFactcheck)
+	authenticator, err := auth.New(configConfig)
+	if err != nil {
+		cleanup()
+		return Container{}, nil, err
+	}
+	authorizer := auth.NewAuthorizer(configConfig, repository)
+	checker := health.New(configConfig, pool)
+	broker, cleanup2 := stream.New(repository, pool)
+	httpServer, cleanup3 := server.New(configConfig, handlerHandler, authenticator, authorizer, checker, broker)
 	diContainer := Container{
 		Container: container,
 		Handler:   handlerHandler,
 		Server:    httpServer,
 	}
 	return diContainer, func() {
+		cleanup3()
 		cleanup2()
 		cleanup()
 	}, nil
@@ -82,18 +130,39 @@ func InitializeContainerTest() (Container, func(), error) {
 	if err != nil {
 		return Container{}, nil, err
 	}
-	queries := postgres.New(pool)
+	queries := postgres.NewQueries(pool)
 	repository := repo.New(queries, pool)
-	serviceFactcheck := core.New(repository)
-	container, cleanup2 := di.NewTest(configConfig, pool, queries, repository, serviceFactcheck)
-	handlerHandler := handler.New(repository, serviceFactcheck)
-	httpServer, cleanup3 := server.New(configConfig, handlerHandler)
+	stub := links.NewStub()
+	detectorScript := language.NewDetectorScript()
+	policy, err := ratelimit.New(configConfig, repository)
+	if err != nil {
+		cleanup()
+		return Container{}, nil, err
+	}
+	serviceFactcheck := core.New(configConfig, repository, stub, stub, detectorScript, policy)
+	recorder := notify.NewRecorder()
+	notifier := notify.New(repository, recorder)
+	v := outbox.NewSinks(configConfig, notifier)
+	relay := outbox.NewRelay(configConfig, repository, v, policy)
+	container, cleanup2 := di.NewTest(configConfig, pool, queries, repository, serviceFactcheck, notifier, relay)
+	handlerHandler := handler.New(configConfig, repository, serviceFactcheck)
+	authenticator, err := auth.New(configConfig)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return Container{}, nil, err
+	}
+	authorizer := auth.NewAuthorizer(configConfig, repository)
+	checker := health.New(configConfig, pool)
+	broker, cleanup3 := stream.New(repository, pool)
+	httpServer, cleanup4 := server.New(configConfig, handlerHandler, authenticator, authorizer, checker, broker)
 	diContainer := Container{
 		Container: container,
 		Handler:   handlerHandler,
 		Server:    httpServer,
 	}
 	return diContainer, func() {
+		cleanup4()
 		cleanup3()
 		cleanup2()
 		cleanup()
@@ -2,31 +2,38 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
 func (h *handler) AssignMessageGroup(w http.ResponseWriter, r *http.Request) {
 	id := paramID(r)
 	if id == "" {
-		errBadRequest(w, "missing message_id")
+		errBadRequest(w, r, codeMissingField, "missing message_id")
 		return
 	}
 	body, err := decode[struct {
 		GroupID string `json:"group_id"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
 		return
 	}
 	if body.GroupID == "" {
-		errBadRequest(w, "missing group_id")
+		errBadRequest(w, r, codeMissingField, "missing group_id")
 		return
 	}
-	msg, err := h.messagesv2.AssignGroup(r.Context(), id, body.GroupID)
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	msg, err := h.service.AssignMessageGroup(r.Context(), user, id, body.GroupID)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceMessage)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, msg)
@@ -35,80 +42,166 @@ func (h *handler) AssignMessageGroup(w http.ResponseWriter, r *http.Request) {
 func (h *handler) AssignGroupTopic(w http.ResponseWriter, r *http.Request) {
 	id := paramID(r)
 	if id == "" {
-		errBadRequest(w, "missing message_group_id")
+		errBadRequest(w, r, codeMissingField, "missing message_group_id")
 		return
 	}
 	body, err := decode[struct {
 		TopicID string `json:"topic_id"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
 		return
 	}
 	if body.TopicID == "" {
-		errBadRequest(w, "missing topic_id")
+		errBadRequest(w, r, codeMissingField, "missing topic_id")
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
 		return
 	}
-	group, err := h.groups.AssignTopic(r.Context(), id, body.TopicID)
+	group, err := h.service.AssignGroupTopic(r.Context(), user, id, body.TopicID)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceMessageGroup)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, group)
 }
 
+func (h *handler) MergeTopics(w http.ResponseWriter, r *http.Request) {
+	id := paramID(r)
+	if id == "" {
+		errBadRequest(w, r, codeMissingField, "missing topic_id")
+		return
+	}
+	body, err := decode[struct {
+		TargetID string `json:"target_id"`
+	}](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	if body.TargetID == "" {
+		errBadRequest(w, r, codeMissingField, "missing target_id")
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	merge, err := h.service.MergeTopics(r.Context(), user, id, body.TargetID)
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, merge)
+}
+
+func (h *handler) SplitTopic(w http.ResponseWriter, r *http.Request) {
+	id := paramID(r)
+	if id == "" {
+		errBadRequest(w, r, codeMissingField, "missing topic_id")
+		return
+	}
+	body, err := decode[struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		GroupIDs    []string `json:"group_ids"`
+	}](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	if body.Name == "" {
+		errBadRequest(w, r, codeMissingField, "missing name")
+		return
+	}
+	if len(body.GroupIDs) == 0 {
+		errBadRequest(w, r, codeMissingField, "missing group_ids")
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	topic := factcheck.Topic{
+		ID:          utils.NewID().String(),
+		Name:        body.Name,
+		Description: body.Description,
+		Status:      factcheck.StatusTopicPending,
+		CreatedAt:   utils.TimeNow(),
+	}
+	split, err := h.service.SplitTopic(r.Context(), user, id, topic, body.GroupIDs)
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusCreated, split)
+}
+
 func (h *handler) PostAnswer(w http.ResponseWriter, r *http.Request) {
 	data, err := decode[struct {
-		Text string `json:"text"`
+		Text    string            `json:"text"`
+		Verdict factcheck.Verdict `json:"verdict"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	if !data.Verdict.IsValid() {
+		errBadRequest(w, r, codeInvalidVerdict, fmt.Sprintf("invalid verdict '%s'", data.Verdict))
 		return
 	}
 	user, err := h.getUserInfo(r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errAuth(w, r, "missing credentials")
 		return
 	}
-	answer, _, _, err := h.service.Resolve(r.Context(), user, paramID(r), data.Text)
+	// Submitters are notified by the outbox relay, after the published answer is committed
+	answer, _, _, err := h.service.Resolve(r.Context(), user, paramID(r), data.Text, data.Verdict)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, answer)
 }
 
+func (h *handler) ListTopicDeliveries(w http.ResponseWriter, r *http.Request) {
+	getBy(w, r, paramID(r), func(ctx context.Context, id string) ([]factcheck.Delivery, error) {
+		id, err := h.topics.ResolveID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return h.deliveries.ListByTopicID(ctx, id)
+	})
+}
+
 func (h *handler) DeleteTopicByID(w http.ResponseWriter, r *http.Request) {
 	user, err := h.getUserInfo(r)
 	if err != nil {
-		errBadRequest(w, "error getting user info from request")
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	if user.UserID == "" { //nolint
-		errAuth(w)
-		return
-	}
-	if user.UserType != factcheck.TypeUserMessageAdmin {
-		errAuth(w)
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	deleteByID[factcheck.Topic](w, r, func(ctx context.Context, s string) error {
-		return h.topics.Delete(ctx, s)
+		return h.service.DeleteTopic(ctx, user, s)
 	})
 }
 
 func (h *handler) DeleteAnswerByID(w http.ResponseWriter, r *http.Request) {
 	user, err := h.getUserInfo(r)
 	if err != nil {
-		errBadRequest(w, "error getting user info from request")
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	if user.UserID == "" { //nolint
-		errAuth(w)
-		return
-	}
-	if user.UserType != factcheck.TypeUserMessageAdmin {
-		errAuth(w)
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	deleteByID[factcheck.Answer](w, r, func(ctx context.Context, id string) error {
@@ -119,18 +212,14 @@ func (h *handler) DeleteAnswerByID(w http.ResponseWriter, r *http.Request) {
 func (h *handler) DeleteGroupByID(w http.ResponseWriter, r *http.Request) {
 	user, err := h.getUserInfo(r)
 	if err != nil {
-		errBadRequest(w, "error getting user info from request")
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	if user.UserID == "" { //nolint
-		errAuth(w)
-		return
-	}
-	if user.UserType != factcheck.TypeUserMessageAdmin {
-		errAuth(w)
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	deleteByID[factcheck.MessageGroup](w, r, func(ctx context.Context, id string) error {
-		return h.groups.Delete(ctx, id)
+		return h.service.DeleteMessageGroup(ctx, user, id)
 	})
 }
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// ListAuditEvents lists audit events, latest first.
+// Query params actor, target, action, since and until (RFC 3339) are optional filters.
+func (h *handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := limitOffSet(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	opts, err := toAuditOptions(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	events, err := h.audit.ListDynamic(r.Context(), limit, offset, opts...)
+	if err != nil {
+		errInternalError(w, r, err)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, events)
+}
+
+func toAuditOptions(r *http.Request) ([]repo.OptionAudit, error) {
+	query := r.URL.Query().Get
+	actor, target, action := query("actor"), query("target"), query("action")
+	var opts []repo.OptionAudit
+	if actor != "" {
+		opts = append(opts, repo.AuditActorID(actor))
+	}
+	if target != "" {
+		opts = append(opts, repo.AuditTargetID(target))
+	}
+	if action != "" {
+		opts = append(opts, repo.AuditAction(factcheck.ActionAudit(action)))
+	}
+	if since := query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, fmt.Errorf("bad query since: '%s'", since)
+		}
+		opts = append(opts, repo.AuditSince(t))
+	}
+	if until := query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("bad query until: '%s'", until)
+		}
+		opts = append(opts, repo.AuditUntil(t))
+	}
+	return opts, nil
+}
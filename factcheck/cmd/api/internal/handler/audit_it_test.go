@@ -0,0 +1,124 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerAudit(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	do := func(t *testing.T, method, path string, body any, requestID string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), method, testServer.URL+path, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		req.Header.Set("Content-Type", "application/json")
+		if requestID != "" {
+			req.Header.Set("X-Request-Id", requestID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	listAudit := func(t *testing.T, query url.Values) []factcheck.AuditEvent {
+		t.Helper()
+		resp := do(t, http.MethodGet, "/admin/audit?"+query.Encode(), nil, "")
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		events := []factcheck.AuditEvent{}
+		err := json.NewDecoder(resp.Body).Decode(&events)
+		assertEq(t, err, nil)
+		return events
+	}
+
+	resp := do(t, http.MethodPost, "/topics/", map[string]string{"name": "lemon soda", "description": "cures cancer"}, "req-create")
+	assertEq(t, resp.StatusCode, http.StatusCreated)
+	topic := factcheck.Topic{}
+	err = json.NewDecoder(resp.Body).Decode(&topic)
+	assertEq(t, err, nil)
+
+	resp = do(t, http.MethodPut, "/topics/"+topic.ID+"/name", map[string]string{"name": "lemon soda 2"}, "req-rename")
+	assertEq(t, resp.StatusCode, http.StatusOK)
+
+	resp = do(t, http.MethodDelete, "/topics/"+topic.ID, nil, "req-delete")
+	assertEq(t, resp.StatusCode, http.StatusOK)
+
+	t.Run("all events of target, latest first", func(t *testing.T) {
+		events := listAudit(t, url.Values{"target": {topic.ID}})
+		assertEq(t, len(events), 3)
+		expected := []struct {
+			action    factcheck.ActionAudit
+			requestID string
+			before    bool
+			after     bool
+		}{
+			{factcheck.ActionAuditTopicDelete, "req-delete", true, false},
+			{factcheck.ActionAuditTopicUpdateName, "req-rename", true, true},
+			{factcheck.ActionAuditTopicCreate, "req-create", false, true},
+		}
+		// Events share the same frozen timestamp, so we look them up by action instead of relying on order
+		byAction := make(map[factcheck.ActionAudit]factcheck.AuditEvent)
+		for i := range events {
+			byAction[events[i].Action] = events[i]
+		}
+		for _, e := range expected {
+			event, ok := byAction[e.action]
+			if !ok {
+				t.Fatalf("missing audit event %s", e.action)
+			}
+			assertEq(t, event.Actor, factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "factcheck-test"})
+			assertEq(t, event.TargetType, factcheck.TypeTargetTopic)
+			assertEq(t, event.TargetIDs[0], topic.ID)
+			assertEq(t, event.RequestID, e.requestID)
+			assertEq(t, string(event.Before) != "null", e.before)
+			assertEq(t, string(event.After) != "null", e.after)
+		}
+
+		renamed := factcheck.Topic{}
+		err := json.Unmarshal(byAction[factcheck.ActionAuditTopicUpdateName].After, &renamed)
+		assertEq(t, err, nil)
+		assertEq(t, renamed.Name, "lemon soda 2")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		events := listAudit(t, url.Values{"action": {string(factcheck.ActionAuditTopicDelete)}})
+		assertEq(t, len(events), 1)
+		events = listAudit(t, url.Values{"actor": {"someone-else"}})
+		assertEq(t, len(events), 0)
+		events = listAudit(t, url.Values{"since": {now.Add(time.Second).Format(time.RFC3339)}})
+		assertEq(t, len(events), 0)
+		events = listAudit(t, url.Values{"until": {now.Add(time.Second).Format(time.RFC3339)}})
+		assertEq(t, len(events), 3)
+		resp := do(t, http.MethodGet, "/admin/audit?since=yesterday", nil, "")
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("append-only", func(t *testing.T) {
+		_, err := app.PostgresConn.Exec(t.Context(), "UPDATE audit_events SET actor_id = 'someone-else'")
+		assertNeq(t, err, nil)
+		_, err = app.PostgresConn.Exec(t.Context(), "DELETE FROM audit_events")
+		assertNeq(t, err, nil)
+	})
+}
@@ -2,10 +2,21 @@ package handler
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
+// defaultPageLimit limits pages when query limit is not given
+const defaultPageLimit = 20
+
+// paginated returns whether r lists with cursor, i.e. has query cursor.
+// First pages are requested with empty cursor, e.g. ?cursor=&limit=10.
+// Requests without cursor keep listing with limit and offset, and get bare arrays instead of repo.Page.
+func paginated(r *http.Request) bool {
+	return r.URL.Query().Has("cursor")
+}
+
 func list[T any](
 	w http.ResponseWriter,
 	r *http.Request,
@@ -13,12 +24,39 @@ func list[T any](
 ) {
 	l, err := listFn(r.Context())
 	if err != nil {
-		errInternalError(w, err.Error())
+		errInternalError(w, r, err)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, l)
 }
 
+// page uses pageFn to list a page from query cursor and limit
+func page[T any](
+	w http.ResponseWriter,
+	r *http.Request,
+	pageFn func(ctx context.Context, limit int, cursor repo.Cursor) (repo.Page[T], error),
+) {
+	cursor, err := repo.ParseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	limit, _, err := limitOffSet(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	p, err := pageFn(r.Context(), limit, cursor)
+	if err != nil {
+		handleError(w, r, err, "")
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, p)
+}
+
 // getBy uses getFn to get a T based on filter F.
 func getBy[T any, F any](
 	w http.ResponseWriter,
@@ -28,7 +66,7 @@ func getBy[T any, F any](
 ) {
 	data, err := getFn(r.Context(), filter)
 	if err != nil {
-		handleNotFound(w, err, "resource", fmt.Sprintf("%+v", filter))
+		handleError(w, r, err, resourceOf[T]())
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, data)
@@ -41,12 +79,12 @@ func deleteByID[T any](
 ) {
 	id := paramID(r)
 	if id == "" {
-		errBadRequest(w, "empty id")
+		errBadRequest(w, r, codeMissingField, "empty id")
 		return
 	}
 	err := deleteFn(r.Context(), id)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceOf[T]())
 		return
 	}
 	sendText(r.Context(), w, "ok", http.StatusOK)
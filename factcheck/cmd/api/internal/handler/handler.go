@@ -3,7 +3,6 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,7 +10,11 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -31,6 +34,10 @@ type Handler interface {
 	UpdateTopicName(http.ResponseWriter, *http.Request)
 	ListTopicMessages(http.ResponseWriter, *http.Request)
 	ListTopicMessageGroups(http.ResponseWriter, *http.Request)
+	ListAnswerSources(http.ResponseWriter, *http.Request)
+	CreateSource(http.ResponseWriter, *http.Request)
+	UpdateSource(http.ResponseWriter, *http.Request)
+	DeleteSource(http.ResponseWriter, *http.Request)
 
 	// API /messages
 	SubmitMessage(http.ResponseWriter, *http.Request)
@@ -39,50 +46,80 @@ type Handler interface {
 
 	// API /groups
 	ListMessageGroupDynamic(http.ResponseWriter, *http.Request)
+	ListSimilarGroups(http.ResponseWriter, *http.Request)
 	AssignGroupTopic(http.ResponseWriter, *http.Request)
+	ApproveGroup(http.ResponseWriter, *http.Request)
+	RejectGroup(http.ResponseWriter, *http.Request)
 	DeleteGroupByID(http.ResponseWriter, *http.Request)
 
+	// API /search
+	Search(http.ResponseWriter, *http.Request)
+
 	// API for admin
 	PostAnswer(w http.ResponseWriter, r *http.Request)
+	MergeTopics(w http.ResponseWriter, r *http.Request)
+	SplitTopic(w http.ResponseWriter, r *http.Request)
+	DraftAnswer(w http.ResponseWriter, r *http.Request)
+	UpdateDraftAnswer(w http.ResponseWriter, r *http.Request)
+	PublishAnswer(w http.ResponseWriter, r *http.Request)
+	ReopenTopic(w http.ResponseWriter, r *http.Request)
+	ListAnswerRevisions(w http.ResponseWriter, r *http.Request)
+	DiffAnswerRevisions(w http.ResponseWriter, r *http.Request)
+	ListTopicDeliveries(w http.ResponseWriter, r *http.Request)
+	ListRoles(w http.ResponseWriter, r *http.Request)
+	GetRole(w http.ResponseWriter, r *http.Request)
+	PutRole(w http.ResponseWriter, r *http.Request)
+	DeleteRole(w http.ResponseWriter, r *http.Request)
+	ListAuditEvents(w http.ResponseWriter, r *http.Request)
+
+	// API /line
+	LINEWebhook(http.ResponseWriter, *http.Request)
 }
 
 type handler struct {
+	line       config.LINE
+	lineClient *line.Client
+	notices    *ratelimit.Notices // Rate-limited LINE chats told to wait
+	detector   language.Detector
+	dedup      config.Dedup
 	repository repo.Repository
 	service    core.Service
 	topics     repo.Topics
 	messagesv2 repo.MessagesV2
 	groups     repo.MessageGroups
 	answers    repo.Answers
+	sources    repo.Sources
+	deliveries repo.Deliveries
+	roles      repo.Roles
+	audit      repo.Audit
+	search     repo.Search
 }
 
 func New(
+	conf config.Config,
 	repo repo.Repository,
 	core core.Service,
 ) Handler {
 	return &handler{
+		line:       conf.LINE,
+		lineClient: line.NewClient(conf.LINE.Endpoint, conf.LINE.ChannelAccessToken),
+		notices:    ratelimit.NewNotices(),
+		detector:   language.NewDetectorScript(),
+		dedup:      conf.Dedup,
 		repository: repo,
 		service:    core,
 		topics:     repo.Topics,
 		messagesv2: repo.MessagesV2,
 		groups:     repo.MessageGroups,
 		answers:    repo.Answers,
+		sources:    repo.Sources,
+		deliveries: repo.Deliveries,
+		roles:      repo.Roles,
+		audit:      repo.Audit,
+		search:     repo.Search,
 	}
 }
 
-// handleNotFound standardizes not-found error handling in handlers
-func handleNotFound(w http.ResponseWriter, err error, resourceType string, filter string) {
-	if repo.IsNotFound(err) {
-		var notFoundErr *repo.ErrNotFound
-		if errors.As(err, &notFoundErr) {
-			errNotFound(w, notFoundErr.Error())
-			return
-		}
-		errNotFound(w, fmt.Sprintf("%s not found: %s", resourceType, filter))
-		return
-	}
-	errInternalError(w, err.Error())
-}
-
 func paramID(r *http.Request) string {
 	return chi.URLParam(r, "id")
 }
@@ -127,11 +164,17 @@ func sendText(ctx context.Context, w http.ResponseWriter, text string, status in
 	}
 }
 
-// sendJSON calls replyJsonError, and on non-nil error, writes 500 response
+// sendJSON calls replyJSON, and on non-nil error, writes 500 problem response
 func sendJSON(ctx context.Context, w http.ResponseWriter, status int, data any) {
 	err := replyJSON(ctx, w, data, status)
 	if err != nil {
-		errInternalError(w, err.Error())
+		slog.ErrorContext(ctx, "error replying json", "err", err)
+		sendProblem(ctx, w, problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Code:   codeInternalError,
+		})
 	}
 }
 
@@ -153,29 +196,6 @@ func replyJSON(ctx context.Context, w http.ResponseWriter, data any, status int)
 	return nil
 }
 
-func errNotFound(w http.ResponseWriter, id string) {
-	w.WriteHeader(http.StatusNotFound)
-	contentTypeText(w.Header())
-	fmt.Fprintf(w, "not found: %s", id)
-}
-
-func errInternalError(w http.ResponseWriter, err string) {
-	w.WriteHeader(http.StatusInternalServerError)
-	contentTypeText(w.Header())
-	fmt.Fprintf(w, "server error: %s", err)
-}
-
-func errBadRequest(w http.ResponseWriter, err string) {
-	w.WriteHeader(http.StatusBadRequest)
-	contentTypeText(w.Header())
-	fmt.Fprintf(w, "bad request: %s", err)
-}
-
-func errAuth(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusUnauthorized)
-	contentTypeText(w.Header())
-}
-
 func contentTypeJSON(h http.Header) {
 	h.Add("Content-Type", "application/json; charset=utf-8")
 }
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+)
+
+// HandlerLivez reports that the server is alive, without checking any dependencies,
+// so that the server is not restarted only because Postgres is down.
+func HandlerLivez(w http.ResponseWriter, r *http.Request) {
+	sendJSON(r.Context(), w, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// HandlerReadyz reports results of checks by c.
+// It responds 503 with the same report if any check fails,
+// and 200 if only optional checks of integrations fail.
+func HandlerReadyz(c health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		switch report.Status {
+		case health.StatusFail:
+			slog.WarnContext(r.Context(), "not ready", "checks", report.Checks)
+			sendJSON(r.Context(), w, http.StatusServiceUnavailable, report)
+			return
+		case health.StatusDegraded:
+			slog.WarnContext(r.Context(), "ready but degraded", "checks", report.Checks)
+		}
+		sendJSON(r.Context(), w, http.StatusOK, report)
+	}
+}
@@ -6,7 +6,11 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"net/http"
 	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 )
 
 func assertEq[X comparable](t *testing.T, actual, expected X) {
@@ -34,3 +38,14 @@ func reqBodyJSON(data any) *bytes.Buffer {
 	}
 	return buf
 }
+
+// authorized authenticates requests to h with the test API key,
+// unless the request already carries credentials
+func authorized(conf config.Config, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(auth.HeaderAPIKey) == "" && r.Header.Get(auth.HeaderAuthorization) == "" {
+			r.Header.Set(auth.HeaderAPIKey, conf.Auth.APIKeys["factcheck-test"])
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+)
+
+// maxBytesLINEWebhook limits webhook body size, LINE webhooks are usually tiny
+const maxBytesLINEWebhook = 1 << 20
+
+// LINEWebhook receives LINE Messaging API webhook events,
+// and submits every text message event with core.Service.SubmitLINE.
+// Rate-limited events are replied to with how long to wait, once per limited user or chat.
+//
+// Once the signature is verified, bad events are only logged, and LINE gets 500 only if
+// submitting failed, so that LINE redelivers the events. Events already submitted are skipped by their IDs.
+func (h *handler) LINEWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytesLINEWebhook))
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	err = line.VerifySignature(h.line.ChannelSecret, body, r.Header.Get(line.HeaderSignature))
+	if err != nil {
+		slog.WarnContext(ctx, "bad line webhook signature", "err", err)
+		errAuth(w, r, "bad signature")
+		return
+	}
+	webhook, err := line.ParseWebhook(body)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	var errs []error
+	for i := range webhook.Events {
+		event := &webhook.Events[i]
+		text, ok := event.Text()
+		if !ok {
+			slog.DebugContext(ctx, "skipping line event",
+				"type", event.Type,
+				"webhook_event_id", event.WebhookEventID,
+			)
+			continue
+		}
+		user, err := event.Source.UserInfo()
+		if err != nil {
+			slog.ErrorContext(ctx, "error mapping line event source",
+				"err", err,
+				"webhook_event_id", event.WebhookEventID,
+			)
+			continue
+		}
+		msg, group, _, err := h.service.SubmitLINE(ctx, user, text, event.WebhookEventID)
+		if errors.Is(err, core.ErrDuplicateEvent) {
+			slog.InfoContext(ctx, "skipping line event already submitted",
+				"webhook_event_id", event.WebhookEventID,
+				"is_redelivery", event.DeliveryContext.IsRedelivery,
+			)
+			continue
+		}
+		if limited, ok := ratelimit.IsLimited(err); ok {
+			slog.InfoContext(ctx, "rate limited line message",
+				"key", limited.Key,
+				"retry_after", limited.RetryAfter,
+				"webhook_event_id", event.WebhookEventID,
+			)
+			h.replyLimited(ctx, event, text, limited)
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error submitting line message",
+				"err", err,
+				"user", user,
+				"webhook_event_id", event.WebhookEventID,
+			)
+			errs = append(errs, err)
+			continue
+		}
+		slog.InfoContext(ctx, "submitted line message",
+			"mid", msg.ID,
+			"gid", group.ID,
+			"webhook_event_id", event.WebhookEventID,
+		)
+	}
+	if len(errs) > 0 {
+		errInternalError(w, r, errors.Join(errs...))
+		return
+	}
+	sendText(ctx, w, "ok", http.StatusOK)
+}
+
+// replyLimited replies to event asking users to wait, in language of their text.
+// Only the first limited event of each key is replied to until the key can retry,
+// so that floods do not cause floods of replies.
+func (h *handler) replyLimited(ctx context.Context, event *line.Event, text string, limited *ratelimit.ErrLimited) {
+	if event.ReplyToken == "" || !h.notices.First(limited) {
+		return
+	}
+	err := h.lineClient.Reply(ctx, event.ReplyToken, line.Text(textLimited(h.detector.Detect(text), retrySeconds(limited))))
+	if err != nil {
+		slog.WarnContext(ctx, "error replying to rate limited line message",
+			"err", err,
+			"key", limited.Key,
+			"webhook_event_id", event.WebhookEventID,
+		)
+	}
+}
+
+// textLimited asks users to wait seconds before sending more messages
+func textLimited(lang factcheck.Language, seconds int) string {
+	if lang == factcheck.LanguageThai {
+		return fmt.Sprintf("คุณส่งข้อความเร็วเกินไป กรุณารอ %d วินาทีแล้วลองใหม่อีกครั้ง", seconds)
+	}
+	return fmt.Sprintf("You are sending messages too quickly, please wait %d seconds and try again", seconds)
+}
@@ -0,0 +1,250 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// serviceSubmitRecorder records Submit and SubmitLINE calls,
+// and rejects events already submitted without err.
+// Calling other methods of core.Service will panic.
+type serviceSubmitRecorder struct {
+	core.Service
+
+	mut       sync.Mutex
+	submitted []submission
+	events    map[string]bool
+	err       error
+}
+
+type submission struct {
+	user factcheck.UserInfo
+	text string
+}
+
+func (s *serviceSubmitRecorder) Submit(_ context.Context, user factcheck.UserInfo, text string, _ string) (factcheck.MessageV2, factcheck.MessageGroup, *factcheck.Topic, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.submitted = append(s.submitted, submission{user: user, text: text})
+	return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, s.err
+}
+
+func (s *serviceSubmitRecorder) SubmitLINE(_ context.Context, user factcheck.UserInfo, text string, webhookEventID string) (factcheck.MessageV2, factcheck.MessageGroup, *factcheck.Topic, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.events[webhookEventID] {
+		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, core.ErrDuplicateEvent
+	}
+	s.submitted = append(s.submitted, submission{user: user, text: text})
+	if s.err == nil {
+		if s.events == nil {
+			s.events = make(map[string]bool)
+		}
+		s.events[webhookEventID] = true
+	}
+	return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, s.err
+}
+
+func TestLINEWebhook(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(t *testing.T, h http.Handler, body []byte, signature string) int {
+		t.Helper()
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/line/webhook", bytes.NewReader(body))
+		req.Header.Set(line.HeaderSignature, signature)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	sign := func(body []byte) string {
+		return base64.StdEncoding.EncodeToString(line.Sign(conf.LINE.ChannelSecret, body))
+	}
+	newServer := func() (http.Handler, *serviceSubmitRecorder) {
+		service := &serviceSubmitRecorder{}
+		srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, service), auth.Chain{}, auth.Authorizer{}, health.Checker{}, nil)
+		return srv.Handler, service
+	}
+
+	t.Run("verify webhook with empty events", func(t *testing.T) {
+		h, service := newServer()
+		body := testdata(t, "line_webhook_verify.json")
+		code := post(t, h, body, sign(body))
+		if code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(service.submitted) != 0 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+
+	t.Run("1:1 chat", func(t *testing.T) {
+		h, service := newServer()
+		body := testdata(t, "line_webhook_chat.json")
+		code := post(t, h, body, sign(body))
+		if code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(service.submitted) != 1 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+		expected := submission{
+			user: factcheck.UserInfo{
+				UserType: factcheck.TypeUserMessageLINEChat,
+				UserID:   "U4af4980629b1c5d3e2f6a7b8c9d0e1f2",
+				ChatID:   "U4af4980629b1c5d3e2f6a7b8c9d0e1f2",
+			},
+			text: "ดื่มน้ำมะนาวผสมโซดาช่วยรักษามะเร็งได้",
+		}
+		if service.submitted[0] != expected {
+			t.Fatalf("unexpected submission: %+v", service.submitted[0])
+		}
+	})
+
+	t.Run("group chat with non-text events", func(t *testing.T) {
+		h, service := newServer()
+		body := testdata(t, "line_webhook_group.json")
+		code := post(t, h, body, sign(body))
+		if code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(service.submitted) != 1 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+		expected := submission{
+			user: factcheck.UserInfo{
+				UserType: factcheck.TypeUserMessageLINEGroupChat,
+				UserID:   "U4af4980629b1c5d3e2f6a7b8c9d0e1f2",
+				ChatID:   "Ca56f94637c1d2e3f4a5b6c7d8e9f0a1b",
+			},
+			text: "Drinking hot water every 15 minutes kills the virus",
+		}
+		if service.submitted[0] != expected {
+			t.Fatalf("unexpected submission: %+v", service.submitted[0])
+		}
+	})
+
+	t.Run("redelivered events", func(t *testing.T) {
+		h, service := newServer()
+		body := testdata(t, "line_webhook_chat.json")
+		// Internal errors get LINE to redeliver
+		service.err = errors.New("some error")
+		code := post(t, h, body, sign(body))
+		if code != http.StatusInternalServerError {
+			t.Fatalf("unexpected status %d of failed submission", code)
+		}
+		service.err = nil
+		for range 2 {
+			code = post(t, h, body, sign(body))
+			if code != http.StatusOK {
+				t.Fatalf("unexpected status %d", code)
+			}
+		}
+		// Redelivered event is submitted again only after failure
+		if len(service.submitted) != 2 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		utils.TimeFreeze(now)
+		defer utils.TimeUnfreeze()
+
+		var (
+			mut     sync.Mutex
+			replies []lineReply
+		)
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reply lineReply
+			err := json.NewDecoder(r.Body).Decode(&reply)
+			if err != nil || r.URL.Path != "/v2/bot/message/reply" {
+				t.Errorf("unexpected request to %s: %v", r.URL.Path, err)
+			}
+			mut.Lock()
+			defer mut.Unlock()
+			replies = append(replies, reply)
+		}))
+		defer api.Close()
+		conf := conf
+		conf.LINE.Endpoint = api.URL
+		conf.LINE.ChannelAccessToken = "token"
+		service := &serviceSubmitRecorder{err: &ratelimit.ErrLimited{Key: "user:USER_CHAT:U1", RetryAfter: 1500 * time.Millisecond}}
+		srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, service), auth.Chain{}, auth.Authorizer{}, health.Checker{}, nil)
+
+		// Floods get a single reply until users can retry
+		body := testdata(t, "line_webhook_chat.json")
+		for range 3 {
+			code := post(t, srv.Handler, body, sign(body))
+			if code != http.StatusOK {
+				t.Fatalf("unexpected status %d", code)
+			}
+		}
+		utils.TimeFreeze(now.Add(2 * time.Second))
+		code := post(t, srv.Handler, body, sign(body))
+		if code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+
+		if len(service.submitted) != 4 || len(replies) != 2 {
+			t.Fatalf("unexpected %d submissions and replies %+v", len(service.submitted), replies)
+		}
+		expected := "คุณส่งข้อความเร็วเกินไป กรุณารอ 2 วินาทีแล้วลองใหม่อีกครั้ง"
+		reply := replies[0]
+		if reply.ReplyToken != "38ef843bde154d9b91c21320ffd17a0f" || len(reply.Messages) != 1 || reply.Messages[0].Text != expected {
+			t.Fatalf("unexpected reply: %+v", reply)
+		}
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		h, service := newServer()
+		body := testdata(t, "line_webhook_chat.json")
+		signature := sign([]byte("some other body"))
+		for _, s := range []string{"", "not-base64!", signature} {
+			code := post(t, h, body, s)
+			if code != http.StatusUnauthorized {
+				t.Fatalf("unexpected status %d for signature '%s'", code, s)
+			}
+		}
+		if len(service.submitted) != 0 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+}
+
+type lineReply struct {
+	ReplyToken string             `json:"replyToken"`
+	Messages   []line.TextMessage `json:"messages"`
+}
+
+func testdata(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -1,31 +1,88 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
 // ListMessageGroupDynamic implements Handler.
 func (h *handler) ListMessageGroupDynamic(w http.ResponseWriter, r *http.Request) {
+	if paginated(r) {
+		opts := toMessageGroupOptions(r)
+		page(w, r, func(ctx context.Context, limit int, cursor repo.Cursor) (repo.Page[factcheck.MessageGroup], error) {
+			return h.groups.ListDynamicPage(ctx, limit, cursor, opts...)
+		})
+		return
+	}
 	limit, offset, err := limitOffSet(r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
 		return
 	}
 	opts := toMessageGroupOptions(r)
 	topics, err := h.groups.ListDynamic(r.Context(), limit, offset, opts...)
 	if err != nil {
-		errInternalError(w, err.Error())
+		errInternalError(w, r, err)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topics)
 }
 
+// ListSimilarGroups lists groups similar to the group, proposed for merging by moderators.
+// Similarity threshold defaults to config.Dedup.ThresholdPropose.
+// Only groups created within config.Dedup.Window and not rejected are listed.
+func (h *handler) ListSimilarGroups(w http.ResponseWriter, r *http.Request) {
+	id := paramID(r)
+	limit, _, err := limitOffSet(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = 10
+	}
+	threshold := h.dedup.ThresholdPropose
+	if q := r.URL.Query().Get("threshold"); q != "" {
+		threshold, err = strconv.ParseFloat(q, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			errBadRequest(w, r, codeInvalidQuery, fmt.Sprintf("bad query threshold: '%s'", q))
+			return
+		}
+	}
+	group, err := h.groups.GetByID(r.Context(), id)
+	if err != nil {
+		handleError(w, r, err, resourceMessageGroup)
+		return
+	}
+	result := []factcheck.MessageGroupSimilar{}
+	if group.TextSimHash == 0 {
+		// Groups created before SimHash was introduced
+		sendJSON(r.Context(), w, http.StatusOK, result)
+		return
+	}
+	similar, err := h.groups.ListSimilar(r.Context(), group.TextSimHash, threshold, utils.TimeNow().Add(-h.dedup.Window()), limit+1)
+	if err != nil {
+		errInternalError(w, r, err)
+		return
+	}
+	for i := range similar {
+		if similar[i].ID != group.ID && len(result) < limit {
+			result = append(result, similar[i])
+		}
+	}
+	sendJSON(r.Context(), w, http.StatusOK, result)
+}
+
 func toMessageGroupOptions(r *http.Request) []repo.OptionMessageGroup {
 	query := r.URL.Query().Get
-	text, idIn, idNotIn := query("like_message_text"), query("in_id"), query("not_in_id")
+	text, idIn, idNotIn, statuses, languages := query("like_message_text"), query("in_id"), query("not_in_id"), query("in_statuses"), query("in_languages")
 
 	var opts []repo.OptionMessageGroup
 
@@ -43,5 +100,59 @@ func toMessageGroupOptions(r *http.Request) []repo.OptionMessageGroup {
 		opts = append(opts, repo.MessageGroupIDNotIn(parts))
 	}
 
+	if statuses != "" {
+		parts := strings.Split(statuses, ",")
+		opts = append(opts, repo.MessageGroupInStatuses(utils.MapNoError(parts, utils.String[string, factcheck.StatusMGroup])))
+	}
+
+	if languages != "" {
+		parts := strings.Split(languages, ",")
+		opts = append(opts, repo.MessageGroupInLanguages(utils.MapNoError(parts, utils.String[string, factcheck.Language])))
+	}
+
 	return opts
 }
+
+func (h *handler) ApproveGroup(w http.ResponseWriter, r *http.Request) {
+	h.moderateGroup(w, r, false, h.service.ApproveGroup)
+}
+
+// RejectGroup rejects a message group, and requires a reason
+func (h *handler) RejectGroup(w http.ResponseWriter, r *http.Request) {
+	h.moderateGroup(w, r, true, h.service.RejectGroup)
+}
+
+func (h *handler) moderateGroup(
+	w http.ResponseWriter,
+	r *http.Request,
+	requireReason bool,
+	moderate func(ctx context.Context, user factcheck.UserInfo, id string, reason string) (factcheck.MessageGroup, error),
+) {
+	id := paramID(r)
+	if id == "" {
+		errBadRequest(w, r, codeMissingField, "missing message_group_id")
+		return
+	}
+	body, err := decode[struct {
+		Reason string `json:"reason"`
+	}](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	if requireReason && body.Reason == "" {
+		errBadRequest(w, r, codeMissingField, "missing reason")
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	group, err := moderate(r.Context(), user, id, body.Reason)
+	if err != nil {
+		handleError(w, r, err, resourceMessageGroup)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, group)
+}
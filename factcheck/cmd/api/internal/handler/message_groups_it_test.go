@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
@@ -22,7 +23,7 @@ func TestHandlerMessageGroup_ListMessageGroupDynamic(t *testing.T) {
 	defer cleanup()
 
 	// Create test server
-	testServer := httptest.NewServer(app.Server.(*http.Server).Handler)
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
 	defer testServer.Close()
 
 	// Create test data
@@ -223,6 +224,39 @@ func TestHandlerMessageGroup_ListMessageGroupDynamic(t *testing.T) {
 		assertEq(t, len(messageGroups), 2)
 	})
 
+	t.Run("ListMessageGroupDynamic - in_statuses filter", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups?in_statuses=MGROUP_PENDING,MGROUP_REJECTED", nil)
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var messageGroups []factcheck.MessageGroup
+		err = json.NewDecoder(resp.Body).Decode(&messageGroups)
+		assertEq(t, err, nil)
+		assertEq(t, len(messageGroups), 3)
+		for i := range messageGroups {
+			assertNeq(t, messageGroups[i].Status, factcheck.StatusMGroupApproved)
+		}
+	})
+
+	t.Run("ListMessageGroupDynamic - in_languages filter", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups?in_languages=th", nil)
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var messageGroups []factcheck.MessageGroup
+		err = json.NewDecoder(resp.Body).Decode(&messageGroups)
+		assertEq(t, err, nil)
+		assertEq(t, len(messageGroups), 1)
+		assertEq(t, messageGroups[0].ID, createdMessageGroup4.ID)
+		assertEq(t, messageGroups[0].Language, factcheck.LanguageThai)
+	})
+
 	t.Run("ListMessageGroupDynamic - no results", func(t *testing.T) {
 		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups?like_message_text=nonexistent", nil)
 		assertEq(t, err, nil)
@@ -237,3 +271,220 @@ func TestHandlerMessageGroup_ListMessageGroupDynamic(t *testing.T) {
 		assertEq(t, len(messageGroups), 0)
 	})
 }
+
+func TestHandlerMessageGroup_Moderation(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	topic, err := app.Repository.Topics.Create(t.Context(), factcheck.Topic{
+		ID:        utils.NewID().String(),
+		Name:      "topic",
+		Status:    factcheck.StatusTopicPending,
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	group, err := app.Repository.MessageGroups.Create(t.Context(), factcheck.MessageGroup{
+		ID:        utils.NewID().String(),
+		TopicID:   topic.ID,
+		Name:      "group",
+		Text:      "lemon soda cures cancer",
+		TextSHA1:  factcheck.SHA1("lemon soda cures cancer"),
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+	assertEq(t, group.Status, factcheck.StatusMGroupPending)
+	message, err := app.Repository.MessagesV2.Create(t.Context(), factcheck.MessageV2{
+		ID:          utils.NewID().String(),
+		GroupID:     group.ID,
+		TopicID:     topic.ID,
+		UserID:      "U1",
+		TypeUser:    factcheck.TypeUserMessageLINEChat,
+		TypeMessage: factcheck.TypeMessageText,
+		Text:        group.Text,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+
+	put := func(t *testing.T, path string, body any) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPut, testServer.URL+path, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		return resp
+	}
+
+	t.Run("reject requires reason", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+group.ID+"/reject", map[string]string{})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("reject unassigns topic", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+group.ID+"/reject", map[string]string{"reason": "spam"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var rejected factcheck.MessageGroup
+		err := json.NewDecoder(resp.Body).Decode(&rejected)
+		assertEq(t, err, nil)
+		assertEq(t, rejected.Status, factcheck.StatusMGroupRejected)
+		assertEq(t, rejected.StatusReason, "spam")
+		assertEq(t, rejected.TopicID, "")
+
+		// Messages of rejected groups are no longer messages of the topic
+		unassigned, err := app.Repository.MessagesV2.GetByID(t.Context(), message.ID)
+		assertEq(t, err, nil)
+		assertEq(t, unassigned.TopicID, "")
+		messages, err := app.Repository.MessagesV2.ListByTopic(t.Context(), topic.ID)
+		assertEq(t, err, nil)
+		assertEq(t, len(messages), 0)
+	})
+
+	t.Run("rejected group cannot be assigned to topic", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+group.ID+"/assign-topic", map[string]string{"topic_id": topic.ID})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+	})
+
+	t.Run("rejected group cannot be rejected again", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+group.ID+"/reject", map[string]string{"reason": "spam"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+	})
+
+	t.Run("approved group can be assigned to topic", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+group.ID+"/approve", map[string]string{"reason": "legit claim"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		resp = put(t, "/message-groups/"+group.ID+"/assign-topic", map[string]string{"topic_id": topic.ID})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var assigned factcheck.MessageGroup
+		err := json.NewDecoder(resp.Body).Decode(&assigned)
+		assertEq(t, err, nil)
+		assertEq(t, assigned.Status, factcheck.StatusMGroupApproved)
+		assertEq(t, assigned.TopicID, topic.ID)
+	})
+
+	t.Run("missing group", func(t *testing.T) {
+		resp := put(t, "/message-groups/"+utils.NewID().String()+"/approve", map[string]string{})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+	})
+}
+
+func TestHandlerMessageGroup_NearDuplicates(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	submit := func(t *testing.T, text string) factcheck.MessageGroup {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, testServer.URL+"/messages/", reqBodyJSON(map[string]string{"text": text}))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusCreated)
+		var body struct {
+			Group factcheck.MessageGroup `json:"group"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		assertEq(t, err, nil)
+		return body.Group
+	}
+
+	original := submit(t, "ข่าวด่วน!! น้ำมะนาวโซดา รักษามะเร็งได้ แชร์ต่อด่วน 🍋")
+
+	t.Run("normalized duplicate joins group", func(t *testing.T) {
+		group := submit(t, "ข่าวด่วน!!  น\u0e49\u0e4d\u0e32มะนาวโซดา\u200b รักษามะเร็งได้ แชร์ต่อด่วน 🍋")
+		assertEq(t, group.ID, original.ID)
+	})
+
+	t.Run("near duplicate joins group", func(t *testing.T) {
+		group := submit(t, "ข่าวด่วน น้ำมะนาวโซดา รักษามะเร็งได้ แชร์ต่อด่วน 🥤🥤🙏")
+		assertEq(t, group.ID, original.ID)
+	})
+
+	unrelated := submit(t, "วัคซีนโควิดทำให้เกิดโรคหัวใจในเด็กทุกคน")
+
+	t.Run("unrelated text creates new group", func(t *testing.T) {
+		assertNeq(t, unrelated.ID, original.ID)
+	})
+
+	t.Run("similar groups", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups/"+unrelated.ID+"/similar?threshold=0", nil)
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var similar []factcheck.MessageGroupSimilar
+		err = json.NewDecoder(resp.Body).Decode(&similar)
+		assertEq(t, err, nil)
+		assertEq(t, len(similar), 1)
+		assertEq(t, similar[0].ID, original.ID)
+
+		req, err = http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups/"+unrelated.ID+"/similar", nil)
+		assertEq(t, err, nil)
+		resp, err = http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		err = json.NewDecoder(resp.Body).Decode(&similar)
+		assertEq(t, err, nil)
+		assertEq(t, len(similar), 0)
+	})
+
+	t.Run("rejected and old groups are not similar", func(t *testing.T) {
+		list := func(t *testing.T) []factcheck.MessageGroupSimilar {
+			t.Helper()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/message-groups/"+unrelated.ID+"/similar?threshold=0", nil)
+			assertEq(t, err, nil)
+			resp, err := http.DefaultClient.Do(req)
+			assertEq(t, err, nil)
+			defer resp.Body.Close()
+			assertEq(t, resp.StatusCode, http.StatusOK)
+			var similar []factcheck.MessageGroupSimilar
+			err = json.NewDecoder(resp.Body).Decode(&similar)
+			assertEq(t, err, nil)
+			return similar
+		}
+		other := submit(t, "ดื่มน้ำร้อนทุก 15 นาทีฆ่าไวรัสได้")
+		_, err := app.Service.RejectGroup(t.Context(), factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "factcheck-test"}, original.ID, "spam")
+		assertEq(t, err, nil)
+		similar := list(t)
+		assertEq(t, len(similar), 1)
+		assertEq(t, similar[0].ID, other.ID)
+
+		utils.TimeFreeze(utils.TimeNow().Add(app.Config.Dedup.Window() + time.Hour))
+		defer utils.TimeUnfreeze()
+		assertEq(t, len(list(t)), 0)
+	})
+}
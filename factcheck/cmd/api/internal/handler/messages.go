@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,13 +10,13 @@ import (
 	"github.com/kaogeek/line-fact-check/factcheck"
 )
 
-// TODO: use middleware to parse the metadata and save it to req context
-// when doing auth
-func (h *handler) getUserInfo(_ *http.Request) (factcheck.UserInfo, error) {
-	return factcheck.UserInfo{
-		UserType: factcheck.TypeUserMessageAdmin,
-		UserID:   "user-mock-getuserinfo",
-	}, nil
+// getUserInfo returns user authenticated by MiddlewareAuth
+func (h *handler) getUserInfo(r *http.Request) (factcheck.UserInfo, error) {
+	user, ok := r.Context().Value(CtxKeyUserInfo).(factcheck.UserInfo)
+	if !ok {
+		return factcheck.UserInfo{}, errors.New("unauthenticated")
+	}
+	return user, nil
 }
 
 func (h *handler) GetMessageByID(w http.ResponseWriter, r *http.Request) {
@@ -25,8 +26,13 @@ func (h *handler) GetMessageByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) DeleteMessageByID(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
 	deleteByID[factcheck.MessageV2](w, r, func(ctx context.Context, s string) error {
-		return h.messagesv2.Delete(ctx, s)
+		return h.service.DeleteMessage(ctx, user, s)
 	})
 }
 
@@ -42,16 +48,16 @@ func (h *handler) SubmitMessage(w http.ResponseWriter, r *http.Request) {
 		TopicID string `json:"topic_id"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
 		return
 	}
 	if body.Text == "" {
-		errBadRequest(w, "empty text")
+		errBadRequest(w, r, codeMissingField, "empty text")
 		return
 	}
 	userInfo, err := h.getUserInfo(r)
 	if err != nil {
-		errInternalError(w, err.Error())
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	msg, group, topic, err := h.service.Submit(
@@ -61,7 +67,7 @@ func (h *handler) SubmitMessage(w http.ResponseWriter, r *http.Request) {
 		body.TopicID,
 	)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusCreated, map[string]any{
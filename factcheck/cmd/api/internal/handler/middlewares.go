@@ -2,10 +2,24 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 )
 
 type (
@@ -18,48 +32,176 @@ const (
 	CtxKeyUserInfo CtxKey = "FACTCHECK_USERINFO"
 )
 
-func decodeAdmin(ctx context.Context, userID string) context.Context {
-	ctx = context.WithValue(ctx, CtxKeyUserID, userID)
-	ctx = context.WithValue(ctx, CtxKeyUserType, factcheck.TypeUserMessageAdmin)
-	ctx = context.WithValue(ctx, CtxKeyUserInfo, factcheck.UserInfo{
-		UserType: factcheck.TypeUserMessageAdmin,
-		UserID:   userID,
-	})
+// withUserInfo saves authenticated user to ctx
+func withUserInfo(ctx context.Context, user factcheck.UserInfo) context.Context {
+	ctx = context.WithValue(ctx, CtxKeyUserID, user.UserID)
+	ctx = context.WithValue(ctx, CtxKeyUserType, user.UserType)
+	ctx = context.WithValue(ctx, CtxKeyUserInfo, user)
 	return ctx
 }
 
-// MiddlewareAuth handles only authentication
-func MiddlewareAuth(next http.Handler) http.Handler {
-	f := func(w http.ResponseWriter, r *http.Request) {
-		r = r.WithContext(decodeAdmin(r.Context(), "mock-factcheck-admin"))
-		next.ServeHTTP(w, r)
+// MiddlewareAuth handles only authentication.
+// Authenticated users are saved to request context, while requests without credentials
+// are passed on as is. Use MiddlewareRequireAuth to reject unauthenticated requests.
+func MiddlewareAuth(a auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := a.Authenticate(r)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				slog.InfoContext(r.Context(), "authentication failed", "err", err, "path", r.URL.Path)
+				errAuth(w, r, "bad credentials")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withUserInfo(r.Context(), user)))
+		})
 	}
-	return http.HandlerFunc(f)
 }
 
-func MiddlewareAdmin(next http.Handler) http.Handler {
+// MiddlewareRequireAuth rejects requests not authenticated by MiddlewareAuth
+func MiddlewareRequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userType := r.Context().Value(CtxKeyUserType)
-		if userType == nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			write(r.Context(), w, []byte("unauthorized: missing data"))
+		_, ok := r.Context().Value(CtxKeyUserInfo).(factcheck.UserInfo)
+		if !ok {
+			errAuth(w, r, "missing credentials")
 			return
 		}
-		userType, ok := userType.(factcheck.TypeUser)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MiddlewarePermit rejects requests from users without permission p
+func MiddlewarePermit(a auth.Authorizer, p factcheck.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(CtxKeyUserInfo).(factcheck.UserInfo)
+			if !ok {
+				errAuth(w, r, "missing credentials")
+				return
+			}
+			can, err := a.Can(r.Context(), user, p)
+			if err != nil {
+				errInternalError(w, r, err)
+				return
+			}
+			if !can {
+				errForbidden(w, r, "missing permission "+string(p))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MiddlewareAdmin rejects requests not authenticated by MiddlewareAuth with 401,
+// and requests from users other than admin users with 403
+func MiddlewareAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userType, ok := r.Context().Value(CtxKeyUserType).(factcheck.TypeUser)
 		if !ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			write(r.Context(), w, []byte("unauthorized: missing data"))
+			errAuth(w, r, "missing credentials")
 			return
 		}
 		if userType != factcheck.TypeUserMessageAdmin {
-			w.WriteHeader(http.StatusUnauthorized)
-			write(r.Context(), w, []byte("unauthorized: bad data"))
+			errForbidden(w, r, "not an admin user")
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+var (
+	metricRequests = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_http_requests_total",
+		Help: "HTTP requests by method, chi route pattern and status code",
+	}, []string{"method", "route", "status"})
+	metricRequestDuration = promauto.With(metrics.Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "factcheck_http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method and chi route pattern",
+		Buckets: metrics.BucketsLatency,
+	}, []string{"method", "route"})
+)
+
+// MiddlewareMetrics records counts and latencies of requests by their chi route patterns,
+// e.g. /topics/{id} and not /topics/some-id, so that routes have bounded number of labels.
+// Requests not matching any routes are recorded with route "unmatched",
+// and requests with methods other than the standard ones with method "OTHER".
+func MiddlewareMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		route, status := routed(r, ww)
+		method := methodKnown(r.Method)
+		metricRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		metricRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// MiddlewareTracing traces requests as server spans, continuing traces from W3C traceparent headers.
+// Sampled flags of traceparent are ignored, and tracing.Sampler decides which traces are exported.
+// Like MiddlewareMetrics, spans are named by chi route patterns after the requests were routed.
+func MiddlewareTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+		method := methodKnown(r.Method)
+		ctx, span := tracing.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+		route, status := routed(r, ww)
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// methodKnown returns method if it is a standard HTTP method, or "OTHER",
+// since clients can send any method
+func methodKnown(method string) string {
+	switch method {
+	case
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodConnect,
+		http.MethodOptions,
+		http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+// routed returns chi route pattern of r, or "unmatched", and status code written to ww
+func routed(r *http.Request, ww middleware.WrapResponseWriter) (string, int) {
+	route := "unmatched"
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return route, status
+}
+
 func write(ctx context.Context, w http.ResponseWriter, b []byte) {
 	_, err := w.Write(b)
 	if err != nil {
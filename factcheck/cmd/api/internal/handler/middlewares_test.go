@@ -0,0 +1,237 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// rolesMemory maps user IDs to roles.
+// Calling methods other than GetByUserID will panic.
+type rolesMemory struct {
+	repo.Roles
+	roles map[string]factcheck.Role
+}
+
+func (r rolesMemory) GetByUserID(_ context.Context, userID string, _ ...repo.Option) (factcheck.UserRole, error) {
+	role, ok := r.roles[userID]
+	if !ok {
+		return factcheck.UserRole{}, &repo.ErrNotFound{Filter: userID}
+	}
+	return factcheck.UserRole{UserID: userID, Role: role}, nil
+}
+
+func TestMiddlewareAuth(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := func(t *testing.T, userType factcheck.TypeUser, userID string) string {
+		t.Helper()
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   userID,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			UserType: userType,
+		}).SignedString([]byte(conf.Auth.JWTSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+	roles := rolesMemory{roles: map[string]factcheck.Role{
+		"viewer-1":  factcheck.RoleViewer,
+		"checker-1": factcheck.RoleFactChecker,
+		"editor-1":  factcheck.RoleEditor,
+	}}
+	authorizer := auth.NewAuthorizer(conf, repo.Repository{Roles: roles})
+	newServer := func() (http.Handler, *serviceSubmitRecorder) {
+		service := &serviceSubmitRecorder{}
+		srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, service), authenticator, authorizer, health.Checker{}, nil)
+		return srv.Handler, service
+	}
+	do := func(t *testing.T, h http.Handler, method, path string, headers map[string]string) int {
+		t.Helper()
+		req := httptest.NewRequestWithContext(t.Context(), method, path, strings.NewReader(`{"text":"lemon soda cures cancer"}`))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("destructive routes reject unauthenticated requests", func(t *testing.T) {
+		h, _ := newServer()
+		routes := []struct{ method, path string }{
+			{http.MethodPost, "/topics/"},
+			{http.MethodDelete, "/topics/some-id"},
+			{http.MethodPut, "/topics/some-id/status"},
+			{http.MethodPut, "/topics/some-id/name"},
+			{http.MethodPut, "/topics/some-id/description"},
+			{http.MethodPost, "/messages/"},
+			{http.MethodDelete, "/messages/"},
+			{http.MethodPut, "/messages/some-id/assign-message-group"},
+			{http.MethodPut, "/message-groups/some-id/assign-topic"},
+			{http.MethodDelete, "/message-groups/some-id"},
+			{http.MethodPut, "/message-groups/some-id/approve"},
+			{http.MethodPut, "/message-groups/some-id/reject"},
+			{http.MethodPost, "/admin/topics/resolve/some-id"},
+			{http.MethodGet, "/admin/topics/some-id/deliveries"},
+		}
+		for _, route := range routes {
+			code := do(t, h, route.method, route.path, nil)
+			if code != http.StatusUnauthorized {
+				t.Fatalf("unexpected status %d for %s %s", code, route.method, route.path)
+			}
+		}
+	})
+
+	t.Run("bad credentials are rejected", func(t *testing.T) {
+		h, service := newServer()
+		bad := []map[string]string{
+			{auth.HeaderAPIKey: "not-a-key"},
+			{auth.HeaderAuthorization: "Bearer not-a-jwt"},
+			{auth.HeaderAuthorization: token(t, "BAD_TYPE", "some-user")},
+			{auth.HeaderAuthorization: token(t, factcheck.TypeUserMessageAdmin, "")},
+		}
+		for _, headers := range bad {
+			code := do(t, h, http.MethodPost, "/messages/", headers)
+			if code != http.StatusUnauthorized {
+				t.Fatalf("unexpected status %d for headers %v", code, headers)
+			}
+		}
+		if len(service.submitted) != 0 {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		h, service := newServer()
+		code := do(t, h, http.MethodPost, "/messages/", map[string]string{auth.HeaderAPIKey: conf.Auth.APIKeys["factcheck-test"]})
+		if code != http.StatusCreated {
+			t.Fatalf("unexpected status %d", code)
+		}
+		expected := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "factcheck-test"}
+		if len(service.submitted) != 1 || service.submitted[0].user != expected {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+
+	t.Run("jwt", func(t *testing.T) {
+		h, service := newServer()
+		code := do(t, h, http.MethodPost, "/messages/", map[string]string{auth.HeaderAuthorization: token(t, factcheck.TypeUserMessageAdmin, "checker-1")})
+		if code != http.StatusCreated {
+			t.Fatalf("unexpected status %d", code)
+		}
+		expected := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "checker-1"}
+		if len(service.submitted) != 1 || service.submitted[0].user != expected {
+			t.Fatalf("unexpected submissions: %+v", service.submitted)
+		}
+	})
+
+	t.Run("permission matrix", func(t *testing.T) {
+		h, _ := newServer()
+		type testCase struct {
+			userType factcheck.TypeUser
+			userID   string
+			method   string
+			path     string
+		}
+		forbidden := []testCase{
+			{factcheck.TypeUserMessageLINEChat, "U1", http.MethodPost, "/messages/"},    // LINE users have no roles
+			{factcheck.TypeUserMessageAdmin, "nobody", http.MethodPost, "/messages/"},   // No role granted
+			{factcheck.TypeUserMessageAdmin, "viewer-1", http.MethodPost, "/messages/"}, // Viewers are read-only
+			{factcheck.TypeUserMessageAdmin, "viewer-1", http.MethodPost, "/topics/"},   // Viewers are read-only
+			{factcheck.TypeUserMessageAdmin, "viewer-1", http.MethodPut, "/message-groups/some-id/reject"},
+			{factcheck.TypeUserMessageAdmin, "checker-1", http.MethodPost, "/admin/topics/resolve/some-id"},
+			{factcheck.TypeUserMessageAdmin, "checker-1", http.MethodPut, "/topics/some-id/status"},
+			{factcheck.TypeUserMessageAdmin, "editor-1", http.MethodDelete, "/topics/some-id"},
+			{factcheck.TypeUserMessageAdmin, "editor-1", http.MethodDelete, "/message-groups/some-id"},
+			{factcheck.TypeUserMessageAdmin, "editor-1", http.MethodGet, "/admin/roles"},
+		}
+		for _, tc := range forbidden {
+			code := do(t, h, tc.method, tc.path, map[string]string{auth.HeaderAuthorization: token(t, tc.userType, tc.userID)})
+			if code != http.StatusForbidden {
+				t.Fatalf("unexpected status %d for %s %s by %s", code, tc.method, tc.path, tc.userID)
+			}
+		}
+	})
+
+	t.Run("admin routes reject non-admin users", func(t *testing.T) {
+		h, _ := newServer()
+		code := do(t, h, http.MethodGet, "/admin/topics/some-id/deliveries", map[string]string{auth.HeaderAuthorization: token(t, factcheck.TypeUserMessageLINEChat, "U1")})
+		if code != http.StatusForbidden {
+			t.Fatalf("unexpected status %d", code)
+		}
+	})
+}
+
+func TestMiddlewareTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	global := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(global)
+
+	var got trace.SpanContext
+	r := chi.NewRouter()
+	r.Use(handler.MiddlewareTracing)
+	r.Get("/topics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	serve := func(ratio float64, traceParent string) {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(tracing.Sampler(ratio)),
+			sdktrace.WithSpanProcessor(recorder),
+		))
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/topics/some-id", nil)
+		req.Header.Set("traceparent", traceParent)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatalf("unexpected trace ID %s, expecting trace of traceparent", got.TraceID())
+		}
+	}
+
+	// Sampled flag of clients is ignored
+	serve(0, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got.IsSampled() || len(recorder.Ended()) != 0 {
+		t.Fatalf("unexpected sampled span of client asking for sampling: %+v", recorder.Ended())
+	}
+	serve(1, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ended := recorder.Ended()
+	if !got.IsSampled() || len(ended) != 1 {
+		t.Fatalf("unexpected spans of client asking for no sampling: %+v", ended)
+	}
+	span := ended[0]
+	if span.Name() != "GET /topics/{id}" || span.SpanKind() != trace.SpanKindServer || span.Status().Code != codes.Error {
+		t.Fatalf("unexpected server span: %s, kind %s, status %+v", span.Name(), span.SpanKind(), span.Status())
+	}
+	if span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected parent of server span: %s", span.Parent().SpanID())
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/openapi"
+)
+
+// HandlerOpenAPI serves OpenAPI document doc as JSON
+func HandlerOpenAPI(doc *openapi.Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sendJSON(r.Context(), w, http.StatusOK, doc)
+	}
+}
+
+// MiddlewareValidate rejects requests not matching their operations in doc.
+// Requests without operations are passed on, so that routers can respond 404 or 405 as usual.
+//
+// It runs before authentication, so bodies are read up to maxBytes,
+// and requests with larger bodies are rejected with 413.
+func MiddlewareValidate(doc *openapi.Document, maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			err := doc.Validate(r)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				errProblem(w, r, http.StatusRequestEntityTooLarge, codeBodyTooLarge,
+					fmt.Sprintf("body is larger than %d bytes", tooLarge.Limit))
+				return
+			}
+			if err != nil && !errors.Is(err, openapi.ErrNoOperation) {
+				errBadRequest(w, r, codeInvalidRequest, err.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// problem is an RFC 7807 error response.
+// Clients should branch on Code, which is stable, and never on Title or Detail.
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	RetryAfter int `json:"retry_after,omitempty"` // Seconds until rate-limited clients can retry
+}
+
+// Stable error codes of problem responses
+const (
+	codeInvalidBody       = "invalid_body"
+	codeBodyTooLarge      = "body_too_large"
+	codeInvalidQuery      = "invalid_query"
+	codeInvalidRequest    = "invalid_request"
+	codeInvalidID         = "invalid_id"
+	codeInvalidStatus     = "invalid_status"
+	codeInvalidVerdict    = "invalid_verdict"
+	codeInvalidRole       = "invalid_role"
+	codeInvalidSource     = "invalid_source"
+	codeMissingField      = "missing_field"
+	codeUnauthorized      = "unauthorized"
+	codeForbidden         = "forbidden"
+	codeNotFound          = "not_found"
+	codeInvalidTransition = "invalid_transition"
+	codeDuplicate         = "duplicate"
+	codeInvalidReference  = "invalid_reference"
+	codeTxConflict        = "tx_conflict"
+	codeRateLimited       = "rate_limited"
+	codeInternalError     = "internal_error"
+)
+
+// resource names resources in not-found codes, e.g. topic_not_found
+type resource string
+
+const (
+	resourceTopic        resource = "topic"
+	resourceMessage      resource = "message"
+	resourceMessageGroup resource = "message_group"
+	resourceAnswer       resource = "answer"
+	resourceSource       resource = "source"
+	resourceRole         resource = "role"
+)
+
+// codeNotFound returns not-found code of resource
+func (r resource) codeNotFound() string {
+	if r == "" {
+		return codeNotFound
+	}
+	return string(r) + "_" + codeNotFound
+}
+
+// resourceOf returns resource of T, or empty resource for other types
+func resourceOf[T any]() resource {
+	var zero T
+	switch any(zero).(type) {
+	case factcheck.Topic:
+		return resourceTopic
+	case factcheck.MessageV2:
+		return resourceMessage
+	case factcheck.MessageGroup:
+		return resourceMessageGroup
+	case factcheck.Answer:
+		return resourceAnswer
+	case factcheck.Source:
+		return resourceSource
+	case factcheck.UserRole:
+		return resourceRole
+	}
+	return ""
+}
+
+// handleError maps errors from repo.Repository and core.Service to problem responses.
+// Unknown errors become 500 responses, with details only logged.
+func handleError(w http.ResponseWriter, r *http.Request, err error, res resource) {
+	switch {
+	case repo.IsNotFound(err):
+		errProblem(w, r, http.StatusNotFound, res.codeNotFound(), err.Error())
+	case errors.Is(err, repo.ErrInvalidID):
+		errProblem(w, r, http.StatusBadRequest, codeInvalidID, err.Error())
+	case errors.Is(err, core.ErrConflict):
+		errProblem(w, r, http.StatusConflict, codeInvalidTransition, err.Error())
+	case repo.IsUniqueViolation(err):
+		errProblem(w, r, http.StatusConflict, codeDuplicate, "resource already exists")
+	case repo.IsForeignKeyViolation(err):
+		errProblem(w, r, http.StatusUnprocessableEntity, codeInvalidReference, "referenced resource does not exist or is still referenced")
+	case repo.IsTxConflict(err):
+		errProblem(w, r, http.StatusConflict, codeTxConflict, "request conflicted with concurrent requests, please retry")
+	case isLimited(err):
+		errRateLimited(w, r, err)
+	default:
+		errInternalError(w, r, err)
+	}
+}
+
+func isLimited(err error) bool {
+	_, ok := ratelimit.IsLimited(err)
+	return ok
+}
+
+// errRateLimited responds with 429 and Retry-After in whole seconds,
+// with detail polite enough for LINE bots to show to users as is
+func errRateLimited(w http.ResponseWriter, r *http.Request, err error) {
+	limited, _ := ratelimit.IsLimited(err)
+	seconds := retrySeconds(limited)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	sendProblem(r.Context(), w, problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(http.StatusTooManyRequests),
+		Status:     http.StatusTooManyRequests,
+		Detail:     fmt.Sprintf("You are sending messages too quickly, please wait %d seconds and try again", seconds),
+		Instance:   r.URL.Path,
+		Code:       codeRateLimited,
+		RequestID:  middleware.GetReqID(r.Context()),
+		RetryAfter: seconds,
+	})
+}
+
+// retrySeconds returns whole seconds until limited clients can retry, at least 1
+func retrySeconds(limited *ratelimit.ErrLimited) int {
+	return max(int(math.Ceil(limited.RetryAfter.Seconds())), 1)
+}
+
+func errBadRequest(w http.ResponseWriter, r *http.Request, code string, detail string) {
+	errProblem(w, r, http.StatusBadRequest, code, detail)
+}
+
+// errInternalError logs err and responds with 500, without leaking err to clients
+func errInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	tracing.RecordError(trace.SpanFromContext(r.Context()), err)
+	slog.ErrorContext(r.Context(), "internal error",
+		"err", err,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"request_id", middleware.GetReqID(r.Context()),
+	)
+	errProblem(w, r, http.StatusInternalServerError, codeInternalError, "")
+}
+
+func errAuth(w http.ResponseWriter, r *http.Request, detail string) {
+	errProblem(w, r, http.StatusUnauthorized, codeUnauthorized, detail)
+}
+
+func errForbidden(w http.ResponseWriter, r *http.Request, detail string) {
+	errProblem(w, r, http.StatusForbidden, codeForbidden, detail)
+}
+
+func errProblem(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	sendProblem(r.Context(), w, problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: middleware.GetReqID(r.Context()),
+	})
+}
+
+func sendProblem(ctx context.Context, w http.ResponseWriter, p problem) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		panic(err) // Marshaling strings and ints never fails
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	write(ctx, w, b)
+}
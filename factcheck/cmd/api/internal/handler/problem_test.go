@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// serviceTopicNameErr fails UpdateTopicName with err.
+// Calling other methods of core.Service will panic.
+type serviceTopicNameErr struct {
+	core.Service
+	err error
+}
+
+func (s serviceTopicNameErr) UpdateTopicName(context.Context, factcheck.UserInfo, string, string) (factcheck.Topic, error) {
+	return factcheck.Topic{}, s.err
+}
+
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id"`
+
+	RetryAfter int `json:"retry_after"`
+}
+
+func TestProblem(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorizer := auth.NewAuthorizer(conf, repo.Repository{})
+	do := func(t *testing.T, service core.Service, path string, body string) (int, problem) {
+		t.Helper()
+		srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, service), authenticator, authorizer, health.Checker{}, nil)
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set(auth.HeaderAPIKey, conf.Auth.APIKeys["factcheck-test"])
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+		if contentType := rec.Header().Get("Content-Type"); contentType != "application/problem+json" {
+			t.Fatalf("unexpected content type '%s'", contentType)
+		}
+		var p problem
+		err := json.NewDecoder(rec.Body).Decode(&p)
+		if err != nil {
+			t.Fatalf("unexpected body: %v", err)
+		}
+		if p.Status != rec.Code {
+			t.Fatalf("unexpected problem status %d for response status %d", p.Status, rec.Code)
+		}
+		if p.Instance != path {
+			t.Fatalf("unexpected instance '%s'", p.Instance)
+		}
+		if p.RequestID == "" {
+			t.Fatalf("missing request_id")
+		}
+		return rec.Code, p
+	}
+
+	type testCase struct {
+		err    error
+		status int
+		code   string
+	}
+	tests := []testCase{
+		{&repo.ErrNotFound{Filter: "some-id"}, http.StatusNotFound, "topic_not_found"},
+		{fmt.Errorf("%w: topic is resolved", core.ErrConflict), http.StatusConflict, "invalid_transition"},
+		{fmt.Errorf("bad id: %w", repo.ErrInvalidID), http.StatusBadRequest, "invalid_id"},
+		{fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}), http.StatusConflict, "duplicate"},
+		{&pgconn.PgError{Code: "23503"}, http.StatusUnprocessableEntity, "invalid_reference"},
+		{&pgconn.PgError{Code: "40001"}, http.StatusConflict, "tx_conflict"},
+		{&pgconn.PgError{Code: "40P01"}, http.StatusConflict, "tx_conflict"},
+		{fmt.Errorf("submit: %w", &ratelimit.ErrLimited{Key: "user:USER_CHAT:U1", RetryAfter: time.Second}), http.StatusTooManyRequests, "rate_limited"},
+		{errors.New("connection to 10.0.0.1:5432 refused"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tc := range tests {
+		status, p := do(t, serviceTopicNameErr{err: tc.err}, "/topics/some-id/name", `{"name":"lemon soda"}`)
+		if status != tc.status || p.Code != tc.code {
+			t.Fatalf("unexpected problem for error '%v': status %d, code '%s'", tc.err, status, p.Code)
+		}
+	}
+
+	t.Run("internal errors are not leaked", func(t *testing.T) {
+		_, p := do(t, serviceTopicNameErr{err: errors.New("connection to 10.0.0.1:5432 refused")}, "/topics/some-id/name", `{"name":"lemon soda"}`)
+		if strings.Contains(p.Detail, "10.0.0.1") {
+			t.Fatalf("leaked internal error: '%s'", p.Detail)
+		}
+	})
+
+	t.Run("rate limited clients are told when to retry", func(t *testing.T) {
+		_, p := do(t, serviceTopicNameErr{err: &ratelimit.ErrLimited{RetryAfter: 2100 * time.Millisecond}}, "/topics/some-id/name", `{"name":"lemon soda"}`)
+		if p.RetryAfter != 3 {
+			t.Fatalf("unexpected retry_after %d", p.RetryAfter)
+		}
+		if !strings.Contains(p.Detail, "3 seconds") {
+			t.Fatalf("unexpected detail '%s'", p.Detail)
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		status, p := do(t, nil, "/topics/some-id/status", `{"status":"TOPIC_UNKNOWN"}`)
+		if status != http.StatusBadRequest || p.Code != "invalid_status" {
+			t.Fatalf("unexpected problem: status %d, code '%s'", status, p.Code)
+		}
+		status, p = do(t, nil, "/topics/some-id/name", `{"name":`)
+		if status != http.StatusBadRequest || p.Code != "invalid_body" {
+			t.Fatalf("unexpected problem: status %d, code '%s'", status, p.Code)
+		}
+	})
+}
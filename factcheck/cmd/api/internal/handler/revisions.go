@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+type bodyAnswer struct {
+	Text    string            `json:"text"`
+	Verdict factcheck.Verdict `json:"verdict"`
+}
+
+func (h *handler) DraftAnswer(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeAnswer(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	draft, err := h.service.DraftAnswer(r.Context(), user, paramID(r), body.Text, body.Verdict)
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusCreated, draft)
+}
+
+func (h *handler) UpdateDraftAnswer(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeAnswer(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	draft, err := h.service.UpdateDraftAnswer(r.Context(), user, paramID(r), chi.URLParam(r, "answer_id"), body.Text, body.Verdict)
+	if err != nil {
+		handleError(w, r, err, resourceAnswer)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, draft)
+}
+
+func (h *handler) PublishAnswer(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	// Like PostAnswer, submitters are re-notified by the outbox relay.
+	// Corrections are told apart in the notification text.
+	answer, _, _, err := h.service.PublishAnswer(r.Context(), user, paramID(r), chi.URLParam(r, "answer_id"))
+	if err != nil {
+		handleError(w, r, err, resourceAnswer)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, answer)
+}
+
+func (h *handler) ReopenTopic(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	topic, err := h.service.ReopenTopic(r.Context(), user, paramID(r))
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, topic)
+}
+
+func (h *handler) ListAnswerRevisions(w http.ResponseWriter, r *http.Request) {
+	getBy(w, r, paramID(r), func(ctx context.Context, id string) ([]factcheck.Answer, error) {
+		id, err := h.topics.ResolveID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return h.answers.ListRevisions(ctx, id)
+	})
+}
+
+// DiffAnswerRevisions diffs revision from against revision to of the topic's answers.
+// Both revisions are required in query parameters from and to.
+func (h *handler) DiffAnswerRevisions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get
+	from, err := strconv.Atoi(query("from"))
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, fmt.Sprintf("invalid revision from '%s'", query("from")))
+		return
+	}
+	to, err := strconv.Atoi(query("to"))
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, fmt.Sprintf("invalid revision to '%s'", query("to")))
+		return
+	}
+	id, err := h.topics.ResolveID(r.Context(), paramID(r))
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
+		return
+	}
+	answerFrom, err := h.answers.GetRevision(r.Context(), id, from)
+	if err != nil {
+		handleError(w, r, err, resourceAnswer)
+		return
+	}
+	answerTo, err := h.answers.GetRevision(r.Context(), id, to)
+	if err != nil {
+		handleError(w, r, err, resourceAnswer)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, factcheck.DiffAnswers(answerFrom, answerTo))
+}
+
+func decodeAnswer(w http.ResponseWriter, r *http.Request) (bodyAnswer, bool) {
+	body, err := decode[bodyAnswer](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return bodyAnswer{}, false
+	}
+	if !body.Verdict.IsValid() {
+		errBadRequest(w, r, codeInvalidVerdict, fmt.Sprintf("invalid verdict '%s'", body.Verdict))
+		return bodyAnswer{}, false
+	}
+	return body, true
+}
@@ -0,0 +1,167 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerTopic_Revisions(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	topic, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+		ID:        utils.NewID().String(),
+		Name:      "lemon soda",
+		Status:    factcheck.StatusTopicPending,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+
+	do := func(t *testing.T, method string, path string, body any) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, testServer.URL+path, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		return resp
+	}
+	decodeAnswer := func(t *testing.T, resp *http.Response) factcheck.Answer {
+		t.Helper()
+		var answer factcheck.Answer
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&answer), nil)
+		return answer
+	}
+
+	var first, second factcheck.Answer
+	t.Run("draft is not public", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/drafts", map[string]any{"text": "lemon soda cures cancer", "verdict": factcheck.VerdictTrue})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusCreated)
+		first = decodeAnswer(t, resp)
+		assertEq(t, first.Status, factcheck.StatusAnswerDraft)
+		assertEq(t, first.Revision, 1)
+
+		answers, err := app.Repository.Answers.ListByTopicID(ctx, topic.ID)
+		assertEq(t, err, nil)
+		assertEq(t, len(answers), 0)
+	})
+
+	t.Run("update draft", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/admin/topics/"+topic.ID+"/drafts/"+first.ID, map[string]any{"text": "lemon soda does not cure cancer", "verdict": factcheck.VerdictFalse})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		updated := decodeAnswer(t, resp)
+		assertEq(t, updated.Text, "lemon soda does not cure cancer")
+		assertEq(t, updated.Verdict, factcheck.VerdictFalse)
+	})
+
+	t.Run("publish", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/drafts/"+first.ID+"/publish", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		published := decodeAnswer(t, resp)
+		assertEq(t, published.Status, factcheck.StatusAnswerPublished)
+		assertEq(t, published.CorrectsID, "")
+
+		resolved, err := app.Repository.Topics.GetByID(ctx, topic.ID)
+		assertEq(t, err, nil)
+		assertEq(t, resolved.Status, factcheck.StatusTopicResolved)
+		assertEq(t, resolved.AnswerID, first.ID)
+	})
+
+	t.Run("published answer cannot be updated", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/admin/topics/"+topic.ID+"/drafts/"+first.ID, map[string]any{"text": "edited", "verdict": factcheck.VerdictFalse})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+	})
+
+	t.Run("re-open and correct", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/reopen", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var reopened factcheck.Topic
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&reopened), nil)
+		assertEq(t, reopened.Status, factcheck.StatusTopicPending)
+		assertEq(t, reopened.AnswerID, first.ID)
+
+		// Published answer is still public until corrected
+		answer, err := app.Repository.Answers.GetByTopicID(ctx, topic.ID)
+		assertEq(t, err, nil)
+		assertEq(t, answer.ID, first.ID)
+
+		resp = do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/reopen", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+
+		resp = do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/drafts", map[string]any{"text": "lemon soda does not cure cancer\nsee sources", "verdict": factcheck.VerdictMisleading})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusCreated)
+		second = decodeAnswer(t, resp)
+		assertEq(t, second.Revision, 2)
+
+		resp = do(t, http.MethodPost, "/admin/topics/"+topic.ID+"/drafts/"+second.ID+"/publish", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		correction := decodeAnswer(t, resp)
+		assertEq(t, correction.CorrectsID, first.ID)
+		assertNeq(t, notify.Text(topic, correction), notify.Text(topic, second))
+
+		resolved, err := app.Repository.Topics.GetByID(ctx, topic.ID)
+		assertEq(t, err, nil)
+		assertEq(t, resolved.Status, factcheck.StatusTopicResolved)
+		assertEq(t, resolved.AnswerID, second.ID)
+		assertEq(t, resolved.Verdict, factcheck.VerdictMisleading)
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/admin/topics/"+topic.ID+"/revisions", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var revisions []factcheck.Answer
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&revisions), nil)
+		assertEq(t, len(revisions), 2)
+		assertEq(t, revisions[0].ID, second.ID)
+		assertEq(t, revisions[1].ID, first.ID)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/admin/topics/"+topic.ID+"/revisions/diff?from=1&to=2", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var diff factcheck.AnswerDiff
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&diff), nil)
+		assertEq(t, diff.VerdictChanged, true)
+		assertEq(t, len(diff.Lines), 2)
+		assertEq(t, diff.Lines[0].Op, factcheck.OpDiffEqual)
+		assertEq(t, diff.Lines[1].Op, factcheck.OpDiffInsert)
+
+		resp = do(t, http.MethodGet, "/admin/topics/"+topic.ID+"/revisions/diff?from=1&to=3", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+
+		resp = do(t, http.MethodGet, "/admin/topics/"+topic.ID+"/revisions/diff?from=one&to=2", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+}
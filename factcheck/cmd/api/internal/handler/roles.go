@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func (h *handler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	list(w, r, func(ctx context.Context) ([]factcheck.UserRole, error) {
+		return h.roles.List(ctx)
+	})
+}
+
+func (h *handler) GetRole(w http.ResponseWriter, r *http.Request) {
+	getBy(w, r, paramID(r), func(ctx context.Context, userID string) (factcheck.UserRole, error) {
+		return h.roles.GetByUserID(ctx, userID)
+	})
+}
+
+// PutRole grants a role to user {id}, replacing the user's previous role
+func (h *handler) PutRole(w http.ResponseWriter, r *http.Request) {
+	userID := paramID(r)
+	if userID == "" {
+		errBadRequest(w, r, codeMissingField, "missing user_id")
+		return
+	}
+	body, err := decode[struct {
+		Role factcheck.Role `json:"role"`
+	}](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	if !body.Role.IsValid() {
+		errBadRequest(w, r, codeInvalidRole, fmt.Sprintf("invalid role '%s'", body.Role))
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	role, err := h.service.GrantRole(r.Context(), user, factcheck.UserRole{
+		UserID:    userID,
+		Role:      body.Role,
+		CreatedAt: utils.TimeNow(),
+	})
+	if err != nil {
+		handleError(w, r, err, resourceRole)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, role)
+}
+
+func (h *handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	userID := paramID(r)
+	if userID == "" {
+		errBadRequest(w, r, codeMissingField, "missing user_id")
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	err = h.service.RevokeRole(r.Context(), user, userID)
+	if err != nil {
+		handleError(w, r, err, resourceRole)
+		return
+	}
+	sendText(r.Context(), w, "ok", http.StatusOK)
+}
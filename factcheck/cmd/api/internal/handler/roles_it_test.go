@@ -0,0 +1,80 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerRoles(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	do := func(t *testing.T, method, path string, body any) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), method, testServer.URL+path, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("invalid role", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/admin/roles/checker-1", map[string]string{"role": "ROLE_SUPERUSER"})
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("grant, update and revoke", func(t *testing.T) {
+		resp := do(t, http.MethodPut, "/admin/roles/checker-1", map[string]string{"role": string(factcheck.RoleFactChecker)})
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		granted := factcheck.UserRole{}
+		err := json.NewDecoder(resp.Body).Decode(&granted)
+		assertEq(t, err, nil)
+		assertEq(t, granted.UserID, "checker-1")
+		assertEq(t, granted.Role, factcheck.RoleFactChecker)
+		assertEq(t, granted.UpdatedAt, nil)
+
+		resp = do(t, http.MethodPut, "/admin/roles/checker-1", map[string]string{"role": string(factcheck.RoleEditor)})
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		updated, err := app.Repository.Roles.GetByUserID(t.Context(), "checker-1")
+		assertEq(t, err, nil)
+		assertEq(t, updated.Role, factcheck.RoleEditor)
+		assertEq(t, updated.CreatedAt, granted.CreatedAt)
+		assertNeq(t, updated.UpdatedAt, nil)
+
+		resp = do(t, http.MethodGet, "/admin/roles", nil)
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		roles := []factcheck.UserRole{}
+		err = json.NewDecoder(resp.Body).Decode(&roles)
+		assertEq(t, err, nil)
+		assertEq(t, len(roles), 1)
+		assertEq(t, roles[0].Role, factcheck.RoleEditor)
+
+		resp = do(t, http.MethodDelete, "/admin/roles/checker-1", nil)
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		resp = do(t, http.MethodGet, "/admin/roles/checker-1", nil)
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+		resp = do(t, http.MethodDelete, "/admin/roles/checker-1", nil)
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// defaultSearchLimit limits search results when query limit is not given
+const defaultSearchLimit = 20
+
+// Search searches topics, approved message groups and published answers for query q, highest rank first.
+// Query in_types is an optional comma-separated list of TypeSearch.
+func (h *handler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if strings.TrimSpace(q) == "" {
+		errBadRequest(w, r, codeMissingField, "missing query q")
+		return
+	}
+	limit, offset, err := limitOffSet(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	opts, err := toSearchOptions(r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
+		return
+	}
+	results, err := h.search.Search(r.Context(), q, limit, offset, opts...)
+	if err != nil {
+		errInternalError(w, r, err)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, results)
+}
+
+func toSearchOptions(r *http.Request) ([]repo.OptionSearch, error) {
+	types := r.URL.Query().Get("in_types")
+	if types == "" {
+		return nil, nil
+	}
+	var parsed []factcheck.TypeSearch
+	for _, t := range strings.Split(types, ",") {
+		typeSearch := factcheck.TypeSearch(t)
+		if !typeSearch.IsValid() {
+			return nil, fmt.Errorf("bad query in_types: '%s'", t)
+		}
+		parsed = append(parsed, typeSearch)
+	}
+	return []repo.OptionSearch{repo.SearchInTypes(parsed)}, nil
+}
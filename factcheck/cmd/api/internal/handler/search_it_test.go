@@ -0,0 +1,175 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerSearch(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	topic, err := app.Repository.Topics.Create(t.Context(), factcheck.Topic{
+		ID:          utils.NewID().String(),
+		Name:        "น้ำมะนาวรักษามะเร็ง",
+		Description: "ข่าวปลอมเรื่องสมุนไพร",
+		Status:      factcheck.StatusTopicPending,
+		CreatedAt:   now,
+	})
+	assertEq(t, err, nil)
+	group, err := app.Repository.MessageGroups.Create(t.Context(), factcheck.MessageGroup{
+		ID:        utils.NewID().String(),
+		TopicID:   topic.ID,
+		Status:    factcheck.StatusMGroupApproved,
+		Name:      "มะนาว",
+		Text:      "ดื่มน้ำมะนาวทุกวันรักษามะเร็งได้",
+		TextSHA1:  "sha1_search_1",
+		Language:  factcheck.LanguageThai,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+	unrelated, err := app.Repository.MessageGroups.Create(t.Context(), factcheck.MessageGroup{
+		ID:        utils.NewID().String(),
+		Status:    factcheck.StatusMGroupApproved,
+		Name:      "vaccine",
+		Text:      "COVID vaccine changes your DNA",
+		TextSHA1:  "sha1_search_2",
+		Language:  factcheck.LanguageEnglish,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+	_, err = app.Repository.MessageGroups.Create(t.Context(), factcheck.MessageGroup{
+		ID:        utils.NewID().String(),
+		Status:    factcheck.StatusMGroupPending,
+		Name:      "vaccine",
+		Text:      "COVID vaccine has microchips",
+		TextSHA1:  "sha1_search_3",
+		Language:  factcheck.LanguageEnglish,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+	answer, err := app.Repository.Answers.Create(t.Context(), factcheck.Answer{
+		ID:        utils.NewID().String(),
+		TopicID:   topic.ID,
+		Status:    factcheck.StatusAnswerPublished,
+		Text:      "แพทย์ยืนยันว่าน้ำมะนาวไม่ได้รักษามะเร็ง",
+		Verdict:   factcheck.VerdictFalse,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+
+	search := func(t *testing.T, query url.Values) []factcheck.SearchResult {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/search?"+query.Encode(), nil)
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var results []factcheck.SearchResult
+		err = json.NewDecoder(resp.Body).Decode(&results)
+		assertEq(t, err, nil)
+		return results
+	}
+	ids := func(results []factcheck.SearchResult) map[string]factcheck.TypeSearch {
+		m := make(map[string]factcheck.TypeSearch)
+		for i := range results {
+			m[results[i].ID] = results[i].Type
+		}
+		return m
+	}
+
+	t.Run("Thai words match across topics, groups and answers", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"มะนาว มะเร็ง"}})
+		found := ids(results)
+		assertEq(t, len(results), 3)
+		assertEq(t, found[topic.ID], factcheck.TypeSearchTopic)
+		assertEq(t, found[group.ID], factcheck.TypeSearchGroup)
+		assertEq(t, found[answer.ID], factcheck.TypeSearchAnswer)
+		// Words in topic names weigh more than words elsewhere
+		assertEq(t, results[0].ID, topic.ID)
+		for i := range results {
+			assertEq(t, results[i].TopicID, topic.ID)
+		}
+	})
+
+	t.Run("query without spaces is segmented", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"น้ำมะนาวรักษามะเร็ง"}})
+		assertEq(t, len(results), 3)
+	})
+
+	t.Run("in_types filter", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"มะนาว"}, "in_types": {"SEARCH_ANSWER"}})
+		assertEq(t, len(results), 1)
+		assertEq(t, results[0].ID, answer.ID)
+	})
+
+	t.Run("English prefix", func(t *testing.T) {
+		// The pending group about microchips is not found
+		results := search(t, url.Values{"q": {"covid vacc"}})
+		assertEq(t, len(results), 1)
+		assertEq(t, results[0].ID, unrelated.ID)
+		assertEq(t, results[0].TopicID, "")
+	})
+
+	t.Run("backfilled tokens", func(t *testing.T) {
+		_, err := app.PostgresConn.Exec(t.Context(), "UPDATE answers SET text_tokens = '' WHERE id = $1", answer.ID)
+		assertEq(t, err, nil)
+		query := url.Values{"q": {"แพทย์"}}
+		assertEq(t, len(search(t, query)), 0)
+
+		for _, typ := range factcheck.TypesSearch() {
+			afterID := ""
+			for {
+				lastID, err := app.Repository.Search.Retokenize(t.Context(), typ, afterID, 1)
+				assertEq(t, err, nil)
+				if lastID == "" {
+					break
+				}
+				afterID = lastID
+			}
+		}
+		results := search(t, query)
+		assertEq(t, len(results), 1)
+		assertEq(t, results[0].ID, answer.ID)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"ไวรัส"}})
+		assertEq(t, len(results), 0)
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{},
+			{"q": {"มะนาว"}, "in_types": {"SEARCH_MESSAGE"}},
+		} {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+"/search?"+query.Encode(), nil)
+			assertEq(t, err, nil)
+			resp, err := http.DefaultClient.Do(req)
+			assertEq(t, err, nil)
+			resp.Body.Close()
+			assertEq(t, resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+type bodySource struct {
+	Type          factcheck.TypeSource `json:"type"`
+	Position      int                  `json:"position"`
+	URL           string               `json:"url"`
+	Publisher     string               `json:"publisher"`
+	ArchiveURL    string               `json:"archive_url"`
+	Quote         string               `json:"quote"`
+	AttachmentURL string               `json:"attachment_url"`
+}
+
+// ListAnswerSources lists sources of a published answer, of the topic or the topic it was merged into.
+// Sources of drafts are not public, and drafts are not found.
+func (h *handler) ListAnswerSources(w http.ResponseWriter, r *http.Request) {
+	answerID := chi.URLParam(r, "answer_id")
+	getBy(w, r, answerID, func(ctx context.Context, id string) ([]factcheck.Source, error) {
+		topicID, err := h.topics.ResolveID(ctx, paramID(r))
+		if err != nil {
+			return nil, err
+		}
+		answer, err := h.answers.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if answer.TopicID != topicID || answer.Status != factcheck.StatusAnswerPublished {
+			return nil, &repo.ErrNotFound{Filter: map[string]string{"id": id, "topic_id": topicID}}
+		}
+		return h.sources.ListByAnswerID(ctx, id)
+	})
+}
+
+func (h *handler) CreateSource(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeSource(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	source := body.source(utils.NewID().String(), chi.URLParam(r, "answer_id"))
+	source.CreatedAt = utils.TimeNow()
+	created, err := h.service.CreateSource(r.Context(), user, paramID(r), source)
+	if err != nil {
+		handleError(w, r, err, resourceAnswer)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusCreated, created)
+}
+
+func (h *handler) UpdateSource(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeSource(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	source := body.source(chi.URLParam(r, "source_id"), chi.URLParam(r, "answer_id"))
+	updated, err := h.service.UpdateSource(r.Context(), user, paramID(r), source)
+	if err != nil {
+		handleError(w, r, err, resourceSource)
+		return
+	}
+	sendJSON(r.Context(), w, http.StatusOK, updated)
+}
+
+func (h *handler) DeleteSource(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	err = h.service.DeleteSource(r.Context(), user, paramID(r), chi.URLParam(r, "answer_id"), chi.URLParam(r, "source_id"))
+	if err != nil {
+		handleError(w, r, err, resourceSource)
+		return
+	}
+	sendText(r.Context(), w, "ok", http.StatusOK)
+}
+
+func decodeSource(w http.ResponseWriter, r *http.Request) (bodySource, bool) {
+	body, err := decode[bodySource](r)
+	if err != nil {
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return bodySource{}, false
+	}
+	err = body.source("", "").Validate()
+	if err != nil {
+		errBadRequest(w, r, codeInvalidSource, err.Error())
+		return bodySource{}, false
+	}
+	return body, true
+}
+
+func (b bodySource) source(id string, answerID string) factcheck.Source {
+	return factcheck.Source{
+		ID:            id,
+		AnswerID:      answerID,
+		Type:          b.Type,
+		Position:      b.Position,
+		URL:           b.URL,
+		Publisher:     b.Publisher,
+		ArchiveURL:    b.ArchiveURL,
+		Quote:         b.Quote,
+		AttachmentURL: b.AttachmentURL,
+	}
+}
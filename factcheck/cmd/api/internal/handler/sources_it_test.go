@@ -0,0 +1,198 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerAnswer_Sources(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	topic, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+		ID:        utils.NewID().String(),
+		Name:      "lemon soda",
+		Status:    factcheck.StatusTopicPending,
+		CreatedAt: now,
+	})
+	assertEq(t, err, nil)
+	newAnswer := func(status factcheck.StatusAnswer) factcheck.Answer {
+		answer, err := app.Repository.Answers.Create(ctx, factcheck.Answer{
+			ID:        utils.NewID().String(),
+			TopicID:   topic.ID,
+			Status:    status,
+			Text:      "lemon soda does not cure cancer",
+			Verdict:   factcheck.VerdictFalse,
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create answer: %v", err)
+		}
+		return answer
+	}
+	draft := newAnswer(factcheck.StatusAnswerDraft)
+
+	do := func(t *testing.T, method string, path string, body any) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, testServer.URL+path, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		return resp
+	}
+	path := func(answerID string) string {
+		return "/topics/" + topic.ID + "/answers/" + answerID + "/sources"
+	}
+	create := func(t *testing.T, answerID string, body map[string]any) factcheck.Source {
+		t.Helper()
+		resp := do(t, http.MethodPost, path(answerID), body)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusCreated)
+		var source factcheck.Source
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&source), nil)
+		return source
+	}
+	list := func(t *testing.T, answerID string) []factcheck.Source {
+		t.Helper()
+		resp := do(t, http.MethodGet, path(answerID), nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var sources []factcheck.Source
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&sources), nil)
+		return sources
+	}
+
+	listDraft := func(t *testing.T) []factcheck.Source {
+		t.Helper()
+		sources, err := app.Repository.Sources.ListByAnswerID(ctx, draft.ID)
+		assertEq(t, err, nil)
+		return sources
+	}
+
+	var report, expert, news factcheck.Source
+	t.Run("create", func(t *testing.T) {
+		report = create(t, draft.ID, map[string]any{
+			"type":        factcheck.TypeSourceOfficialData,
+			"url":         "https://example.com/report.pdf",
+			"publisher":   "Ministry of Public Health",
+			"archive_url": "https://archive.example.com/report.pdf",
+		})
+		assertEq(t, report.Position, 1)
+		assertEq(t, report.AnswerID, draft.ID)
+		expert = create(t, draft.ID, map[string]any{
+			"type":  factcheck.TypeSourceExpert,
+			"quote": "There is no evidence lemon soda cures cancer",
+		})
+		assertEq(t, expert.Position, 2)
+		news = create(t, draft.ID, map[string]any{"type": factcheck.TypeSourceMedia, "url": "https://example.com/news"})
+		assertEq(t, news.Position, 3)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		resp := do(t, http.MethodPost, path(draft.ID), map[string]any{"type": factcheck.TypeSourceOther})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+
+		resp = do(t, http.MethodPost, path(draft.ID), map[string]any{"type": factcheck.TypeSourceOther, "url": "ftp://example.com"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("answer of other topic", func(t *testing.T) {
+		resp := do(t, http.MethodPost, "/topics/"+utils.NewID().String()+"/answers/"+draft.ID+"/sources", map[string]any{"type": factcheck.TypeSourceOther, "quote": "quote"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		resp := do(t, http.MethodPut, path(draft.ID)+"/"+report.ID, map[string]any{
+			"type":      factcheck.TypeSourceOfficialData,
+			"position":  4,
+			"url":       "https://example.com/report.pdf",
+			"publisher": "Ministry of Public Health",
+		})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		sources := listDraft(t)
+		assertEq(t, len(sources), 3)
+		assertEq(t, sources[0].ID, expert.ID)
+		assertEq(t, sources[2].ID, report.ID)
+		assertEq(t, sources[2].ArchiveURL, "")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := do(t, http.MethodDelete, path(draft.ID)+"/"+news.ID, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		sources := listDraft(t)
+		assertEq(t, len(sources), 2)
+		assertEq(t, sources[0].ID, expert.ID)
+		assertEq(t, sources[1].ID, report.ID)
+	})
+
+	t.Run("sources of drafts are not public", func(t *testing.T) {
+		resp := do(t, http.MethodGet, path(draft.ID), nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	published, err := app.Repository.Answers.Publish(ctx, draft.ID, "")
+	assertEq(t, err, nil)
+	_, err = app.Repository.Topics.Resolve(ctx, topic.ID, published)
+	assertEq(t, err, nil)
+
+	t.Run("get answer with sources", func(t *testing.T) {
+		resp := do(t, http.MethodGet, "/topics/"+topic.ID+"/answer", nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var answer factcheck.Answer
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&answer), nil)
+		assertEq(t, answer.ID, published.ID)
+		assertEq(t, len(answer.Sources), 2)
+		assertEq(t, answer.Sources[0].ID, expert.ID)
+	})
+
+	t.Run("sources of published answers cannot be changed", func(t *testing.T) {
+		resp := do(t, http.MethodPost, path(published.ID), map[string]any{"type": factcheck.TypeSourceMedia, "url": "https://example.com/other"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+
+		resp = do(t, http.MethodPut, path(published.ID)+"/"+report.ID, map[string]any{
+			"type": factcheck.TypeSourceOfficialData,
+			"url":  "https://example.com/other.pdf",
+		})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+
+		resp = do(t, http.MethodDelete, path(published.ID)+"/"+expert.ID, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+
+		sources := list(t, published.ID)
+		assertEq(t, len(sources), 2)
+		assertEq(t, sources[0].ID, expert.ID)
+		assertEq(t, sources[1].URL, "https://example.com/report.pdf")
+	})
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
+)
+
+const (
+	// streamHeartbeat is how often idle streams send comments,
+	// so that proxies and clients do not close them
+	streamHeartbeat = 15 * time.Second
+	// streamTimeoutWrite limits each write to slow or gone clients
+	streamTimeoutWrite = 10 * time.Second
+)
+
+// HandlerStream streams outbox events as server-sent events (SSE), as soon as they are committed by any instance.
+// Each SSE has event type as its event, a resume seq as its ID, and the event JSON as its data.
+// The resume seq is event seq, or an earlier seq if events before it could still commit late,
+// so streams resumed after seq gaps may repeat events, which clients dedupe by event JSON id.
+//
+// Clients resume after header Last-Event-ID, which browsers send when reconnecting,
+// or query last_event_id for new connections. Without either, only new events are streamed.
+// Query params topic_id and type are comma-separated filters.
+func HandlerStream(broker *stream.Broker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, err := toStreamFilter(r)
+		if err != nil {
+			errBadRequest(w, r, codeInvalidQuery, err.Error())
+			return
+		}
+		seq, err := lastEventID(r)
+		if err != nil {
+			errBadRequest(w, r, codeInvalidRequest, err.Error())
+			return
+		}
+		sub, err := broker.Subscribe(ctx, seq, filter)
+		if err != nil {
+			errInternalError(w, r, err)
+			return
+		}
+		defer sub.Close()
+
+		rc := http.NewResponseController(w)
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no") // Disables buffering of nginx
+		w.WriteHeader(http.StatusOK)
+		err = sendEvent(rc, w, ": connected\n\n")
+		if err != nil {
+			slog.DebugContext(ctx, "stream write error", "err", err)
+			return
+		}
+		slog.InfoContext(ctx, "stream started", "after", sub.Cursor(), "filter", filter)
+		for {
+			events, err := sub.Next(ctx, streamHeartbeat)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, stream.ErrClosed) {
+					slog.ErrorContext(ctx, "stream error", "err", err, "cursor", sub.Cursor())
+				}
+				return
+			}
+			if len(events) == 0 {
+				err = sendEvent(rc, w, ": heartbeat\n\n")
+				if err != nil {
+					slog.DebugContext(ctx, "stream write error", "err", err)
+					return
+				}
+				continue
+			}
+			var b strings.Builder
+			cursor := sub.Cursor()
+			for i := range events {
+				data, err := json.Marshal(events[i])
+				if err != nil {
+					slog.ErrorContext(ctx, "stream marshal error", "err", err, "seq", events[i].Seq)
+					return
+				}
+				fmt.Fprintf(&b, "id: %d\nevent: %s\ndata: %s\n\n", min(events[i].Seq, cursor), events[i].Type, data)
+			}
+			err = sendEvent(rc, w, b.String())
+			if err != nil {
+				slog.DebugContext(ctx, "stream write error", "err", err)
+				return
+			}
+		}
+	}
+}
+
+// sendEvent writes and flushes s within streamTimeoutWrite.
+// The deadline also lifts server write timeout, which streams outlive.
+func sendEvent(rc *http.ResponseController, w http.ResponseWriter, s string) error {
+	err := rc.SetWriteDeadline(time.Now().Add(streamTimeoutWrite))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	_, err = w.Write([]byte(s))
+	if err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// lastEventID returns seq after which events are streamed, or -1 for new events only
+func lastEventID(r *http.Request) (int64, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("last_event_id")
+	}
+	if id == "" {
+		return -1, nil
+	}
+	seq, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("bad last event id: '%s'", id)
+	}
+	return seq, nil
+}
+
+func toStreamFilter(r *http.Request) (stream.Filter, error) {
+	query := r.URL.Query().Get
+	var filter stream.Filter
+	if topicIDs := query("topic_id"); topicIDs != "" {
+		filter.TopicIDs = strings.Split(topicIDs, ",")
+	}
+	if types := query("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			typeEvent := factcheck.TypeEvent(t)
+			if !typeEvent.IsValid() {
+				return stream.Filter{}, fmt.Errorf("bad query type: '%s'", t)
+			}
+			filter.Types = append(filter.Types, typeEvent)
+		}
+	}
+	return filter, nil
+}
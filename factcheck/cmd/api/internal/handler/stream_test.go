@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
+)
+
+// outboxEvents lists fixed events.
+// Calling other methods of repo.Outbox will panic.
+type outboxEvents struct {
+	repo.Outbox
+	events []factcheck.Event
+}
+
+func (o outboxEvents) ListAfter(_ context.Context, seq int64, limit int, _ ...repo.Option) ([]factcheck.Event, error) {
+	var events []factcheck.Event
+	for _, e := range o.events {
+		if e.Seq > seq && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (o outboxEvents) LastSeq(context.Context, ...repo.Option) (int64, error) {
+	return o.events[len(o.events)-1].Seq, nil
+}
+
+func TestHandlerStream(t *testing.T) {
+	outbox := outboxEvents{events: []factcheck.Event{
+		{ID: "e1", Seq: 1, Type: factcheck.TypeEventGroupCreated, Payload: json.RawMessage(`{"id":"g1"}`)},
+		{ID: "e2", Seq: 2, Type: factcheck.TypeEventMessageSubmitted, Payload: json.RawMessage(`{"id":"m1"}`)},
+		{ID: "e3", Seq: 3, Type: factcheck.TypeEventGroupAssigned, Payload: json.RawMessage(`{"id":"g1","topic_id":"t1"}`)},
+	}}
+	broker := stream.NewBroker(repo.Repository{Outbox: outbox})
+	srv := httptest.NewServer(handler.HandlerStream(broker))
+	defer srv.Close()
+	defer broker.Close()
+
+	t.Run("resume with filters", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?type=group.created,group.assigned", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Last-Event-ID", "0")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("unexpected response: %d, content type '%s'", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+
+		var ids, types []string
+		var events []factcheck.Event
+		scanner := bufio.NewScanner(resp.Body)
+		for len(events) < 2 && scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				ids = append(ids, strings.TrimPrefix(line, "id: "))
+			case strings.HasPrefix(line, "event: "):
+				types = append(types, strings.TrimPrefix(line, "event: "))
+			case strings.HasPrefix(line, "data: "):
+				var event factcheck.Event
+				err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event)
+				if err != nil {
+					t.Fatalf("unexpected data '%s': %v", line, err)
+				}
+				events = append(events, event)
+			}
+		}
+		if strings.Join(ids, ",") != "1,3" || strings.Join(types, ",") != "group.created,group.assigned" {
+			t.Fatalf("unexpected events: ids %v, types %v", ids, types)
+		}
+		if events[1].ID != "e3" {
+			t.Fatalf("unexpected data of event 3: %+v", events[1])
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for _, query := range []string{"?type=group.unknown", "?last_event_id=abc", "?last_event_id=-1"} {
+			resp, err := http.Get(srv.URL + query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("unexpected status %d for query '%s'", resp.StatusCode, query)
+			}
+		}
+	})
+}
@@ -22,33 +22,42 @@ func (h *handler) ListAllTopics(w http.ResponseWriter, r *http.Request) {
 func (h *handler) ListTopics(w http.ResponseWriter, r *http.Request) {
 	limit, offset, err := limitOffSet(r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
 		return
 	}
 	topics, err := h.topics.List(r.Context(), limit, offset)
 	if err != nil {
-		errInternalError(w, err.Error())
+		errInternalError(w, r, err)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topics)
 }
 
+// GetTopicByID gets topic, or the topic it was merged into.
+// Reads of topic messages, groups, answers and their sources follow merges too.
 func (h *handler) GetTopicByID(w http.ResponseWriter, r *http.Request) {
 	getBy(w, r, paramID(r), func(ctx context.Context, id string) (factcheck.Topic, error) {
-		return h.topics.GetByID(ctx, id)
+		return h.topics.GetByIDFollowRedirect(ctx, id)
 	})
 }
 
 func (h *handler) ListTopicsHome(w http.ResponseWriter, r *http.Request) {
+	if paginated(r) {
+		opts := toTopicOptions(r)
+		page(w, r, func(ctx context.Context, limit int, cursor repo.Cursor) (repo.Page[factcheck.Topic], error) {
+			return h.topics.ListDynamicV2Page(ctx, limit, cursor, opts...)
+		})
+		return
+	}
 	limit, offset, err := limitOffSet(r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidQuery, err.Error())
 		return
 	}
 	opts := toTopicOptions(r)
 	topics, err := h.topics.ListDynamicV2(r.Context(), limit, offset, opts...)
 	if err != nil {
-		errInternalError(w, err.Error())
+		errInternalError(w, r, err)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topics)
@@ -58,7 +67,12 @@ func (h *handler) CountTopicsHome(w http.ResponseWriter, r *http.Request) {
 	opts := toTopicOptions(r)
 	counts, err := h.topics.CountByStatusDynamicV2(r.Context(), opts...)
 	if err != nil {
-		errInternalError(w, err.Error())
+		errInternalError(w, r, err)
+		return
+	}
+	verdicts, err := h.topics.CountByVerdictDynamicV2(r.Context(), opts...)
+	if err != nil {
+		errInternalError(w, r, err)
 		return
 	}
 	result := make(map[string]int64)
@@ -66,6 +80,10 @@ func (h *handler) CountTopicsHome(w http.ResponseWriter, r *http.Request) {
 		result[string(k)] = v
 		result["total"] += v
 	}
+	// Verdict keys never collide with status keys
+	for k, v := range verdicts {
+		result[string(k)] = v
+	}
 	sendJSON(r.Context(), w, http.StatusOK, result)
 }
 
@@ -75,7 +93,12 @@ func (h *handler) CreateTopic(w http.ResponseWriter, r *http.Request) {
 		Description string `json:"description"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
 		return
 	}
 	now := utils.TimeNow()
@@ -90,9 +113,9 @@ func (h *handler) CreateTopic(w http.ResponseWriter, r *http.Request) {
 		Status:      factcheck.StatusTopicPending,
 		CreatedAt:   now,
 	}
-	created, err := h.topics.Create(r.Context(), topic)
+	created, err := h.service.CreateTopic(r.Context(), user, topic)
 	if err != nil {
-		errInternalError(w, err.Error())
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusCreated, created)
@@ -103,12 +126,22 @@ func (h *handler) UpdateTopicStatus(w http.ResponseWriter, r *http.Request) {
 		Status string `json:"status"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	status := factcheck.StatusTopic(body.Status)
+	if !status.IsValid() {
+		errBadRequest(w, r, codeInvalidStatus, fmt.Sprintf("invalid status '%s'", body.Status))
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
 		return
 	}
-	topic, err := h.topics.UpdateStatus(r.Context(), paramID(r), factcheck.StatusTopic(body.Status))
+	topic, err := h.service.UpdateTopicStatus(r.Context(), user, paramID(r), status)
 	if err != nil {
-		handleNotFound(w, err, "topic", paramID(r))
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topic)
@@ -119,12 +152,17 @@ func (h *handler) UpdateTopicDescription(w http.ResponseWriter, r *http.Request)
 		Description string `json:"description"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
 		return
 	}
-	topic, err := h.topics.UpdateDescription(r.Context(), paramID(r), body.Description)
+	user, err := h.getUserInfo(r)
 	if err != nil {
-		handleNotFound(w, err, "topic", paramID(r))
+		errAuth(w, r, "missing credentials")
+		return
+	}
+	topic, err := h.service.UpdateTopicDescription(r.Context(), user, paramID(r), body.Description)
+	if err != nil {
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topic)
@@ -135,44 +173,95 @@ func (h *handler) UpdateTopicName(w http.ResponseWriter, r *http.Request) {
 		Name string `json:"name"`
 	}](r)
 	if err != nil {
-		errBadRequest(w, err.Error())
+		errBadRequest(w, r, codeInvalidBody, err.Error())
+		return
+	}
+	user, err := h.getUserInfo(r)
+	if err != nil {
+		errAuth(w, r, "missing credentials")
 		return
 	}
-	topic, err := h.topics.UpdateName(r.Context(), paramID(r), body.Name)
+	topic, err := h.service.UpdateTopicName(r.Context(), user, paramID(r), body.Name)
 	if err != nil {
-		handleNotFound(w, err, "topic", paramID(r))
+		handleError(w, r, err, resourceTopic)
 		return
 	}
 	sendJSON(r.Context(), w, http.StatusOK, topic)
 }
 
 func (h *handler) ListTopicMessages(w http.ResponseWriter, r *http.Request) {
+	if paginated(r) {
+		id := paramID(r)
+		page(w, r, func(ctx context.Context, limit int, cursor repo.Cursor) (repo.Page[factcheck.MessageV2], error) {
+			id, err := h.topics.ResolveID(ctx, id)
+			if err != nil {
+				return repo.Page[factcheck.MessageV2]{}, err
+			}
+			return h.messagesv2.ListByTopicPage(ctx, id, limit, cursor)
+		})
+		return
+	}
 	getBy(w, r, paramID(r), func(ctx context.Context, id string) ([]factcheck.MessageV2, error) {
+		id, err := h.topics.ResolveID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
 		return h.messagesv2.ListByTopic(ctx, id)
 	})
 }
 
 func (h *handler) ListTopicMessageGroups(w http.ResponseWriter, r *http.Request) {
 	getBy(w, r, paramID(r), func(ctx context.Context, s string) ([]factcheck.MessageGroup, error) {
-		return h.groups.ListByTopic(ctx, s)
+		id, err := h.topics.ResolveID(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		return h.groups.ListByTopic(ctx, id)
 	})
 }
 
 func (h *handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
 	getBy(w, r, paramID(r), func(ctx context.Context, s string) (factcheck.Answer, error) {
-		return h.answers.GetByTopicID(ctx, s)
+		id, err := h.topics.ResolveID(ctx, s)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		answer, err := h.answers.GetByTopicID(ctx, id)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		answer.Sources, err = h.sources.ListByAnswerID(ctx, answer.ID)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		return answer, nil
 	})
 }
 
 func (h *handler) ListAnswers(w http.ResponseWriter, r *http.Request) {
+	if paginated(r) {
+		id := paramID(r)
+		page(w, r, func(ctx context.Context, limit int, cursor repo.Cursor) (repo.Page[factcheck.Answer], error) {
+			id, err := h.topics.ResolveID(ctx, id)
+			if err != nil {
+				return repo.Page[factcheck.Answer]{}, err
+			}
+			return h.answers.ListByTopicIDPage(ctx, id, limit, cursor)
+		})
+		return
+	}
 	getBy(w, r, paramID(r), func(ctx context.Context, s string) ([]factcheck.Answer, error) {
-		return h.answers.ListByTopicID(ctx, s)
+		id, err := h.topics.ResolveID(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		return h.answers.ListByTopicID(ctx, id)
 	})
 }
 
 func toTopicOptions(r *http.Request) []repo.OptionTopic {
 	query := r.URL.Query().Get
-	id, text, statuses := query("like_id"), query("like_message_text"), query("in_statuses")
+	id, text, statuses, verdicts, languages := query("like_id"), query("like_message_text"), query("in_statuses"), query("in_verdicts"), query("in_languages")
 	var opts []repo.OptionTopic
 	if statuses != "" {
 		parts := strings.Split(statuses, ",")
@@ -183,6 +272,14 @@ func toTopicOptions(r *http.Request) []repo.OptionTopic {
 			opts = append(opts, repo.TopicInStatuses(statuses))
 		}
 	}
+	if verdicts != "" {
+		parts := strings.Split(verdicts, ",")
+		opts = append(opts, repo.TopicInVerdicts(utils.MapNoError(parts, utils.String[string, factcheck.Verdict])))
+	}
+	if languages != "" {
+		parts := strings.Split(languages, ",")
+		opts = append(opts, repo.TopicInLanguages(utils.MapNoError(parts, utils.String[string, factcheck.Language])))
+	}
 	if id != "" {
 		opts = append(opts, repo.TopicLikeID(id))
 	}
@@ -11,9 +11,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -25,7 +27,7 @@ func TestHandlerTopic_Stateful(t *testing.T) {
 	defer cleanup()
 
 	// Create test server
-	testServer := httptest.NewServer(app.Server.(*http.Server).Handler)
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
 	defer testServer.Close()
 
 	t.Run("CRUD 1 topic", func(t *testing.T) {
@@ -303,14 +305,21 @@ func TestHandlerTopic_Stateful(t *testing.T) {
 		defer respUpdateDesc.Body.Close()
 		assertEq(t, respUpdateDesc.StatusCode, http.StatusNotFound)
 
-		// Test that the error response contains detailed information
-		body, err := io.ReadAll(respUpdateDesc.Body)
-		assertEq(t, err, nil)
-		errorMessage := string(body)
-		t.Logf("Error message: %s", errorMessage)
-		// The error message should contain the filter information from our custom error type
-		assertEq(t, strings.Contains(errorMessage, "not found for filter"), true)
-		assertEq(t, strings.Contains(errorMessage, nonExistentID), true)
+		// Test that the error response is a problem with stable code and detailed information
+		assertEq(t, respUpdateDesc.Header.Get("Content-Type"), "application/problem+json")
+		var problem struct {
+			Status int    `json:"status"`
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		err = json.NewDecoder(respUpdateDesc.Body).Decode(&problem)
+		assertEq(t, err, nil)
+		t.Logf("Error problem: %+v", problem)
+		assertEq(t, problem.Status, http.StatusNotFound)
+		assertEq(t, problem.Code, "topic_not_found")
+		// The detail should contain the filter information from our custom error type
+		assertEq(t, strings.Contains(problem.Detail, "not found for filter"), true)
+		assertEq(t, strings.Contains(problem.Detail, nonExistentID), true)
 	})
 }
 
@@ -322,7 +331,7 @@ func TestHandlerTopic_CountTopicsHome(t *testing.T) {
 	defer cleanup()
 
 	// Create test server
-	testServer := httptest.NewServer(app.Server.(*http.Server).Handler)
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
 	defer testServer.Close()
 
 	// Create test data
@@ -681,7 +690,7 @@ func TestHandlerTopic_ListTopicsHome(t *testing.T) {
 	defer cleanup()
 
 	// Create test server
-	testServer := httptest.NewServer(app.Server.(*http.Server).Handler)
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
 	defer testServer.Close()
 
 	// Create test data
@@ -1006,3 +1015,108 @@ func TestHandlerTopic_ListTopicsHome(t *testing.T) {
 		assertEq(t, len(topics), 1)
 	})
 }
+
+func TestHandlerTopic_ListTopicsHomeCursor(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0).Truncate(time.Microsecond)
+	create := func(name string, createdAt time.Time) factcheck.Topic {
+		t.Helper()
+		topic, err := app.Repository.Topics.Create(t.Context(), factcheck.Topic{
+			ID:          utils.NewID().String(),
+			Name:        name,
+			Description: name,
+			Status:      factcheck.StatusTopicPending,
+			CreatedAt:   createdAt,
+		})
+		assertEq(t, err, nil)
+		return topic
+	}
+	get := func(t *testing.T, path string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testServer.URL+path, nil)
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	listPage := func(t *testing.T, cursor string) repo.Page[factcheck.Topic] {
+		t.Helper()
+		resp := get(t, "/topics/?limit=2&cursor="+cursor)
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var page repo.Page[factcheck.Topic]
+		err := json.NewDecoder(resp.Body).Decode(&page)
+		assertEq(t, err, nil)
+		return page
+	}
+	names := func(page repo.Page[factcheck.Topic]) string {
+		result := make([]string, len(page.Data))
+		for i := range page.Data {
+			result[i] = page.Data[i].Name
+		}
+		return strings.Join(result, ",")
+	}
+
+	// Topics 3 and 4 have the same created_at, and are ordered by ID
+	create("0", now)
+	create("1", now.Add(-time.Minute))
+	create("2", now.Add(-2*time.Minute))
+	topic3 := create("3", now.Add(-3*time.Minute))
+	topic4 := create("4", now.Add(-3*time.Minute))
+	tied := "3,4"
+	if topic4.ID > topic3.ID {
+		tied = "4,3"
+	}
+
+	first := listPage(t, "")
+	assertEq(t, names(first), "0,1")
+	assertEq(t, first.Prev, "")
+	assertNeq(t, first.Next, "")
+
+	// New topics do not shift the next pages
+	create("new", now.Add(time.Minute))
+
+	second := listPage(t, first.Next)
+	assertEq(t, names(second), "2,"+tied[:1])
+	assertNeq(t, second.Prev, "")
+	assertNeq(t, second.Next, "")
+
+	last := listPage(t, second.Next)
+	assertEq(t, names(last), tied[2:])
+	assertEq(t, last.Next, "")
+	assertNeq(t, last.Prev, "")
+
+	back := listPage(t, last.Prev)
+	assertEq(t, names(back), names(second))
+
+	backToFirst := listPage(t, back.Prev)
+	assertEq(t, names(backToFirst), "0,1")
+	assertNeq(t, backToFirst.Prev, "") // The new topic is before the first page
+
+	newest := listPage(t, backToFirst.Prev)
+	assertEq(t, names(newest), "new")
+	assertEq(t, newest.Prev, "")
+
+	t.Run("bad cursor", func(t *testing.T) {
+		resp := get(t, "/topics/?cursor=not-a-cursor")
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("offset mode without cursor", func(t *testing.T) {
+		resp := get(t, "/topics/?limit=2&offset=2")
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var topics []factcheck.Topic
+		err := json.NewDecoder(resp.Body).Decode(&topics)
+		assertEq(t, err, nil)
+		assertEq(t, len(topics), 2)
+		assertEq(t, topics[0].Name, "1")
+	})
+}
@@ -0,0 +1,194 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerTopic_Merge(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	newTopic := func(name string) factcheck.Topic {
+		topic, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+			ID:        utils.NewID().String(),
+			Name:      name,
+			Status:    factcheck.StatusTopicPending,
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create topic: %v", err)
+		}
+		return topic
+	}
+	newGroup := func(topicID string, text string) factcheck.MessageGroup {
+		group, err := app.Repository.MessageGroups.Create(ctx, factcheck.MessageGroup{
+			ID:        utils.NewID().String(),
+			TopicID:   topicID,
+			Name:      text,
+			Text:      text,
+			TextSHA1:  factcheck.SHA1(text),
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create group: %v", err)
+		}
+		return group
+	}
+	newMessage := func(group factcheck.MessageGroup) factcheck.MessageV2 {
+		message, err := app.Repository.MessagesV2.Create(ctx, factcheck.MessageV2{
+			ID:          utils.NewID().String(),
+			GroupID:     group.ID,
+			TopicID:     group.TopicID,
+			UserID:      "U1",
+			TypeUser:    factcheck.TypeUserMessageLINEChat,
+			TypeMessage: factcheck.TypeMessageText,
+			Text:        group.Text,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		return message
+	}
+
+	source, target, canonical := newTopic("source"), newTopic("target"), newTopic("canonical")
+	sourceDuplicate := newGroup(source.ID, "lemon soda cures cancer")
+	sourceOnly := newGroup(source.ID, "lime soda cures cancer")
+	targetDuplicate := newGroup(target.ID, "lemon soda cures cancer")
+	movedToTarget := newMessage(sourceDuplicate)
+	newMessage(sourceOnly)
+	newMessage(targetDuplicate)
+	answer, err := app.Repository.Answers.Create(ctx, factcheck.Answer{
+		ID:        utils.NewID().String(),
+		TopicID:   source.ID,
+		Status:    factcheck.StatusAnswerPublished,
+		Text:      "not true",
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create answer: %v", err)
+	}
+
+	merge := func(t *testing.T, sourceID string, targetID string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, testServer.URL+"/admin/topics/merge/"+sourceID, reqBodyJSON(map[string]string{"target_id": targetID}))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		return resp
+	}
+	getTopic := func(t *testing.T, id string) factcheck.Topic {
+		t.Helper()
+		resp, err := http.Get(testServer.URL + "/topics/" + id)
+		assertEq(t, err, nil)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		var topic factcheck.Topic
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&topic), nil)
+		return topic
+	}
+
+	t.Run("merge into itself", func(t *testing.T) {
+		resp := merge(t, source.ID, source.ID)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+	})
+
+	t.Run("merge into missing topic", func(t *testing.T) {
+		resp := merge(t, source.ID, utils.NewID().String())
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		resp := merge(t, source.ID, target.ID)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+
+		var merged factcheck.TopicMerge
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&merged), nil)
+		assertEq(t, merged.SourceID, source.ID)
+		assertEq(t, merged.Target.ID, target.ID)
+		assertEq(t, merged.Groups, 1)
+		assertEq(t, merged.GroupsMerged, 1)
+		assertEq(t, merged.Messages, 2)
+		assertEq(t, merged.Answers, 1)
+
+		groups, err := app.Repository.MessageGroups.ListByTopic(ctx, target.ID)
+		assertEq(t, err, nil)
+		assertEq(t, len(groups), 2)
+		message, err := app.Repository.MessagesV2.GetByID(ctx, movedToTarget.ID)
+		assertEq(t, err, nil)
+		assertEq(t, message.GroupID, targetDuplicate.ID)
+		assertEq(t, message.TopicID, target.ID)
+		answers, err := app.Repository.Answers.ListByTopicID(ctx, target.ID)
+		assertEq(t, err, nil)
+		assertEq(t, len(answers), 1)
+
+		_, err = app.Repository.Topics.GetByID(ctx, source.ID)
+		assertNeq(t, err, nil)
+		assertEq(t, getTopic(t, source.ID).ID, target.ID)
+
+		events, err := app.Repository.Audit.ListDynamic(ctx, 0, 0, repo.AuditTargetID(source.ID), repo.AuditAction(factcheck.ActionAuditTopicMerge))
+		assertEq(t, err, nil)
+		assertEq(t, len(events), 1)
+	})
+
+	t.Run("merge again collapses redirects", func(t *testing.T) {
+		resp := merge(t, target.ID, canonical.ID)
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusOK)
+		assertEq(t, getTopic(t, source.ID).ID, canonical.ID)
+		assertEq(t, getTopic(t, target.ID).ID, canonical.ID)
+	})
+
+	t.Run("topic reads follow redirects", func(t *testing.T) {
+		get := func(t *testing.T, path string, v any) {
+			t.Helper()
+			resp, err := http.Get(testServer.URL + "/topics/" + source.ID + path)
+			assertEq(t, err, nil)
+			defer resp.Body.Close()
+			assertEq(t, resp.StatusCode, http.StatusOK)
+			assertEq(t, json.NewDecoder(resp.Body).Decode(v), nil)
+		}
+		var answers []factcheck.Answer
+		get(t, "/answers", &answers)
+		assertEq(t, len(answers), 1)
+		assertEq(t, answers[0].ID, answer.ID)
+		var published factcheck.Answer
+		get(t, "/answer", &published)
+		assertEq(t, published.ID, answer.ID)
+		assertEq(t, published.TopicID, canonical.ID)
+		var sources []factcheck.Source
+		get(t, "/answers/"+answer.ID+"/sources", &sources)
+		assertEq(t, len(sources), 0)
+		var groups []factcheck.MessageGroup
+		get(t, "/message-group", &groups)
+		assertEq(t, len(groups), 2)
+		var messages []factcheck.MessageV2
+		get(t, "/messages", &messages)
+		assertEq(t, len(messages), 3)
+	})
+}
@@ -0,0 +1,130 @@
+//go:build integration_test
+// +build integration_test
+
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestHandlerTopic_Split(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	testServer := httptest.NewServer(authorized(app.Config, app.Server.(*http.Server).Handler))
+	defer testServer.Close()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	newTopic := func(name string) factcheck.Topic {
+		topic, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+			ID:        utils.NewID().String(),
+			Name:      name,
+			Status:    factcheck.StatusTopicPending,
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create topic: %v", err)
+		}
+		return topic
+	}
+	newGroup := func(topicID string, text string, messages int) factcheck.MessageGroup {
+		group, err := app.Repository.MessageGroups.Create(ctx, factcheck.MessageGroup{
+			ID:        utils.NewID().String(),
+			TopicID:   topicID,
+			Name:      text,
+			Text:      text,
+			TextSHA1:  factcheck.SHA1(text),
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create group: %v", err)
+		}
+		for range messages {
+			_, err := app.Repository.MessagesV2.Create(ctx, factcheck.MessageV2{
+				ID:          utils.NewID().String(),
+				GroupID:     group.ID,
+				TopicID:     topicID,
+				UserID:      "U1",
+				TypeUser:    factcheck.TypeUserMessageLINEChat,
+				TypeMessage: factcheck.TypeMessageText,
+				Text:        text,
+				CreatedAt:   now,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create message: %v", err)
+			}
+		}
+		return group
+	}
+
+	source, other := newTopic("lemon and lime"), newTopic("other")
+	lemon := newGroup(source.ID, "lemon soda cures cancer", 2)
+	lime := newGroup(source.ID, "lime soda cures cancer", 3)
+	orange := newGroup(source.ID, "orange soda cures cancer", 1)
+	elsewhere := newGroup(other.ID, "grape soda cures cancer", 1)
+
+	split := func(t *testing.T, body any) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, testServer.URL+"/admin/topics/split/"+source.ID, reqBodyJSON(body))
+		assertEq(t, err, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assertEq(t, err, nil)
+		return resp
+	}
+
+	t.Run("missing groups", func(t *testing.T) {
+		resp := split(t, map[string]any{"name": "lime"})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("group of other topic is not moved", func(t *testing.T) {
+		resp := split(t, map[string]any{"name": "lime", "group_ids": []string{lime.ID, elsewhere.ID}})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusConflict)
+
+		group, err := app.Repository.MessageGroups.GetByID(ctx, lime.ID)
+		assertEq(t, err, nil)
+		assertEq(t, group.TopicID, source.ID)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		resp := split(t, map[string]any{"name": "lime and orange", "group_ids": []string{lime.ID, orange.ID}})
+		defer resp.Body.Close()
+		assertEq(t, resp.StatusCode, http.StatusCreated)
+
+		var result factcheck.TopicSplit
+		assertEq(t, json.NewDecoder(resp.Body).Decode(&result), nil)
+		assertEq(t, result.Source.Topic.ID, source.ID)
+		assertEq(t, result.Source.Groups, 1)
+		assertEq(t, result.Source.Messages, 2)
+		assertEq(t, result.Split.Topic.Name, "lime and orange")
+		assertEq(t, result.Split.Topic.Status, factcheck.StatusTopicPending)
+		assertEq(t, result.Split.Groups, 2)
+		assertEq(t, result.Split.Messages, 4)
+
+		groups, err := app.Repository.MessageGroups.ListByTopic(ctx, source.ID)
+		assertEq(t, err, nil)
+		assertEq(t, len(groups), 1)
+		assertEq(t, groups[0].ID, lemon.ID)
+		messages, err := app.Repository.MessagesV2.ListByGroup(ctx, lime.ID)
+		assertEq(t, err, nil)
+		for _, m := range messages {
+			assertEq(t, m.TopicID, result.Split.Topic.ID)
+		}
+	})
+}
@@ -0,0 +1,764 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/openapi"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// Tags group operations by their mounted routers
+const (
+	tagMisc          = "misc"
+	tagTopics        = "topics"
+	tagMessages      = "messages"
+	tagMessageGroups = "message-groups"
+	tagAdmin         = "admin"
+	tagLINE          = "line"
+)
+
+// operation describes a route. Common parameters and error responses
+// are added by spec.add, so only route-specific ones are listed here.
+type operation struct {
+	id          string
+	tag         string
+	summary     string
+	description string
+	permission  factcheck.Permission // Empty for public routes
+	params      []openapi.Parameter
+	body        *openapi.Schema
+	status      int             // Status of successful responses, defaults to 200
+	response    *openapi.Schema // JSON response body, or nil for text "ok"
+	contentType string          // Content type of successful responses, defaults to JSON
+	errors      []int           // Statuses of route-specific problem responses, e.g. 404 and 409
+}
+
+type spec struct {
+	doc *openapi.Document
+	c   *openapi.Components
+}
+
+// Spec returns OpenAPI document of all routes in New.
+// Every route must have its operation here, which is enforced by tests.
+func Spec() *openapi.Document {
+	c := openapi.NewComponents()
+	c.SecuritySchemes["apiKey"] = openapi.SecurityScheme{
+		Type:        "apiKey",
+		Description: "API key of backoffice services",
+		Name:        auth.HeaderAPIKey,
+		In:          "header",
+	}
+	c.SecuritySchemes["bearer"] = openapi.SecurityScheme{
+		Type:         "http",
+		Description:  "JWT of backoffice users",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	}
+	c.Define(factcheck.StatusTopic(""), openapi.Enum(factcheck.StatusTopicPending, factcheck.StatusTopicResolved))
+	c.Define(factcheck.StatusMGroup(""), openapi.Enum(factcheck.StatusMGroupPending, factcheck.StatusMGroupApproved, factcheck.StatusMGroupRejected))
+	c.Define(factcheck.StatusAnswer(""), openapi.Enum(factcheck.StatusAnswerDraft, factcheck.StatusAnswerPublished))
+	c.Define(factcheck.TypeSource(""), openapi.Enum(
+		factcheck.TypeSourcePrimary,
+		factcheck.TypeSourceExpert,
+		factcheck.TypeSourceOfficialData,
+		factcheck.TypeSourceMedia,
+		factcheck.TypeSourceOther,
+	))
+	c.Define(factcheck.OpDiff(""), openapi.Enum(factcheck.OpDiffEqual, factcheck.OpDiffDelete, factcheck.OpDiffInsert))
+	c.Define(factcheck.StatusDelivery(""), openapi.Enum(factcheck.StatusDeliverySent, factcheck.StatusDeliveryFailed))
+	c.Define(factcheck.TypeMessage(""), openapi.Enum(factcheck.TypeMessageText, factcheck.TypeMessageURL))
+	c.Define(factcheck.TypeUser(""), openapi.Enum(factcheck.TypeUserMessageLINEChat, factcheck.TypeUserMessageLINEGroupChat, factcheck.TypeUserMessageAdmin))
+	c.Define(factcheck.Language(""), openapi.Enum(factcheck.LanguageEnglish, factcheck.LanguageThai))
+	c.Define(factcheck.Role(""), openapi.Enum(factcheck.RoleViewer, factcheck.RoleFactChecker, factcheck.RoleEditor, factcheck.RoleAdmin))
+	c.Define(factcheck.TypeSearch(""), openapi.Enum(factcheck.TypesSearch()...))
+	c.Define(factcheck.Verdict(""), openapi.Enum(
+		factcheck.VerdictTrue,
+		factcheck.VerdictFalse,
+		factcheck.VerdictMisleading,
+		factcheck.VerdictPartlyTrue,
+		factcheck.VerdictUnverifiable,
+		factcheck.VerdictSatire,
+	))
+	c.Schemas["Problem"] = openapi.Object(map[string]*openapi.Schema{
+		"type":        openapi.String(),
+		"title":       openapi.String(),
+		"status":      openapi.Integer(),
+		"detail":      openapi.String(),
+		"instance":    openapi.String(),
+		"code":        {Type: "string", Description: "Stable error code for clients to branch on, e.g. topic_not_found or invalid_status"},
+		"request_id":  openapi.String(),
+		"retry_after": {Type: "integer", Description: "Seconds until rate-limited clients can retry, also sent as Retry-After header"},
+	}, "type", "title", "status", "code")
+
+	s := spec{
+		doc: &openapi.Document{
+			OpenAPI: openapi.Version,
+			Info: openapi.Info{
+				Title:       "factcheck-api",
+				Description: "API of the fact-checking backoffice and LINE chatbot",
+				Version:     "1.0.0",
+			},
+			Paths:      make(map[string]*openapi.PathItem),
+			Components: c,
+		},
+		c: c,
+	}
+	s.misc()
+	s.topics()
+	s.messages()
+	s.messageGroups()
+	s.admin()
+	s.line()
+	return s.doc
+}
+
+func (s spec) misc() {
+	s.add(http.MethodGet, "/", operation{
+		id:          "Echo",
+		tag:         tagMisc,
+		summary:     "Echo request",
+		contentType: openapi.ContentTypeText,
+		response:    openapi.String(),
+	})
+	s.add(http.MethodGet, "/health", operation{
+		id:          "Health",
+		tag:         tagMisc,
+		summary:     "Check health",
+		contentType: openapi.ContentTypeText,
+		response:    openapi.String(),
+	})
+	s.add(http.MethodGet, "/livez", operation{
+		id:          "Livez",
+		tag:         tagMisc,
+		summary:     "Check liveness",
+		description: "Always ok while the server is running, without checking dependencies",
+		response:    s.c.SchemaOf(health.Report{}),
+	})
+	s.add(http.MethodGet, "/readyz", operation{
+		id:          "Readyz",
+		tag:         tagMisc,
+		summary:     "Check readiness",
+		description: "Checks Postgres, schema migrations and enabled integrations. Responds 503 with the same report if any check fails, and 200 with status degraded if only integrations fail.",
+		response:    s.c.SchemaOf(health.Report{}),
+	})
+	s.add(http.MethodGet, "/metrics", operation{
+		id:          "Metrics",
+		tag:         tagMisc,
+		summary:     "Get Prometheus metrics",
+		description: "Metrics of HTTP requests, database queries, transactions and connection pool, and business counters in Prometheus text format",
+		contentType: openapi.ContentTypeText,
+		response:    openapi.String(),
+	})
+	s.add(http.MethodGet, "/openapi.json", operation{
+		id:       "OpenAPI",
+		tag:      tagMisc,
+		summary:  "Get this OpenAPI document",
+		response: &openapi.Schema{Type: "object"},
+	})
+	s.add(http.MethodGet, "/search", operation{
+		id:          "Search",
+		tag:         tagMisc,
+		summary:     "Search topics, message groups and answers",
+		description: "Results are ordered by rank, highest first. Only approved message groups and published answers are searched. Thai queries are segmented into words.",
+		params: append([]openapi.Parameter{
+			{Name: "q", In: "query", Required: true, Schema: openapi.String()},
+			{Name: "in_types", In: "query", Description: "Comma-separated types of results", Schema: openapi.Array(s.c.SchemaOf(factcheck.TypeSearch("")))},
+		}, limitOffset()...),
+		response: openapi.Array(s.c.SchemaOf(factcheck.SearchResult{})),
+	})
+}
+
+func (s spec) topics() {
+	topic := s.c.SchemaOf(factcheck.Topic{})
+	s.add(http.MethodGet, "/topics/all", operation{
+		id:       "ListAllTopics",
+		tag:      tagTopics,
+		summary:  "List all topics",
+		response: openapi.Array(topic),
+	})
+	s.add(http.MethodGet, "/topics/", operation{
+		id:          "ListTopicsHome",
+		tag:         tagTopics,
+		summary:     "List topics with filters",
+		description: "Lists with limit and offset, or with cursor if query cursor is given",
+		params:      append(topicFilters(s.c), paged()...),
+		response:    pageOf[factcheck.Topic](s.c),
+	})
+	s.add(http.MethodGet, "/topics/count", operation{
+		id:          "CountTopicsHome",
+		tag:         tagTopics,
+		summary:     "Count topics with filters",
+		description: "Counts topics by status and verdict, plus key total for all topics",
+		params:      topicFilters(s.c),
+		response:    s.c.SchemaOf(map[string]int64{}),
+	})
+	s.add(http.MethodGet, "/topics/{id}", operation{
+		id:          "GetTopicByID",
+		tag:         tagTopics,
+		summary:     "Get topic",
+		description: "IDs of topics merged into other topics return the topic they were merged into",
+		params:      pathID(),
+		response:    topic,
+		errors:      []int{http.StatusNotFound},
+	})
+	s.add(http.MethodGet, "/topics/{id}/answer", operation{
+		id:       "GetAnswer",
+		tag:      tagTopics,
+		summary:  "Get latest answer of topic, with its sources",
+		params:   pathID(),
+		response: s.c.SchemaOf(factcheck.Answer{}),
+		errors:   []int{http.StatusNotFound},
+	})
+	s.add(http.MethodGet, "/topics/{id}/answers", operation{
+		id:       "ListAnswers",
+		tag:      tagTopics,
+		summary:  "List answers of topic",
+		params:   append(pathID(), limit(), cursor()),
+		response: pageOf[factcheck.Answer](s.c),
+	})
+	s.add(http.MethodGet, "/topics/{id}/answers/{answer_id}/sources", operation{
+		id:          "ListAnswerSources",
+		tag:         tagTopics,
+		summary:     "List sources of answer, by position",
+		description: "Only sources of published answers are public",
+		params:      pathIDAnswer(),
+		response:    openapi.Array(s.c.SchemaOf(factcheck.Source{})),
+		errors:      []int{http.StatusNotFound},
+	})
+	s.add(http.MethodGet, "/topics/{id}/messages", operation{
+		id:       "ListTopicMessages",
+		tag:      tagTopics,
+		summary:  "List messages of topic",
+		params:   append(pathID(), limit(), cursor()),
+		response: pageOf[factcheck.MessageV2](s.c),
+	})
+	s.add(http.MethodGet, "/topics/{id}/message-group", operation{
+		id:       "ListTopicMessageGroups",
+		tag:      tagTopics,
+		summary:  "List message groups of topic",
+		params:   pathID(),
+		response: openapi.Array(s.c.SchemaOf(factcheck.MessageGroup{})),
+	})
+	s.add(http.MethodPost, "/topics/", operation{
+		id:         "CreateTopic",
+		tag:        tagTopics,
+		summary:    "Create topic",
+		permission: factcheck.PermissionTopicEdit,
+		body: openapi.Object(map[string]*openapi.Schema{
+			"name":        openapi.String(),
+			"description": openapi.String(),
+		}),
+		status:   http.StatusCreated,
+		response: topic,
+	})
+	s.add(http.MethodPut, "/topics/{id}/status", operation{
+		id:         "UpdateTopicStatus",
+		tag:        tagTopics,
+		summary:    "Update topic status",
+		permission: factcheck.PermissionTopicResolve,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"status": s.c.SchemaOf(factcheck.StatusTopic(""))}, "status"),
+		response:   topic,
+		errors:     []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/topics/{id}/description", operation{
+		id:         "UpdateTopicDescription",
+		tag:        tagTopics,
+		summary:    "Update topic description",
+		permission: factcheck.PermissionTopicEdit,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"description": openapi.String()}, "description"),
+		response:   topic,
+		errors:     []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/topics/{id}/name", operation{
+		id:         "UpdateTopicName",
+		tag:        tagTopics,
+		summary:    "Update topic name",
+		permission: factcheck.PermissionTopicEdit,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"name": openapi.String()}, "name"),
+		response:   topic,
+		errors:     []int{http.StatusNotFound},
+	})
+	source := openapi.Object(map[string]*openapi.Schema{
+		"type":           s.c.SchemaOf(factcheck.TypeSource("")),
+		"position":       {Type: "integer", Description: "Position of the source in the answer, starting from 1. 0 appends new sources, and keeps position of updated sources"},
+		"url":            openapi.String(),
+		"publisher":      openapi.String(),
+		"archive_url":    openapi.String(),
+		"quote":          openapi.String(),
+		"attachment_url": openapi.String(),
+	}, "type")
+	s.add(http.MethodPost, "/topics/{id}/answers/{answer_id}/sources", operation{
+		id:          "CreateSource",
+		tag:         tagTopics,
+		summary:     "Add source to answer",
+		description: "At least one of url, quote and attachment_url is required. Sources can only be changed while their answer is a draft",
+		permission:  factcheck.PermissionAnswerDraft,
+		params:      pathIDAnswer(),
+		body:        source,
+		status:      http.StatusCreated,
+		response:    s.c.SchemaOf(factcheck.Source{}),
+		errors:      []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPut, "/topics/{id}/answers/{answer_id}/sources/{source_id}", operation{
+		id:         "UpdateSource",
+		tag:        tagTopics,
+		summary:    "Update source of answer",
+		permission: factcheck.PermissionAnswerDraft,
+		params:     pathIDSource(),
+		body:       source,
+		response:   s.c.SchemaOf(factcheck.Source{}),
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodDelete, "/topics/{id}/answers/{answer_id}/sources/{source_id}", operation{
+		id:         "DeleteSource",
+		tag:        tagTopics,
+		summary:    "Delete source of answer",
+		permission: factcheck.PermissionAnswerDraft,
+		params:     pathIDSource(),
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodDelete, "/topics/{id}", operation{
+		id:         "DeleteTopicByID",
+		tag:        tagTopics,
+		summary:    "Delete topic",
+		permission: factcheck.PermissionDelete,
+		params:     pathID(),
+		errors:     []int{http.StatusNotFound},
+	})
+}
+
+func (s spec) messages() {
+	s.add(http.MethodPost, "/messages/", operation{
+		id:          "SubmitMessage",
+		tag:         tagMessages,
+		summary:     "Submit message",
+		description: "Submits message to its group, creating the group if it is new. Users and group chats submitting too quickly get 429 with retry_after",
+		permission:  factcheck.PermissionSubmit,
+		body: openapi.Object(map[string]*openapi.Schema{
+			"text":     openapi.String(),
+			"topic_id": openapi.String(),
+		}, "text"),
+		status: http.StatusCreated,
+		response: openapi.Object(map[string]*openapi.Schema{
+			"message": s.c.SchemaOf(factcheck.MessageV2{}),
+			"group":   s.c.SchemaOf(factcheck.MessageGroup{}),
+			"topic":   s.c.SchemaOf(&factcheck.Topic{}),
+		}, "message", "group", "topic"),
+		errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+	})
+	s.add(http.MethodPut, "/messages/{id}/assign-message-group", operation{
+		id:         "AssignMessageGroup",
+		tag:        tagMessages,
+		summary:    "Assign message to group",
+		permission: factcheck.PermissionAssign,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"group_id": openapi.String()}, "group_id"),
+		response:   s.c.SchemaOf(factcheck.MessageV2{}),
+		errors:     []int{http.StatusNotFound},
+	})
+	s.add(http.MethodDelete, "/messages/", operation{
+		id:          "DeleteMessageByID",
+		tag:         tagMessages,
+		summary:     "Delete message",
+		description: "Deprecated: always fails because the route has no message ID",
+		permission:  factcheck.PermissionDelete,
+	})
+}
+
+func (s spec) messageGroups() {
+	group := s.c.SchemaOf(factcheck.MessageGroup{})
+	s.add(http.MethodGet, "/message-groups/", operation{
+		id:          "ListMessageGroupDynamic",
+		tag:         tagMessageGroups,
+		summary:     "List message groups with filters",
+		description: "Lists with limit and offset, or with cursor if query cursor is given",
+		params: append([]openapi.Parameter{
+			{Name: "like_message_text", In: "query", Schema: openapi.String()},
+			{Name: "in_id", In: "query", Description: "Comma-separated IDs", Schema: openapi.Array(openapi.String())},
+			{Name: "not_in_id", In: "query", Description: "Comma-separated IDs", Schema: openapi.Array(openapi.String())},
+			{Name: "in_statuses", In: "query", Description: "Comma-separated statuses", Schema: openapi.Array(s.c.SchemaOf(factcheck.StatusMGroup("")))},
+			{Name: "in_languages", In: "query", Description: "Comma-separated languages", Schema: openapi.Array(s.c.SchemaOf(factcheck.Language("")))},
+		}, paged()...),
+		response: pageOf[factcheck.MessageGroup](s.c),
+	})
+	zero, one := 0.0, 1.0
+	s.add(http.MethodGet, "/message-groups/{id}/similar", operation{
+		id:      "ListSimilarGroups",
+		tag:     tagMessageGroups,
+		summary: "List groups similar to group",
+		params: append(pathID(),
+			limit(),
+			openapi.Parameter{Name: "threshold", In: "query", Description: "Minimum similarity, from 0 to 1", Schema: &openapi.Schema{Type: "number", Minimum: &zero, Maximum: &one}},
+		),
+		response: openapi.Array(s.c.SchemaOf(factcheck.MessageGroupSimilar{})),
+		errors:   []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/message-groups/{id}/assign-topic", operation{
+		id:         "AssignGroupTopic",
+		tag:        tagMessageGroups,
+		summary:    "Assign group to topic",
+		permission: factcheck.PermissionAssign,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"topic_id": openapi.String()}, "topic_id"),
+		response:   group,
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPut, "/message-groups/{id}/approve", operation{
+		id:         "ApproveGroup",
+		tag:        tagMessageGroups,
+		summary:    "Approve group",
+		permission: factcheck.PermissionModerate,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"reason": openapi.String()}),
+		response:   group,
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPut, "/message-groups/{id}/reject", operation{
+		id:         "RejectGroup",
+		tag:        tagMessageGroups,
+		summary:    "Reject group",
+		permission: factcheck.PermissionModerate,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"reason": openapi.String()}, "reason"),
+		response:   group,
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodDelete, "/message-groups/{id}", operation{
+		id:         "DeleteGroupByID",
+		tag:        tagMessageGroups,
+		summary:    "Delete group",
+		permission: factcheck.PermissionDelete,
+		params:     pathID(),
+		errors:     []int{http.StatusNotFound},
+	})
+}
+
+func (s spec) admin() {
+	s.add(http.MethodPut, "/admin/messages/assign/{id}", operation{
+		id:         "AdminAssignMessageGroup",
+		tag:        tagAdmin,
+		summary:    "Assign message to group",
+		permission: factcheck.PermissionAssign,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"group_id": openapi.String()}, "group_id"),
+		response:   s.c.SchemaOf(factcheck.MessageV2{}),
+		errors:     []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/admin/message-groups/assign/{id}", operation{
+		id:         "AdminAssignGroupTopic",
+		tag:        tagAdmin,
+		summary:    "Assign group to topic",
+		permission: factcheck.PermissionAssign,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"topic_id": openapi.String()}, "topic_id"),
+		response:   s.c.SchemaOf(factcheck.MessageGroup{}),
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPost, "/admin/topics/resolve/{id}", operation{
+		id:          "PostAnswer",
+		tag:         tagAdmin,
+		summary:     "Answer and resolve topic",
+		description: "Submitters of the topic's messages are notified in background",
+		permission:  factcheck.PermissionTopicResolve,
+		params:      pathID(),
+		body: openapi.Object(map[string]*openapi.Schema{
+			"text":    openapi.String(),
+			"verdict": s.c.SchemaOf(factcheck.Verdict("")),
+		}, "text", "verdict"),
+		response: s.c.SchemaOf(factcheck.Answer{}),
+		errors:   []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPost, "/admin/topics/merge/{id}", operation{
+		id:          "MergeTopics",
+		tag:         tagAdmin,
+		summary:     "Merge topic into another topic",
+		description: "Groups, messages and answers are moved to the target topic, and the merged topic's ID redirects to the target in all topic reads",
+		permission:  factcheck.PermissionTopicMerge,
+		params:      pathID(),
+		body:        openapi.Object(map[string]*openapi.Schema{"target_id": openapi.String()}, "target_id"),
+		response:    s.c.SchemaOf(factcheck.TopicMerge{}),
+		errors:      []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPost, "/admin/topics/split/{id}", operation{
+		id:          "SplitTopic",
+		tag:         tagAdmin,
+		summary:     "Split groups of topic into a new topic",
+		description: "Groups and their messages are moved to the new topic",
+		permission:  factcheck.PermissionTopicMerge,
+		params:      pathID(),
+		body: openapi.Object(map[string]*openapi.Schema{
+			"name":        openapi.String(),
+			"description": openapi.String(),
+			"group_ids":   openapi.Array(openapi.String()),
+		}, "name", "group_ids"),
+		status:   http.StatusCreated,
+		response: s.c.SchemaOf(factcheck.TopicSplit{}),
+		errors:   []int{http.StatusNotFound, http.StatusConflict},
+	})
+	answer := openapi.Object(map[string]*openapi.Schema{
+		"text":    openapi.String(),
+		"verdict": s.c.SchemaOf(factcheck.Verdict("")),
+	}, "text", "verdict")
+	s.add(http.MethodPost, "/admin/topics/{id}/reopen", operation{
+		id:          "ReopenTopic",
+		tag:         tagAdmin,
+		summary:     "Re-open resolved topic",
+		description: "The topic goes back to pending, and keeps its published answer until a correction is published",
+		permission:  factcheck.PermissionTopicResolve,
+		params:      pathID(),
+		response:    s.c.SchemaOf(factcheck.Topic{}),
+		errors:      []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPost, "/admin/topics/{id}/drafts", operation{
+		id:          "DraftAnswer",
+		tag:         tagAdmin,
+		summary:     "Draft answer to topic",
+		description: "The draft is the next revision of the topic's answers, and is not public until published",
+		permission:  factcheck.PermissionAnswerDraft,
+		params:      pathID(),
+		body:        answer,
+		status:      http.StatusCreated,
+		response:    s.c.SchemaOf(factcheck.Answer{}),
+		errors:      []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/admin/topics/{id}/drafts/{answer_id}", operation{
+		id:         "UpdateDraftAnswer",
+		tag:        tagAdmin,
+		summary:    "Update draft answer",
+		permission: factcheck.PermissionAnswerDraft,
+		params:     pathIDAnswer(),
+		body:       answer,
+		response:   s.c.SchemaOf(factcheck.Answer{}),
+		errors:     []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodPost, "/admin/topics/{id}/drafts/{answer_id}/publish", operation{
+		id:          "PublishAnswer",
+		tag:         tagAdmin,
+		summary:     "Publish draft answer and resolve topic",
+		description: "The draft corrects the previously published answer if any. Submitters of the topic's messages are notified in background",
+		permission:  factcheck.PermissionTopicResolve,
+		params:      pathIDAnswer(),
+		response:    s.c.SchemaOf(factcheck.Answer{}),
+		errors:      []int{http.StatusNotFound, http.StatusConflict},
+	})
+	s.add(http.MethodGet, "/admin/topics/{id}/revisions", operation{
+		id:         "ListAnswerRevisions",
+		tag:        tagAdmin,
+		summary:    "List all answer revisions of topic including drafts, latest first",
+		permission: factcheck.PermissionRead,
+		params:     pathID(),
+		response:   openapi.Array(s.c.SchemaOf(factcheck.Answer{})),
+	})
+	s.add(http.MethodGet, "/admin/topics/{id}/revisions/diff", operation{
+		id:         "DiffAnswerRevisions",
+		tag:        tagAdmin,
+		summary:    "Diff two answer revisions of topic",
+		permission: factcheck.PermissionRead,
+		params: append(pathID(),
+			openapi.Parameter{Name: "from", In: "query", Required: true, Schema: openapi.Integer()},
+			openapi.Parameter{Name: "to", In: "query", Required: true, Schema: openapi.Integer()},
+		),
+		response: s.c.SchemaOf(factcheck.AnswerDiff{}),
+		errors:   []int{http.StatusNotFound},
+	})
+	s.add(http.MethodGet, "/admin/topics/{id}/deliveries", operation{
+		id:         "ListTopicDeliveries",
+		tag:        tagAdmin,
+		summary:    "List answer deliveries of topic",
+		permission: factcheck.PermissionRead,
+		params:     pathID(),
+		response:   openapi.Array(s.c.SchemaOf(factcheck.Delivery{})),
+	})
+	s.add(http.MethodGet, "/admin/audit", operation{
+		id:         "ListAuditEvents",
+		tag:        tagAdmin,
+		summary:    "List audit events, latest first",
+		permission: factcheck.PermissionAuditRead,
+		params: append([]openapi.Parameter{
+			{Name: "actor", In: "query", Schema: openapi.String()},
+			{Name: "target", In: "query", Schema: openapi.String()},
+			{Name: "action", In: "query", Schema: openapi.String()},
+			{Name: "since", In: "query", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+			{Name: "until", In: "query", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+		}, limitOffset()...),
+		response: openapi.Array(s.c.SchemaOf(factcheck.AuditEvent{})),
+	})
+	s.add(http.MethodGet, "/admin/stream", operation{
+		id:      "StreamEvents",
+		tag:     tagAdmin,
+		summary: "Stream events as they happen",
+		description: "Streams events like submitted messages, new groups, assignments and resolutions as server-sent events. " +
+			"Each event has event type as event, seq as id and the event as data. " +
+			"Clients resume after Last-Event-ID header or last_event_id query, otherwise only new events are streamed.",
+		permission: factcheck.PermissionRead,
+		params: []openapi.Parameter{
+			{Name: "topic_id", In: "query", Schema: openapi.String()},
+			{Name: "type", In: "query", Schema: openapi.String()},
+			{Name: "last_event_id", In: "query", Schema: openapi.Integer()},
+			{Name: "Last-Event-ID", In: "header", Schema: openapi.Integer()},
+		},
+		contentType: openapi.ContentTypeEvents,
+		response:    openapi.String(),
+	})
+	role := s.c.SchemaOf(factcheck.UserRole{})
+	s.add(http.MethodGet, "/admin/roles", operation{
+		id:         "ListRoles",
+		tag:        tagAdmin,
+		summary:    "List granted roles",
+		permission: factcheck.PermissionRolesManage,
+		response:   openapi.Array(role),
+	})
+	s.add(http.MethodGet, "/admin/roles/{id}", operation{
+		id:         "GetRole",
+		tag:        tagAdmin,
+		summary:    "Get role of user",
+		permission: factcheck.PermissionRolesManage,
+		params:     pathID(),
+		response:   role,
+		errors:     []int{http.StatusNotFound},
+	})
+	s.add(http.MethodPut, "/admin/roles/{id}", operation{
+		id:         "PutRole",
+		tag:        tagAdmin,
+		summary:    "Grant role to user",
+		permission: factcheck.PermissionRolesManage,
+		params:     pathID(),
+		body:       openapi.Object(map[string]*openapi.Schema{"role": s.c.SchemaOf(factcheck.Role(""))}, "role"),
+		response:   role,
+	})
+	s.add(http.MethodDelete, "/admin/roles/{id}", operation{
+		id:         "DeleteRole",
+		tag:        tagAdmin,
+		summary:    "Revoke role of user",
+		permission: factcheck.PermissionRolesManage,
+		params:     pathID(),
+		errors:     []int{http.StatusNotFound},
+	})
+}
+
+func (s spec) line() {
+	s.add(http.MethodPost, "/line/webhook", operation{
+		id:          "LINEWebhook",
+		tag:         tagLINE,
+		summary:     "Receive LINE webhook events",
+		description: "Authenticated by LINE signature instead of API credentials",
+		params: []openapi.Parameter{
+			{Name: "x-line-signature", In: "header", Required: true, Schema: openapi.String()},
+		},
+		body:   &openapi.Schema{Type: "object"},
+		errors: []int{http.StatusUnauthorized},
+	})
+}
+
+// add adds operation op of method and path, with common error responses
+func (s spec) add(method string, path string, op operation) {
+	item, ok := s.doc.Paths[path]
+	if !ok {
+		item = &openapi.PathItem{}
+		s.doc.Paths[path] = item
+	}
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &openapi.Response{Description: http.StatusText(status)}
+	switch {
+	case op.response == nil:
+		success.Content = map[string]openapi.MediaType{openapi.ContentTypeText: {Schema: openapi.String()}}
+	case op.contentType != "":
+		success.Content = map[string]openapi.MediaType{op.contentType: {Schema: op.response}}
+	default:
+		success.Content = map[string]openapi.MediaType{openapi.ContentTypeJSON: {Schema: op.response}}
+	}
+	o := &openapi.Operation{
+		OperationID: op.id,
+		Summary:     op.summary,
+		Description: op.description,
+		Tags:        []string{op.tag},
+		Parameters:  op.params,
+		Responses:   map[string]*openapi.Response{strconv.Itoa(status): success},
+	}
+	errs := append([]int{http.StatusInternalServerError}, op.errors...)
+	if len(op.params) != 0 || op.body != nil {
+		errs = append(errs, http.StatusBadRequest)
+	}
+	if op.body != nil {
+		o.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{openapi.ContentTypeJSON: {Schema: op.body}},
+		}
+	}
+	if op.permission != "" {
+		o.Security = []openapi.Requirement{{"apiKey": {}}, {"bearer": {}}}
+		o.Description = strings.TrimSpace(o.Description + "\n\nRequires permission " + string(op.permission))
+		errs = append(errs, http.StatusUnauthorized, http.StatusForbidden)
+	}
+	for _, code := range errs {
+		o.Responses[strconv.Itoa(code)] = &openapi.Response{
+			Description: http.StatusText(code),
+			Content:     map[string]openapi.MediaType{openapi.ContentTypeProblem: {Schema: openapi.Ref("Problem")}},
+		}
+	}
+	(*item)[strings.ToLower(method)] = o
+}
+
+func pathID() []openapi.Parameter {
+	return []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: openapi.String()}}
+}
+
+func pathIDAnswer() []openapi.Parameter {
+	return append(pathID(), openapi.Parameter{Name: "answer_id", In: "path", Required: true, Schema: openapi.String()})
+}
+
+func pathIDSource() []openapi.Parameter {
+	return append(pathIDAnswer(), openapi.Parameter{Name: "source_id", In: "path", Required: true, Schema: openapi.String()})
+}
+
+func limit() openapi.Parameter {
+	return openapi.Parameter{Name: "limit", In: "query", Schema: openapi.Integer()}
+}
+
+func limitOffset() []openapi.Parameter {
+	return []openapi.Parameter{limit(), {Name: "offset", In: "query", Schema: openapi.Integer()}}
+}
+
+func cursor() openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "cursor",
+		In:          "query",
+		Description: "Opaque cursor from previous pages, or empty for the first page. Responses are pages instead of arrays if given.",
+		Schema:      openapi.String(),
+	}
+}
+
+// paged returns parameters of lists paginated with either limit and offset, or cursor
+func paged() []openapi.Parameter {
+	return append(limitOffset(), cursor())
+}
+
+// pageOf returns schema of responses from lists paginated with either limit and offset, or cursor
+func pageOf[T any](c *openapi.Components) *openapi.Schema {
+	var zero T
+	return &openapi.Schema{OneOf: []*openapi.Schema{
+		openapi.Array(c.SchemaOf(zero)),
+		c.SchemaOf(repo.Page[T]{}),
+	}}
+}
+
+func topicFilters(c *openapi.Components) []openapi.Parameter {
+	return []openapi.Parameter{
+		{Name: "like_id", In: "query", Schema: openapi.String()},
+		{Name: "like_message_text", In: "query", Schema: openapi.String()},
+		{Name: "in_statuses", In: "query", Description: "Comma-separated statuses", Schema: openapi.Array(c.SchemaOf(factcheck.StatusTopic("")))},
+		{Name: "in_verdicts", In: "query", Description: "Comma-separated verdicts", Schema: openapi.Array(c.SchemaOf(factcheck.Verdict("")))},
+		{Name: "in_languages", In: "query", Description: "Comma-separated languages", Schema: openapi.Array(c.SchemaOf(factcheck.Language("")))},
+	}
+}
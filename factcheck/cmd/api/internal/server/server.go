@@ -11,8 +11,13 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/kaogeek/line-fact-check/pillars"
 
+	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -21,52 +26,114 @@ type Server interface {
 	Shutdown(context.Context) error
 }
 
-func New(conf config.Config, h handler.Handler) (*http.Server, func()) {
+func New(
+	conf config.Config,
+	h handler.Handler,
+	authenticator auth.Authenticator,
+	authorizer auth.Authorizer,
+	checker health.Checker,
+	broker *stream.Broker,
+) (*http.Server, func()) {
+	// can returns middleware for the permission matrix
+	can := func(p factcheck.Permission) func(http.Handler) http.Handler {
+		return handler.MiddlewarePermit(authorizer, p)
+	}
+
 	admin := chi.NewMux()
 	admin.Use(
-		handler.MiddlewareAuth,
+		handler.MiddlewareRequireAuth,
 		handler.MiddlewareAdmin,
 	)
-	admin.Put("/messages/assign/{id}", h.AssignMessageGroup)
-	admin.Put("/message-groups/assign/{id}", h.AssignGroupTopic)
-	admin.Post("/topics/resolve/{id}", h.PostAnswer)
+	admin.With(can(factcheck.PermissionAssign)).Put("/messages/assign/{id}", h.AssignMessageGroup)
+	admin.With(can(factcheck.PermissionAssign)).Put("/message-groups/assign/{id}", h.AssignGroupTopic)
+	admin.With(can(factcheck.PermissionTopicResolve)).Post("/topics/resolve/{id}", h.PostAnswer)
+	admin.With(can(factcheck.PermissionTopicMerge)).Post("/topics/merge/{id}", h.MergeTopics)
+	admin.With(can(factcheck.PermissionTopicMerge)).Post("/topics/split/{id}", h.SplitTopic)
+	admin.With(can(factcheck.PermissionTopicResolve)).Post("/topics/{id}/reopen", h.ReopenTopic)
+	admin.With(can(factcheck.PermissionAnswerDraft)).Post("/topics/{id}/drafts", h.DraftAnswer)
+	admin.With(can(factcheck.PermissionAnswerDraft)).Put("/topics/{id}/drafts/{answer_id}", h.UpdateDraftAnswer)
+	admin.With(can(factcheck.PermissionTopicResolve)).Post("/topics/{id}/drafts/{answer_id}/publish", h.PublishAnswer)
+	admin.With(can(factcheck.PermissionRead)).Get("/topics/{id}/revisions", h.ListAnswerRevisions)
+	admin.With(can(factcheck.PermissionRead)).Get("/topics/{id}/revisions/diff", h.DiffAnswerRevisions)
+	admin.With(can(factcheck.PermissionRead)).Get("/topics/{id}/deliveries", h.ListTopicDeliveries)
+	admin.With(can(factcheck.PermissionAuditRead)).Get("/audit", h.ListAuditEvents)
+	admin.With(can(factcheck.PermissionRead)).Get("/stream", handler.HandlerStream(broker))
+	admin.Group(func(r chi.Router) {
+		r.Use(can(factcheck.PermissionRolesManage))
+		r.Get("/roles", h.ListRoles)
+		r.Get("/roles/{id}", h.GetRole)
+		r.Put("/roles/{id}", h.PutRole)
+		r.Delete("/roles/{id}", h.DeleteRole)
+	})
 
 	messages := chi.NewMux()
-	messages.Post("/", h.SubmitMessage)
-	messages.Put("/{id}/assign-message-group", h.AssignMessageGroup)
-	messages.Delete("/", h.DeleteMessageByID)
+	messages.Group(func(r chi.Router) {
+		r.Use(handler.MiddlewareRequireAuth)
+		r.With(can(factcheck.PermissionSubmit)).Post("/", h.SubmitMessage)
+		r.With(can(factcheck.PermissionAssign)).Put("/{id}/assign-message-group", h.AssignMessageGroup)
+		r.With(can(factcheck.PermissionDelete)).Delete("/", h.DeleteMessageByID)
+	})
 
 	messageGroups := chi.NewMux()
 	messageGroups.Get("/", h.ListMessageGroupDynamic)
-	messageGroups.Put("/{id}/assign-topic", h.AssignGroupTopic)
-	messageGroups.Delete("/{id}", h.DeleteGroupByID)
+	messageGroups.Get("/{id}/similar", h.ListSimilarGroups)
+	messageGroups.Group(func(r chi.Router) {
+		r.Use(handler.MiddlewareRequireAuth)
+		r.With(can(factcheck.PermissionAssign)).Put("/{id}/assign-topic", h.AssignGroupTopic)
+		r.With(can(factcheck.PermissionModerate)).Put("/{id}/approve", h.ApproveGroup)
+		r.With(can(factcheck.PermissionModerate)).Put("/{id}/reject", h.RejectGroup)
+		r.With(can(factcheck.PermissionDelete)).Delete("/{id}", h.DeleteGroupByID)
+	})
+
+	line := chi.NewMux()
+	line.Post("/webhook", h.LINEWebhook)
 
 	topics := chi.NewMux()
-	topics.Post("/", h.CreateTopic) // TODO: move to admin API
 	topics.Get("/all", h.ListAllTopics)
 	topics.Get("/", h.ListTopicsHome)
 	topics.Get("/count", h.CountTopicsHome)
 	topics.Get("/{id}", h.GetTopicByID)
 	topics.Get("/{id}/answer", h.GetAnswer)
 	topics.Get("/{id}/answers", h.ListAnswers)
+	topics.Get("/{id}/answers/{answer_id}/sources", h.ListAnswerSources)
 	topics.Get("/{id}/messages", h.ListTopicMessages)
 	topics.Get("/{id}/message-group", h.ListTopicMessageGroups)
-	topics.Put("/{id}/status", h.UpdateTopicStatus)
-	topics.Put("/{id}/description", h.UpdateTopicDescription)
-	topics.Put("/{id}/name", h.UpdateTopicName)
-	topics.Delete("/{id}", h.DeleteTopicByID)
+	topics.Group(func(r chi.Router) {
+		r.Use(handler.MiddlewareRequireAuth)
+		r.With(can(factcheck.PermissionTopicEdit)).Post("/", h.CreateTopic) // TODO: move to admin API
+		r.With(can(factcheck.PermissionTopicResolve)).Put("/{id}/status", h.UpdateTopicStatus)
+		r.With(can(factcheck.PermissionTopicEdit)).Put("/{id}/description", h.UpdateTopicDescription)
+		r.With(can(factcheck.PermissionTopicEdit)).Put("/{id}/name", h.UpdateTopicName)
+		r.With(can(factcheck.PermissionAnswerDraft)).Post("/{id}/answers/{answer_id}/sources", h.CreateSource)
+		r.With(can(factcheck.PermissionAnswerDraft)).Put("/{id}/answers/{answer_id}/sources/{source_id}", h.UpdateSource)
+		r.With(can(factcheck.PermissionAnswerDraft)).Delete("/{id}/answers/{answer_id}/sources/{source_id}", h.DeleteSource)
+		r.With(can(factcheck.PermissionDelete)).Delete("/{id}", h.DeleteTopicByID)
+	})
 
+	doc := Spec()
 	r := chi.NewRouter()
+	r.Use(handler.MiddlewareTracing)
+	r.Use(handler.MiddlewareMetrics)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(handler.MiddlewareAuth(authenticator))
+	if conf.HTTP.ValidateRequests {
+		r.Use(handler.MiddlewareValidate(doc, conf.HTTP.MaxBytesBody))
+	}
 	r.Handle("/", pillars.HandlerEcho(conf.AppName))
 	r.Handle("/health", pillars.HandlerOk(conf.AppName))
+	r.Get("/livez", handler.HandlerLivez)
+	r.Get("/readyz", handler.HandlerReadyz(checker))
+	r.Get("/metrics", metrics.Handler().ServeHTTP)
+	r.Get("/openapi.json", handler.HandlerOpenAPI(doc))
+	r.Get("/search", h.Search)
 	r.Mount("/admin", admin)
 	r.Mount("/topics", topics)
 	r.Mount("/messages", messages)
 	r.Mount("/message-groups", messageGroups)
+	r.Mount("/line", line)
 
 	server := &http.Server{
 		Addr:         utils.DefaultIfZero(conf.HTTP.ListenAddr, ":8080"),
@@ -74,6 +141,10 @@ func New(conf config.Config, h handler.Handler) (*http.Server, func()) {
 		ReadTimeout:  utils.DefaultIfZero(time.Duration(conf.HTTP.TimeoutMsRead)*time.Millisecond, time.Second),
 		WriteTimeout: utils.DefaultIfZero(time.Duration(conf.HTTP.TimeoutMsWrite)*time.Millisecond, time.Second),
 	}
+	if broker != nil {
+		// Streams never end on their own, so they are closed for shutdown to complete
+		server.RegisterOnShutdown(broker.Close)
+	}
 	cleanup := func() {
 		ctx := context.Background()
 		start := utils.TimeNow()
@@ -0,0 +1,215 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/handler"
+	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/internal/server"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/openapi"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+func newServer(t *testing.T, conf config.Config) http.Handler {
+	t.Helper()
+	srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, nil), auth.Chain{}, auth.Authorizer{}, health.Checker{}, nil)
+	return srv.Handler
+}
+
+// TestSpecRoutes fails when routes are registered without operations in server.Spec, or vice versa
+func TestSpecRoutes(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := server.Spec()
+	routes, ok := newServer(t, conf).(chi.Routes)
+	if !ok {
+		t.Fatalf("unexpected handler type")
+	}
+	registered := make(map[string]bool)
+	err = chi.Walk(routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		item, ok := doc.Paths[route]
+		if !ok {
+			t.Errorf("route %s %s has no path in spec", method, route)
+			return nil
+		}
+		registered[route] = true
+		if _, ok := (*item)[strings.ToLower(method)]; !ok && !routedAllMethods(item) {
+			t.Errorf("route %s %s has no operation in spec", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path := range doc.Paths {
+		if !registered[path] {
+			t.Errorf("path %s in spec is not routed", path)
+		}
+	}
+}
+
+// routedAllMethods returns whether item is for routes with handlers of all methods,
+// i.e. registered with chi.Router.Handle. Only their GET operations are documented.
+func routedAllMethods(item *openapi.PathItem) bool {
+	_, ok := (*item)["get"]
+	return ok && len(*item) == 1
+}
+
+func TestSpecOperationIDs(t *testing.T) {
+	seen := make(map[string]string)
+	for path, item := range server.Spec().Paths {
+		for method, op := range *item {
+			if op.OperationID == "" {
+				t.Fatalf("missing operationId of %s %s", method, path)
+			}
+			if other, ok := seen[op.OperationID]; ok {
+				t.Fatalf("duplicate operationId %s of %s %s and %s", op.OperationID, method, path, other)
+			}
+			seen[op.OperationID] = method + " " + path
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("serve document", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newServer(t, conf).ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/openapi.json", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		var doc openapi.Document
+		err := json.NewDecoder(rec.Body).Decode(&doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.OpenAPI != openapi.Version || len(doc.Paths) == 0 {
+			t.Fatalf("unexpected document %+v", doc)
+		}
+		for _, name := range []string{"Topic", "MessageV2", "MessageGroup", "Answer", "PageTopic", "Problem"} {
+			if _, ok := doc.Components.Schemas[name]; !ok {
+				t.Fatalf("missing schema %s", name)
+			}
+		}
+	})
+
+	t.Run("validate requests", func(t *testing.T) {
+		conf := conf
+		conf.HTTP.ValidateRequests = true
+		conf.HTTP.MaxBytesBody = 64
+		h := newServer(t, conf)
+		type testCase struct {
+			method, target, body string
+			status               int
+		}
+		tests := []testCase{
+			{http.MethodPut, "/topics/some-id/status", `{"status":"TOPIC_UNKNOWN"}`, http.StatusBadRequest},
+			{http.MethodPut, "/topics/some-id/name", `{}`, http.StatusBadRequest},
+			{http.MethodPut, "/topics/some-id/name", `{"name":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+			{http.MethodGet, "/topics/?in_verdicts=VERDICT_MAYBE", "", http.StatusBadRequest},
+			{http.MethodGet, "/search", "", http.StatusBadRequest},
+			{http.MethodPut, "/topics/some-id/status", `{"status":"TOPIC_RESOLVED"}`, http.StatusUnauthorized}, // Valid, but unauthenticated
+			{http.MethodGet, "/no-such-route", "", http.StatusNotFound},
+		}
+		for _, tc := range tests {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), tc.method, tc.target, strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Fatalf("unexpected status %d for %s %s %s: %s", rec.Code, tc.method, tc.target, tc.body, rec.Body.String())
+			}
+		}
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newServer(t, conf)
+	for _, target := range []string{"/topics/some-id/answers/other-id/sources", "/no-such-route"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodPost, target, nil))
+	}
+	// Methods of clients are not labels
+	for _, method := range []string{"FOO", "BAR"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), method, "/no-such-route", nil))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`factcheck_http_requests_total{method="POST",route="/topics/{id}/answers/{answer_id}/sources",status="401"} 1`,
+		`factcheck_http_requests_total{method="POST",route="unmatched",status="404"} 1`,
+		`factcheck_http_request_duration_seconds_count{method="POST",route="/topics/{id}/answers/{answer_id}/sources"} 1`,
+		`factcheck_http_request_duration_seconds_count{method="OTHER",route="unmatched"} 2`,
+		"go_goroutines ",
+		"process_cpu_seconds_total ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("missing metric %s in:\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "FOO") {
+		t.Fatalf("unexpected method of client in:\n%s", body)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := health.NewChecker(time.Second, map[string]health.Check{
+		health.CheckPostgres:   func(context.Context) error { return nil },
+		health.CheckMigrations: func(context.Context) error { return errors.New("pending migrations") },
+	})
+	srv, _ := server.New(conf, handler.New(conf, repo.Repository{}, nil), auth.Chain{}, auth.Authorizer{}, checker, nil)
+
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected livez status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected readyz status %d", rec.Code)
+	}
+	var report health.Report
+	err = json.Unmarshal(rec.Body.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != health.StatusFail {
+		t.Fatalf("unexpected report status: %+v", report)
+	}
+	if report.Checks[health.CheckPostgres].Status != health.StatusOK {
+		t.Fatalf("unexpected postgres result: %+v", report.Checks)
+	}
+	if report.Checks[health.CheckMigrations].Error != "pending migrations" {
+		t.Fatalf("unexpected migrations result: %+v", report.Checks)
+	}
+}
@@ -9,7 +9,11 @@ import (
 	"os/signal"
 	"syscall"
 
+	"go.opentelemetry.io/otel"
+
 	"github.com/kaogeek/line-fact-check/factcheck/cmd/api/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 )
 
 func main() {
@@ -24,6 +28,25 @@ func main() {
 		slog.InfoContext(ctx, "[main] server cleanup completed, exiting...")
 	}()
 
+	// Logs are tagged with IDs of traces, which are exported as configured
+	tracerProvider, stopTracing, err := tracing.New(container.Config)
+	if err != nil {
+		panic(err)
+	}
+	defer stopTracing()
+	otel.SetTracerProvider(tracerProvider)
+	slog.SetDefault(slog.New(tracing.NewLogHandler(slog.NewTextHandler(os.Stderr, nil))))
+
+	// Refuse to serve against an outdated schema, see cmd/factcheck
+	err = migrate.Check(ctx, container.PostgresConn)
+	if err != nil {
+		panic(err)
+	}
+
+	relayCtx, stopRelay := context.WithCancel(ctx)
+	defer stopRelay()
+	go container.Relay.Run(relayCtx)
+
 	quit := make(chan os.Signal, 1) // Buffered so it won't block on 2x Ctrl-C
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
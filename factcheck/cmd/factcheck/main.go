@@ -0,0 +1,197 @@
+// Command factcheck provides operational commands, like schema migrations.
+//
+//	factcheck migrate up          # Apply all pending migrations
+//	factcheck migrate down [n]    # Revert the latest n migrations, defaults to 1
+//	factcheck migrate status      # List migrations and when they were applied
+//	factcheck search backfill     # Re-tokenize all rows for full-text search
+//	factcheck groups backfill     # Recompute hashes by which submissions join message groups
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexflint/go-arg"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+type cli struct {
+	Migrate *cmdMigrate `arg:"subcommand:migrate" help:"manage schema migrations"`
+	Search  *cmdSearch  `arg:"subcommand:search" help:"manage full-text search"`
+	Groups  *cmdGroups  `arg:"subcommand:groups" help:"manage message groups"`
+}
+
+type cmdMigrate struct {
+	Up     *cmdMigrateUp     `arg:"subcommand:up" help:"apply all pending migrations"`
+	Down   *cmdMigrateDown   `arg:"subcommand:down" help:"revert the latest migrations"`
+	Status *cmdMigrateStatus `arg:"subcommand:status" help:"list migrations and when they were applied"`
+}
+
+type (
+	cmdMigrateUp     struct{}
+	cmdMigrateStatus struct{}
+	cmdMigrateDown   struct {
+		Steps int `arg:"positional" default:"1" help:"number of migrations to revert"`
+	}
+)
+
+type cmdSearch struct {
+	Backfill *cmdSearchBackfill `arg:"subcommand:backfill" help:"re-tokenize all topics, message groups and answers"`
+}
+
+type cmdSearchBackfill struct {
+	Batch int `arg:"--batch" default:"500" help:"number of rows re-tokenized per transaction"`
+}
+
+type cmdGroups struct {
+	Backfill *cmdGroupsBackfill `arg:"subcommand:backfill" help:"recompute hashes of all message groups, e.g. of groups created before texts were normalized"`
+}
+
+type cmdGroupsBackfill struct {
+	Batch int `arg:"--batch" default:"100" help:"number of groups updated per transaction"`
+}
+
+func main() {
+	c := cli{}
+	p := arg.MustParse(&c)
+	noMigrate := c.Migrate == nil || c.Migrate.Up == nil && c.Migrate.Down == nil && c.Migrate.Status == nil
+	noSearch := c.Search == nil || c.Search.Backfill == nil
+	noGroups := c.Groups == nil || c.Groups.Backfill == nil
+	if noMigrate && noSearch && noGroups {
+		p.WriteHelp(os.Stderr)
+		os.Exit(2)
+	}
+	conf, err := config.New()
+	if err != nil {
+		panic(err)
+	}
+	pool, cleanup, err := postgres.NewConn(conf)
+	if err != nil {
+		panic(err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	repository := repo.New(postgres.NewQueries(pool), pool)
+	switch {
+	case c.Search != nil:
+		err = backfillTokens(ctx, repository, c.Search.Backfill.Batch)
+		if err != nil {
+			panic(err)
+		}
+		return
+
+	case c.Groups != nil:
+		// Short links are resolved like in submissions, so that groups get the same keys
+		service := core.New(conf, repository, links.NewResolver(conf), links.NewFetcher(conf), language.NewDetectorScript(), ratelimit.Policy{})
+		err = backfill(ctx, "rekey groups", c.Groups.Backfill.Batch, func(afterID string) (string, error) {
+			return service.RekeyGroups(ctx, afterID, c.Groups.Backfill.Batch)
+		})
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+	migrator, err := migrate.New(pool)
+	if err != nil {
+		panic(err)
+	}
+
+	switch {
+	case c.Migrate.Up != nil:
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			slog.InfoContext(ctx, "applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			panic(err)
+		}
+		slog.InfoContext(ctx, "schema is up to date", "applied", len(applied))
+
+	case c.Migrate.Down != nil:
+		reverted, err := migrator.Down(ctx, c.Migrate.Down.Steps)
+		for _, m := range reverted {
+			slog.InfoContext(ctx, "reverted migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			panic(err)
+		}
+
+	case c.Migrate.Status != nil:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			panic(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED_AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		err = w.Flush()
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// backfillTokens re-tokenizes rows of every search type in batches of one transaction each,
+// for rows written before search tokens, or after the tokenizer changed
+func backfillTokens(ctx context.Context, repository repo.Repository, batch int) error {
+	for _, typ := range factcheck.TypesSearch() {
+		err := backfill(ctx, "re-tokenize "+string(typ), batch, func(afterID string) (string, error) {
+			tx, err := repository.Begin(ctx)
+			if err != nil {
+				return "", err
+			}
+			lastID, err := repository.Search.Retokenize(ctx, typ, afterID, batch, repo.WithTx(tx))
+			if err != nil {
+				_ = tx.Rollback(ctx)
+				return "", err
+			}
+			return lastID, tx.Commit(ctx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfill calls fn with the last ID of its previous batch, starting from empty ID,
+// until fn returns empty ID after the last batch
+func backfill(ctx context.Context, job string, batch int, fn func(afterID string) (string, error)) error {
+	if batch <= 0 {
+		return fmt.Errorf("bad batch size %d", batch)
+	}
+	afterID, batches := "", 0
+	for {
+		lastID, err := fn(afterID)
+		if err != nil {
+			return fmt.Errorf("error in batch of %s after '%s': %w", job, afterID, err)
+		}
+		if lastID == "" {
+			break
+		}
+		afterID, batches = lastID, batches+1
+		slog.InfoContext(ctx, "backfilled batch", "job", job, "last_id", lastID)
+	}
+	slog.InfoContext(ctx, "backfilled all rows", "job", job, "batches", batches)
+	return nil
+}
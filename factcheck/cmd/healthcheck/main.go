@@ -1,33 +1,62 @@
+// Command healthcheck checks a running factcheck-api, e.g. as container health check.
+// It exits non-zero with details if the server or its dependencies are not healthy.
+//
+//	healthcheck                  # Check readiness: Postgres, migrations and enabled integrations
+//	healthcheck --probe live     # Check only that the server is alive
+//	healthcheck --probe health   # Check the legacy /health endpoint
 package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
+	"github.com/alexflint/go-arg"
+
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
 )
 
+type cli struct {
+	Probe string `arg:"--probe" default:"ready" help:"endpoint to check: ready (/readyz), live (/livez) or health (/health)"`
+}
+
+var paths = map[string]string{
+	"ready":  "/readyz",
+	"live":   "/livez",
+	"health": "/health",
+}
+
 func main() {
+	c := cli{}
+	p := arg.MustParse(&c)
+	path, ok := paths[c.Probe]
+	if !ok {
+		p.Fail(fmt.Sprintf("unknown probe '%s'", c.Probe))
+	}
 	conf, err := config.New()
 	if err != nil {
 		panic(err)
 	}
 	addr := conf.HTTP.ListenAddr
-	url := fmt.Sprintf("http://0.0.0.0%s/health", addr) // TODO: port or addr config?
+	url := fmt.Sprintf("http://0.0.0.0%s%s", addr, path) // TODO: port or addr config?
 	timeoutMsRead := time.Millisecond * time.Duration(conf.HTTP.TimeoutMsRead)
 	timeoutMsWrite := time.Millisecond * time.Duration(conf.HTTP.TimeoutMsWrite)
 	timeout := timeoutMsRead + timeoutMsWrite
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	c := http.Client{
+	client := http.Client{
 		Timeout: timeoutMsRead + timeoutMsWrite,
 	}
 
 	slog.InfoContext(ctx, "healthcheck",
+		"probe", c.Probe,
 		"addr", addr,
 		"url", url,
 		"timeout_ms", timeout,
@@ -39,33 +68,39 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	resp, err := c.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		panic(err)
+		slog.ErrorContext(ctx, "healthcheck request failed", "url", url, "error", err)
+		os.Exit(1)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	_ = resp.Body.Close()
+	if err != nil {
+		slog.ErrorContext(ctx, "error reading response body", "url", url, "error", err)
+		os.Exit(1)
 	}
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			slog.ErrorContext(ctx, "error closing response body",
-				"error", err,
-				"addr", addr,
-				"url", url,
-				"timeout_ms", timeout,
-				"timeout_ms_read", timeoutMsRead,
-				"timeout_ms_write", timeoutMsWrite,
-			)
-		}
-	}()
 	if resp.StatusCode == http.StatusOK {
 		return
 	}
+
+	// Readiness reports failed checks, which are logged one by one
+	var report health.Report
+	if json.Unmarshal(body, &report) == nil && report.Status != "" {
+		for name, result := range report.Checks {
+			if result.Status != health.StatusOK {
+				slog.ErrorContext(ctx, "check failed", "check", name, "error", result.Error, "duration_ms", result.DurationMs)
+			}
+		}
+	}
 	slog.ErrorContext(ctx, "got wrong code",
 		"actual", resp.StatusCode,
 		"expected", http.StatusOK,
+		"body", string(body),
 		"addr", addr,
 		"url", url,
 		"timeout_ms", timeout,
 		"timeout_ms_read", timeoutMsRead,
 		"timeout_ms_write", timeoutMsWrite,
 	)
+	os.Exit(1)
 }
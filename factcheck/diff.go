@@ -0,0 +1,60 @@
+package factcheck
+
+import "strings"
+
+// DiffAnswers returns line diff of answer revisions from and to
+func DiffAnswers(from Answer, to Answer) AnswerDiff {
+	return AnswerDiff{
+		From:           from,
+		To:             to,
+		VerdictChanged: from.Verdict != to.Verdict,
+		Lines:          DiffLines(from.Text, to.Text),
+	}
+}
+
+// DiffLines returns lines of a and b as kept, deleted or inserted lines,
+// based on their longest common subsequence. Deletions come before insertions
+// when lines were replaced.
+func DiffLines(a string, b string) []DiffLine {
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+	n, m := len(linesA), len(linesB)
+
+	// lcs[i][j] is length of the longest common subsequence of linesA[i:] and linesB[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]DiffLine, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case linesA[i] == linesB[j]:
+			diff = append(diff, DiffLine{Op: OpDiffEqual, Text: linesA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: OpDiffDelete, Text: linesA[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: OpDiffInsert, Text: linesB[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, DiffLine{Op: OpDiffDelete, Text: linesA[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, DiffLine{Op: OpDiffInsert, Text: linesB[j]})
+	}
+	return diff
+}
@@ -0,0 +1,47 @@
+package factcheck_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+func TestDiffLines(t *testing.T) {
+	type testCase struct {
+		a        string
+		b        string
+		expected []factcheck.DiffLine
+	}
+	eq := func(s string) factcheck.DiffLine { return factcheck.DiffLine{Op: factcheck.OpDiffEqual, Text: s} }
+	del := func(s string) factcheck.DiffLine { return factcheck.DiffLine{Op: factcheck.OpDiffDelete, Text: s} }
+	ins := func(s string) factcheck.DiffLine { return factcheck.DiffLine{Op: factcheck.OpDiffInsert, Text: s} }
+	tests := map[string]testCase{
+		"same": {
+			a:        "lemon\nsoda",
+			b:        "lemon\nsoda",
+			expected: []factcheck.DiffLine{eq("lemon"), eq("soda")},
+		},
+		"replaced line": {
+			a:        "lemon soda\ncures cancer\nsource: none",
+			b:        "lemon soda\ndoes not cure cancer\nsource: none",
+			expected: []factcheck.DiffLine{eq("lemon soda"), del("cures cancer"), ins("does not cure cancer"), eq("source: none")},
+		},
+		"appended": {
+			a:        "น้ำมะนาวโซดา",
+			b:        "น้ำมะนาวโซดา\nไม่รักษามะเร็ง",
+			expected: []factcheck.DiffLine{eq("น้ำมะนาวโซดา"), ins("ไม่รักษามะเร็ง")},
+		},
+		"removed": {
+			a:        "a\nb\nc",
+			b:        "a\nc",
+			expected: []factcheck.DiffLine{eq("a"), del("b"), eq("c")},
+		},
+	}
+	for name, tc := range tests {
+		actual := factcheck.DiffLines(tc.a, tc.b)
+		if !slices.Equal(actual, tc.expected) {
+			t.Fatalf("unexpected diff for %s: expected=%v, actual=%v", name, tc.expected, actual)
+		}
+	}
+}
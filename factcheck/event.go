@@ -0,0 +1,53 @@
+package factcheck
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type TypeEvent string
+
+const (
+	TypeEventMessageSubmitted TypeEvent = "message.submitted" // Payload is MessageV2
+	TypeEventMessageAssigned  TypeEvent = "message.assigned"  // Payload is MessageV2
+	TypeEventGroupCreated     TypeEvent = "group.created"     // Payload is MessageGroup
+	TypeEventGroupAssigned    TypeEvent = "group.assigned"    // Payload is MessageGroup
+	TypeEventGroupApproved    TypeEvent = "group.approved"    // Payload is MessageGroup
+	TypeEventGroupRejected    TypeEvent = "group.rejected"    // Payload is MessageGroup
+	TypeEventTopicResolved    TypeEvent = "topic.resolved"    // Payload is Topic
+	TypeEventTopicMerged      TypeEvent = "topic.merged"      // Payload is TopicMerge
+	TypeEventTopicSplit       TypeEvent = "topic.split"       // Payload is TopicSplit
+	TypeEventAnswerCreated    TypeEvent = "answer.created"    // Payload is Answer
+	TypeEventAnswerPublished  TypeEvent = "answer.published"  // Payload is Answer
+)
+
+// Event is a domain event, recorded in the same transaction as the change it describes.
+// Seq is assigned by storage and increases with every event.
+type Event struct {
+	ID          string          `json:"id"`
+	Seq         int64           `json:"seq"`
+	Type        TypeEvent       `json:"type"`
+	SubjectID   string          `json:"subject_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	PublishedAt *time.Time      `json:"published_at"`
+}
+
+func (t TypeEvent) IsValid() bool {
+	switch t {
+	case
+		TypeEventMessageSubmitted,
+		TypeEventMessageAssigned,
+		TypeEventGroupCreated,
+		TypeEventGroupAssigned,
+		TypeEventGroupApproved,
+		TypeEventGroupRejected,
+		TypeEventTopicResolved,
+		TypeEventTopicMerged,
+		TypeEventTopicSplit,
+		TypeEventAnswerCreated,
+		TypeEventAnswerPublished:
+		return true
+	}
+	return false
+}
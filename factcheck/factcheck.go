@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -18,11 +19,15 @@ import (
 var checksum = sha1.New()
 
 type (
-	Language     string
-	TypeMessage  string
-	TypeUser     string
-	StatusTopic  string
-	StatusMGroup string
+	Language       string
+	TypeMessage    string
+	TypeUser       string
+	StatusTopic    string
+	StatusMGroup   string
+	StatusDelivery string
+	StatusAnswer   string
+	TypeSource     string
+	Verdict        string
 )
 
 const (
@@ -40,6 +45,25 @@ const (
 	StatusMGroupApproved StatusMGroup = "MGROUP_APPROVED"
 	StatusMGroupRejected StatusMGroup = "MGROUP_REJECTED"
 
+	StatusDeliverySent   StatusDelivery = "DELIVERY_SENT"
+	StatusDeliveryFailed StatusDelivery = "DELIVERY_FAILED"
+
+	StatusAnswerDraft     StatusAnswer = "ANSWER_DRAFT"     // Editable, and not visible to the public
+	StatusAnswerPublished StatusAnswer = "ANSWER_PUBLISHED" // Immutable, published to topic and submitters
+
+	TypeSourcePrimary      TypeSource = "SOURCE_PRIMARY"       // Original document, recording or post the claim is about
+	TypeSourceExpert       TypeSource = "SOURCE_EXPERT"        // Statement of an expert in the field
+	TypeSourceOfficialData TypeSource = "SOURCE_OFFICIAL_DATA" // Data or statement published by authorities
+	TypeSourceMedia        TypeSource = "SOURCE_MEDIA"         // Reporting by news organizations
+	TypeSourceOther        TypeSource = "SOURCE_OTHER"
+
+	VerdictTrue         Verdict = "VERDICT_TRUE"
+	VerdictFalse        Verdict = "VERDICT_FALSE"
+	VerdictMisleading   Verdict = "VERDICT_MISLEADING"   // Contains facts, but presented to mislead
+	VerdictPartlyTrue   Verdict = "VERDICT_PARTLY_TRUE"  // Some claims are true, some are not
+	VerdictUnverifiable Verdict = "VERDICT_UNVERIFIABLE" // Could not be proven or disproven
+	VerdictSatire       Verdict = "VERDICT_SATIRE"       // Satire or parody, not meant as fact
+
 	LanguageEnglish Language = "en"
 	LanguageThai    Language = "th"
 )
@@ -50,11 +74,37 @@ type Topic struct {
 	Description string      `json:"description"`
 	Status      StatusTopic `json:"status"`
 	Result      string      `json:"result"`
+	Verdict     Verdict     `json:"verdict"`
+	AnswerID    string      `json:"answer_id"` // Published answer, kept when the topic is re-opened
 	RepliedAt   *time.Time  `json:"replied_at"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   *time.Time  `json:"updated_at"`
 }
 
+// TopicMerge describes a topic merged into its target topic.
+// The source topic is deleted, and its ID redirects to the target.
+type TopicMerge struct {
+	SourceID     string `json:"source_id"`
+	Target       Topic  `json:"target"`
+	Groups       int64  `json:"groups"`        // Groups moved to target
+	GroupsMerged int64  `json:"groups_merged"` // Groups merged into target's groups with identical text
+	Messages     int64  `json:"messages"`
+	Answers      int64  `json:"answers"`
+}
+
+// TopicSplit describes message groups split from a topic into a new topic
+type TopicSplit struct {
+	Source TopicCounts `json:"source"`
+	Split  TopicCounts `json:"split"` // New topic with the split groups
+}
+
+// TopicCounts is a topic, with counts of its groups and messages
+type TopicCounts struct {
+	Topic    Topic `json:"topic"`
+	Groups   int64 `json:"groups"`
+	Messages int64 `json:"messages"`
+}
+
 type MessageV2 struct {
 	ID          string          `json:"id"`
 	GroupID     string          `json:"group_id"`
@@ -63,6 +113,7 @@ type MessageV2 struct {
 	TypeUser    TypeUser        `json:"type_user"`
 	TypeMessage TypeMessage     `json:"type"`
 	Text        string          `json:"text"`
+	Language    Language        `json:"language"`
 	Metadata    json.RawMessage `json:"metadata"`
 	RepliedAt   *time.Time      `json:"replied_at"`
 	CreatedAt   time.Time       `json:"created_at"`
@@ -70,23 +121,103 @@ type MessageV2 struct {
 }
 
 type MessageGroup struct {
-	ID        string       `json:"id"`
-	Status    StatusMGroup `json:"status"`
-	TopicID   string       `json:"topic_id"`
-	Name      string       `json:"name"`
-	Text      string       `json:"text"`
-	TextSHA1  string       `json:"text_sha1"`
-	Language  Language     `json:"language"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt *time.Time   `json:"updated_at"`
+	ID           string       `json:"id"`
+	Status       StatusMGroup `json:"status"`
+	StatusReason string       `json:"status_reason"` // Moderator's reason for approval or rejection
+	TopicID      string       `json:"topic_id"`
+	Name         string       `json:"name"`
+	Text         string       `json:"text"`
+	TextSHA1     string       `json:"text_sha1"`
+	TextSimHash  uint64       `json:"-"`       // SimHash of normalized text, 0 if unknown
+	Preview      *LinkPreview `json:"preview"` // Preview of linked page, for groups of URL messages
+	Language     Language     `json:"language"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    *time.Time   `json:"updated_at"`
 }
 
+// LinkPreview describes the page linked by a URL message
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	SiteName    string `json:"site_name"`
+}
+
+// MessageGroupSimilar is a message group similar to some text
+type MessageGroupSimilar struct {
+	MessageGroup
+	Similarity float64 `json:"similarity"` // From 0 to 1, with 1 being identical after normalization
+}
+
+// Answer is a revision of the answer to a topic. Revisions are numbered from 1 per topic,
+// and only published revisions are visible to the public.
 type Answer struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	TopicID   string    `json:"topic_id"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"`
+	TopicID    string       `json:"topic_id"`
+	Revision   int          `json:"revision"`
+	Status     StatusAnswer `json:"status"`
+	Text       string       `json:"text"`
+	Verdict    Verdict      `json:"verdict"`
+	CorrectsID string       `json:"corrects_id,omitempty"` // Previously published answer corrected by this answer
+	Sources    []Source     `json:"sources,omitempty"`     // Evidence cited by the answer, only included when getting a single answer
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  *time.Time   `json:"updated_at"`
+}
+
+// Source is evidence cited by an answer, e.g. a web page, a quote of an expert or an attached document.
+// Sources of an answer are ordered by Position, starting from 1.
+// Position 0 appends new sources after existing ones, and keeps the position of updated sources.
+// Sources can only be changed while their answer is a draft.
+type Source struct {
+	ID            string     `json:"id"`
+	AnswerID      string     `json:"answer_id"`
+	Type          TypeSource `json:"type"`
+	Position      int        `json:"position"`
+	URL           string     `json:"url,omitempty"`
+	Publisher     string     `json:"publisher,omitempty"`
+	ArchiveURL    string     `json:"archive_url,omitempty"` // Archived copy of URL, in case the original is changed or taken down
+	Quote         string     `json:"quote,omitempty"`
+	AttachmentURL string     `json:"attachment_url,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+}
+
+// AnswerDiff is the difference between two revisions of a topic's answer
+type AnswerDiff struct {
+	From           Answer     `json:"from"`
+	To             Answer     `json:"to"`
+	VerdictChanged bool       `json:"verdict_changed"`
+	Lines          []DiffLine `json:"lines"`
+}
+
+type OpDiff string
+
+const (
+	OpDiffEqual  OpDiff = "="
+	OpDiffDelete OpDiff = "-"
+	OpDiffInsert OpDiff = "+"
+)
+
+// DiffLine is a line of text, and whether it was kept, deleted or inserted
+type DiffLine struct {
+	Op   OpDiff `json:"op"`
+	Text string `json:"text"`
+}
+
+// Delivery records an answer sent (or failed to be sent) to a submitter
+type Delivery struct {
+	ID        string         `json:"id"`
+	TopicID   string         `json:"topic_id"`
+	AnswerID  string         `json:"answer_id"`
+	UserID    string         `json:"user_id"`
+	TypeUser  TypeUser       `json:"type_user"`
+	ChatID    string         `json:"chat_id"`
+	Sender    string         `json:"sender"`
+	Status    StatusDelivery `json:"status"`
+	Text      string         `json:"text"`
+	Error     string         `json:"error,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
 func (s StatusTopic) IsValid() bool {
@@ -99,6 +230,79 @@ func (s StatusTopic) IsValid() bool {
 	return false
 }
 
+func (v Verdict) IsValid() bool {
+	switch v {
+	case
+		VerdictTrue,
+		VerdictFalse,
+		VerdictMisleading,
+		VerdictPartlyTrue,
+		VerdictUnverifiable,
+		VerdictSatire:
+		return true
+	}
+	return false
+}
+
+func (s StatusAnswer) IsValid() bool {
+	switch s {
+	case
+		StatusAnswerDraft,
+		StatusAnswerPublished:
+		return true
+	}
+	return false
+}
+
+func (t TypeSource) IsValid() bool {
+	switch t {
+	case
+		TypeSourcePrimary,
+		TypeSourceExpert,
+		TypeSourceOfficialData,
+		TypeSourceMedia,
+		TypeSourceOther:
+		return true
+	}
+	return false
+}
+
+func (s StatusMGroup) IsValid() bool {
+	switch s {
+	case
+		StatusMGroupPending,
+		StatusMGroupApproved,
+		StatusMGroupRejected:
+		return true
+	}
+	return false
+}
+
+// CanTransition returns whether a group with status s could be moved to status next.
+// New groups are pending, and moderators could then approve or reject them,
+// or later reverse their decision. Groups never go back to pending.
+func (s StatusMGroup) CanTransition(next StatusMGroup) bool {
+	switch s {
+	case StatusMGroupPending:
+		return next == StatusMGroupApproved || next == StatusMGroupRejected
+	case StatusMGroupApproved:
+		return next == StatusMGroupRejected
+	case StatusMGroupRejected:
+		return next == StatusMGroupApproved
+	}
+	return false
+}
+
+func (l Language) IsValid() bool {
+	switch l {
+	case
+		LanguageEnglish,
+		LanguageThai:
+		return true
+	}
+	return false
+}
+
 func (t TypeUser) IsValid() bool {
 	switch t {
 	case
@@ -111,7 +315,13 @@ func (t TypeUser) IsValid() bool {
 }
 
 func (t TypeMessage) IsValid() bool {
-	return t == TypeMessageText
+	switch t {
+	case
+		TypeMessageText,
+		TypeMessageURL:
+		return true
+	}
+	return false
 }
 
 func (t Topic) Validate() error {
@@ -128,6 +338,38 @@ func (t Topic) Validate() error {
 	return nil
 }
 
+// Validate validates type and links of s. A source must cite something,
+// i.e. at least one of URL, Quote and AttachmentURL is not empty.
+func (s Source) Validate() error {
+	if !s.Type.IsValid() {
+		return fmt.Errorf("invalid source type '%s'", s.Type)
+	}
+	if s.Position < 0 {
+		return fmt.Errorf("invalid source position %d", s.Position)
+	}
+	if s.URL == "" && s.Quote == "" && s.AttachmentURL == "" {
+		return errors.New("source has no url, quote or attachment")
+	}
+	links := []struct {
+		name string
+		link string
+	}{
+		{name: "url", link: s.URL},
+		{name: "archive_url", link: s.ArchiveURL},
+		{name: "attachment_url", link: s.AttachmentURL},
+	}
+	for i := range links {
+		if links[i].link == "" {
+			continue
+		}
+		u, err := url.Parse(links[i].link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid source %s '%s'", links[i].name, links[i].link)
+		}
+	}
+	return nil
+}
+
 func (m MessageV2) Validate() error {
 	if m.Text == "" {
 		return errors.New("empty text")
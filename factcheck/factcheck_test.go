@@ -11,6 +11,10 @@ func TestValidate(t *testing.T) {
 		factcheck.StatusTopicPending,
 		factcheck.StatusTopicResolved,
 		factcheck.TypeMessageText,
+		factcheck.VerdictFalse,
+		factcheck.VerdictSatire,
+		factcheck.TypeSearchGroup,
+		factcheck.TypeSourceOfficialData,
 	}
 	for i := range shouldOk {
 		s := shouldOk[i]
@@ -20,3 +24,71 @@ func TestValidate(t *testing.T) {
 		t.Fatalf("unexpected invalid value: %v", s)
 	}
 }
+
+func TestValidateInvalid(t *testing.T) {
+	shouldInvalid := []interface{ IsValid() bool }{
+		factcheck.Verdict(""),
+		factcheck.Verdict("VERDICT_MAYBE"),
+		factcheck.Verdict("false"),
+		factcheck.TypeSearch("SEARCH_MESSAGE"),
+		factcheck.TypeSource("SOURCE_RUMOR"),
+	}
+	for i := range shouldInvalid {
+		s := shouldInvalid[i]
+		if !s.IsValid() {
+			continue
+		}
+		t.Fatalf("unexpected valid value: %v", s)
+	}
+}
+
+func TestSourceValidate(t *testing.T) {
+	type testCase struct {
+		source factcheck.Source
+		ok     bool
+	}
+	tests := []testCase{
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourcePrimary, URL: "https://example.com/report.pdf"},
+			ok:     true,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceExpert, Quote: "Lemon soda does not cure cancer", Publisher: "Some hospital"},
+			ok:     true,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceMedia, URL: "https://example.com/news", ArchiveURL: "https://archive.example.com/news"},
+			ok:     true,
+		},
+		{
+			source: factcheck.Source{URL: "https://example.com"},
+			ok:     false,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceOther, Publisher: "Nothing cited"},
+			ok:     false,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceOther, URL: "javascript:alert(1)"},
+			ok:     false,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceOther, Quote: "some quote", ArchiveURL: "archive.example.com"},
+			ok:     false,
+		},
+		{
+			source: factcheck.Source{Type: factcheck.TypeSourceOther, Quote: "some quote", Position: -1},
+			ok:     false,
+		},
+	}
+	for i := range tests {
+		tc := &tests[i]
+		err := tc.source.Validate()
+		if tc.ok && err != nil {
+			t.Fatalf("[case %d] unexpected error: %v", i, err)
+		}
+		if !tc.ok && err == nil {
+			t.Fatalf("[case %d] unexpected nil error", i)
+		}
+	}
+}
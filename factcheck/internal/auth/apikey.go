@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sort"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+// APIKeys authenticates service-to-service callers with static keys in header X-API-Key.
+// Callers are identified by the key names as admin users.
+type APIKeys struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	name string
+	key  []byte
+}
+
+// NewAPIKeys returns APIKeys from a map of name to key
+func NewAPIKeys(keys map[string]string) APIKeys {
+	a := APIKeys{}
+	for name, key := range keys {
+		if key == "" {
+			continue
+		}
+		a.keys = append(a.keys, apiKey{name: name, key: []byte(key)})
+	}
+	// Deterministic order, since map iteration is random
+	sort.Slice(a.keys, func(i, j int) bool { return a.keys[i].name < a.keys[j].name })
+	return a
+}
+
+func (a APIKeys) Authenticate(r *http.Request) (factcheck.UserInfo, error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		return factcheck.UserInfo{}, ErrNoCredentials
+	}
+	// Compare with every key so that timing does not leak which one matched
+	found := -1
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), a.keys[i].key) == 1 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return factcheck.UserInfo{}, invalid("unknown api key")
+	}
+	return factcheck.UserInfo{
+		UserType: factcheck.TypeUserMessageAdmin,
+		UserID:   a.keys[found].name,
+	}, nil
+}
@@ -0,0 +1,80 @@
+// Package auth authenticates API requests.
+//
+// Callers are identified either by bearer JWTs (HS256 with a shared secret,
+// or RS256 with public keys from a local JWKS file), or by static API keys
+// for service-to-service calls.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderAPIKey        = "X-API-Key"
+)
+
+var (
+	// ErrNoCredentials is returned when the request carries no credentials
+	// for the authenticator, so that the next authenticator could be tried.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials were present but rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator identifies the caller of a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (factcheck.UserInfo, error)
+}
+
+// Chain tries authenticators in order until one of them finds credentials
+type Chain []Authenticator
+
+// New returns an authenticator chain with all authenticators enabled by conf.
+// With nothing configured, every request is unauthenticated.
+func New(conf config.Config) (Authenticator, error) {
+	chain := Chain{}
+	if len(conf.Auth.APIKeys) != 0 {
+		chain = append(chain, NewAPIKeys(conf.Auth.APIKeys))
+	}
+	if conf.Auth.JWTSecret != "" || conf.Auth.JWKSFile != "" {
+		j, err := NewJWT(conf.Auth)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, j)
+	}
+	return chain, nil
+}
+
+func (c Chain) Authenticate(r *http.Request) (factcheck.UserInfo, error) {
+	for i := range c {
+		user, err := c[i].Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return user, err
+	}
+	return factcheck.UserInfo{}, ErrNoCredentials
+}
+
+// bearer returns the bearer token in Authorization header
+func bearer(r *http.Request) (string, bool) {
+	h := r.Header.Get(HeaderAuthorization)
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,190 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/auth"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+)
+
+func request(t *testing.T, headers map[string]string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func claims(userType factcheck.TypeUser, sub string, exp time.Time) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			Issuer:    "factcheck-test",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+		UserType: userType,
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(auth.JWKS{Keys: []auth.JWK{{
+		Kty: "RSA",
+		Kid: "key-1",
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksFile, jwks, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := "factcheck-test-secret"
+	a, err := auth.New(config.Config{Auth: config.Auth{
+		JWTSecret: secret,
+		JWKSFile:  jwksFile,
+		JWTIssuer: "factcheck-test",
+		APIKeys:   map[string]string{"svc-bot": "bot-key", "svc-etl": "etl-key"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs256 := func(c auth.Claims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + s
+	}
+	rs256 := func(c auth.Claims, kid string, k *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + s
+	}
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	type testCase struct {
+		name     string
+		headers  map[string]string
+		expected factcheck.UserInfo
+		err      error
+	}
+	tests := []testCase{
+		{
+			name: "no credentials",
+			err:  auth.ErrNoCredentials,
+		},
+		{
+			name:     "api key",
+			headers:  map[string]string{auth.HeaderAPIKey: "etl-key"},
+			expected: factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "svc-etl"},
+		},
+		{
+			name:    "bad api key",
+			headers: map[string]string{auth.HeaderAPIKey: "etl-key-2"},
+			err:     auth.ErrInvalidCredentials,
+		},
+		{
+			name:     "hs256",
+			headers:  map[string]string{auth.HeaderAuthorization: hs256(claims(factcheck.TypeUserMessageAdmin, "admin-1", future))},
+			expected: factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin-1"},
+		},
+		{
+			name:    "hs256 expired",
+			headers: map[string]string{auth.HeaderAuthorization: hs256(claims(factcheck.TypeUserMessageAdmin, "admin-1", past))},
+			err:     auth.ErrInvalidCredentials,
+		},
+		{
+			name: "hs256 bad issuer",
+			headers: map[string]string{auth.HeaderAuthorization: hs256(func() auth.Claims {
+				c := claims(factcheck.TypeUserMessageAdmin, "admin-1", future)
+				c.Issuer = "someone-else"
+				return c
+			}())},
+			err: auth.ErrInvalidCredentials,
+		},
+		{
+			name:     "rs256",
+			headers:  map[string]string{auth.HeaderAuthorization: rs256(claims(factcheck.TypeUserMessageLINEChat, "U1", future), "key-1", key)},
+			expected: factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1"},
+		},
+		{
+			name:    "rs256 unknown kid",
+			headers: map[string]string{auth.HeaderAuthorization: rs256(claims(factcheck.TypeUserMessageLINEChat, "U1", future), "key-2", key)},
+			err:     auth.ErrInvalidCredentials,
+		},
+		{
+			name:    "rs256 wrong key",
+			headers: map[string]string{auth.HeaderAuthorization: rs256(claims(factcheck.TypeUserMessageLINEChat, "U1", future), "key-1", other)},
+			err:     auth.ErrInvalidCredentials,
+		},
+		{
+			name:    "basic auth is not bearer",
+			headers: map[string]string{auth.HeaderAuthorization: "Basic Zm9vOmJhcg=="},
+			err:     auth.ErrNoCredentials,
+		},
+	}
+	for i := range tests {
+		tc := &tests[i]
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := a.Authenticate(request(t, tc.headers))
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("unexpected error: %v, expected %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user != tc.expected {
+				t.Fatalf("unexpected user: %+v, expected %+v", user, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNew_NothingConfigured(t *testing.T) {
+	a, err := auth.New(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.Authenticate(request(t, map[string]string{auth.HeaderAPIKey: "some-key"}))
+	if !errors.Is(err, auth.ErrNoCredentials) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// Authorizer checks permissions of authenticated users against their roles.
+// Roles are stored in Postgres, except for admins from config which are always admin.
+type Authorizer struct {
+	roles  repo.Roles
+	admins map[string]struct{}
+}
+
+func NewAuthorizer(conf config.Config, repo repo.Repository) Authorizer {
+	admins := make(map[string]struct{}, len(conf.Auth.Admins))
+	for _, id := range conf.Auth.Admins {
+		admins[id] = struct{}{}
+	}
+	return Authorizer{
+		roles:  repo.Roles,
+		admins: admins,
+	}
+}
+
+// Role returns role of user, or an empty role if user has none.
+// Only admin users could have roles: LINE users never do.
+func (a Authorizer) Role(ctx context.Context, user factcheck.UserInfo) (factcheck.Role, error) {
+	if user.UserType != factcheck.TypeUserMessageAdmin || user.UserID == "" {
+		return "", nil
+	}
+	if _, ok := a.admins[user.UserID]; ok {
+		return factcheck.RoleAdmin, nil
+	}
+	role, err := a.roles.GetByUserID(ctx, user.UserID)
+	if repo.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return role.Role, nil
+}
+
+// Can returns whether user has permission p
+func (a Authorizer) Can(ctx context.Context, user factcheck.UserInfo, p factcheck.Permission) (bool, error) {
+	role, err := a.Role(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return role.Can(p), nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+)
+
+// Claims are JWT claims accepted by JWT.
+// Subject is used as UserID.
+type Claims struct {
+	jwt.RegisteredClaims
+	UserType factcheck.TypeUser `json:"user_type"`
+}
+
+// JWT authenticates bearer tokens signed with HS256 or RS256
+type JWT struct {
+	secret []byte
+	keys   map[string]*rsa.PublicKey // RS256 keys by kid
+	parser *jwt.Parser
+}
+
+// JWKS is a JSON Web Key Set. Only RSA keys are used.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func NewJWT(conf config.Auth) (JWT, error) {
+	j := JWT{}
+	methods := []string{}
+	if conf.JWTSecret != "" {
+		j.secret = []byte(conf.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if conf.JWKSFile != "" {
+		keys, err := ReadJWKS(conf.JWKSFile)
+		if err != nil {
+			return JWT{}, err
+		}
+		j.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return JWT{}, errors.New("jwt: neither secret nor jwks file configured")
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+	}
+	if conf.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.JWTIssuer))
+	}
+	if conf.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(conf.JWTAudience))
+	}
+	j.parser = jwt.NewParser(opts...)
+	return j, nil
+}
+
+func (j JWT) Authenticate(r *http.Request) (factcheck.UserInfo, error) {
+	token, ok := bearer(r)
+	if !ok {
+		return factcheck.UserInfo{}, ErrNoCredentials
+	}
+	claims := Claims{}
+	_, err := j.parser.ParseWithClaims(token, &claims, j.key)
+	if err != nil {
+		return factcheck.UserInfo{}, invalid("jwt: %s", err.Error())
+	}
+	if claims.Subject == "" {
+		return factcheck.UserInfo{}, invalid("jwt: empty subject")
+	}
+	if !claims.UserType.IsValid() {
+		return factcheck.UserInfo{}, invalid("jwt: bad user_type '%s'", claims.UserType)
+	}
+	return factcheck.UserInfo{
+		UserType: claims.UserType,
+		UserID:   claims.Subject,
+	}, nil
+}
+
+// key returns verification key for token based on its alg and kid
+func (j JWT) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return j.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" && len(j.keys) == 1 {
+			for _, key := range j.keys {
+				return key, nil
+			}
+		}
+		key, ok := j.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid '%s'", kid)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unexpected alg '%s'", token.Method.Alg())
+}
+
+// ReadJWKS reads RSA public keys from a JWKS file
+func ReadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("error reading jwks file '%s': %w", path, err)
+	}
+	return ParseJWKS(b)
+}
+
+// ParseJWKS parses RSA public keys from JWKS JSON
+func ParseJWKS(b []byte) (map[string]*rsa.PublicKey, error) {
+	jwks := JWKS{}
+	err := json.Unmarshal(b, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jwks: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for i := range jwks.Keys {
+		k := jwks.Keys[i]
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := k.RSA()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing jwk '%s': %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// RSA returns the RSA public key of k
+func (k JWK) RSA() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("bad modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("bad exponent: %w", err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() > 1<<31-1 || exp.Int64() < 2 {
+		return nil, errors.New("bad exponent")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exp.Int64()),
+	}, nil
+}
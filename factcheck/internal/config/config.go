@@ -3,6 +3,7 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/sethvargo/go-envconfig"
 )
@@ -13,6 +14,10 @@ type HTTP struct {
 	ListenAddr     string `env:"FACTCHECKAPI_LISTEN_ADDRESS, required"`
 	TimeoutMsRead  int    `env:"FACTCHECKAPI_TIMEOUTMS_READ, default=1000"`
 	TimeoutMsWrite int    `env:"FACTCHECKAPI_TIMEOUTMS_WRITE, default=1000"`
+	// ValidateRequests rejects requests not matching the OpenAPI document with 400
+	ValidateRequests bool `env:"FACTCHECKAPI_VALIDATE_REQUESTS, default=false"`
+	// MaxBytesBody limits bodies read by request validation, larger bodies are rejected with 413
+	MaxBytesBody int64 `env:"FACTCHECKAPI_MAX_BYTES_BODY, default=1048576"`
 }
 
 type Postgres struct {
@@ -23,10 +28,92 @@ type Postgres struct {
 	DB       string `env:"POSTGRES_DB, required"`
 }
 
+// LINE configures LINE Messaging API channel
+type LINE struct {
+	ChannelSecret      string `env:"LINE_CHANNEL_SECRET"`
+	ChannelAccessToken string `env:"LINE_CHANNEL_ACCESS_TOKEN"`
+	Endpoint           string `env:"LINE_API_ENDPOINT"`
+	// WebhookRetentionMs is how long IDs of webhook events submitted are kept to skip redeliveries
+	WebhookRetentionMs int `env:"LINE_WEBHOOK_RETENTIONMS, default=604800000"`
+}
+
+// Outbox configures relay publishing domain events from outbox
+type Outbox struct {
+	RelayIntervalMs int    `env:"OUTBOX_RELAY_INTERVALMS, default=1000"`
+	BatchSize       int    `env:"OUTBOX_BATCH_SIZE, default=100"`
+	WebhookURL      string `env:"OUTBOX_WEBHOOK_URL"`
+	LeaseMs         int    `env:"OUTBOX_LEASEMS, default=300000"` // How long claimed events are skipped by other relays
+	BackoffMs       int    `env:"OUTBOX_BACKOFFMS, default=1000"` // Delay after the first failure, doubled after every later failure
+	BackoffMaxMs    int    `env:"OUTBOX_BACKOFFMAXMS, default=3600000"`
+	MaxAttempts     int    `env:"OUTBOX_MAX_ATTEMPTS, default=20"`          // Events failing this many times are parked
+	SweepIntervalMs int    `env:"OUTBOX_SWEEP_INTERVALMS, default=3600000"` // How often the relay deletes expired state, like old webhook event IDs and idle rate limits
+}
+
+// Auth configures authentication of API requests.
+// JWTs are accepted if JWTSecret (HS256) or JWKSFile (RS256) is set.
+type Auth struct {
+	JWTSecret   string            `env:"AUTH_JWT_SECRET"`
+	JWKSFile    string            `env:"AUTH_JWKS_FILE"`
+	JWTIssuer   string            `env:"AUTH_JWT_ISSUER"`
+	JWTAudience string            `env:"AUTH_JWT_AUDIENCE"`
+	APIKeys     map[string]string `env:"AUTH_API_KEYS"` // Format: "name1:key1,name2:key2"
+	Admins      []string          `env:"AUTH_ADMINS"`   // User IDs always granted admin role, e.g. for bootstrapping
+}
+
+// Dedup configures near-duplicate message grouping.
+// Thresholds are similarities of normalized texts, from 0 to 1.
+type Dedup struct {
+	ThresholdJoin    float64 `env:"DEDUP_THRESHOLD_JOIN, default=0.9"`     // New messages join existing groups at least this similar. Set to 0 to disable.
+	ThresholdPropose float64 `env:"DEDUP_THRESHOLD_PROPOSE, default=0.75"` // Groups at least this similar are proposed to moderators
+	WindowMs         int     `env:"DEDUP_WINDOWMS, default=2592000000"`    // Only groups created this recently are compared, which bounds the scan
+}
+
+// Window returns how recently groups must have been created to be compared, 30 days if unset
+func (d Dedup) Window() time.Duration {
+	if d.WindowMs <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(d.WindowMs) * time.Millisecond
+}
+
+// Links configures handling of URL messages.
+// Both resolving and fetching connect to untrusted URLs, but never to private networks.
+// Both happen while submissions are handled, so they are disabled by default,
+// and each is limited to a quarter of the write timeout.
+type Links struct {
+	ResolveShorteners bool `env:"LINKS_RESOLVE_SHORTENERS, default=false"` // Follow redirects of known short links
+	FetchPreview      bool `env:"LINKS_FETCH_PREVIEW, default=false"`      // Fetch title and description of linked pages
+	TimeoutMs         int  `env:"LINKS_TIMEOUTMS, default=250"`
+}
+
+// RateLimit configures token-bucket limits of message submissions by user type.
+// Limits are formatted "burst/period", e.g. "20/1m" allows bursts of 20 messages, refilled at 20 per minute.
+// User types without limits, like admins, are not limited.
+type RateLimit struct {
+	Backend string            `env:"RATELIMIT_BACKEND, default=memory"`                             // "memory", "postgres" for multiple instances, or "none"
+	Users   map[string]string `env:"RATELIMIT_USERS, default=USER_CHAT:20/1m,USER_GROUPCHAT:20/1m"` // Limits of each user by user type
+	Chats   map[string]string `env:"RATELIMIT_CHATS, default=USER_GROUPCHAT:60/1m"`                 // Limits of each group chat, shared by its users
+}
+
+// Tracing configures exporting of trace spans.
+// Spans are always propagated and their IDs logged, but are exported only if Exporter is set.
+type Tracing struct {
+	Exporter     string  `env:"TRACING_EXPORTER"`                                     // "otlp", "stdout", or empty to not export
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT, default=http://localhost:4318"` // Base URL of OTLP/HTTP collector
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO, default=1"`                      // Fraction of traces exported, regardless of sampled flags of callers
+}
+
 type Config struct {
-	AppName  string `env:"APP_NAME, default=factcheck-api"`
-	HTTP     HTTP
-	Postgres Postgres
+	AppName   string `env:"APP_NAME, default=factcheck-api"`
+	HTTP      HTTP
+	Postgres  Postgres
+	LINE      LINE
+	Outbox    Outbox
+	Auth      Auth
+	Dedup     Dedup
+	Links     Links
+	Tracing   Tracing
+	RateLimit RateLimit
 }
 
 func New() (Config, error) {
@@ -46,6 +133,7 @@ func NewTest() (Config, error) {
 			ListenAddr:     ":8080",
 			TimeoutMsRead:  10000,
 			TimeoutMsWrite: 10000,
+			MaxBytesBody:   1 << 20,
 		},
 		Postgres: Postgres{
 			Host:     "localhost",
@@ -54,6 +142,30 @@ func NewTest() (Config, error) {
 			Password: hack(),
 			DB:       "factcheck",
 		},
+		LINE: LINE{
+			ChannelSecret: "factcheck-test-line-secret",
+		},
+		Outbox: Outbox{
+			RelayIntervalMs: 100,
+			BatchSize:       100,
+		},
+		Auth: Auth{
+			JWTSecret: "factcheck-test-jwt-secret",
+			APIKeys: map[string]string{
+				"factcheck-test": "factcheck-test-api-key",
+			},
+			Admins: []string{"factcheck-test"},
+		},
+		Dedup: Dedup{
+			ThresholdJoin:    0.9,
+			ThresholdPropose: 0.75,
+		},
+		Links: Links{
+			TimeoutMs: 1000,
+		},
+		RateLimit: RateLimit{
+			Backend: "none", // Tests submit many messages as the same users
+		},
 	}, nil
 }
 
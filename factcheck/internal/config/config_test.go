@@ -105,4 +105,17 @@ func TestParseEnvConfig(t *testing.T) {
 			t.Fatalf("unexpected timeout write: %+v", conf.HTTP)
 		}
 	})
+
+	t.Run("normal - auth api keys", func(t *testing.T) {
+		defer setRequired(":8080", "some_db")()
+		os.Setenv("AUTH_API_KEYS", "bot:key-1,etl:key-2")
+		defer os.Unsetenv("AUTH_API_KEYS")
+		conf, err := config.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conf.Auth.APIKeys) != 2 || conf.Auth.APIKeys["bot"] != "key-1" || conf.Auth.APIKeys["etl"] != "key-2" {
+			t.Fatalf("unexpected api keys: %+v", conf.Auth.APIKeys)
+		}
+	})
 }
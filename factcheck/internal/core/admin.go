@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// emptyResult is returned by inTx callbacks of operations without results
+type emptyResult struct{}
+
+func (s ServiceFactcheck) CreateTopic(ctx context.Context, user factcheck.UserInfo, topic factcheck.Topic) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "core.CreateTopic")
+	defer span.End()
+	return inTx(ctx, s, "create topic", func(withTx repo.Option) (factcheck.Topic, error) {
+		created, err := s.repo.Topics.Create(ctx, topic, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditTopicCreate, factcheck.TypeTargetTopic, []string{created.ID}, nil, created, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		return created, nil
+	})
+}
+
+func (s ServiceFactcheck) UpdateTopicStatus(ctx context.Context, user factcheck.UserInfo, id string, status factcheck.StatusTopic) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "core.UpdateTopicStatus")
+	defer span.End()
+	return s.updateTopic(ctx, user, factcheck.ActionAuditTopicUpdateStatus, id, func(withTx repo.Option) (factcheck.Topic, error) {
+		return s.repo.Topics.UpdateStatus(ctx, id, status, withTx)
+	})
+}
+
+func (s ServiceFactcheck) UpdateTopicName(ctx context.Context, user factcheck.UserInfo, id string, name string) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "core.UpdateTopicName")
+	defer span.End()
+	return s.updateTopic(ctx, user, factcheck.ActionAuditTopicUpdateName, id, func(withTx repo.Option) (factcheck.Topic, error) {
+		return s.repo.Topics.UpdateName(ctx, id, name, withTx)
+	})
+}
+
+func (s ServiceFactcheck) UpdateTopicDescription(ctx context.Context, user factcheck.UserInfo, id string, description string) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "core.UpdateTopicDescription")
+	defer span.End()
+	return s.updateTopic(ctx, user, factcheck.ActionAuditTopicUpdateDescription, id, func(withTx repo.Option) (factcheck.Topic, error) {
+		return s.repo.Topics.UpdateDescription(ctx, id, description, withTx)
+	})
+}
+
+func (s ServiceFactcheck) updateTopic(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	action factcheck.ActionAudit,
+	id string,
+	update func(withTx repo.Option) (factcheck.Topic, error),
+) (
+	factcheck.Topic,
+	error,
+) {
+	return inTx(ctx, s, string(action), func(withTx repo.Option) (factcheck.Topic, error) {
+		before, err := s.repo.Topics.GetByID(ctx, id, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		after, err := update(withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		err = s.audit(ctx, user, action, factcheck.TypeTargetTopic, []string{id}, before, after, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		return after, nil
+	})
+}
+
+func (s ServiceFactcheck) DeleteTopic(ctx context.Context, user factcheck.UserInfo, id string) error {
+	ctx, span := tracing.Start(ctx, "core.DeleteTopic")
+	defer span.End()
+	_, err := inTx(ctx, s, "delete topic", func(withTx repo.Option) (emptyResult, error) {
+		before, err := s.repo.Topics.GetByID(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		err = s.repo.Topics.Delete(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		return emptyResult{}, s.audit(ctx, user, factcheck.ActionAuditTopicDelete, factcheck.TypeTargetTopic, []string{id}, before, nil, withTx)
+	})
+	return err
+}
+
+func (s ServiceFactcheck) DeleteMessageGroup(ctx context.Context, user factcheck.UserInfo, id string) error {
+	ctx, span := tracing.Start(ctx, "core.DeleteMessageGroup")
+	defer span.End()
+	_, err := inTx(ctx, s, "delete message group", func(withTx repo.Option) (emptyResult, error) {
+		before, err := s.repo.MessageGroups.GetByID(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		err = s.repo.MessageGroups.Delete(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		return emptyResult{}, s.audit(ctx, user, factcheck.ActionAuditGroupDelete, factcheck.TypeTargetGroup, []string{id}, before, nil, withTx)
+	})
+	return err
+}
+
+func (s ServiceFactcheck) DeleteMessage(ctx context.Context, user factcheck.UserInfo, id string) error {
+	ctx, span := tracing.Start(ctx, "core.DeleteMessage")
+	defer span.End()
+	_, err := inTx(ctx, s, "delete message", func(withTx repo.Option) (emptyResult, error) {
+		before, err := s.repo.MessagesV2.GetByID(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		err = s.repo.MessagesV2.Delete(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		return emptyResult{}, s.audit(ctx, user, factcheck.ActionAuditMessageDelete, factcheck.TypeTargetMessage, []string{id}, before, nil, withTx)
+	})
+	return err
+}
+
+func (s ServiceFactcheck) GrantRole(ctx context.Context, user factcheck.UserInfo, role factcheck.UserRole) (factcheck.UserRole, error) {
+	ctx, span := tracing.Start(ctx, "core.GrantRole")
+	defer span.End()
+	return inTx(ctx, s, "grant role", func(withTx repo.Option) (factcheck.UserRole, error) {
+		var before any
+		previous, err := s.repo.Roles.GetByUserID(ctx, role.UserID, withTx)
+		switch {
+		case err == nil:
+			before = previous
+		case !repo.IsNotFound(err):
+			return factcheck.UserRole{}, err
+		}
+		granted, err := s.repo.Roles.Upsert(ctx, role, withTx)
+		if err != nil {
+			return factcheck.UserRole{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditRoleGrant, factcheck.TypeTargetRole, []string{role.UserID}, before, granted, withTx)
+		if err != nil {
+			return factcheck.UserRole{}, err
+		}
+		return granted, nil
+	})
+}
+
+func (s ServiceFactcheck) RevokeRole(ctx context.Context, user factcheck.UserInfo, userID string) error {
+	ctx, span := tracing.Start(ctx, "core.RevokeRole")
+	defer span.End()
+	_, err := inTx(ctx, s, "revoke role", func(withTx repo.Option) (emptyResult, error) {
+		before, err := s.repo.Roles.GetByUserID(ctx, userID, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		err = s.repo.Roles.Delete(ctx, userID, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		return emptyResult{}, s.audit(ctx, user, factcheck.ActionAuditRoleRevoke, factcheck.TypeTargetRole, []string{userID}, before, nil, withTx)
+	})
+	return err
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+func (s ServiceFactcheck) AssignGroupTopic(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	groupID string,
+	topicID string,
+) (
+	factcheck.MessageGroup,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.AssignGroupTopic")
+	defer span.End()
+	tx, err := s.repo.BeginTx(ctx, repo.RepeatableRead)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err == nil {
+			return
+		}
+		slog.ErrorContext(ctx, "error rolling back after failure to assign group topic",
+			"group_id", groupID,
+			"topic_id", topicID,
+			"user", user,
+		)
+	}()
+
+	withTx := repo.WithTx(tx)
+	before, err := s.repo.MessageGroups.GetByID(ctx, groupID, withTx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	if before.Status == factcheck.StatusMGroupRejected {
+		return factcheck.MessageGroup{}, fmt.Errorf("%w: group '%s' was rejected and cannot be assigned to topics", ErrConflict, groupID)
+	}
+	group, err := s.repo.MessageGroups.AssignTopic(ctx, groupID, topicID, withTx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	err = s.audit(ctx, user, factcheck.ActionAuditGroupAssignTopic, factcheck.TypeTargetGroup, []string{groupID, topicID}, before, group, withTx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	err = s.emit(ctx, factcheck.TypeEventGroupAssigned, group.ID, group, withTx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	return group, nil
+}
+
+func (s ServiceFactcheck) AssignMessageGroup(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	messageID string,
+	groupID string,
+) (
+	factcheck.MessageV2,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.AssignMessageGroup")
+	defer span.End()
+	tx, err := s.repo.BeginTx(ctx, repo.RepeatableRead)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err == nil {
+			return
+		}
+		slog.ErrorContext(ctx, "error rolling back after failure to assign message group",
+			"message_id", messageID,
+			"group_id", groupID,
+			"user", user,
+		)
+	}()
+
+	withTx := repo.WithTx(tx)
+	before, err := s.repo.MessagesV2.GetByID(ctx, messageID, withTx)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	message, err := s.repo.MessagesV2.AssignGroup(ctx, messageID, groupID, withTx)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	err = s.audit(ctx, user, factcheck.ActionAuditMessageAssignGroup, factcheck.TypeTargetMessage, []string{messageID, groupID}, before, message, withTx)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	err = s.emit(ctx, factcheck.TypeEventMessageAssigned, message.ID, message, withTx)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return factcheck.MessageV2{}, err
+	}
+	return message, nil
+}
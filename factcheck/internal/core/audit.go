@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// audit writes audit event of an action by user into the audit log.
+// Callers should pass the transaction of the change as opts.
+//
+// before and after are snapshots of the first target, and should be nil
+// for creations and deletions respectively.
+// The request ID is taken from ctx if the request went through chi middleware.RequestID.
+func (s ServiceFactcheck) audit(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	action factcheck.ActionAudit,
+	targetType factcheck.TypeTarget,
+	targetIDs []string,
+	before any,
+	after any,
+	opts ...repo.Option,
+) error {
+	snapshotBefore, err := snapshot(before)
+	if err != nil {
+		return fmt.Errorf("error marshaling snapshot before %s of %v: %w", action, targetIDs, err)
+	}
+	snapshotAfter, err := snapshot(after)
+	if err != nil {
+		return fmt.Errorf("error marshaling snapshot after %s of %v: %w", action, targetIDs, err)
+	}
+	_, err = s.repo.Audit.Create(ctx, factcheck.AuditEvent{
+		ID:         utils.NewID().String(),
+		Actor:      factcheck.UserInfo{UserType: user.UserType, UserID: user.UserID},
+		Action:     action,
+		TargetType: targetType,
+		TargetIDs:  targetIDs,
+		Before:     snapshotBefore,
+		After:      snapshotAfter,
+		RequestID:  middleware.GetReqID(ctx),
+		CreatedAt:  utils.TimeNow(),
+	}, opts...)
+	if err != nil {
+		return fmt.Errorf("error writing audit event %s of %v: %w", action, targetIDs, err)
+	}
+	return nil
+}
+
+func snapshot(data any) (json.RawMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+	return json.Marshal(data)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// emit writes a new event into outbox.
+// Callers should pass the transaction of the change as opts.
+func (s ServiceFactcheck) emit(
+	ctx context.Context,
+	typ factcheck.TypeEvent,
+	subjectID string,
+	payload any,
+	opts ...repo.Option,
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload for event %s of '%s': %w", typ, subjectID, err)
+	}
+	_, err = s.repo.Outbox.Create(ctx, factcheck.Event{
+		ID:        utils.NewID().String(),
+		Type:      typ,
+		SubjectID: subjectID,
+		Payload:   data,
+		CreatedAt: utils.TimeNow(),
+	}, opts...)
+	if err != nil {
+		return fmt.Errorf("error writing event %s of '%s' to outbox: %w", typ, subjectID, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+func (s ServiceFactcheck) MergeTopics(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	sourceID string,
+	targetID string,
+) (
+	factcheck.TopicMerge,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.MergeTopics")
+	defer span.End()
+	if sourceID == targetID {
+		return factcheck.TopicMerge{}, fmt.Errorf("%w: topic '%s' cannot be merged into itself", ErrConflict, sourceID)
+	}
+	merge, err := inTx(ctx, s, string(factcheck.ActionAuditTopicMerge), func(withTx repo.Option) (factcheck.TopicMerge, error) {
+		source, err := s.repo.Topics.GetByID(ctx, sourceID, withTx)
+		if err != nil {
+			return factcheck.TopicMerge{}, err
+		}
+		_, err = s.repo.Topics.GetByID(ctx, targetID, withTx)
+		if err != nil {
+			return factcheck.TopicMerge{}, err
+		}
+		merge, err := s.repo.Topics.Merge(ctx, sourceID, targetID, withTx)
+		if err != nil {
+			return factcheck.TopicMerge{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditTopicMerge, factcheck.TypeTargetTopic, []string{sourceID, targetID}, source, merge, withTx)
+		if err != nil {
+			return factcheck.TopicMerge{}, err
+		}
+		err = s.emit(ctx, factcheck.TypeEventTopicMerged, targetID, merge, withTx)
+		if err != nil {
+			return factcheck.TopicMerge{}, err
+		}
+		return merge, nil
+	})
+	if err != nil {
+		return factcheck.TopicMerge{}, err
+	}
+	slog.InfoContext(ctx, "merged topics",
+		"source_id", sourceID,
+		"target_id", targetID,
+		"groups", merge.Groups,
+		"groups_merged", merge.GroupsMerged,
+		"messages", merge.Messages,
+		"answers", merge.Answers,
+		"user", user,
+	)
+	return merge, nil
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+)
+
+// Business counters, which are only incremented after their transactions commit
+var (
+	metricSubmissions = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_submissions_total",
+		Help: "Messages submitted by type of user and type of message",
+	}, []string{"type_user", "type_message"})
+	metricGroupsCreated = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_groups_created_total",
+		Help: "Message groups created for submissions not matching existing groups",
+	}, []string{"type_message"})
+	metricResolutions = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_resolutions_total",
+		Help: "Topics resolved by published answers, by verdict and whether the answer is a correction",
+	}, []string{"verdict", "correction"})
+)
+
+func countResolution(answer factcheck.Answer) {
+	metricResolutions.WithLabelValues(string(answer.Verdict), strconv.FormatBool(answer.CorrectsID != "")).Inc()
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// ErrConflict is returned when an operation is not allowed
+// in the current state of its data, e.g. assigning a rejected group to a topic
+var ErrConflict = errors.New("conflict")
+
+func (s ServiceFactcheck) ApproveGroup(ctx context.Context, user factcheck.UserInfo, id string, reason string) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "core.ApproveGroup")
+	defer span.End()
+	return s.moderateGroup(ctx, user, id, factcheck.StatusMGroupApproved, reason)
+}
+
+func (s ServiceFactcheck) RejectGroup(ctx context.Context, user factcheck.UserInfo, id string, reason string) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "core.RejectGroup")
+	defer span.End()
+	return s.moderateGroup(ctx, user, id, factcheck.StatusMGroupRejected, reason)
+}
+
+// moderateGroup moves group to status. Rejected groups and their messages are also
+// unassigned from their topics, because rejected groups can't belong to topics.
+func (s ServiceFactcheck) moderateGroup(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	id string,
+	status factcheck.StatusMGroup,
+	reason string,
+) (
+	factcheck.MessageGroup,
+	error,
+) {
+	action, typeEvent := factcheck.ActionAuditGroupApprove, factcheck.TypeEventGroupApproved
+	if status == factcheck.StatusMGroupRejected {
+		action, typeEvent = factcheck.ActionAuditGroupReject, factcheck.TypeEventGroupRejected
+	}
+	return inTx(ctx, s, string(action), func(withTx repo.Option) (factcheck.MessageGroup, error) {
+		before, err := s.repo.MessageGroups.GetByID(ctx, id, withTx)
+		if err != nil {
+			return factcheck.MessageGroup{}, err
+		}
+		if !before.Status.CanTransition(status) {
+			return factcheck.MessageGroup{}, fmt.Errorf("%w: group '%s' is %s and cannot become %s", ErrConflict, id, before.Status, status)
+		}
+		targetIDs := []string{id}
+		if status == factcheck.StatusMGroupRejected && before.TopicID != "" {
+			_, err = s.repo.MessageGroups.UnassignTopic(ctx, id, withTx)
+			if err != nil {
+				return factcheck.MessageGroup{}, err
+			}
+			targetIDs = append(targetIDs, before.TopicID)
+		}
+		if status == factcheck.StatusMGroupRejected {
+			// Messages keep topics their groups had when they were submitted, so they are unassigned separately
+			_, err = s.repo.MessagesV2.UnassignTopicByGroup(ctx, id, withTx)
+			if err != nil {
+				return factcheck.MessageGroup{}, err
+			}
+		}
+		group, err := s.repo.MessageGroups.UpdateStatus(ctx, id, status, reason, withTx)
+		if err != nil {
+			return factcheck.MessageGroup{}, err
+		}
+		err = s.audit(ctx, user, action, factcheck.TypeTargetGroup, targetIDs, before, group, withTx)
+		if err != nil {
+			return factcheck.MessageGroup{}, err
+		}
+		err = s.emit(ctx, typeEvent, group.ID, group, withTx)
+		if err != nil {
+			return factcheck.MessageGroup{}, err
+		}
+		return group, nil
+	})
+}
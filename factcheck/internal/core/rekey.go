@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+func (s ServiceFactcheck) RekeyGroups(ctx context.Context, afterID string, limit int) (string, error) {
+	ctx, span := tracing.Start(ctx, "core.RekeyGroups")
+	defer span.End()
+	groups, err := s.repo.MessageGroups.ListAfterID(ctx, afterID, limit)
+	if err != nil {
+		return "", err
+	}
+	if len(groups) == 0 {
+		return "", nil
+	}
+	// Compute keys before transaction, because short links are resolved over the network
+	keys := make([]groupKey, len(groups))
+	for i := range groups {
+		keys[i], err = s.groupKey(ctx, groups[i].Text)
+		if err != nil {
+			return "", fmt.Errorf("error computing key of group %s: %w", groups[i].ID, err)
+		}
+	}
+	return inTx(ctx, s, "RekeyGroups", func(withTx repo.Option) (string, error) {
+		for i := range groups {
+			group, key := groups[i], keys[i]
+			if group.TextSHA1 == key.sha1 && group.TextSimHash == key.simhash {
+				continue
+			}
+			existing, err := s.repo.MessageGroups.GetBySHA1(ctx, key.sha1, withTx)
+			if err == nil && existing.ID != group.ID {
+				// Submissions could only join one of them, so moderators should merge their topics
+				slog.WarnContext(ctx, "skipping group with the same key as another group",
+					"gid", group.ID,
+					"other_gid", existing.ID,
+					"sha1", key.sha1,
+				)
+				continue
+			}
+			if err != nil && !repo.IsNotFound(err) {
+				return "", err
+			}
+			err = s.repo.MessageGroups.UpdateKey(ctx, group.ID, key.sha1, key.simhash, withTx)
+			if err != nil {
+				return "", fmt.Errorf("error updating key of group %s: %w", group.ID, err)
+			}
+		}
+		return groups[len(groups)-1].ID, nil
+	})
+}
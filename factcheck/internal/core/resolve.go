@@ -2,10 +2,12 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -14,12 +16,18 @@ func (s ServiceFactcheck) Resolve(
 	user factcheck.UserInfo,
 	topicID string,
 	answerText string,
+	verdict factcheck.Verdict,
 ) (
 	factcheck.Answer,
 	factcheck.Topic,
 	[]factcheck.MessageV2,
 	error,
 ) {
+	ctx, span := tracing.Start(ctx, "core.Resolve")
+	defer span.End()
+	if !verdict.IsValid() {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, fmt.Errorf("invalid verdict '%s'", verdict)
+	}
 	tx, err := s.repo.BeginTx(ctx, repo.RepeatableRead)
 	if err != nil {
 		return factcheck.Answer{}, factcheck.Topic{}, nil, err
@@ -36,26 +44,237 @@ func (s ServiceFactcheck) Resolve(
 	}()
 
 	withTx := repo.WithTx(tx)
-	answer := factcheck.Answer{
+	before, err := s.repo.Topics.GetByID(ctx, topicID, withTx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	draft, err := s.createDraft(ctx, user, topicID, answerText, verdict, withTx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	answer, resolved, messages, err := s.publish(ctx, user, before, draft, withTx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	countResolution(answer)
+	return answer, resolved, messages, nil
+}
+
+func (s ServiceFactcheck) DraftAnswer(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	answerText string,
+	verdict factcheck.Verdict,
+) (
+	factcheck.Answer,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.DraftAnswer")
+	defer span.End()
+	if !verdict.IsValid() {
+		return factcheck.Answer{}, fmt.Errorf("invalid verdict '%s'", verdict)
+	}
+	return inTx(ctx, s, string(factcheck.ActionAuditAnswerDraft), func(withTx repo.Option) (factcheck.Answer, error) {
+		_, err := s.repo.Topics.GetByID(ctx, topicID, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		draft, err := s.createDraft(ctx, user, topicID, answerText, verdict, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditAnswerDraft, factcheck.TypeTargetAnswer, []string{draft.ID, topicID}, nil, draft, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		return draft, nil
+	})
+}
+
+func (s ServiceFactcheck) UpdateDraftAnswer(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	answerID string,
+	answerText string,
+	verdict factcheck.Verdict,
+) (
+	factcheck.Answer,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.UpdateDraftAnswer")
+	defer span.End()
+	if !verdict.IsValid() {
+		return factcheck.Answer{}, fmt.Errorf("invalid verdict '%s'", verdict)
+	}
+	return inTx(ctx, s, string(factcheck.ActionAuditAnswerUpdateDraft), func(withTx repo.Option) (factcheck.Answer, error) {
+		before, err := s.getDraft(ctx, topicID, answerID, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		updated, err := s.repo.Answers.UpdateDraft(ctx, answerID, answerText, verdict, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditAnswerUpdateDraft, factcheck.TypeTargetAnswer, []string{answerID, topicID}, before, updated, withTx)
+		if err != nil {
+			return factcheck.Answer{}, err
+		}
+		return updated, nil
+	})
+}
+
+func (s ServiceFactcheck) PublishAnswer(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	answerID string,
+) (
+	factcheck.Answer,
+	factcheck.Topic,
+	[]factcheck.MessageV2,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.PublishAnswer")
+	defer span.End()
+	type result struct {
+		answer   factcheck.Answer
+		topic    factcheck.Topic
+		messages []factcheck.MessageV2
+	}
+	r, err := inTx(ctx, s, "publish answer", func(withTx repo.Option) (result, error) {
+		before, err := s.repo.Topics.GetByID(ctx, topicID, withTx)
+		if err != nil {
+			return result{}, err
+		}
+		draft, err := s.getDraft(ctx, topicID, answerID, withTx)
+		if err != nil {
+			return result{}, err
+		}
+		answer, resolved, messages, err := s.publish(ctx, user, before, draft, withTx)
+		if err != nil {
+			return result{}, err
+		}
+		return result{answer: answer, topic: resolved, messages: messages}, nil
+	})
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	countResolution(r.answer)
+	return r.answer, r.topic, r.messages, nil
+}
+
+func (s ServiceFactcheck) ReopenTopic(ctx context.Context, user factcheck.UserInfo, topicID string) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "core.ReopenTopic")
+	defer span.End()
+	return inTx(ctx, s, string(factcheck.ActionAuditTopicReopen), func(withTx repo.Option) (factcheck.Topic, error) {
+		before, err := s.repo.Topics.GetByID(ctx, topicID, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		if before.Status != factcheck.StatusTopicResolved {
+			return factcheck.Topic{}, fmt.Errorf("%w: topic '%s' is %s and cannot be re-opened", ErrConflict, topicID, before.Status)
+		}
+		reopened, err := s.repo.Topics.Reopen(ctx, topicID, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditTopicReopen, factcheck.TypeTargetTopic, []string{topicID}, before, reopened, withTx)
+		if err != nil {
+			return factcheck.Topic{}, err
+		}
+		return reopened, nil
+	})
+}
+
+// createDraft creates draft answer as the next revision of topic's answers
+func (s ServiceFactcheck) createDraft(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	answerText string,
+	verdict factcheck.Verdict,
+	withTx repo.Option,
+) (
+	factcheck.Answer,
+	error,
+) {
+	draft, err := s.repo.Answers.Create(ctx, factcheck.Answer{
 		ID:        utils.NewID().String(),
 		UserID:    user.UserID,
 		TopicID:   topicID,
+		Status:    factcheck.StatusAnswerDraft,
 		Text:      answerText,
+		Verdict:   verdict,
 		CreatedAt: utils.TimeNow(),
+	}, withTx)
+	if err != nil {
+		return factcheck.Answer{}, err
 	}
-	answer, err = s.repo.Answers.Create(ctx, answer, withTx)
+	err = s.emit(ctx, factcheck.TypeEventAnswerCreated, draft.ID, draft, withTx)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	return draft, nil
+}
+
+// getDraft gets draft answerID of topicID. Published answers cannot be changed, and ErrConflict is returned.
+func (s ServiceFactcheck) getDraft(ctx context.Context, topicID string, answerID string, withTx repo.Option) (factcheck.Answer, error) {
+	draft, err := s.repo.Answers.GetByID(ctx, answerID, withTx)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	if draft.TopicID != topicID {
+		return factcheck.Answer{}, fmt.Errorf("%w: answer '%s' is not of topic '%s'", ErrConflict, answerID, topicID)
+	}
+	if draft.Status != factcheck.StatusAnswerDraft {
+		return factcheck.Answer{}, fmt.Errorf("%w: answer '%s' was already published", ErrConflict, answerID)
+	}
+	return draft, nil
+}
+
+// publish publishes draft as the answer of topic before, and resolves the topic.
+// If topic already had a published answer, draft is published as its correction.
+// It returns messages of the topic, whose submitters are notified by the outbox relay
+// once answer.published emitted here is committed.
+func (s ServiceFactcheck) publish(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	before factcheck.Topic,
+	draft factcheck.Answer,
+	withTx repo.Option,
+) (
+	factcheck.Answer,
+	factcheck.Topic,
+	[]factcheck.MessageV2,
+	error,
+) {
+	answer, err := s.repo.Answers.Publish(ctx, draft.ID, before.AnswerID, withTx)
 	if err != nil {
 		return factcheck.Answer{}, factcheck.Topic{}, nil, err
 	}
-	resolved, err := s.repo.Topics.Resolve(ctx, topicID, answerText, withTx)
+	resolved, err := s.repo.Topics.Resolve(ctx, before.ID, answer, withTx)
 	if err != nil {
 		return factcheck.Answer{}, factcheck.Topic{}, nil, err
 	}
-	messages, err := s.repo.MessagesV2.ListByTopic(ctx, topicID, withTx)
+	messages, err := s.repo.MessagesV2.ListByTopic(ctx, before.ID, withTx)
 	if err != nil {
 		return factcheck.Answer{}, factcheck.Topic{}, nil, err
 	}
-	err = tx.Commit(ctx)
+	err = s.audit(ctx, user, factcheck.ActionAuditTopicResolve, factcheck.TypeTargetTopic, []string{before.ID, answer.ID}, before, resolved, withTx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	err = s.emit(ctx, factcheck.TypeEventAnswerPublished, answer.ID, answer, withTx)
+	if err != nil {
+		return factcheck.Answer{}, factcheck.Topic{}, nil, err
+	}
+	err = s.emit(ctx, factcheck.TypeEventTopicResolved, resolved.ID, resolved, withTx)
 	if err != nil {
 		return factcheck.Answer{}, factcheck.Topic{}, nil, err
 	}
@@ -6,6 +6,10 @@ import (
 	"context"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -14,19 +18,113 @@ import (
 // just use the repository.
 type Service interface {
 	// Submit handles new message submission by creating the message and assigning it to a group.
+	// Messages join groups with identical normalized text, or groups similar enough by config.Dedup.
+	// Messages that are URLs are instead grouped by their canonical URLs.
+	// Language of the message and its new group is detected from the text.
 	// Submit returns message created, message group assigned to the new message, and topic (if any)
 	//
 	// Caller could call this Submit, and on success gets all the messages from users for replies.
 	Submit(ctx context.Context, user factcheck.UserInfo, text string, topicID string) (factcheck.MessageV2, factcheck.MessageGroup, *factcheck.Topic, error)
 
-	// Resolve resolves topic and returns list of messages associated with the topic.
-	Resolve(ctx context.Context, user factcheck.UserInfo, topicID string, answer string) (factcheck.Answer, factcheck.Topic, []factcheck.MessageV2, error)
+	// SubmitLINE submits text of LINE webhook event webhookEventID like Submit, but at most once per event.
+	// The event ID is recorded in the transaction of the submission, so events redelivered by LINE
+	// after failures are submitted again, but events already submitted return ErrDuplicateEvent.
+	// Events without IDs are always submitted.
+	SubmitLINE(ctx context.Context, user factcheck.UserInfo, text string, webhookEventID string) (factcheck.MessageV2, factcheck.MessageGroup, *factcheck.Topic, error)
+
+	// Resolve resolves topic with answer and verdict, and returns list of messages associated with the topic.
+	// The answer is published at once as the next revision, correcting the previously published answer if any.
+	// Submitters of the messages are notified later, when the outbox relay publishes answer.published.
+	Resolve(ctx context.Context, user factcheck.UserInfo, topicID string, answer string, verdict factcheck.Verdict) (factcheck.Answer, factcheck.Topic, []factcheck.MessageV2, error)
+
+	// DraftAnswer creates a draft answer as the next revision of topic's answers.
+	// Drafts are not visible to the public until published.
+	DraftAnswer(ctx context.Context, user factcheck.UserInfo, topicID string, answer string, verdict factcheck.Verdict) (factcheck.Answer, error)
+
+	// UpdateDraftAnswer updates text and verdict of draft answerID.
+	// Published answers cannot be updated, and ErrConflict is returned.
+	UpdateDraftAnswer(ctx context.Context, user factcheck.UserInfo, topicID string, answerID string, answer string, verdict factcheck.Verdict) (factcheck.Answer, error)
+
+	// PublishAnswer publishes draft answerID and resolves the topic, like Resolve.
+	// If the topic already had a published answer, the draft is published as its correction.
+	PublishAnswer(ctx context.Context, user factcheck.UserInfo, topicID string, answerID string) (factcheck.Answer, factcheck.Topic, []factcheck.MessageV2, error)
+
+	// ReopenTopic moves resolved topic back to pending, so that its answer could be corrected.
+	// The published answer is kept until a correction is published.
+	ReopenTopic(ctx context.Context, user factcheck.UserInfo, topicID string) (factcheck.Topic, error)
+
+	// CreateSource creates source cited by answer source.AnswerID of topicID.
+	// Answers of other topics are not found.
+	CreateSource(ctx context.Context, user factcheck.UserInfo, topicID string, source factcheck.Source) (factcheck.Source, error)
+
+	// UpdateSource updates source cited by answer source.AnswerID of topicID.
+	UpdateSource(ctx context.Context, user factcheck.UserInfo, topicID string, source factcheck.Source) (factcheck.Source, error)
+
+	// DeleteSource deletes source id cited by answerID of topicID.
+	DeleteSource(ctx context.Context, user factcheck.UserInfo, topicID string, answerID string, id string) error
+
+	// AssignGroupTopic assigns message group to topic.
+	// Rejected groups cannot be assigned, and ErrConflict is returned.
+	AssignGroupTopic(ctx context.Context, user factcheck.UserInfo, groupID string, topicID string) (factcheck.MessageGroup, error)
+
+	// AssignMessageGroup assigns message to message group
+	AssignMessageGroup(ctx context.Context, user factcheck.UserInfo, messageID string, groupID string) (factcheck.MessageV2, error)
+
+	// MergeTopics merges topic sourceID into targetID in one transaction.
+	// Groups, messages and answers are moved to the target, and the source topic
+	// is replaced by a redirect, so that its ID still resolves to the target.
+	// Merging a topic into itself returns ErrConflict.
+	MergeTopics(ctx context.Context, user factcheck.UserInfo, sourceID string, targetID string) (factcheck.TopicMerge, error)
+
+	// SplitTopic creates topic, and moves message groups groupIDs of topic sourceID
+	// and their messages into it, in one transaction.
+	// Groups not in the source topic return ErrConflict.
+	SplitTopic(ctx context.Context, user factcheck.UserInfo, sourceID string, topic factcheck.Topic, groupIDs []string) (factcheck.TopicSplit, error)
+
+	// RekeyGroups recomputes TextSHA1 and TextSimHash of up to limit groups with IDs after afterID,
+	// like Submit computes them for new groups, e.g. for groups created before we normalized texts.
+	// Groups whose new key is taken by another group are skipped and logged.
+	// It returns ID of the last group, and empty ID when there are no more groups.
+	RekeyGroups(ctx context.Context, afterID string, limit int) (string, error)
+
+	// Admin operations below write audit events in the same transaction as the change
+
+	CreateTopic(ctx context.Context, user factcheck.UserInfo, topic factcheck.Topic) (factcheck.Topic, error)
+	UpdateTopicStatus(ctx context.Context, user factcheck.UserInfo, id string, status factcheck.StatusTopic) (factcheck.Topic, error)
+	UpdateTopicName(ctx context.Context, user factcheck.UserInfo, id string, name string) (factcheck.Topic, error)
+	UpdateTopicDescription(ctx context.Context, user factcheck.UserInfo, id string, description string) (factcheck.Topic, error)
+	DeleteTopic(ctx context.Context, user factcheck.UserInfo, id string) error
+	DeleteMessageGroup(ctx context.Context, user factcheck.UserInfo, id string) error
+	DeleteMessage(ctx context.Context, user factcheck.UserInfo, id string) error
+	ApproveGroup(ctx context.Context, user factcheck.UserInfo, id string, reason string) (factcheck.MessageGroup, error)
+	RejectGroup(ctx context.Context, user factcheck.UserInfo, id string, reason string) (factcheck.MessageGroup, error)
+	GrantRole(ctx context.Context, user factcheck.UserInfo, role factcheck.UserRole) (factcheck.UserRole, error)
+	RevokeRole(ctx context.Context, user factcheck.UserInfo, userID string) error
 }
 
-func New(repo repo.Repository) ServiceFactcheck {
-	return ServiceFactcheck{repo: repo}
+func New(
+	conf config.Config,
+	repo repo.Repository,
+	resolver links.Resolver,
+	fetcher links.Fetcher,
+	detector language.Detector,
+	limits ratelimit.Policy,
+) ServiceFactcheck {
+	return ServiceFactcheck{
+		repo:     repo,
+		dedup:    conf.Dedup,
+		resolver: resolver,
+		fetcher:  fetcher,
+		detector: detector,
+		limits:   limits,
+	}
 }
 
 type ServiceFactcheck struct {
-	repo repo.Repository
+	repo     repo.Repository
+	dedup    config.Dedup
+	resolver links.Resolver
+	fetcher  links.Fetcher
+	detector language.Detector
+	limits   ratelimit.Policy
 }
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+func (s ServiceFactcheck) CreateSource(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	source factcheck.Source,
+) (
+	factcheck.Source,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.CreateSource")
+	defer span.End()
+	err := source.Validate()
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	return inTx(ctx, s, string(factcheck.ActionAuditSourceCreate), func(withTx repo.Option) (factcheck.Source, error) {
+		_, err := s.getDraftOfTopic(ctx, topicID, source.AnswerID, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		created, err := s.repo.Sources.Create(ctx, source, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditSourceCreate, factcheck.TypeTargetSource, []string{created.ID, created.AnswerID, topicID}, nil, created, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		return created, nil
+	})
+}
+
+func (s ServiceFactcheck) UpdateSource(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	topicID string,
+	source factcheck.Source,
+) (
+	factcheck.Source,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.UpdateSource")
+	defer span.End()
+	err := source.Validate()
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	return inTx(ctx, s, string(factcheck.ActionAuditSourceUpdate), func(withTx repo.Option) (factcheck.Source, error) {
+		before, err := s.getSourceOfTopic(ctx, topicID, source.AnswerID, source.ID, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		updated, err := s.repo.Sources.Update(ctx, source, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		err = s.audit(ctx, user, factcheck.ActionAuditSourceUpdate, factcheck.TypeTargetSource, []string{updated.ID, updated.AnswerID, topicID}, before, updated, withTx)
+		if err != nil {
+			return factcheck.Source{}, err
+		}
+		return updated, nil
+	})
+}
+
+func (s ServiceFactcheck) DeleteSource(ctx context.Context, user factcheck.UserInfo, topicID string, answerID string, id string) error {
+	ctx, span := tracing.Start(ctx, "core.DeleteSource")
+	defer span.End()
+	_, err := inTx(ctx, s, string(factcheck.ActionAuditSourceDelete), func(withTx repo.Option) (emptyResult, error) {
+		before, err := s.getSourceOfTopic(ctx, topicID, answerID, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		err = s.repo.Sources.Delete(ctx, id, withTx)
+		if err != nil {
+			return emptyResult{}, err
+		}
+		return emptyResult{}, s.audit(ctx, user, factcheck.ActionAuditSourceDelete, factcheck.TypeTargetSource, []string{id, answerID, topicID}, before, nil, withTx)
+	})
+	return err
+}
+
+// getDraftOfTopic gets answer answerID, which is not found if it is not an answer to topicID.
+// Sources of published answers cannot be changed, so ErrConflict is returned for published answers.
+func (s ServiceFactcheck) getDraftOfTopic(ctx context.Context, topicID string, answerID string, withTx repo.Option) (factcheck.Answer, error) {
+	answer, err := s.repo.Answers.GetByID(ctx, answerID, withTx)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	if answer.TopicID != topicID {
+		return factcheck.Answer{}, &repo.ErrNotFound{Filter: map[string]string{"id": answerID, "topic_id": topicID}}
+	}
+	if answer.Status != factcheck.StatusAnswerDraft {
+		return factcheck.Answer{}, fmt.Errorf("%w: answer '%s' was already published, and its sources cannot be changed", ErrConflict, answerID)
+	}
+	return answer, nil
+}
+
+// getSourceOfTopic gets source id of draft answerID, which is not found if it is not cited by answerID of topicID
+func (s ServiceFactcheck) getSourceOfTopic(ctx context.Context, topicID string, answerID string, id string, withTx repo.Option) (factcheck.Source, error) {
+	_, err := s.getDraftOfTopic(ctx, topicID, answerID, withTx)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	source, err := s.repo.Sources.GetByID(ctx, id, withTx)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	if source.AnswerID != answerID {
+		return factcheck.Source{}, &repo.ErrNotFound{Filter: map[string]string{"id": id, "answer_id": answerID}}
+	}
+	return source, nil
+}
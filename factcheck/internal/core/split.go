@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+func (s ServiceFactcheck) SplitTopic(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	sourceID string,
+	topic factcheck.Topic,
+	groupIDs []string,
+) (
+	factcheck.TopicSplit,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.SplitTopic")
+	defer span.End()
+	groupIDs = slices.Compact(slices.Sorted(slices.Values(groupIDs)))
+	if len(groupIDs) == 0 {
+		return factcheck.TopicSplit{}, fmt.Errorf("%w: no groups to split from topic '%s'", ErrConflict, sourceID)
+	}
+	split, err := inTx(ctx, s, string(factcheck.ActionAuditTopicSplit), func(withTx repo.Option) (factcheck.TopicSplit, error) {
+		source, err := s.repo.Topics.GetByID(ctx, sourceID, withTx)
+		if err != nil {
+			return factcheck.TopicSplit{}, err
+		}
+		for _, id := range groupIDs {
+			group, err := s.repo.MessageGroups.GetByID(ctx, id, withTx)
+			if err != nil && !repo.IsNotFound(err) {
+				return factcheck.TopicSplit{}, err
+			}
+			if err != nil || group.TopicID != sourceID {
+				return factcheck.TopicSplit{}, fmt.Errorf("%w: group '%s' is not in topic '%s'", ErrConflict, id, sourceID)
+			}
+		}
+		split, err := s.repo.Topics.Split(ctx, sourceID, topic, groupIDs, withTx)
+		if err != nil {
+			return factcheck.TopicSplit{}, err
+		}
+		targetIDs := append([]string{sourceID, split.Split.Topic.ID}, groupIDs...)
+		err = s.audit(ctx, user, factcheck.ActionAuditTopicSplit, factcheck.TypeTargetTopic, targetIDs, source, split, withTx)
+		if err != nil {
+			return factcheck.TopicSplit{}, err
+		}
+		err = s.emit(ctx, factcheck.TypeEventTopicSplit, sourceID, split, withTx)
+		if err != nil {
+			return factcheck.TopicSplit{}, err
+		}
+		return split, nil
+	})
+	if err != nil {
+		return factcheck.TopicSplit{}, err
+	}
+	slog.InfoContext(ctx, "split topic",
+		"source_id", sourceID,
+		"split_id", split.Split.Topic.ID,
+		"groups", split.Split.Groups,
+		"messages", split.Split.Messages,
+		"user", user,
+	)
+	return split, nil
+}
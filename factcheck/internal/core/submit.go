@@ -9,7 +9,10 @@ import (
 	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -23,64 +26,172 @@ func (s ServiceFactcheck) Submit(
 	factcheck.MessageGroup,
 	*factcheck.Topic,
 	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.Submit")
+	defer span.End()
+	return s.submitOnce(ctx, user, text, topicID, "")
+}
+
+// ErrDuplicateEvent is returned by SubmitLINE for webhook events already submitted
+var ErrDuplicateEvent = errors.New("duplicate webhook event")
+
+func (s ServiceFactcheck) SubmitLINE(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	text string,
+	webhookEventID string,
+) (
+	factcheck.MessageV2,
+	factcheck.MessageGroup,
+	*factcheck.Topic,
+	error,
+) {
+	ctx, span := tracing.Start(ctx, "core.SubmitLINE")
+	defer span.End()
+	return s.submitOnce(ctx, user, text, "", webhookEventID)
+}
+
+// submitOnce submits text like Submit. If webhookEventID is not empty, it is recorded
+// in the transaction of the submission, which fails with ErrDuplicateEvent if it was already recorded.
+func (s ServiceFactcheck) submitOnce(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	text string,
+	topicID string,
+	webhookEventID string,
+) (
+	factcheck.MessageV2,
+	factcheck.MessageGroup,
+	*factcheck.Topic,
+	error,
 ) {
 	if text == "" {
 		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, errors.New("empty message text submitted")
 	}
+	err := s.limits.Allow(ctx, user)
+	if err != nil {
+		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, err
+	}
 
 	slog.InfoContext(ctx, "got submission", "text", text, "topic_id", topicID)
 	meta := factcheck.Metadata[factcheck.UserInfo]{
 		Type: factcheck.TypeMetadataUserInfo,
 		Data: user,
 	}
-	textSHA1 := factcheck.SHA1(text)
+	key, err := s.groupKey(ctx, text)
+	if err != nil {
+		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error computing group key: %w", err)
+	}
+	textSHA1 := key.sha1
 	metaJSON, err := json.Marshal(meta)
 	if err != nil {
 		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error creating metadata %s: %w", textSHA1, err)
 	}
-	tx, err := s.repo.BeginTx(ctx, repo.RepeatableRead)
+	// Fetch preview before transaction, because it could take a while
+	preview := s.preview(ctx, key)
+	lang := s.detectLanguage(key, preview)
+	// Scan for near-duplicates before transaction too, because the scan reads every recent group with SimHash
+	similarID, err := s.similarGroup(ctx, key)
 	if err != nil {
 		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, err
 	}
-	defer func() {
-		err := tx.Rollback(ctx)
-		if err == nil {
-			return
+	// Concurrent submissions of the same new text all try to create its group,
+	// and all but the first to commit fail on the unique index of unassigned groups.
+	// Retrying them joins the group of the first.
+	submit := func(withTx repo.Option) (submission, error) {
+		if webhookEventID != "" {
+			created, err := s.repo.LINEWebhookEvents.Create(ctx, webhookEventID, utils.TimeNow(), withTx)
+			if err != nil {
+				return submission{}, fmt.Errorf("error recording webhook event: %w", err)
+			}
+			if !created {
+				return submission{}, ErrDuplicateEvent
+			}
 		}
-		slog.ErrorContext(ctx, "error rolling back AdminSubmit", "err", err)
-	}()
+		return s.submit(ctx, user, text, topicID, key, lang, preview, similarID, metaJSON, withTx)
+	}
+	result, err := inTx(ctx, s, "Submit", submit)
+	if repo.IsUniqueViolation(err) || repo.IsTxConflict(err) {
+		slog.InfoContext(ctx, "retrying submission of concurrently created group", "sha1", textSHA1, "err", err)
+		result, err = inTx(ctx, s, "Submit", submit)
+	}
+	if errors.Is(err, ErrDuplicateEvent) {
+		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, err
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error submitting message",
+			"err", err,
+			"sha1", textSHA1,
+		)
+		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, err
+	}
+	metricSubmissions.WithLabelValues(string(user.UserType), string(key.typeMessage)).Inc()
+	if result.newGroup {
+		metricGroupsCreated.WithLabelValues(string(key.typeMessage)).Inc()
+	}
+	return result.message, result.group, result.topic, nil
+}
 
+// submission is what Submit created or joined
+type submission struct {
+	message  factcheck.MessageV2
+	group    factcheck.MessageGroup
+	topic    *factcheck.Topic
+	newGroup bool
+}
+
+// submit creates message of key in transaction withTx,
+// joining its existing group, or else creating a new group with lang and preview.
+func (s ServiceFactcheck) submit(
+	ctx context.Context,
+	user factcheck.UserInfo,
+	text string,
+	topicID string,
+	key groupKey,
+	lang factcheck.Language,
+	preview *factcheck.LinkPreview,
+	similarID string,
+	metaJSON []byte,
+	withTx repo.Option,
+) (
+	submission,
+	error,
+) {
 	now := time.Now()
-	withTx := repo.WithTx(tx)
+	textSHA1 := key.sha1
 
 	var topic *factcheck.Topic
 	if topicID != "" {
 		topicDB, err := s.repo.Topics.GetByID(ctx, topicID, withTx)
 		if err != nil {
-			return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error getting topic '%s' for a new message: %w", topicID, err)
+			return submission{}, fmt.Errorf("error getting topic '%s' for a new message: %w", topicID, err)
 		}
 		err = topicDB.Validate()
 		if err != nil {
-			return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error validating topic '%s' for a new message: %w", topicID, err)
+			return submission{}, fmt.Errorf("error validating topic '%s' for a new message: %w", topicID, err)
 		}
 		topic = &topicDB
 	}
 
-	group, err := s.repo.MessageGroups.GetBySHA1(ctx, textSHA1, withTx)
+	newGroup := false
+	group, err := s.findGroup(ctx, key, similarID, withTx)
 	if err != nil {
 		if !repo.IsNotFound(err) {
-			return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error finding group based on sha1 hash '%s': %w", textSHA1, err)
+			return submission{}, fmt.Errorf("error finding group based on sha1 hash '%s': %w", textSHA1, err)
 		}
 
 		// If not found, we'll create a new group for it.
 		// But the group will not have topicID - to be assigned topic by admin
 		slog.InfoContext(ctx, "pre-creating new group", "sha1", textSHA1)
 		group = factcheck.MessageGroup{
-			ID:        utils.NewID().String(),
-			Status:    factcheck.StatusMGroupPending,
-			Text:      text,
-			TextSHA1:  textSHA1,
-			CreatedAt: now,
+			ID:          utils.NewID().String(),
+			Status:      factcheck.StatusMGroupPending,
+			Text:        key.text,
+			TextSHA1:    textSHA1,
+			TextSimHash: key.simhash,
+			Language:    lang,
+			Preview:     preview,
+			CreatedAt:   now,
 		}
 		slog.InfoContext(ctx, "creating new group without topic",
 			"gid", group.ID,
@@ -94,40 +205,152 @@ func (s ServiceFactcheck) Submit(
 				"sha1", textSHA1,
 				"err", err,
 			)
-			return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error pre-creating group %s: %w", textSHA1, err)
+			return submission{}, fmt.Errorf("error pre-creating group %s: %w", textSHA1, err)
 		}
+		err = s.emit(ctx, factcheck.TypeEventGroupCreated, group.ID, group, withTx)
+		if err != nil {
+			return submission{}, err
+		}
+		newGroup = true
 	}
 	if !utils.Empty(topicID, group.ID) && topicID != group.ID {
 		// TODO: what to do?
 		// Mismatch topicID
-		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("mismatch topic '%s': found group %s (%s) has topic '%s'", topicID, group.ID, textSHA1, group.TopicID)
+		return submission{}, fmt.Errorf("mismatch topic '%s': found group %s (%s) has topic '%s'", topicID, group.ID, textSHA1, group.TopicID)
 	}
 
+	if lang == "" {
+		// e.g. URL messages joining existing groups
+		lang = group.Language
+	}
 	message := factcheck.MessageV2{
 		ID:          utils.NewID().String(),
 		TopicID:     group.TopicID,
 		GroupID:     group.ID,
 		UserID:      user.UserID,
 		TypeUser:    user.UserType,
-		TypeMessage: factcheck.TypeMessageText,
+		TypeMessage: key.typeMessage,
 		Text:        text,
+		Language:    lang,
 		Metadata:    metaJSON,
 		CreatedAt:   now,
 	}
 
 	created, err := s.repo.MessagesV2.Create(ctx, message, withTx)
 	if err != nil {
-		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error creating message: %w", err)
+		return submission{}, fmt.Errorf("error creating message: %w", err)
 	}
-	err = tx.Commit(ctx)
+	err = s.emit(ctx, factcheck.TypeEventMessageSubmitted, created.ID, created, withTx)
 	if err != nil {
-		slog.ErrorContext(ctx, "error committing admin submission",
-			"err", err,
-			"mid", message.ID,
-			"gid", group.ID,
-			"sha1", textSHA1,
-		)
-		return factcheck.MessageV2{}, factcheck.MessageGroup{}, nil, fmt.Errorf("error committing message: %w", err)
+		return submission{}, err
+	}
+	return submission{message: created, group: group, topic: topic, newGroup: newGroup}, nil
+}
+
+// groupKey identifies which group a submitted text belongs to
+type groupKey struct {
+	typeMessage factcheck.TypeMessage
+	text        string // Text of new group: the submitted text, or canonical URL
+	sha1        string
+	simhash     uint64 // 0 for URLs, which are only grouped by canonical URL
+}
+
+// groupKey returns key of text. URLs are keyed by their canonical forms,
+// and other texts by their normalized forms.
+func (s ServiceFactcheck) groupKey(ctx context.Context, text string) (groupKey, error) {
+	if !links.IsURL(text) {
+		normalized := dedup.Normalize(text)
+		return groupKey{
+			typeMessage: factcheck.TypeMessageText,
+			text:        text,
+			sha1:        factcheck.SHA1(normalized),
+			simhash:     dedup.SimHash(normalized),
+		}, nil
+	}
+	canonical, err := links.Canonical(ctx, s.resolver, text)
+	if err != nil {
+		return groupKey{}, err
+	}
+	return groupKey{
+		typeMessage: factcheck.TypeMessageURL,
+		text:        canonical,
+		sha1:        factcheck.SHA1(canonical),
+	}, nil
+}
+
+// preview fetches preview of URL key, if no group exists for it yet.
+// The lookup only saves fetching previews of known URLs: whether a group is created
+// is decided in the transaction of Submit, which discards preview when joining a group.
+// Failure to fetch is only logged, because previews are optional.
+func (s ServiceFactcheck) preview(ctx context.Context, key groupKey) *factcheck.LinkPreview {
+	if key.typeMessage != factcheck.TypeMessageURL {
+		return nil
+	}
+	_, err := s.repo.MessageGroups.GetBySHA1(ctx, key.sha1)
+	if !repo.IsNotFound(err) {
+		return nil
+	}
+	preview, err := s.fetcher.Fetch(ctx, key.text)
+	if err != nil {
+		slog.WarnContext(ctx, "error fetching link preview", "url", key.text, "err", err)
+		return nil
+	}
+	return &preview
+}
+
+// detectLanguage detects language of text, or of preview for URL messages
+func (s ServiceFactcheck) detectLanguage(key groupKey, preview *factcheck.LinkPreview) factcheck.Language {
+	if key.typeMessage != factcheck.TypeMessageURL {
+		return s.detector.Detect(key.text)
+	}
+	if preview == nil {
+		return ""
+	}
+	return s.detector.Detect(preview.Title + " " + preview.Description)
+}
+
+// similarGroup returns ID of the group most similar to key above s.dedup.ThresholdJoin,
+// or empty ID if there is none, or if a group with identical key exists.
+// Callers scan outside of transactions, so concurrent near-duplicate submissions
+// could create separate groups, which moderators can still merge.
+func (s ServiceFactcheck) similarGroup(ctx context.Context, key groupKey) (string, error) {
+	if key.simhash == 0 || s.dedup.ThresholdJoin <= 0 {
+		return "", nil
+	}
+	_, err := s.repo.MessageGroups.GetBySHA1(ctx, key.sha1)
+	if err == nil {
+		return "", nil
+	}
+	if !repo.IsNotFound(err) {
+		return "", fmt.Errorf("error finding group based on sha1 hash '%s': %w", key.sha1, err)
+	}
+	similar, err := s.repo.MessageGroups.ListSimilar(ctx, key.simhash, s.dedup.ThresholdJoin, utils.TimeNow().Add(-s.dedup.Window()), 1)
+	if err != nil {
+		return "", fmt.Errorf("error finding similar group: %w", err)
+	}
+	if len(similar) == 0 {
+		return "", nil
+	}
+	slog.InfoContext(ctx, "found similar group",
+		"gid", similar[0].ID,
+		"sha1", key.sha1,
+		"similarity", similar[0].Similarity,
+	)
+	return similar[0].ID, nil
+}
+
+// findGroup finds group with identical key, or else group similarID from similarGroup if any.
+// If no group is found, the error is repo.ErrNotFound.
+func (s ServiceFactcheck) findGroup(ctx context.Context, key groupKey, similarID string, withTx repo.Option) (factcheck.MessageGroup, error) {
+	group, err := s.repo.MessageGroups.GetBySHA1(ctx, key.sha1, withTx)
+	if err == nil || !repo.IsNotFound(err) || similarID == "" {
+		return group, err
+	}
+	// The similar group could have been deleted since the scan
+	group, err = s.repo.MessageGroups.GetByID(ctx, similarID, withTx)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
 	}
-	return created, group, topic, nil
+	slog.InfoContext(ctx, "joining similar group", "gid", group.ID, "sha1", key.sha1)
+	return group, nil
 }
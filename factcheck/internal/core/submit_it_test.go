@@ -0,0 +1,212 @@
+//go:build integration_test
+// +build integration_test
+
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestSubmit_URL(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	stub := links.NewStub()
+	stub.Redirect("https://bit.ly/lemon", "https://news.example.com/lemon-soda/?utm_source=line")
+	stub.Preview("https://news.example.com/lemon-soda", factcheck.LinkPreview{
+		URL:   "https://news.example.com/lemon-soda",
+		Title: "Lemon soda cures cancer",
+	})
+	service := core.New(app.Config, app.Repository, stub, stub, language.NewDetectorScript(), ratelimit.Policy{})
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+
+	submitted := []string{
+		"https://news.example.com/lemon-soda?fbclid=abc",
+		" https://NEWS.example.com/lemon-soda/#comments ",
+		"https://bit.ly/lemon",
+	}
+	var groupID string
+	for i, text := range submitted {
+		message, group, _, err := service.Submit(ctx, user, text, "")
+		if err != nil {
+			t.Fatalf("unexpected error submitting %s: %v", text, err)
+		}
+		if message.TypeMessage != factcheck.TypeMessageURL {
+			t.Fatalf("unexpected message type %s for %s", message.TypeMessage, text)
+		}
+		if message.Text != text {
+			t.Fatalf("unexpected message text '%s', expected original '%s'", message.Text, text)
+		}
+		if group.Text != "https://news.example.com/lemon-soda" {
+			t.Fatalf("unexpected group text '%s' for %s", group.Text, text)
+		}
+		if i == 0 {
+			groupID = group.ID
+			continue
+		}
+		if group.ID != groupID {
+			t.Fatalf("unexpected group %s for %s, expected %s", group.ID, text, groupID)
+		}
+	}
+
+	group, err := app.Repository.MessageGroups.GetByID(ctx, groupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Preview == nil || group.Preview.Title != "Lemon soda cures cancer" {
+		t.Fatalf("unexpected preview: %+v", group.Preview)
+	}
+	if group.Language != factcheck.LanguageEnglish {
+		t.Fatalf("unexpected language '%s' detected from preview", group.Language)
+	}
+	if fetched := stub.Fetched(); len(fetched) != 1 {
+		t.Fatalf("expected preview to be fetched once for new group, got %v", fetched)
+	}
+
+	message, group, _, err := service.Submit(ctx, user, "see https://news.example.com/lemon-soda", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.TypeMessage != factcheck.TypeMessageText {
+		t.Fatalf("unexpected message type %s for text with URL", message.TypeMessage)
+	}
+	if group.ID == groupID {
+		t.Fatalf("unexpected text with URL in URL group")
+	}
+}
+
+func TestSubmit_Language(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	tests := map[string]factcheck.Language{
+		"น้ำมะนาวโซดารักษามะเร็งได้": factcheck.LanguageThai,
+		"Lemon soda cures cancer": factcheck.LanguageEnglish,
+		"🍋🥤🍋🥤":                    "",
+	}
+	for text, expected := range tests {
+		message, group, _, err := app.Service.Submit(ctx, user, text, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if message.Language != expected || group.Language != expected {
+			t.Fatalf("unexpected language for '%s': message '%s', group '%s'", text, message.Language, group.Language)
+		}
+		stored, err := app.Repository.MessagesV2.GetByID(ctx, message.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Language != expected {
+			t.Fatalf("unexpected stored language for '%s': '%s'", text, stored.Language)
+		}
+	}
+}
+
+func TestSubmit_Concurrent(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	stub := links.NewStub()
+	stub.Preview("https://news.example.com/lemon-soda", factcheck.LinkPreview{
+		URL:   "https://news.example.com/lemon-soda",
+		Title: "Lemon soda cures cancer",
+	})
+	service := core.New(app.Config, app.Repository, stub, stub, language.NewDetectorScript(), ratelimit.Policy{})
+
+	const n = 8
+	for _, text := range []string{"https://news.example.com/lemon-soda", "hot water kills viruses"} {
+		groupIDs := make([]string, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: fmt.Sprintf("U%d", i), ChatID: fmt.Sprintf("U%d", i)}
+				_, group, _, err := service.Submit(ctx, user, text, "")
+				groupIDs[i], errs[i] = group.ID, err
+			}()
+		}
+		wg.Wait()
+		for i := range n {
+			if errs[i] != nil {
+				t.Fatalf("unexpected error submitting %s: %v", text, errs[i])
+			}
+			if groupIDs[i] != groupIDs[0] {
+				t.Fatalf("unexpected groups of concurrent submissions of %s: %v", text, groupIDs)
+			}
+		}
+	}
+}
+
+func TestSubmitLINE_Redelivered(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	// Concurrent redeliveries of the same event are submitted once
+	const n = 4
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, errs[i] = app.Service.SubmitLINE(ctx, user, "hot water kills viruses", "01H810YECXQQZ37VAXPF6H9E6T")
+		}()
+	}
+	wg.Wait()
+	submitted := 0
+	for i := range n {
+		switch {
+		case errs[i] == nil:
+			submitted++
+		case !errors.Is(errs[i], core.ErrDuplicateEvent):
+			t.Fatalf("unexpected error: %v", errs[i])
+		}
+	}
+	if submitted != 1 {
+		t.Fatalf("unexpected %d submissions of the same event", submitted)
+	}
+
+	// Swept events are submitted again
+	utils.TimeFreeze(utils.TimeNow().Add(30 * 24 * time.Hour))
+	defer utils.TimeUnfreeze()
+	err = app.Relay.Sweep(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = app.Service.SubmitLINE(ctx, user, "hot water kills viruses", "01H810YECXQQZ37VAXPF6H9E6T")
+	if err != nil {
+		t.Fatalf("unexpected error submitting swept event: %v", err)
+	}
+}
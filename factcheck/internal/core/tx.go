@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// inTx runs fn in a new transaction, which is committed only if fn succeeds.
+// fn should pass withTx to every repository call.
+func inTx[T any](
+	ctx context.Context,
+	s ServiceFactcheck,
+	operation string,
+	fn func(withTx repo.Option) (T, error),
+) (
+	T,
+	error,
+) {
+	var zero T
+	tx, err := s.repo.BeginTx(ctx, repo.RepeatableRead)
+	if err != nil {
+		return zero, err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		err := tx.Rollback(ctx)
+		if err == nil {
+			return
+		}
+		slog.ErrorContext(ctx, "error rolling back", "operation", operation, "err", err)
+	}()
+
+	result, err := fn(repo.WithTx(tx))
+	if err != nil {
+		return zero, err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return zero, err
+	}
+	committed = true
+	return result, nil
+}
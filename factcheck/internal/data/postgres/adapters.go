@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/search"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -30,13 +32,16 @@ func TopicCreator(topic factcheck.Topic) (CreateTopicParams, error) {
 		return CreateTopicParams{}, err
 	}
 	return CreateTopicParams{
-		ID:          id,
-		Name:        topic.Name,
-		Description: topic.Description,
-		Status:      string(topic.Status),
-		Result:      result,
-		CreatedAt:   createdAt,
-		UpdatedAt:   updatedAt,
+		ID:                id,
+		Name:              topic.Name,
+		Description:       topic.Description,
+		Status:            string(topic.Status),
+		Result:            result,
+		ResultStatus:      TextNullable(topic.Verdict),
+		CreatedAt:         createdAt,
+		UpdatedAt:         updatedAt,
+		NameTokens:        search.Tokens(topic.Name),
+		DescriptionTokens: search.Tokens(topic.Description),
 	}, nil
 }
 
@@ -52,6 +57,12 @@ func ToTopic(data Topic) factcheck.Topic {
 	if data.Result.Valid {
 		topic.Result = data.Result.String
 	}
+	if data.ResultStatus.Valid {
+		topic.Verdict = factcheck.Verdict(data.ResultStatus.String)
+	}
+	if data.AnswerID.Valid {
+		topic.AnswerID = data.AnswerID.String()
+	}
 	if data.CreatedAt.Valid {
 		topic.CreatedAt = data.CreatedAt.Time
 	}
@@ -78,6 +89,12 @@ func ToTopicFromRow(data ListTopicsRow) factcheck.Topic {
 	if data.Result.Valid {
 		topic.Result = data.Result.String
 	}
+	if data.ResultStatus.Valid {
+		topic.Verdict = factcheck.Verdict(data.ResultStatus.String)
+	}
+	if data.AnswerID.Valid {
+		topic.AnswerID = data.AnswerID.String()
+	}
 	if data.CreatedAt.Valid {
 		topic.CreatedAt = data.CreatedAt.Time
 	}
@@ -100,6 +117,12 @@ func ToTopicFromStatusRow(data ListTopicsByStatusRow) factcheck.Topic {
 	if data.Result.Valid {
 		topic.Result = data.Result.String
 	}
+	if data.ResultStatus.Valid {
+		topic.Verdict = factcheck.Verdict(data.ResultStatus.String)
+	}
+	if data.AnswerID.Valid {
+		topic.AnswerID = data.AnswerID.String()
+	}
 	if data.CreatedAt.Valid {
 		topic.CreatedAt = data.CreatedAt.Time
 	}
@@ -122,6 +145,12 @@ func ToTopicFromIDRow(data ListTopicsLikeIDRow) factcheck.Topic {
 	if data.Result.Valid {
 		topic.Result = data.Result.String
 	}
+	if data.ResultStatus.Valid {
+		topic.Verdict = factcheck.Verdict(data.ResultStatus.String)
+	}
+	if data.AnswerID.Valid {
+		topic.AnswerID = data.AnswerID.String()
+	}
 	if data.CreatedAt.Valid {
 		topic.CreatedAt = data.CreatedAt.Time
 	}
@@ -157,7 +186,7 @@ func MessageV2Creator(m factcheck.MessageV2) (CreateMessageV2Params, error) {
 		TypeUser:  string(m.TypeUser),
 		Type:      string(m.TypeMessage),
 		Text:      m.Text,
-		Language:  pgtype.Text{}, // MessageV2 doesn't have Language field
+		Language:  TextNullable(m.Language),
 		Metadata:  metadata,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
@@ -183,6 +212,7 @@ func ToMessageV2(data MessagesV2) (factcheck.MessageV2, error) {
 		TypeUser:    factcheck.TypeUser(data.TypeUser),
 		TypeMessage: factcheck.TypeMessage(data.Type),
 		Text:        data.Text,
+		Language:    factcheck.Language(data.Language.String),
 		Metadata:    metadata,
 		CreatedAt:   createdAt,
 		UpdatedAt:   TimeNullable(data.UpdatedAt),
@@ -193,7 +223,6 @@ func ToMessageV2(data MessagesV2) (factcheck.MessageV2, error) {
 	if data.GroupID.Valid {
 		message.GroupID = data.GroupID.String()
 	}
-	// MessageV2 doesn't have Language field
 	return message, nil
 }
 
@@ -214,24 +243,44 @@ func MessageGroupCreator(g factcheck.MessageGroup) (CreateMessageGroupParams, er
 	if err != nil {
 		return CreateMessageGroupParams{}, err
 	}
-	language, err := Text(g.Language)
-	if err != nil {
-		return CreateMessageGroupParams{}, err
-	}
 	topicID := UUIDNullable(g.TopicID)
+	var preview []byte
+	if g.Preview != nil {
+		preview, err = json.Marshal(g.Preview)
+		if err != nil {
+			return CreateMessageGroupParams{}, err
+		}
+	}
+	status := g.Status
+	if status == "" {
+		status = factcheck.StatusMGroupPending
+	}
 
 	return CreateMessageGroupParams{
-		ID:        id,
-		TopicID:   topicID,
-		Name:      g.Name,
-		Text:      g.Text,
-		TextSha1:  g.TextSHA1,
-		Language:  language,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		ID:          id,
+		TopicID:     topicID,
+		Name:        g.Name,
+		Text:        g.Text,
+		TextSha1:    g.TextSHA1,
+		TextSimhash: Int8Nullable(g.TextSimHash),
+		Language:    TextNullable(g.Language),
+		Status:      string(status),
+		Preview:     preview,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		TextTokens:  MessageGroupTokens(g),
 	}, nil
 }
 
+// MessageGroupTokens returns search tokens of g.
+// Text of URL groups is just the URL, so we also search the linked page.
+func MessageGroupTokens(g factcheck.MessageGroup) string {
+	if g.Preview != nil {
+		return search.Tokens(g.Text, g.Preview.Title, g.Preview.Description)
+	}
+	return search.Tokens(g.Text)
+}
+
 func ToMessageGroup(data MessageGroup) (factcheck.MessageGroup, error) {
 	id, err := FromUUID(data.ID)
 	if err != nil {
@@ -248,14 +297,27 @@ func ToMessageGroup(data MessageGroup) (factcheck.MessageGroup, error) {
 	if err != nil {
 		return factcheck.MessageGroup{}, err
 	}
+	var preview *factcheck.LinkPreview
+	if len(data.Preview) > 0 {
+		preview = new(factcheck.LinkPreview)
+		err = json.Unmarshal(data.Preview, preview)
+		if err != nil {
+			return factcheck.MessageGroup{}, err
+		}
+	}
 	group := factcheck.MessageGroup{
-		ID:        id,
-		Name:      data.Name,
-		Text:      data.Text,
-		TextSHA1:  data.TextSha1,
-		TopicID:   topicID,
-		CreatedAt: createdAt,
-		UpdatedAt: TimeNullable(data.UpdatedAt),
+		ID:           id,
+		Status:       factcheck.StatusMGroup(data.Status),
+		StatusReason: data.StatusReason.String,
+		Name:         data.Name,
+		Text:         data.Text,
+		TextSHA1:     data.TextSha1,
+		Language:     factcheck.Language(data.Language.String),
+		TextSimHash:  uint64(data.TextSimhash.Int64), //nolint:gosec
+		Preview:      preview,
+		TopicID:      topicID,
+		CreatedAt:    createdAt,
+		UpdatedAt:    TimeNullable(data.UpdatedAt),
 	}
 	return group, nil
 }
@@ -264,11 +326,22 @@ func ToMessageGroups(data []MessageGroup) ([]factcheck.MessageGroup, error) {
 	return utils.Map(data, ToMessageGroup)
 }
 
+// Int8Nullable stores uint64 bits as bigint, with 0 as NULL
+func Int8Nullable(u uint64) pgtype.Int8 {
+	if u == 0 {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: int64(u), Valid: true} //nolint:gosec
+}
+
+// ErrInvalidUUID is returned by UUID when id is not a valid UUID string
+var ErrInvalidUUID = errors.New("invalid uuid")
+
 func UUID(id string) (pgtype.UUID, error) {
 	var uuid pgtype.UUID
 	err := uuid.Scan(id)
 	if err != nil {
-		return pgtype.UUID{}, err
+		return pgtype.UUID{}, fmt.Errorf("%w '%s': %w", ErrInvalidUUID, id, err)
 	}
 	return uuid, nil
 }
@@ -358,10 +431,15 @@ func AnswerCreator(a factcheck.Answer) (CreateAnswerParams, error) {
 		return CreateAnswerParams{}, err
 	}
 	return CreateAnswerParams{
-		ID:        id,
-		TopicID:   topicID,
-		Text:      a.Text,
-		CreatedAt: createdAt,
+		ID:         id,
+		TopicID:    topicID,
+		Text:       a.Text,
+		Verdict:    TextNullable(a.Verdict),
+		CreatedAt:  createdAt,
+		TextTokens: search.Tokens(a.Text),
+		Status:     string(utils.DefaultIfZero(a.Status, factcheck.StatusAnswerDraft)),
+		UserID:     a.UserID,
+		CorrectsID: UUIDNullable(a.CorrectsID),
 	}, nil
 }
 
@@ -378,14 +456,318 @@ func ToAnswer(data Answer) (factcheck.Answer, error) {
 	if err != nil {
 		return factcheck.Answer{}, err
 	}
-	return factcheck.Answer{
+	answer := factcheck.Answer{
 		ID:        id,
+		UserID:    data.UserID,
 		TopicID:   topicID,
+		Revision:  int(data.Revision),
+		Status:    factcheck.StatusAnswer(data.Status),
 		Text:      data.Text,
+		Verdict:   factcheck.Verdict(data.Verdict.String),
 		CreatedAt: createdAt,
-	}, nil
+		UpdatedAt: TimeNullable(data.UpdatedAt),
+	}
+	if data.CorrectsID.Valid {
+		answer.CorrectsID = data.CorrectsID.String()
+	}
+	return answer, nil
 }
 
 func ToAnswers(data []Answer) ([]factcheck.Answer, error) {
 	return utils.Map(data, ToAnswer)
 }
+
+func SourceCreator(s factcheck.Source) (CreateAnswerSourceParams, error) {
+	id, err := UUID(s.ID)
+	if err != nil {
+		return CreateAnswerSourceParams{}, err
+	}
+	answerID, err := UUID(s.AnswerID)
+	if err != nil {
+		return CreateAnswerSourceParams{}, err
+	}
+	createdAt, err := Timestamptz(s.CreatedAt)
+	if err != nil {
+		return CreateAnswerSourceParams{}, err
+	}
+	return CreateAnswerSourceParams{
+		ID:            id,
+		AnswerID:      answerID,
+		Type:          string(s.Type),
+		Position:      int32(s.Position), //nolint:gosec
+		Url:           s.URL,
+		Publisher:     s.Publisher,
+		ArchiveUrl:    s.ArchiveURL,
+		Quote:         s.Quote,
+		AttachmentUrl: s.AttachmentURL,
+		CreatedAt:     createdAt,
+	}, nil
+}
+
+func SourceUpdater(s factcheck.Source) (UpdateAnswerSourceParams, error) {
+	id, err := UUID(s.ID)
+	if err != nil {
+		return UpdateAnswerSourceParams{}, err
+	}
+	return UpdateAnswerSourceParams{
+		ID:            id,
+		Type:          string(s.Type),
+		Position:      int32(s.Position), //nolint:gosec
+		Url:           s.URL,
+		Publisher:     s.Publisher,
+		ArchiveUrl:    s.ArchiveURL,
+		Quote:         s.Quote,
+		AttachmentUrl: s.AttachmentURL,
+	}, nil
+}
+
+func ToSource(data AnswerSource) (factcheck.Source, error) {
+	id, err := FromUUID(data.ID)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	answerID, err := FromUUID(data.AnswerID)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	return factcheck.Source{
+		ID:            id,
+		AnswerID:      answerID,
+		Type:          factcheck.TypeSource(data.Type),
+		Position:      int(data.Position),
+		URL:           data.Url,
+		Publisher:     data.Publisher,
+		ArchiveURL:    data.ArchiveUrl,
+		Quote:         data.Quote,
+		AttachmentURL: data.AttachmentUrl,
+		CreatedAt:     createdAt,
+		UpdatedAt:     TimeNullable(data.UpdatedAt),
+	}, nil
+}
+
+func ToSources(data []AnswerSource) ([]factcheck.Source, error) {
+	return utils.Map(data, ToSource)
+}
+
+func ToSearchResult(data ListSearchResultsRow) (factcheck.SearchResult, error) {
+	id, err := FromUUID(data.ID)
+	if err != nil {
+		return factcheck.SearchResult{}, err
+	}
+	var topicID string
+	if data.TopicID.Valid {
+		topicID = data.TopicID.String()
+	}
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.SearchResult{}, err
+	}
+	return factcheck.SearchResult{
+		Type:      factcheck.TypeSearch(data.Type),
+		ID:        id,
+		TopicID:   topicID,
+		Title:     data.Title,
+		Text:      data.Text,
+		Rank:      data.Rank,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func DeliveryCreator(d factcheck.Delivery) (CreateDeliveryParams, error) {
+	id, err := UUID(d.ID)
+	if err != nil {
+		return CreateDeliveryParams{}, err
+	}
+	topicID, err := UUID(d.TopicID)
+	if err != nil {
+		return CreateDeliveryParams{}, err
+	}
+	answerID, err := UUID(d.AnswerID)
+	if err != nil {
+		return CreateDeliveryParams{}, err
+	}
+	createdAt, err := Timestamptz(d.CreatedAt)
+	if err != nil {
+		return CreateDeliveryParams{}, err
+	}
+	return CreateDeliveryParams{
+		ID:        id,
+		TopicID:   topicID,
+		AnswerID:  answerID,
+		UserID:    d.UserID,
+		TypeUser:  string(d.TypeUser),
+		ChatID:    d.ChatID,
+		Sender:    d.Sender,
+		Status:    string(d.Status),
+		Text:      d.Text,
+		Error:     TextNullable(d.Error),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func ToDelivery(data Delivery) (factcheck.Delivery, error) {
+	id, err := FromUUID(data.ID)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	topicID, err := FromUUID(data.TopicID)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	answerID, err := FromUUID(data.AnswerID)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	return factcheck.Delivery{
+		ID:        id,
+		TopicID:   topicID,
+		AnswerID:  answerID,
+		UserID:    data.UserID,
+		TypeUser:  factcheck.TypeUser(data.TypeUser),
+		ChatID:    data.ChatID,
+		Sender:    data.Sender,
+		Status:    factcheck.StatusDelivery(data.Status),
+		Text:      data.Text,
+		Error:     data.Error.String,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func ToDeliveries(data []Delivery) ([]factcheck.Delivery, error) {
+	return utils.Map(data, ToDelivery)
+}
+
+func EventCreator(e factcheck.Event) (CreateOutboxEventParams, error) {
+	id, err := UUID(e.ID)
+	if err != nil {
+		return CreateOutboxEventParams{}, err
+	}
+	createdAt, err := Timestamptz(e.CreatedAt)
+	if err != nil {
+		return CreateOutboxEventParams{}, err
+	}
+	return CreateOutboxEventParams{
+		ID:        id,
+		Type:      string(e.Type),
+		SubjectID: e.SubjectID,
+		Payload:   e.Payload,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func ToEvent(data Outbox) (factcheck.Event, error) {
+	id, err := FromUUID(data.ID)
+	if err != nil {
+		return factcheck.Event{}, err
+	}
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.Event{}, err
+	}
+	return factcheck.Event{
+		ID:          id,
+		Seq:         data.Seq,
+		Type:        factcheck.TypeEvent(data.Type),
+		SubjectID:   data.SubjectID,
+		Payload:     json.RawMessage(data.Payload),
+		CreatedAt:   createdAt,
+		PublishedAt: TimeNullable(data.PublishedAt),
+	}, nil
+}
+
+func ToEvents(data []Outbox) ([]factcheck.Event, error) {
+	return utils.Map(data, ToEvent)
+}
+
+func UserRoleUpserter(r factcheck.UserRole) (UpsertUserRoleParams, error) {
+	createdAt, err := Timestamptz(r.CreatedAt)
+	if err != nil {
+		return UpsertUserRoleParams{}, err
+	}
+	return UpsertUserRoleParams{
+		UserID:    r.UserID,
+		Role:      string(r.Role),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func ToUserRole(data UserRole) (factcheck.UserRole, error) {
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.UserRole{}, err
+	}
+	return factcheck.UserRole{
+		UserID:    data.UserID,
+		Role:      factcheck.Role(data.Role),
+		CreatedAt: createdAt,
+		UpdatedAt: TimeNullable(data.UpdatedAt),
+	}, nil
+}
+
+func ToUserRoles(data []UserRole) ([]factcheck.UserRole, error) {
+	return utils.Map(data, ToUserRole)
+}
+
+func AuditEventCreator(e factcheck.AuditEvent) (CreateAuditEventParams, error) {
+	id, err := UUID(e.ID)
+	if err != nil {
+		return CreateAuditEventParams{}, err
+	}
+	createdAt, err := Timestamptz(e.CreatedAt)
+	if err != nil {
+		return CreateAuditEventParams{}, err
+	}
+	targetIDs := e.TargetIDs
+	if targetIDs == nil {
+		targetIDs = []string{}
+	}
+	return CreateAuditEventParams{
+		ID:         id,
+		ActorType:  string(e.Actor.UserType),
+		ActorID:    e.Actor.UserID,
+		Action:     string(e.Action),
+		TargetType: string(e.TargetType),
+		TargetIds:  targetIDs,
+		DataBefore: e.Before,
+		DataAfter:  e.After,
+		RequestID:  TextNullable(e.RequestID),
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+func ToAuditEvent(data AuditEvent) (factcheck.AuditEvent, error) {
+	id, err := FromUUID(data.ID)
+	if err != nil {
+		return factcheck.AuditEvent{}, err
+	}
+	createdAt, err := Time(data.CreatedAt)
+	if err != nil {
+		return factcheck.AuditEvent{}, err
+	}
+	return factcheck.AuditEvent{
+		ID: id,
+		Actor: factcheck.UserInfo{
+			UserType: factcheck.TypeUser(data.ActorType),
+			UserID:   data.ActorID,
+		},
+		Action:     factcheck.ActionAudit(data.Action),
+		TargetType: factcheck.TypeTarget(data.TargetType),
+		TargetIDs:  data.TargetIds,
+		Before:     json.RawMessage(data.DataBefore),
+		After:      json.RawMessage(data.DataAfter),
+		RequestID:  data.RequestID.String,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+func ToAuditEvents(data []AuditEvent) ([]factcheck.AuditEvent, error) {
+	return utils.Map(data, ToAuditEvent)
+}
@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+const (
+	outcomeTxCommit               = "commit"
+	outcomeTxRollback             = "rollback"
+	outcomeTxSerializationFailure = "serialization_failure"
+)
+
+var (
+	metricQueryDuration = promauto.With(metrics.Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "factcheck_db_query_duration_seconds",
+		Help:    "Duration of queries by sqlc query name",
+		Buckets: metrics.BucketsLatency,
+	}, []string{"query"})
+	metricQueryErrors = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_db_query_errors_total",
+		Help: "Failed queries by sqlc query name, not counting queries without rows",
+	}, []string{"query"})
+	metricTx = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_db_transactions_total",
+		Help: "Finished transactions by outcome: commit, rollback or serialization_failure",
+	}, []string{"outcome"})
+)
+
+// NewQueries returns queries on pool, with every query timed for metrics and traced as a span.
+// Queries in transactions from TxnManager are timed and traced too.
+func NewQueries(pool *pgxpool.Pool) *Queries {
+	return New(dbMetrics{db: pool})
+}
+
+// poolStats serves stats of the latest pool from NewConn as metrics
+var poolStats = newPoolCollector()
+
+func init() {
+	metrics.Registry.MustRegister(poolStats)
+}
+
+// registerPoolStats serves stats of pool as metrics, replacing stats of previous pools
+func registerPoolStats(pool *pgxpool.Pool) {
+	poolStats.pool.Store(pool)
+}
+
+// poolStat is a metric of pgxpool.Stat
+type poolStat struct {
+	desc  *prometheus.Desc
+	typ   prometheus.ValueType
+	value func(s *pgxpool.Stat) float64
+}
+
+// poolCollector collects stats of its pool at scrape time, and nothing before it has a pool
+type poolCollector struct {
+	pool  atomic.Pointer[pgxpool.Pool]
+	stats []poolStat
+}
+
+func newPoolCollector() *poolCollector {
+	stat := func(name string, help string, typ prometheus.ValueType, value func(s *pgxpool.Stat) float64) poolStat {
+		return poolStat{desc: prometheus.NewDesc(name, help, nil, nil), typ: typ, value: value}
+	}
+	gauge, counter := prometheus.GaugeValue, prometheus.CounterValue
+	return &poolCollector{stats: []poolStat{
+		stat("factcheck_db_pool_conns_total", "Connections in the pool", gauge, func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+		stat("factcheck_db_pool_conns_acquired", "Connections currently in use", gauge, func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+		stat("factcheck_db_pool_conns_idle", "Idle connections in the pool", gauge, func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+		stat("factcheck_db_pool_conns_max", "Maximum size of the pool", gauge, func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+		stat("factcheck_db_pool_acquires_total", "Connections acquired from the pool", counter, func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+		stat("factcheck_db_pool_acquires_empty_total", "Acquires that waited for a connection because the pool was empty", counter, func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+		stat("factcheck_db_pool_acquire_seconds_total", "Total time spent acquiring connections", counter, func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+	}}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for i := range c.stats {
+		ch <- c.stats[i].desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	pool := c.pool.Load()
+	if pool == nil {
+		return
+	}
+	s := pool.Stat()
+	for i := range c.stats {
+		ch <- prometheus.MustNewConstMetric(c.stats[i].desc, c.stats[i].typ, c.stats[i].value(s))
+	}
+}
+
+// queryName returns name of sqlc query from its leading comment, e.g. "-- name: GetTopic :one"
+func queryName(sql string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(sql, prefix) {
+		return "unknown"
+	}
+	name, _, ok := strings.Cut(sql[len(prefix):], " ")
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+// startQuery starts span of query, named after sqlc query name
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "postgres."+query, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", query),
+	))
+}
+
+// observeQuery records duration and error of query, ends its span,
+// and reports whether err is from transaction conflict
+func observeQuery(span trace.Span, query string, start time.Time, err error) bool {
+	defer span.End()
+	metricQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+	if err == nil || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	tracing.RecordError(span, err)
+	metricQueryErrors.WithLabelValues(query).Inc()
+	return isTxConflict(err)
+}
+
+// isTxConflict is repo.IsTxConflict, which is not importable here
+func isTxConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// dbMetrics times and traces queries on db
+type dbMetrics struct {
+	db DBTX
+}
+
+func (d dbMetrics) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, _, err := exec(ctx, d.db, sql, args...)
+	return tag, err
+}
+
+func (d dbMetrics) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, d.db, sql, nil, args...)
+}
+
+func (d dbMetrics) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, d.db, sql, nil, args...)
+}
+
+// txMetrics times and traces queries in tx, and counts the transaction by its outcome.
+// The transaction itself is traced as a span from begin to commit or rollback.
+type txMetrics struct {
+	pgx.Tx
+	conflict *bool // Whether any query failed from transaction conflict
+	span     trace.Span
+}
+
+func newTxMetrics(tx pgx.Tx, span trace.Span) txMetrics {
+	return txMetrics{Tx: tx, conflict: new(bool), span: span}
+}
+
+// startTx starts span of transaction, to be passed to newTxMetrics
+func startTx(ctx context.Context) trace.Span {
+	_, span := tracing.Start(ctx, "postgres.Transaction", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "postgresql"),
+	))
+	return span
+}
+
+// endTx ends span of tx with its outcome
+func (t txMetrics) endTx(outcome string, err error) {
+	t.span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+	tracing.RecordError(t.span, err)
+	t.span.End()
+}
+
+func (t txMetrics) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, conflict, err := exec(ctx, t.Tx, sql, args...)
+	*t.conflict = *t.conflict || conflict
+	return tag, err
+}
+
+func (t txMetrics) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, t.Tx, sql, t.conflict, args...)
+}
+
+func (t txMetrics) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, t.Tx, sql, t.conflict, args...)
+}
+
+func (t txMetrics) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	outcome := outcomeTxRollback
+	switch {
+	case err == nil:
+		outcome = outcomeTxCommit
+	case *t.conflict || isTxConflict(err):
+		outcome = outcomeTxSerializationFailure
+	}
+	metricTx.WithLabelValues(outcome).Inc()
+	t.endTx(outcome, err)
+	return err
+}
+
+// Rollback counts only transactions actually rolled back,
+// and not deferred rollbacks after commits.
+func (t txMetrics) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+	if err != nil {
+		return err
+	}
+	outcome := outcomeTxRollback
+	if *t.conflict {
+		outcome = outcomeTxSerializationFailure
+	}
+	metricTx.WithLabelValues(outcome).Inc()
+	t.endTx(outcome, nil)
+	return nil
+}
+
+func exec(ctx context.Context, db DBTX, sql string, args ...any) (pgconn.CommandTag, bool, error) {
+	name := queryName(sql)
+	ctx, span := startQuery(ctx, name)
+	start := time.Now()
+	tag, err := db.Exec(ctx, sql, args...)
+	conflict := observeQuery(span, name, start, err)
+	return tag, conflict, err
+}
+
+func query(ctx context.Context, db DBTX, sql string, conflict *bool, args ...any) (pgx.Rows, error) {
+	name := queryName(sql)
+	ctx, span := startQuery(ctx, name)
+	start := time.Now()
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		observed := observeQuery(span, name, start, err)
+		if conflict != nil {
+			*conflict = *conflict || observed
+		}
+		return nil, err
+	}
+	return &rowsMetrics{Rows: rows, name: name, span: span, start: start, conflict: conflict}, nil
+}
+
+func queryRow(ctx context.Context, db DBTX, sql string, conflict *bool, args ...any) pgx.Row {
+	name := queryName(sql)
+	ctx, span := startQuery(ctx, name)
+	start := time.Now()
+	row := db.QueryRow(ctx, sql, args...)
+	return rowMetrics{row: row, name: name, span: span, start: start, conflict: conflict}
+}
+
+// rowsMetrics observes the query when rows are closed, after all rows were read
+type rowsMetrics struct {
+	pgx.Rows
+	name     string
+	span     trace.Span
+	start    time.Time
+	conflict *bool
+	closed   bool
+}
+
+func (r *rowsMetrics) Close() {
+	r.Rows.Close()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	observed := observeQuery(r.span, r.name, r.start, r.Rows.Err())
+	if r.conflict != nil {
+		*r.conflict = *r.conflict || observed
+	}
+}
+
+// rowMetrics observes the query when the row is scanned
+type rowMetrics struct {
+	row      pgx.Row
+	name     string
+	span     trace.Span
+	start    time.Time
+	conflict *bool
+}
+
+func (r rowMetrics) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	observed := observeQuery(r.span, r.name, r.start, err)
+	if r.conflict != nil {
+		*r.conflict = *r.conflict || observed
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+package postgres
+
+import "embed"
+
+// Migrations holds numbered schema migrations, applied in order by package migrate.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql,
+// and sqlc generates code from the up migrations.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,4 @@
+DROP TABLE answers;
+DROP TABLE messages_v2;
+DROP TABLE message_groups;
+DROP TABLE topics;
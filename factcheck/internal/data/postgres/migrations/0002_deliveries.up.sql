@@ -0,0 +1,17 @@
+-- Deliveries table (log of answers sent back to original submitters)
+CREATE TABLE deliveries (
+    id         UUID NOT NULL PRIMARY KEY,
+    topic_id   UUID NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    answer_id  UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    user_id    text NOT NULL,
+    type_user  text NOT NULL,
+    chat_id    text NOT NULL,
+    sender     text NOT NULL,
+    status     text NOT NULL,
+    text       text NOT NULL,
+    error      text,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX idx_deliveries_topic_id ON deliveries(topic_id);
+CREATE INDEX idx_deliveries_answer_id ON deliveries(answer_id);
@@ -0,0 +1,14 @@
+-- Outbox table (domain events written in the same transaction as the change)
+CREATE TABLE outbox (
+    seq          BIGSERIAL PRIMARY KEY,
+    id           UUID NOT NULL UNIQUE,
+    type         text NOT NULL,
+    subject_id   text NOT NULL,
+    payload      jsonb NOT NULL,
+    attempts     integer NOT NULL DEFAULT 0,
+    last_error   text,
+    created_at   timestamptz NOT NULL,
+    published_at timestamptz
+);
+
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
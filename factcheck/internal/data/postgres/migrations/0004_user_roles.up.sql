@@ -0,0 +1,7 @@
+-- User roles table (RBAC roles of admin users, keyed by authenticated user ID)
+CREATE TABLE user_roles (
+    user_id    text NOT NULL PRIMARY KEY,
+    role       text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz
+);
@@ -0,0 +1,27 @@
+-- Audit events table (append-only log of admin actions, with snapshots before and after the change)
+CREATE TABLE audit_events (
+    id          UUID NOT NULL PRIMARY KEY,
+    actor_type  text NOT NULL,
+    actor_id    text NOT NULL,
+    action      text NOT NULL,
+    target_type text NOT NULL,
+    target_ids  text[] NOT NULL,
+    data_before jsonb,
+    data_after  jsonb,
+    request_id  text,
+    created_at  timestamptz NOT NULL
+);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX idx_audit_events_target_ids ON audit_events USING GIN (target_ids);
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
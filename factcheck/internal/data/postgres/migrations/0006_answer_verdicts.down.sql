@@ -0,0 +1,5 @@
+DROP INDEX idx_topics_result_status;
+
+COMMENT ON COLUMN topics.result_status IS NULL;
+
+ALTER TABLE answers DROP COLUMN verdict;
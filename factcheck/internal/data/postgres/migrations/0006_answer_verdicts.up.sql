@@ -0,0 +1,7 @@
+-- Verdicts of answers, and of topics from their latest answer.
+-- Existing topics keep their result_status, which is now a verdict.
+ALTER TABLE answers ADD COLUMN verdict text;
+
+COMMENT ON COLUMN topics.result_status IS 'Verdict of the latest answer';
+
+CREATE INDEX idx_topics_result_status ON topics(result_status);
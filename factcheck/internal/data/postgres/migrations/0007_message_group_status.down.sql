@@ -0,0 +1,5 @@
+DROP INDEX idx_message_groups_status;
+
+ALTER TABLE message_groups
+    DROP COLUMN status_reason,
+    DROP COLUMN status;
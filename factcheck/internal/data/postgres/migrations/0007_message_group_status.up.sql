@@ -0,0 +1,7 @@
+-- Moderation status of message groups.
+-- Existing groups were never moderated, so they are pending.
+ALTER TABLE message_groups
+    ADD COLUMN status        text NOT NULL DEFAULT 'MGROUP_PENDING', -- StatusMGroup
+    ADD COLUMN status_reason text;                                   -- Moderator's reason for last approval or rejection
+
+CREATE INDEX idx_message_groups_status ON message_groups(status);
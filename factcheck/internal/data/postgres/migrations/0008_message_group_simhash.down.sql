@@ -0,0 +1 @@
+ALTER TABLE message_groups DROP COLUMN text_simhash;
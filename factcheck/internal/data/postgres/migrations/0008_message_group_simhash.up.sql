@@ -0,0 +1,4 @@
+-- SimHash of normalized text, for near-duplicate matches.
+-- text_sha1 is now SHA1 of normalized text, for exact matches.
+-- Groups created before this migration are rekeyed with `factcheck groups backfill`.
+ALTER TABLE message_groups ADD COLUMN text_simhash bigint;
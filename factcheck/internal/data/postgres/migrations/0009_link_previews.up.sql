@@ -0,0 +1,3 @@
+-- LinkPreview, for groups of URL messages.
+-- text_sha1 of URL groups is SHA1 of canonical URL.
+ALTER TABLE message_groups ADD COLUMN preview jsonb;
@@ -0,0 +1 @@
+DROP INDEX idx_message_groups_language;
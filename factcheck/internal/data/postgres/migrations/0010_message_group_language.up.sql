@@ -0,0 +1 @@
+CREATE INDEX idx_message_groups_language ON message_groups(language);
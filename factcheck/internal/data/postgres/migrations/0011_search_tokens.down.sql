@@ -0,0 +1,9 @@
+DROP INDEX idx_answers_search;
+DROP INDEX idx_message_groups_search;
+DROP INDEX idx_topics_search;
+
+ALTER TABLE answers DROP COLUMN text_tokens;
+ALTER TABLE message_groups DROP COLUMN text_tokens;
+ALTER TABLE topics
+    DROP COLUMN description_tokens,
+    DROP COLUMN name_tokens;
@@ -0,0 +1,19 @@
+-- Words segmented by package search, for full-text search.
+-- Rows written before this migration have no tokens, and are not found until re-tokenized
+-- with `factcheck search backfill`.
+ALTER TABLE topics
+    ADD COLUMN name_tokens        text NOT NULL DEFAULT '', -- Words of name
+    ADD COLUMN description_tokens text NOT NULL DEFAULT ''; -- Words of description
+
+ALTER TABLE message_groups ADD COLUMN text_tokens text NOT NULL DEFAULT ''; -- Words of text and preview
+
+ALTER TABLE answers ADD COLUMN text_tokens text NOT NULL DEFAULT ''; -- Words of text
+
+-- Full-text search indexes over pre-segmented tokens.
+-- Queries must use the exact same expressions for the indexes to be used.
+CREATE INDEX idx_topics_search ON topics USING GIN ((
+    setweight(array_to_tsvector(string_to_array(name_tokens, ' ')), 'A') ||
+    setweight(array_to_tsvector(string_to_array(description_tokens, ' ')), 'B')
+));
+CREATE INDEX idx_message_groups_search ON message_groups USING GIN ((array_to_tsvector(string_to_array(text_tokens, ' '))));
+CREATE INDEX idx_answers_search ON answers USING GIN ((array_to_tsvector(string_to_array(text_tokens, ' '))));
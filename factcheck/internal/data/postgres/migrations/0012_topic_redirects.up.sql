@@ -0,0 +1,9 @@
+-- Topic redirects table (topics merged into other topics, so that old IDs still resolve)
+-- Redirects always point to existing topics: chains are collapsed when the target is merged again.
+CREATE TABLE topic_redirects (
+    from_id    UUID NOT NULL PRIMARY KEY,
+    to_id      UUID NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX idx_topic_redirects_to_id ON topic_redirects(to_id);
@@ -0,0 +1,13 @@
+ALTER TABLE topics DROP COLUMN answer_id;
+
+DROP INDEX idx_answers_status;
+
+-- Drafts were never answers before revisions
+DELETE FROM answers WHERE status = 'ANSWER_DRAFT';
+
+ALTER TABLE answers
+    DROP CONSTRAINT answers_topic_id_revision_key,
+    DROP COLUMN corrects_id,
+    DROP COLUMN user_id,
+    DROP COLUMN status,
+    DROP COLUMN revision;
@@ -0,0 +1,32 @@
+-- Answers are drafted, then published as numbered revisions of their topic.
+-- Existing answers were all published when created.
+ALTER TABLE answers
+    ADD COLUMN revision    integer,
+    ADD COLUMN status      text NOT NULL DEFAULT 'ANSWER_PUBLISHED', -- StatusAnswer
+    ADD COLUMN user_id     text NOT NULL DEFAULT '',                 -- Author of the answer
+    ADD COLUMN corrects_id UUID REFERENCES answers(id) ON DELETE SET NULL; -- Published answer this answer corrected
+
+UPDATE answers SET revision = numbered.revision
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY topic_id ORDER BY created_at, id) AS revision
+    FROM answers
+) AS numbered
+WHERE answers.id = numbered.id;
+
+ALTER TABLE answers
+    ALTER COLUMN revision SET NOT NULL,
+    ALTER COLUMN status DROP DEFAULT,
+    ADD CONSTRAINT answers_topic_id_revision_key UNIQUE (topic_id, revision);
+
+-- Published answer of the topic, kept when the topic is re-opened for corrections
+ALTER TABLE topics ADD COLUMN answer_id UUID REFERENCES answers(id) ON DELETE SET NULL;
+
+UPDATE topics SET answer_id = latest.id
+FROM (
+    SELECT DISTINCT ON (topic_id) topic_id, id
+    FROM answers
+    ORDER BY topic_id, revision DESC
+) AS latest
+WHERE topics.id = latest.topic_id AND topics.status = 'TOPIC_RESOLVED';
+
+CREATE INDEX idx_answers_status ON answers(status);
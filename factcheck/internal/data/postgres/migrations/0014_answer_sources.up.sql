@@ -0,0 +1,16 @@
+-- Answer sources table (evidence cited by answers, ordered by position per answer)
+CREATE TABLE answer_sources (
+    id             UUID NOT NULL PRIMARY KEY,
+    answer_id      UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    type           text NOT NULL, -- TypeSource
+    position       integer NOT NULL,
+    url            text NOT NULL DEFAULT '',
+    publisher      text NOT NULL DEFAULT '',
+    archive_url    text NOT NULL DEFAULT '', -- Archived copy of url
+    quote          text NOT NULL DEFAULT '',
+    attachment_url text NOT NULL DEFAULT '',
+    created_at     timestamptz NOT NULL,
+    updated_at     timestamptz
+);
+
+CREATE INDEX idx_answer_sources_answer_id_position ON answer_sources(answer_id, position);
@@ -0,0 +1,7 @@
+-- Rate limits table (token buckets of rate limiters shared by multiple API instances)
+-- Buckets are keyed by what is limited, e.g. user:USER_CHAT:U123 or chat:USER_GROUPCHAT:C123.
+CREATE TABLE rate_limits (
+    key        text NOT NULL PRIMARY KEY,
+    tokens     double precision NOT NULL,
+    updated_at timestamptz NOT NULL
+);
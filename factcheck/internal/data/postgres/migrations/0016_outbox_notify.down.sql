@@ -0,0 +1,2 @@
+DROP TRIGGER trg_outbox_notify ON outbox;
+DROP FUNCTION outbox_notify;
@@ -0,0 +1,12 @@
+-- Notify listeners of new outbox events, e.g. API instances streaming events to admins.
+-- Notifications are sent on commit, and their payload is seq of the new event.
+CREATE FUNCTION outbox_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', NEW.seq::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_outbox_notify
+    AFTER INSERT ON outbox
+    FOR EACH ROW EXECUTE FUNCTION outbox_notify();
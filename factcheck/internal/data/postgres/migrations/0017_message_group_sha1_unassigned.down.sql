@@ -0,0 +1 @@
+DROP INDEX idx_message_groups_text_sha1_unassigned;
@@ -0,0 +1,20 @@
+-- Unassigned groups are unique by text_sha1, so that concurrent submissions of the same new text
+-- cannot both create groups: all but the first to commit fail, and retry joining the group of the first.
+-- Duplicates created before this migration are merged into the oldest of them.
+UPDATE messages_v2 m SET group_id = d.keep_id
+FROM (
+    SELECT id, first_value(id) OVER (PARTITION BY text_sha1 ORDER BY created_at, id) AS keep_id
+    FROM message_groups
+    WHERE topic_id IS NULL
+) d
+WHERE m.group_id = d.id AND d.id <> d.keep_id;
+
+DELETE FROM message_groups g
+USING (
+    SELECT id, first_value(id) OVER (PARTITION BY text_sha1 ORDER BY created_at, id) AS keep_id
+    FROM message_groups
+    WHERE topic_id IS NULL
+) d
+WHERE g.id = d.id AND d.id <> d.keep_id;
+
+CREATE UNIQUE INDEX idx_message_groups_text_sha1_unassigned ON message_groups(text_sha1) WHERE topic_id IS NULL;
@@ -0,0 +1,2 @@
+-- Messages of rejected groups stay unassigned: their former topics are not recorded.
+SELECT 1;
@@ -0,0 +1,4 @@
+-- Messages of rejected groups are unassigned from their topics, like their groups.
+UPDATE messages_v2 m SET topic_id = NULL, updated_at = NOW()
+FROM message_groups g
+WHERE m.group_id = g.id AND g.status = 'MGROUP_REJECTED' AND m.topic_id IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP TABLE outbox_sinks;
+
+DROP INDEX idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN parked_at,
+    DROP COLUMN next_attempt_at,
+    DROP COLUMN claimed_until;
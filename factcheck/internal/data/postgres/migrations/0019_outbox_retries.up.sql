@@ -0,0 +1,21 @@
+-- Relays claim events by leasing them until claimed_until, and publish them outside of transactions.
+-- Failed events are retried after next_attempt_at with exponential backoff,
+-- and parked at parked_at after too many attempts, so that they no longer block later events.
+ALTER TABLE outbox
+    ADD COLUMN claimed_until   timestamptz,
+    ADD COLUMN next_attempt_at timestamptz,
+    ADD COLUMN parked_at       timestamptz; -- Parked events are not relayed, until parked_at is cleared
+
+DROP INDEX idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL AND parked_at IS NULL;
+
+-- Outbox sinks table (publishing attempts of events to each sink),
+-- so that retries skip sinks which already published the event
+CREATE TABLE outbox_sinks (
+    event_id     UUID NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    sink         text NOT NULL,
+    attempts     integer NOT NULL,
+    last_error   text,
+    published_at timestamptz,
+    PRIMARY KEY (event_id, sink)
+);
@@ -0,0 +1 @@
+DROP TABLE line_webhook_events;
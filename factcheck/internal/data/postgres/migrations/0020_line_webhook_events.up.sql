@@ -0,0 +1,9 @@
+-- LINE webhook events table (IDs of webhook events submitted),
+-- so that events redelivered by LINE are not submitted again.
+-- Rows are swept once they are too old to be redelivered.
+CREATE TABLE line_webhook_events (
+    webhook_event_id text PRIMARY KEY,
+    received_at      timestamptz NOT NULL
+);
+
+CREATE INDEX idx_line_webhook_events_received_at ON line_webhook_events(received_at);
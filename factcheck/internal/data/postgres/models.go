@@ -9,22 +9,79 @@ import (
 )
 
 type Answer struct {
+	ID         pgtype.UUID        `json:"id"`
+	TopicID    pgtype.UUID        `json:"topic_id"`
+	Text       string             `json:"text"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	Verdict    pgtype.Text        `json:"verdict"`
+	TextTokens string             `json:"text_tokens"`
+	Revision   int32              `json:"revision"`
+	Status     string             `json:"status"`
+	UserID     string             `json:"user_id"`
+	CorrectsID pgtype.UUID        `json:"corrects_id"`
+}
+
+type AnswerSource struct {
+	ID            pgtype.UUID        `json:"id"`
+	AnswerID      pgtype.UUID        `json:"answer_id"`
+	Type          string             `json:"type"`
+	Position      int32              `json:"position"`
+	Url           string             `json:"url"`
+	Publisher     string             `json:"publisher"`
+	ArchiveUrl    string             `json:"archive_url"`
+	Quote         string             `json:"quote"`
+	AttachmentUrl string             `json:"attachment_url"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AuditEvent struct {
+	ID         pgtype.UUID        `json:"id"`
+	ActorType  string             `json:"actor_type"`
+	ActorID    string             `json:"actor_id"`
+	Action     string             `json:"action"`
+	TargetType string             `json:"target_type"`
+	TargetIds  []string           `json:"target_ids"`
+	DataBefore []byte             `json:"data_before"`
+	DataAfter  []byte             `json:"data_after"`
+	RequestID  pgtype.Text        `json:"request_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Delivery struct {
 	ID        pgtype.UUID        `json:"id"`
 	TopicID   pgtype.UUID        `json:"topic_id"`
+	AnswerID  pgtype.UUID        `json:"answer_id"`
+	UserID    string             `json:"user_id"`
+	TypeUser  string             `json:"type_user"`
+	ChatID    string             `json:"chat_id"`
+	Sender    string             `json:"sender"`
+	Status    string             `json:"status"`
 	Text      string             `json:"text"`
+	Error     pgtype.Text        `json:"error"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type LineWebhookEvent struct {
+	WebhookEventID string             `json:"webhook_event_id"`
+	ReceivedAt     pgtype.Timestamptz `json:"received_at"`
 }
 
 type MessageGroup struct {
-	ID        pgtype.UUID        `json:"id"`
-	TopicID   pgtype.UUID        `json:"topic_id"`
-	Name      string             `json:"name"`
-	Text      string             `json:"text"`
-	TextSha1  string             `json:"text_sha1"`
-	Language  pgtype.Text        `json:"language"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID           pgtype.UUID        `json:"id"`
+	TopicID      pgtype.UUID        `json:"topic_id"`
+	Name         string             `json:"name"`
+	Text         string             `json:"text"`
+	TextSha1     string             `json:"text_sha1"`
+	Language     pgtype.Text        `json:"language"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Status       string             `json:"status"`
+	StatusReason pgtype.Text        `json:"status_reason"`
+	TextSimhash  pgtype.Int8        `json:"text_simhash"`
+	Preview      []byte             `json:"preview"`
+	TextTokens   string             `json:"text_tokens"`
 }
 
 type MessagesV2 struct {
@@ -41,13 +98,59 @@ type MessagesV2 struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type Outbox struct {
+	Seq           int64              `json:"seq"`
+	ID            pgtype.UUID        `json:"id"`
+	Type          string             `json:"type"`
+	SubjectID     string             `json:"subject_id"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	PublishedAt   pgtype.Timestamptz `json:"published_at"`
+	ClaimedUntil  pgtype.Timestamptz `json:"claimed_until"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	ParkedAt      pgtype.Timestamptz `json:"parked_at"`
+}
+
+type OutboxSink struct {
+	EventID     pgtype.UUID        `json:"event_id"`
+	Sink        string             `json:"sink"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+type RateLimit struct {
+	Key       string             `json:"key"`
+	Tokens    float64            `json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Topic struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Status       string             `json:"status"`
-	Result       pgtype.Text        `json:"result"`
-	ResultStatus pgtype.Text        `json:"result_status"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ID          pgtype.UUID `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Status      string      `json:"status"`
+	Result      pgtype.Text `json:"result"`
+	// Verdict of the latest answer
+	ResultStatus      pgtype.Text        `json:"result_status"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	NameTokens        string             `json:"name_tokens"`
+	DescriptionTokens string             `json:"description_tokens"`
+	AnswerID          pgtype.UUID        `json:"answer_id"`
+}
+
+type TopicRedirect struct {
+	FromID    pgtype.UUID        `json:"from_id"`
+	ToID      pgtype.UUID        `json:"to_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserRole struct {
+	UserID    string             `json:"user_id"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
@@ -9,8 +9,10 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 )
 
 type (
@@ -28,14 +30,31 @@ type TxnManager struct {
 	c *pgxpool.Pool
 }
 
+// Begin begins transaction, whose queries and outcome are recorded in metrics and traces
 func (t TxnManager) Begin(ctx context.Context) (Tx, error) {
-	return t.c.Begin(ctx)
+	span := startTx(ctx)
+	tx, err := t.c.Begin(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return newTxMetrics(tx, span), nil
 }
 
+// BeginTx begins transaction with isolation level, whose queries and outcome are recorded in metrics and traces
 func (t TxnManager) BeginTx(ctx context.Context, level IsoLevel) (Tx, error) {
-	return t.c.BeginTx(ctx, pgx.TxOptions{
+	span := startTx(ctx)
+	span.SetAttributes(attribute.String("db.transaction.isolation_level", string(level)))
+	tx, err := t.c.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.TxIsoLevel(level),
 	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return newTxMetrics(tx, span), nil
 }
 
 func NewTxnManager(conn *pgxpool.Pool) TxnManager {
@@ -70,6 +89,7 @@ func NewConn(c config.Config) (*pgxpool.Pool, func(), error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("postgres pool ping error: %w", err)
 	}
+	registerPoolStats(pool)
 	cleanup := func() {
 		defer slog.InfoContext(ctx, "postgres conn closed or cleaned up")
 		if pool == nil {
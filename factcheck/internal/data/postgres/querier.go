@@ -14,42 +14,143 @@ type Querier interface {
 	AssignMessageGroupToTopic(ctx context.Context, arg AssignMessageGroupToTopicParams) (MessageGroup, error)
 	AssignMessageV2ToMessageGroup(ctx context.Context, arg AssignMessageV2ToMessageGroupParams) (MessagesV2, error)
 	AssignMessageV2ToTopic(ctx context.Context, arg AssignMessageV2ToTopicParams) (MessagesV2, error)
+	// ClaimOutboxEvents leases oldest due events until claimed_until, so that other relays skip them
+	// while they are published outside of transactions. Rows are returned in no particular order.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error)
+	CountTopicGroupsAndMessages(ctx context.Context, topicID pgtype.UUID) (CountTopicGroupsAndMessagesRow, error)
 	CountTopicsByStatus(ctx context.Context, status string) (int64, error)
 	CountTopicsGroupByStatusDynamicV2(ctx context.Context, arg CountTopicsGroupByStatusDynamicV2Params) ([]CountTopicsGroupByStatusDynamicV2Row, error)
+	CountTopicsGroupByVerdictDynamicV2(ctx context.Context, arg CountTopicsGroupByVerdictDynamicV2Params) ([]CountTopicsGroupByVerdictDynamicV2Row, error)
 	CountTopicsGroupedByStatus(ctx context.Context) ([]CountTopicsGroupedByStatusRow, error)
+	// CreateAnswer creates answer as the next revision of its topic.
 	CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Answer, error)
+	// CreateAnswerSource appends the source after existing sources of the answer if position is 0.
+	CreateAnswerSource(ctx context.Context, arg CreateAnswerSourceParams) (AnswerSource, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateDelivery(ctx context.Context, arg CreateDeliveryParams) (Delivery, error)
+	// CreateLINEWebhookEvent records webhook event, and affects no rows if it was already recorded.
+	CreateLINEWebhookEvent(ctx context.Context, arg CreateLINEWebhookEventParams) (int64, error)
 	CreateMessageGroup(ctx context.Context, arg CreateMessageGroupParams) (MessageGroup, error)
 	CreateMessageV2(ctx context.Context, arg CreateMessageV2Params) (MessagesV2, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
 	CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic, error)
+	CreateTopicRedirect(ctx context.Context, arg CreateTopicRedirectParams) (TopicRedirect, error)
 	DeleteAnswer(ctx context.Context, id pgtype.UUID) error
+	DeleteAnswerSource(ctx context.Context, id pgtype.UUID) (int64, error)
+	// DeleteDuplicateMessageGroups deletes groups of topic from_id if topic to_id has groups with identical text.
+	DeleteDuplicateMessageGroups(ctx context.Context, arg DeleteDuplicateMessageGroupsParams) (int64, error)
+	DeleteLINEWebhookEventsBefore(ctx context.Context, receivedAt pgtype.Timestamptz) (int64, error)
 	DeleteMessageGroup(ctx context.Context, id pgtype.UUID) error
 	DeleteMessageV2(ctx context.Context, id pgtype.UUID) error
+	// DeleteRateLimitsBefore deletes buckets last updated before updated_at.
+	// Buckets being taken from are locked and updated, so they are never deleted.
+	DeleteRateLimitsBefore(ctx context.Context, updatedAt pgtype.Timestamptz) (int64, error)
 	DeleteTopic(ctx context.Context, id pgtype.UUID) error
+	DeleteUserRole(ctx context.Context, userID string) (int64, error)
 	GetAnswerByID(ctx context.Context, id pgtype.UUID) (Answer, error)
-	GetAnswerByTopicID(ctx context.Context, topicID pgtype.UUID) (Answer, error)
+	// GetAnswerByTopicID gets the published answer of topic.
+	GetAnswerByTopicID(ctx context.Context, id pgtype.UUID) (Answer, error)
+	GetAnswerRevision(ctx context.Context, arg GetAnswerRevisionParams) (Answer, error)
+	GetAnswerSource(ctx context.Context, id pgtype.UUID) (AnswerSource, error)
 	GetMessageGroup(ctx context.Context, id pgtype.UUID) (MessageGroup, error)
 	GetMessageGroupBySHA1(ctx context.Context, textSha1 string) (MessageGroup, error)
 	GetMessageV2(ctx context.Context, id pgtype.UUID) (MessagesV2, error)
+	GetOutboxLastSeq(ctx context.Context) (int64, error)
 	GetTopic(ctx context.Context, id pgtype.UUID) (Topic, error)
+	// GetTopicFollowRedirect gets topic by ID, or the topic it was merged into.
+	GetTopicFollowRedirect(ctx context.Context, id pgtype.UUID) (Topic, error)
 	GetTopicStatus(ctx context.Context, id pgtype.UUID) (string, error)
+	GetUserRole(ctx context.Context, userID string) (UserRole, error)
+	// ListAnswerRevisions lists all revisions of answers to topic, including drafts.
+	ListAnswerRevisions(ctx context.Context, topicID pgtype.UUID) ([]Answer, error)
+	ListAnswerSourcesByAnswerID(ctx context.Context, answerID pgtype.UUID) ([]AnswerSource, error)
+	// ListAnswersAfterID lists answers in order of IDs, for batch jobs over all answers.
+	ListAnswersAfterID(ctx context.Context, arg ListAnswersAfterIDParams) ([]Answer, error)
 	ListAnswersByTopicID(ctx context.Context, topicID pgtype.UUID) ([]Answer, error)
+	// ListAnswersByTopicIDPage lists answers after the cursor, latest first,
+	// or answers before the cursor in reverse order if cursor_prev is true.
+	ListAnswersByTopicIDPage(ctx context.Context, arg ListAnswersByTopicIDPageParams) ([]Answer, error)
+	ListAuditEventsDynamic(ctx context.Context, arg ListAuditEventsDynamicParams) ([]AuditEvent, error)
+	ListDeliveriesByAnswerID(ctx context.Context, answerID pgtype.UUID) ([]Delivery, error)
+	ListDeliveriesByTopicID(ctx context.Context, topicID pgtype.UUID) ([]Delivery, error)
 	ListMessageGroupDynamic(ctx context.Context, arg ListMessageGroupDynamicParams) ([]MessageGroup, error)
+	// ListMessageGroupsAfterID lists message groups in order of IDs, for batch jobs over all groups.
+	ListMessageGroupsAfterID(ctx context.Context, arg ListMessageGroupsAfterIDParams) ([]MessageGroup, error)
 	ListMessageGroupsByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessageGroup, error)
 	ListMessagesV2ByGroup(ctx context.Context, groupID pgtype.UUID) ([]MessagesV2, error)
 	ListMessagesV2ByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessagesV2, error)
+	// ListMessagesV2ByTopicPage lists messages after the cursor, oldest first,
+	// or messages before the cursor in reverse order if cursor_prev is true.
+	ListMessagesV2ByTopicPage(ctx context.Context, arg ListMessagesV2ByTopicPageParams) ([]MessagesV2, error)
+	ListOutboxAfter(ctx context.Context, arg ListOutboxAfterParams) ([]Outbox, error)
+	ListOutboxBySeqs(ctx context.Context, seqs []int64) ([]Outbox, error)
+	ListOutboxSinksPublished(ctx context.Context, eventID pgtype.UUID) ([]string, error)
+	// ListSearchResults ranks topics, approved message groups and published answers matching tsquery from package search.
+	// The tsvector expressions must match the GIN indexes in migrations.
+	ListSearchResults(ctx context.Context, arg ListSearchResultsParams) ([]ListSearchResultsRow, error)
+	// Lists groups created after created_after whose SimHash is within max_distance bits of simhash, closest first.
+	// Rejected groups are never similar. Hamming distance can't use indexes, so this scans all groups
+	// created after created_after, and callers should not hold transactions.
+	ListSimilarMessageGroups(ctx context.Context, arg ListSimilarMessageGroupsParams) ([]ListSimilarMessageGroupsRow, error)
 	ListTopics(ctx context.Context, arg ListTopicsParams) ([]ListTopicsRow, error)
+	// ListTopicsAfterID lists topics in order of IDs, for batch jobs over all topics.
+	ListTopicsAfterID(ctx context.Context, arg ListTopicsAfterIDParams) ([]Topic, error)
 	ListTopicsByStatus(ctx context.Context, arg ListTopicsByStatusParams) ([]ListTopicsByStatusRow, error)
+	// With cursor ($8, $9), only topics after the cursor are listed,
+	// or topics before the cursor in reverse order if $10 is true.
 	ListTopicsDynamicV2(ctx context.Context, arg ListTopicsDynamicV2Params) ([]Topic, error)
 	ListTopicsInIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]Topic, error)
 	ListTopicsLikeID(ctx context.Context, arg ListTopicsLikeIDParams) ([]ListTopicsLikeIDRow, error)
+	ListUserRoles(ctx context.Context) ([]UserRole, error)
+	// LockRateLimit creates the bucket if missing, and returns it locked until the transaction ends.
+	LockRateLimit(ctx context.Context, arg LockRateLimitParams) (RateLimit, error)
+	// MarkOutboxFailed schedules the next attempt after backoff_secs doubled for every earlier attempt,
+	// up to backoff_max_secs, or parks the event if this was attempt max_attempts.
+	MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) error
+	MarkOutboxPublished(ctx context.Context, arg MarkOutboxPublishedParams) error
+	// MergeMessagesV2IntoDuplicateGroups moves messages in groups of topic from_id into groups of topic to_id with identical text.
+	MergeMessagesV2IntoDuplicateGroups(ctx context.Context, arg MergeMessagesV2IntoDuplicateGroupsParams) (int64, error)
+	// MoveAnswersTopic moves answers, renumbering them as revisions after the target topic's answers.
+	MoveAnswersTopic(ctx context.Context, arg MoveAnswersTopicParams) (int64, error)
+	MoveDeliveriesTopic(ctx context.Context, arg MoveDeliveriesTopicParams) (int64, error)
+	MoveMessageGroupsTopic(ctx context.Context, arg MoveMessageGroupsTopicParams) (int64, error)
+	MoveMessageGroupsTopicInIDs(ctx context.Context, arg MoveMessageGroupsTopicInIDsParams) (int64, error)
+	MoveMessagesV2Topic(ctx context.Context, arg MoveMessagesV2TopicParams) (int64, error)
+	// MoveMessagesV2TopicInGroupIDs moves all messages of the groups, including messages not yet assigned to topics.
+	MoveMessagesV2TopicInGroupIDs(ctx context.Context, arg MoveMessagesV2TopicInGroupIDsParams) (int64, error)
+	PublishAnswer(ctx context.Context, arg PublishAnswerParams) (Answer, error)
+	// ReopenTopic moves resolved topic back to pending, keeping its published answer until corrected.
+	ReopenTopic(ctx context.Context, id pgtype.UUID) (Topic, error)
 	ResolveTopic(ctx context.Context, arg ResolveTopicParams) (Topic, error)
+	// ResolveTopicID returns ID of the topic id was merged into, or id itself.
+	ResolveTopicID(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error)
 	TopicExists(ctx context.Context, id pgtype.UUID) (bool, error)
 	UnassignMessageGroupFromTopic(ctx context.Context, id pgtype.UUID) (MessageGroup, error)
 	UnassignMessageV2FromTopic(ctx context.Context, id pgtype.UUID) (MessagesV2, error)
+	// UnassignMessagesV2FromTopicInGroup unassigns all messages of group from their topics.
+	UnassignMessagesV2FromTopicInGroup(ctx context.Context, groupID pgtype.UUID) (int64, error)
+	UpdateAnswerDraft(ctx context.Context, arg UpdateAnswerDraftParams) (Answer, error)
+	// UpdateAnswerSource keeps the position of the source if position is 0.
+	UpdateAnswerSource(ctx context.Context, arg UpdateAnswerSourceParams) (AnswerSource, error)
+	// UpdateAnswerTokens sets search tokens of answer, leaving updated_at alone.
+	UpdateAnswerTokens(ctx context.Context, arg UpdateAnswerTokensParams) error
+	// UpdateMessageGroupKey sets hashes by which submissions join message group, leaving updated_at alone.
+	UpdateMessageGroupKey(ctx context.Context, arg UpdateMessageGroupKeyParams) error
 	UpdateMessageGroupName(ctx context.Context, arg UpdateMessageGroupNameParams) (MessageGroup, error)
+	UpdateMessageGroupStatus(ctx context.Context, arg UpdateMessageGroupStatusParams) (MessageGroup, error)
+	// UpdateMessageGroupTokens sets search tokens of message group, leaving updated_at alone.
+	UpdateMessageGroupTokens(ctx context.Context, arg UpdateMessageGroupTokensParams) error
+	UpdateRateLimit(ctx context.Context, arg UpdateRateLimitParams) error
 	UpdateTopicDescription(ctx context.Context, arg UpdateTopicDescriptionParams) (Topic, error)
 	UpdateTopicName(ctx context.Context, arg UpdateTopicNameParams) (Topic, error)
+	// UpdateTopicRedirectsTo repoints redirects into a merged topic to its target, so that chains are never followed.
+	UpdateTopicRedirectsTo(ctx context.Context, arg UpdateTopicRedirectsToParams) (int64, error)
 	UpdateTopicStatus(ctx context.Context, arg UpdateTopicStatusParams) (Topic, error)
+	// UpdateTopicTokens sets search tokens of topic, leaving updated_at alone.
+	UpdateTopicTokens(ctx context.Context, arg UpdateTopicTokensParams) error
+	// UpsertOutboxSinkAttempt records an attempt of sink publishing event, which succeeded if published_at is set.
+	UpsertOutboxSinkAttempt(ctx context.Context, arg UpsertOutboxSinkAttemptParams) error
+	UpsertUserRole(ctx context.Context, arg UpsertUserRoleParams) (UserRole, error)
 }
 
 var _ Querier = (*Queries)(nil)
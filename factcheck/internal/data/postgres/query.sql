@@ -1,8 +1,8 @@
 -- name: CreateTopic :one
 INSERT INTO topics (
-    id, name, description, status, result, result_status, created_at, updated_at
+    id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 ) RETURNING *;
 
 -- name: GetTopic :one
@@ -21,7 +21,7 @@ WITH numbered_topics AS (
            COUNT(*) OVER () as total_count
     FROM topics
 )
-SELECT id, name, description, status, result, result_status, created_at, updated_at
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
 FROM numbered_topics
 WHERE CASE
     WHEN $1 = 0 THEN true  -- No pagination
@@ -38,7 +38,7 @@ WITH numbered_topics AS (
     FROM topics
     WHERE status = $1
 )
-SELECT id, name, description, status, result, result_status, created_at, updated_at
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
 FROM numbered_topics
 WHERE CASE
     WHEN $2 = 0 THEN true  -- No pagination
@@ -60,7 +60,7 @@ WITH numbered_topics AS (
     FROM topics t
     WHERE t.id::text LIKE $1::text
 )
-SELECT id, name, description, status, result, result_status, created_at, updated_at
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
 FROM numbered_topics
 WHERE CASE
     WHEN $2 = 0 THEN true  -- No pagination
@@ -78,22 +78,35 @@ WHERE id = $1 RETURNING *;
 -- name: UpdateTopicDescription :one
 UPDATE topics SET
     description = $2,
+    description_tokens = $3,
     updated_at = NOW()
 WHERE id = $1 RETURNING *;
 
 -- name: UpdateTopicName :one
 UPDATE topics SET
     name = $2,
+    name_tokens = $3,
     updated_at = NOW()
 WHERE id = $1 RETURNING *;
 
 -- name: ResolveTopic :one
 UPDATE topics SET
     result = $2,
-    result_status = $3,
+    status = $3,
+    result_status = $4,
+    answer_id = $5,
     updated_at = NOW()
 WHERE id = $1 RETURNING *;
 
+-- name: ReopenTopic :one
+-- ReopenTopic moves resolved topic back to pending, keeping its published answer until corrected.
+UPDATE topics SET
+    result = NULL,
+    status = 'TOPIC_PENDING',
+    result_status = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'TOPIC_RESOLVED' RETURNING *;
+
 -- name: CountTopicsByStatus :one
 SELECT COUNT(*) FROM topics WHERE status = $1;
 
@@ -105,7 +118,89 @@ GROUP BY status;
 -- name: DeleteTopic :exec
 DELETE FROM topics WHERE id = $1;
 
+-- name: GetTopicFollowRedirect :one
+-- GetTopicFollowRedirect gets topic by ID, or the topic it was merged into.
+SELECT * FROM topics
+WHERE id = COALESCE((SELECT to_id FROM topic_redirects WHERE from_id = sqlc.arg('id')), sqlc.arg('id'));
+
+-- name: ResolveTopicID :one
+-- ResolveTopicID returns ID of the topic id was merged into, or id itself.
+SELECT COALESCE((SELECT to_id FROM topic_redirects WHERE from_id = sqlc.arg('id')::uuid), sqlc.arg('id')::uuid)::uuid AS id;
+
+-- name: CreateTopicRedirect :one
+INSERT INTO topic_redirects (
+    from_id, to_id, created_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UpdateTopicRedirectsTo :execrows
+-- UpdateTopicRedirectsTo repoints redirects into a merged topic to its target, so that chains are never followed.
+UPDATE topic_redirects SET to_id = sqlc.arg('to_id') WHERE to_id = sqlc.arg('from_id');
+
+-- name: MergeMessagesV2IntoDuplicateGroups :execrows
+-- MergeMessagesV2IntoDuplicateGroups moves messages in groups of topic from_id into groups of topic to_id with identical text.
+UPDATE messages_v2 m SET
+    group_id = target.id,
+    updated_at = NOW()
+FROM message_groups source
+JOIN message_groups target ON target.text_sha1 = source.text_sha1
+WHERE source.topic_id = sqlc.arg('from_id')
+    AND target.topic_id = sqlc.arg('to_id')
+    AND m.group_id = source.id;
+
+-- name: DeleteDuplicateMessageGroups :execrows
+-- DeleteDuplicateMessageGroups deletes groups of topic from_id if topic to_id has groups with identical text.
+DELETE FROM message_groups source
+USING message_groups target
+WHERE source.topic_id = sqlc.arg('from_id')
+    AND target.topic_id = sqlc.arg('to_id')
+    AND target.text_sha1 = source.text_sha1;
+
+-- name: MoveMessageGroupsTopic :execrows
+UPDATE message_groups SET
+    topic_id = sqlc.arg('to_id'),
+    updated_at = NOW()
+WHERE topic_id = sqlc.arg('from_id');
+
+-- name: MoveMessagesV2Topic :execrows
+UPDATE messages_v2 SET
+    topic_id = sqlc.arg('to_id'),
+    updated_at = NOW()
+WHERE topic_id = sqlc.arg('from_id');
+
+-- name: MoveAnswersTopic :execrows
+-- MoveAnswersTopic moves answers, renumbering them as revisions after the target topic's answers.
+UPDATE answers source SET
+    topic_id = sqlc.arg('to_id'),
+    revision = source.revision + (SELECT COALESCE(MAX(target.revision), 0) FROM answers target WHERE target.topic_id = sqlc.arg('to_id'))
+WHERE source.topic_id = sqlc.arg('from_id');
+
+-- name: MoveDeliveriesTopic :execrows
+UPDATE deliveries SET topic_id = sqlc.arg('to_id') WHERE topic_id = sqlc.arg('from_id');
+
+-- name: MoveMessageGroupsTopicInIDs :execrows
+UPDATE message_groups SET
+    topic_id = sqlc.arg('to_id'),
+    updated_at = NOW()
+WHERE topic_id = sqlc.arg('from_id') AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: MoveMessagesV2TopicInGroupIDs :execrows
+-- MoveMessagesV2TopicInGroupIDs moves all messages of the groups, including messages not yet assigned to topics.
+UPDATE messages_v2 SET
+    topic_id = sqlc.arg('to_id'),
+    updated_at = NOW()
+WHERE group_id = ANY(sqlc.arg('group_ids')::uuid[]);
+
+-- name: CountTopicGroupsAndMessages :one
+SELECT
+    (SELECT COUNT(*) FROM message_groups WHERE message_groups.topic_id = $1) AS groups,
+    (SELECT COUNT(*) FROM messages_v2 WHERE messages_v2.topic_id = $1) AS messages;
+
 -- name: ListTopicsDynamicV2 :many
+-- With cursor ($8, $9), only topics after the cursor are listed,
+-- or topics before the cursor in reverse order if $10 is true.
+SELECT t.* FROM (
 SELECT DISTINCT t.*
 FROM topics t
 LEFT JOIN message_groups m ON t.id = m.topic_id
@@ -128,7 +223,25 @@ WHERE 1=1
         )
         ELSE true
     END
-ORDER BY t.created_at DESC
+    AND CASE
+        WHEN array_length($6::text[], 1) > 0 THEN t.result_status = ANY($6::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($7::text[], 1) > 0 THEN m.language = ANY($7::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN $8::timestamptz IS NULL THEN true
+        WHEN $10::boolean THEN (t.created_at, t.id) > ($8::timestamptz, $9::uuid)
+        ELSE (t.created_at, t.id) < ($8::timestamptz, $9::uuid)
+    END
+) t
+ORDER BY
+    CASE WHEN $10::boolean THEN t.created_at END ASC,
+    CASE WHEN $10::boolean THEN t.id END ASC,
+    t.created_at DESC,
+    t.id DESC
 LIMIT CASE WHEN $4::integer = 0 THEN NULL ELSE $4::integer END
 OFFSET CASE WHEN $4::integer = 0 THEN 0 ELSE $5::integer END;
 
@@ -151,8 +264,37 @@ WHERE 1=1
         )
         ELSE true
     END
+    AND CASE
+        WHEN array_length($3::text[], 1) > 0 THEN m.language = ANY($3::text[])
+        ELSE true
+    END
 GROUP BY t.status;
 
+-- name: CountTopicsGroupByVerdictDynamicV2 :many
+SELECT t.result_status, COUNT(DISTINCT t.id) as count
+FROM topics t
+LEFT JOIN message_groups m ON t.id = m.topic_id
+WHERE t.result_status IS NOT NULL
+    AND CASE
+        WHEN $1::text != '' THEN t.id::text LIKE $1::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $2::text != '' THEN (
+            CASE
+                WHEN m.language = 'th' THEN m.text LIKE $2::text COLLATE "C"
+                WHEN m.language = 'en' THEN m.text ILIKE $2::text
+                ELSE m.text ILIKE $2::text  -- fallback for unknown language
+            END
+        )
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($3::text[], 1) > 0 THEN m.language = ANY($3::text[])
+        ELSE true
+    END
+GROUP BY t.result_status;
+
 -- name: CreateMessageV2 :one
 INSERT INTO messages_v2 (
     id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at
@@ -166,6 +308,23 @@ SELECT * FROM messages_v2 WHERE id = $1;
 -- name: ListMessagesV2ByTopic :many
 SELECT * FROM messages_v2 WHERE topic_id = $1 ORDER BY created_at ASC;
 
+-- name: ListMessagesV2ByTopicPage :many
+-- ListMessagesV2ByTopicPage lists messages after the cursor, oldest first,
+-- or messages before the cursor in reverse order if cursor_prev is true.
+SELECT * FROM messages_v2
+WHERE topic_id = sqlc.arg('topic_id')
+    AND CASE
+        WHEN sqlc.narg('cursor_created_at')::timestamptz IS NULL THEN true
+        WHEN sqlc.arg('cursor_prev')::boolean THEN (created_at, id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+        ELSE (created_at, id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    END
+ORDER BY
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN created_at END DESC,
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN id END DESC,
+    created_at ASC,
+    id ASC
+LIMIT sqlc.arg('limit')::integer;
+
 -- name: ListMessagesV2ByGroup :many
 SELECT * FROM messages_v2 WHERE group_id = $1 ORDER BY created_at ASC;
 
@@ -181,6 +340,13 @@ UPDATE messages_v2 SET
     updated_at = NOW()
 WHERE id = $1 RETURNING *;
 
+-- name: UnassignMessagesV2FromTopicInGroup :execrows
+-- UnassignMessagesV2FromTopicInGroup unassigns all messages of group from their topics.
+UPDATE messages_v2 SET
+    topic_id = NULL,
+    updated_at = NOW()
+WHERE group_id = $1 AND topic_id IS NOT NULL;
+
 -- name: AssignMessageV2ToMessageGroup :one
 UPDATE messages_v2 SET
     group_id = $2,
@@ -192,9 +358,9 @@ DELETE FROM messages_v2 WHERE id = $1;
 
 -- name: CreateMessageGroup :one
 INSERT INTO message_groups (
-    id, topic_id, name, text, text_sha1, language, created_at, updated_at
+    id, topic_id, name, text, text_sha1, text_simhash, language, status, preview, created_at, updated_at, text_tokens
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: ListMessageGroupDynamic :many
@@ -213,7 +379,24 @@ WHERE 1=1
         WHEN array_length(sqlc.arg('id_not_in')::text[], 1) > 0 THEN NOT (mg.id = ANY((sqlc.arg('id_not_in')::text[])::uuid[]))
         ELSE true
     END
-ORDER BY mg.created_at DESC
+    AND CASE
+        WHEN array_length(sqlc.arg('statuses')::text[], 1) > 0 THEN mg.status = ANY(sqlc.arg('statuses')::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length(sqlc.arg('languages')::text[], 1) > 0 THEN mg.language = ANY(sqlc.arg('languages')::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN sqlc.narg('cursor_created_at')::timestamptz IS NULL THEN true
+        WHEN sqlc.arg('cursor_prev')::boolean THEN (mg.created_at, mg.id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+        ELSE (mg.created_at, mg.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    END
+ORDER BY
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN mg.created_at END ASC,
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN mg.id END ASC,
+    mg.created_at DESC,
+    mg.id DESC
 LIMIT CASE WHEN sqlc.arg('limit')::integer = 0 THEN NULL ELSE sqlc.arg('limit')::integer END
 OFFSET CASE WHEN sqlc.arg('offset')::integer = 0 THEN 0 ELSE sqlc.arg('offset')::integer END;
 
@@ -223,6 +406,23 @@ SELECT * FROM message_groups WHERE id = $1;
 -- name: GetMessageGroupBySHA1 :one
 SELECT * FROM message_groups WHERE text_sha1 = $1;
 
+-- name: UpdateMessageGroupKey :exec
+-- UpdateMessageGroupKey sets hashes by which submissions join message group, leaving updated_at alone.
+UPDATE message_groups SET text_sha1 = $2, text_simhash = $3 WHERE id = $1;
+
+-- name: ListSimilarMessageGroups :many
+-- Lists groups created after created_after whose SimHash is within max_distance bits of simhash, closest first.
+-- Rejected groups are never similar. Hamming distance can't use indexes, so this scans all groups
+-- created after created_after, and callers should not hold transactions.
+SELECT sqlc.embed(mg), bit_count((mg.text_simhash # sqlc.arg('simhash')::bigint)::bit(64))::integer AS distance
+FROM message_groups mg
+WHERE mg.created_at > sqlc.arg('created_after')::timestamptz
+    AND mg.status != 'MGROUP_REJECTED'
+    AND mg.text_simhash IS NOT NULL
+    AND bit_count((mg.text_simhash # sqlc.arg('simhash')::bigint)::bit(64)) <= sqlc.arg('max_distance')::integer
+ORDER BY distance ASC, mg.created_at ASC
+LIMIT sqlc.arg('limit')::integer;
+
 -- name: ListMessageGroupsByTopic :many
 SELECT * FROM message_groups WHERE topic_id = $1 ORDER BY created_at ASC;
 
@@ -238,6 +438,13 @@ UPDATE message_groups SET
     updated_at = NOW()
 WHERE id = $1 RETURNING *;
 
+-- name: UpdateMessageGroupStatus :one
+UPDATE message_groups SET
+    status = $2,
+    status_reason = $3,
+    updated_at = NOW()
+WHERE id = $1 RETURNING *;
+
 -- name: UnassignMessageGroupFromTopic :one
 UPDATE message_groups SET
     topic_id = NULL,
@@ -248,20 +455,350 @@ WHERE id = $1 RETURNING *;
 DELETE FROM message_groups WHERE id = $1;
 
 -- name: CreateAnswer :one
+-- CreateAnswer creates answer as the next revision of its topic.
 INSERT INTO answers (
-    id, topic_id, text, created_at, updated_at
+    id, topic_id, text, verdict, created_at, updated_at, text_tokens, status, user_id, corrects_id, revision
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+    (SELECT COALESCE(MAX(revision), 0) + 1 FROM answers WHERE topic_id = $2)
 ) RETURNING *;
 
 -- name: GetAnswerByID :one
 SELECT * FROM answers WHERE id = $1;
 
 -- name: GetAnswerByTopicID :one
-SELECT * FROM answers WHERE topic_id = $1 ORDER BY created_at DESC LIMIT 1;
+-- GetAnswerByTopicID gets the published answer of topic.
+SELECT a.* FROM answers a
+JOIN topics t ON t.answer_id = a.id
+WHERE t.id = $1;
+
+-- name: GetAnswerRevision :one
+SELECT * FROM answers WHERE topic_id = $1 AND revision = $2;
 
 -- name: ListAnswersByTopicID :many
-SELECT * FROM answers WHERE topic_id = $1 ORDER BY created_at DESC;
+SELECT * FROM answers WHERE topic_id = $1 AND status = 'ANSWER_PUBLISHED' ORDER BY revision DESC;
+
+-- name: ListAnswerRevisions :many
+-- ListAnswerRevisions lists all revisions of answers to topic, including drafts.
+SELECT * FROM answers WHERE topic_id = $1 ORDER BY revision DESC;
+
+-- name: UpdateAnswerDraft :one
+UPDATE answers SET
+    text = $2,
+    verdict = $3,
+    text_tokens = $4,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'ANSWER_DRAFT' RETURNING *;
+
+-- name: PublishAnswer :one
+UPDATE answers SET
+    status = 'ANSWER_PUBLISHED',
+    corrects_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'ANSWER_DRAFT' RETURNING *;
+
+-- name: ListAnswersByTopicIDPage :many
+-- ListAnswersByTopicIDPage lists answers after the cursor, latest first,
+-- or answers before the cursor in reverse order if cursor_prev is true.
+SELECT * FROM answers
+WHERE topic_id = sqlc.arg('topic_id')
+    AND status = 'ANSWER_PUBLISHED'
+    AND CASE
+        WHEN sqlc.narg('cursor_created_at')::timestamptz IS NULL THEN true
+        WHEN sqlc.arg('cursor_prev')::boolean THEN (created_at, id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+        ELSE (created_at, id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    END
+ORDER BY
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN created_at END ASC,
+    CASE WHEN sqlc.arg('cursor_prev')::boolean THEN id END ASC,
+    created_at DESC,
+    id DESC
+LIMIT sqlc.arg('limit')::integer;
 
 -- name: DeleteAnswer :exec
 DELETE FROM answers WHERE id = $1;
+
+-- name: CreateAnswerSource :one
+-- CreateAnswerSource appends the source after existing sources of the answer if position is 0.
+INSERT INTO answer_sources (
+    id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('answer_id'),
+    sqlc.arg('type'),
+    COALESCE(
+        NULLIF(sqlc.arg('position')::integer, 0),
+        (SELECT COALESCE(MAX(position), 0) + 1 FROM answer_sources WHERE answer_id = sqlc.arg('answer_id'))
+    ),
+    sqlc.arg('url'),
+    sqlc.arg('publisher'),
+    sqlc.arg('archive_url'),
+    sqlc.arg('quote'),
+    sqlc.arg('attachment_url'),
+    sqlc.arg('created_at')
+) RETURNING *;
+
+-- name: GetAnswerSource :one
+SELECT * FROM answer_sources WHERE id = $1;
+
+-- name: ListAnswerSourcesByAnswerID :many
+SELECT * FROM answer_sources WHERE answer_id = $1 ORDER BY position ASC, created_at ASC;
+
+-- name: UpdateAnswerSource :one
+-- UpdateAnswerSource keeps the position of the source if position is 0.
+UPDATE answer_sources SET
+    type = sqlc.arg('type'),
+    position = COALESCE(NULLIF(sqlc.arg('position')::integer, 0), position),
+    url = sqlc.arg('url'),
+    publisher = sqlc.arg('publisher'),
+    archive_url = sqlc.arg('archive_url'),
+    quote = sqlc.arg('quote'),
+    attachment_url = sqlc.arg('attachment_url'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id') RETURNING *;
+
+-- name: DeleteAnswerSource :execrows
+DELETE FROM answer_sources WHERE id = $1;
+
+-- name: CreateDelivery :one
+INSERT INTO deliveries (
+    id, topic_id, answer_id, user_id, type_user, chat_id, sender, status, text, error, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: ListDeliveriesByTopicID :many
+SELECT * FROM deliveries WHERE topic_id = $1 ORDER BY created_at ASC;
+
+-- name: ListDeliveriesByAnswerID :many
+SELECT * FROM deliveries WHERE answer_id = $1 ORDER BY created_at ASC;
+
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    id, type, subject_id, payload, created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- ClaimOutboxEvents leases oldest due events until claimed_until, so that other relays skip them
+-- while they are published outside of transactions. Rows are returned in no particular order.
+UPDATE outbox SET claimed_until = sqlc.arg('claimed_until')
+WHERE seq IN (
+    SELECT o.seq FROM outbox o
+    WHERE o.published_at IS NULL
+    AND o.parked_at IS NULL
+    AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= sqlc.arg('now'))
+    AND (o.claimed_until IS NULL OR o.claimed_until <= sqlc.arg('now'))
+    ORDER BY o.seq ASC
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListOutboxAfter :many
+SELECT * FROM outbox
+WHERE seq > $1
+ORDER BY seq ASC
+LIMIT $2;
+
+-- name: ListOutboxBySeqs :many
+SELECT * FROM outbox
+WHERE seq = ANY(sqlc.arg('seqs')::bigint[])
+ORDER BY seq ASC;
+
+-- name: GetOutboxLastSeq :one
+SELECT COALESCE(MAX(seq), 0)::bigint AS seq FROM outbox;
+
+-- name: MarkOutboxPublished :exec
+UPDATE outbox SET
+    published_at = $2,
+    attempts = attempts + 1,
+    last_error = NULL,
+    claimed_until = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxFailed :exec
+-- MarkOutboxFailed schedules the next attempt after backoff_secs doubled for every earlier attempt,
+-- up to backoff_max_secs, or parks the event if this was attempt max_attempts.
+UPDATE outbox SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    claimed_until = NULL,
+    next_attempt_at = sqlc.arg('now')::timestamptz
+        + make_interval(secs => LEAST(sqlc.arg('backoff_secs')::float8 * power(2, attempts), sqlc.arg('backoff_max_secs')::float8)),
+    parked_at = CASE WHEN attempts + 1 >= sqlc.arg('max_attempts')::int THEN sqlc.arg('now')::timestamptz END
+WHERE id = sqlc.arg('id');
+
+-- name: ListOutboxSinksPublished :many
+SELECT sink FROM outbox_sinks WHERE event_id = $1 AND published_at IS NOT NULL;
+
+-- name: UpsertOutboxSinkAttempt :exec
+-- UpsertOutboxSinkAttempt records an attempt of sink publishing event, which succeeded if published_at is set.
+INSERT INTO outbox_sinks (
+    event_id, sink, attempts, last_error, published_at
+) VALUES (
+    $1, $2, 1, $3, $4
+)
+ON CONFLICT (event_id, sink) DO UPDATE SET
+    attempts = outbox_sinks.attempts + 1,
+    last_error = EXCLUDED.last_error,
+    published_at = EXCLUDED.published_at;
+
+-- name: UpsertUserRole :one
+INSERT INTO user_roles (
+    user_id, role, created_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    role = EXCLUDED.role,
+    updated_at = EXCLUDED.created_at
+RETURNING *;
+
+-- name: GetUserRole :one
+SELECT * FROM user_roles WHERE user_id = $1;
+
+-- name: ListUserRoles :many
+SELECT * FROM user_roles ORDER BY user_id ASC;
+
+-- name: DeleteUserRole :execrows
+DELETE FROM user_roles WHERE user_id = $1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    id, actor_type, actor_id, action, target_type, target_ids, data_before, data_after, request_id, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: ListAuditEventsDynamic :many
+SELECT * FROM audit_events
+WHERE 1=1
+    AND CASE
+        WHEN $1::text != '' THEN actor_id = $1::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $2::text != '' THEN target_ids @> ARRAY[$2::text]
+        ELSE true
+    END
+    AND CASE
+        WHEN $3::text != '' THEN action = $3::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $4::timestamptz IS NOT NULL THEN created_at >= $4::timestamptz
+        ELSE true
+    END
+    AND CASE
+        WHEN $5::timestamptz IS NOT NULL THEN created_at < $5::timestamptz
+        ELSE true
+    END
+ORDER BY created_at DESC, id ASC
+LIMIT CASE WHEN $6::integer = 0 THEN NULL ELSE $6::integer END
+OFFSET CASE WHEN $6::integer = 0 THEN 0 ELSE $7::integer END;
+
+-- name: ListSearchResults :many
+-- ListSearchResults ranks topics, approved message groups and published answers matching tsquery from package search.
+-- The tsvector expressions must match the GIN indexes in migrations.
+SELECT results.type, results.id, results.topic_id, results.title, results.text, results.rank, results.created_at
+FROM (
+    SELECT
+        'SEARCH_TOPIC'::text AS type,
+        t.id,
+        t.id AS topic_id,
+        t.name AS title,
+        t.description AS text,
+        ts_rank(
+            setweight(array_to_tsvector(string_to_array(t.name_tokens, ' ')), 'A') ||
+            setweight(array_to_tsvector(string_to_array(t.description_tokens, ' ')), 'B'),
+            sqlc.arg('query')::text::tsquery
+        ) AS rank,
+        t.created_at
+    FROM topics t
+    WHERE 'SEARCH_TOPIC' = ANY(sqlc.arg('types')::text[])
+        AND (
+            setweight(array_to_tsvector(string_to_array(t.name_tokens, ' ')), 'A') ||
+            setweight(array_to_tsvector(string_to_array(t.description_tokens, ' ')), 'B')
+        ) @@ sqlc.arg('query')::text::tsquery
+
+    UNION ALL
+
+    SELECT
+        'SEARCH_MESSAGE_GROUP'::text AS type,
+        mg.id,
+        mg.topic_id,
+        mg.name AS title,
+        mg.text,
+        ts_rank(array_to_tsvector(string_to_array(mg.text_tokens, ' ')), sqlc.arg('query')::text::tsquery) AS rank,
+        mg.created_at
+    FROM message_groups mg
+    WHERE 'SEARCH_MESSAGE_GROUP' = ANY(sqlc.arg('types')::text[])
+        AND mg.status = 'MGROUP_APPROVED'
+        AND array_to_tsvector(string_to_array(mg.text_tokens, ' ')) @@ sqlc.arg('query')::text::tsquery
+
+    UNION ALL
+
+    SELECT
+        'SEARCH_ANSWER'::text AS type,
+        a.id,
+        a.topic_id,
+        ''::text AS title,
+        a.text,
+        ts_rank(array_to_tsvector(string_to_array(a.text_tokens, ' ')), sqlc.arg('query')::text::tsquery) AS rank,
+        a.created_at
+    FROM answers a
+    WHERE 'SEARCH_ANSWER' = ANY(sqlc.arg('types')::text[])
+        AND a.status = 'ANSWER_PUBLISHED'
+        AND array_to_tsvector(string_to_array(a.text_tokens, ' ')) @@ sqlc.arg('query')::text::tsquery
+) AS results
+ORDER BY results.rank DESC, results.created_at DESC, results.id
+LIMIT CASE WHEN sqlc.arg('limit')::integer = 0 THEN NULL ELSE sqlc.arg('limit')::integer END
+OFFSET CASE WHEN sqlc.arg('offset')::integer = 0 THEN 0 ELSE sqlc.arg('offset')::integer END;
+
+-- name: ListTopicsAfterID :many
+-- ListTopicsAfterID lists topics in order of IDs, for batch jobs over all topics.
+SELECT * FROM topics WHERE id > $1 ORDER BY id LIMIT $2;
+
+-- name: ListMessageGroupsAfterID :many
+-- ListMessageGroupsAfterID lists message groups in order of IDs, for batch jobs over all groups.
+SELECT * FROM message_groups WHERE id > $1 ORDER BY id LIMIT $2;
+
+-- name: ListAnswersAfterID :many
+-- ListAnswersAfterID lists answers in order of IDs, for batch jobs over all answers.
+SELECT * FROM answers WHERE id > $1 ORDER BY id LIMIT $2;
+
+-- name: UpdateTopicTokens :exec
+-- UpdateTopicTokens sets search tokens of topic, leaving updated_at alone.
+UPDATE topics SET name_tokens = $2, description_tokens = $3 WHERE id = $1;
+
+-- name: UpdateMessageGroupTokens :exec
+-- UpdateMessageGroupTokens sets search tokens of message group, leaving updated_at alone.
+UPDATE message_groups SET text_tokens = $2 WHERE id = $1;
+
+-- name: UpdateAnswerTokens :exec
+-- UpdateAnswerTokens sets search tokens of answer, leaving updated_at alone.
+UPDATE answers SET text_tokens = $2 WHERE id = $1;
+
+-- name: LockRateLimit :one
+-- LockRateLimit creates the bucket if missing, and returns it locked until the transaction ends.
+INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+RETURNING *;
+
+-- name: UpdateRateLimit :exec
+UPDATE rate_limits SET tokens = $2, updated_at = $3 WHERE key = $1;
+
+-- name: DeleteRateLimitsBefore :execrows
+-- DeleteRateLimitsBefore deletes buckets last updated before updated_at.
+-- Buckets being taken from are locked and updated, so they are never deleted.
+DELETE FROM rate_limits WHERE updated_at < $1;
+
+-- name: CreateLINEWebhookEvent :execrows
+-- CreateLINEWebhookEvent records webhook event, and affects no rows if it was already recorded.
+INSERT INTO line_webhook_events (webhook_event_id, received_at) VALUES ($1, $2)
+ON CONFLICT (webhook_event_id) DO NOTHING;
+
+-- name: DeleteLINEWebhookEventsBefore :execrows
+DELETE FROM line_webhook_events WHERE received_at < $1;
@@ -15,7 +15,7 @@ const assignMessageGroupToTopic = `-- name: AssignMessageGroupToTopic :one
 UPDATE message_groups SET
     topic_id = $2,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at
+WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens
 `
 
 type AssignMessageGroupToTopicParams struct {
@@ -35,6 +35,11 @@ func (q *Queries) AssignMessageGroupToTopic(ctx context.Context, arg AssignMessa
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
 	)
 	return i, err
 }
@@ -101,6 +106,80 @@ func (q *Queries) AssignMessageV2ToTopic(ctx context.Context, arg AssignMessageV
 	return i, err
 }
 
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+UPDATE outbox SET claimed_until = $1
+WHERE seq IN (
+    SELECT o.seq FROM outbox o
+    WHERE o.published_at IS NULL
+    AND o.parked_at IS NULL
+    AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= $2)
+    AND (o.claimed_until IS NULL OR o.claimed_until <= $2)
+    ORDER BY o.seq ASC
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING seq, id, type, subject_id, payload, attempts, last_error, created_at, published_at, claimed_until, next_attempt_at, parked_at
+`
+
+type ClaimOutboxEventsParams struct {
+	ClaimedUntil pgtype.Timestamptz `json:"claimed_until"`
+	Now          pgtype.Timestamptz `json:"now"`
+	Limit        int32              `json:"limit"`
+}
+
+// ClaimOutboxEvents leases oldest due events until claimed_until, so that other relays skip them
+// while they are published outside of transactions. Rows are returned in no particular order.
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.ClaimedUntil, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.Type,
+			&i.SubjectID,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+			&i.ClaimedUntil,
+			&i.NextAttemptAt,
+			&i.ParkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countTopicGroupsAndMessages = `-- name: CountTopicGroupsAndMessages :one
+SELECT
+    (SELECT COUNT(*) FROM message_groups WHERE message_groups.topic_id = $1) AS groups,
+    (SELECT COUNT(*) FROM messages_v2 WHERE messages_v2.topic_id = $1) AS messages
+`
+
+type CountTopicGroupsAndMessagesRow struct {
+	Groups   int64 `json:"groups"`
+	Messages int64 `json:"messages"`
+}
+
+func (q *Queries) CountTopicGroupsAndMessages(ctx context.Context, topicID pgtype.UUID) (CountTopicGroupsAndMessagesRow, error) {
+	row := q.db.QueryRow(ctx, countTopicGroupsAndMessages, topicID)
+	var i CountTopicGroupsAndMessagesRow
+	err := row.Scan(&i.Groups, &i.Messages)
+	return i, err
+}
+
 const countTopicsByStatus = `-- name: CountTopicsByStatus :one
 SELECT COUNT(*) FROM topics WHERE status = $1
 `
@@ -131,12 +210,17 @@ WHERE 1=1
         )
         ELSE true
     END
+    AND CASE
+        WHEN array_length($3::text[], 1) > 0 THEN m.language = ANY($3::text[])
+        ELSE true
+    END
 GROUP BY t.status
 `
 
 type CountTopicsGroupByStatusDynamicV2Params struct {
-	Column1 string `json:"column_1"`
-	Column2 string `json:"column_2"`
+	Column1 string   `json:"column_1"`
+	Column2 string   `json:"column_2"`
+	Column3 []string `json:"column_3"`
 }
 
 type CountTopicsGroupByStatusDynamicV2Row struct {
@@ -145,7 +229,7 @@ type CountTopicsGroupByStatusDynamicV2Row struct {
 }
 
 func (q *Queries) CountTopicsGroupByStatusDynamicV2(ctx context.Context, arg CountTopicsGroupByStatusDynamicV2Params) ([]CountTopicsGroupByStatusDynamicV2Row, error) {
-	rows, err := q.db.Query(ctx, countTopicsGroupByStatusDynamicV2, arg.Column1, arg.Column2)
+	rows, err := q.db.Query(ctx, countTopicsGroupByStatusDynamicV2, arg.Column1, arg.Column2, arg.Column3)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +248,63 @@ func (q *Queries) CountTopicsGroupByStatusDynamicV2(ctx context.Context, arg Cou
 	return items, nil
 }
 
+const countTopicsGroupByVerdictDynamicV2 = `-- name: CountTopicsGroupByVerdictDynamicV2 :many
+SELECT t.result_status, COUNT(DISTINCT t.id) as count
+FROM topics t
+LEFT JOIN message_groups m ON t.id = m.topic_id
+WHERE t.result_status IS NOT NULL
+    AND CASE
+        WHEN $1::text != '' THEN t.id::text LIKE $1::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $2::text != '' THEN (
+            CASE
+                WHEN m.language = 'th' THEN m.text LIKE $2::text COLLATE "C"
+                WHEN m.language = 'en' THEN m.text ILIKE $2::text
+                ELSE m.text ILIKE $2::text  -- fallback for unknown language
+            END
+        )
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($3::text[], 1) > 0 THEN m.language = ANY($3::text[])
+        ELSE true
+    END
+GROUP BY t.result_status
+`
+
+type CountTopicsGroupByVerdictDynamicV2Params struct {
+	Column1 string   `json:"column_1"`
+	Column2 string   `json:"column_2"`
+	Column3 []string `json:"column_3"`
+}
+
+type CountTopicsGroupByVerdictDynamicV2Row struct {
+	ResultStatus pgtype.Text `json:"result_status"`
+	Count        int64       `json:"count"`
+}
+
+func (q *Queries) CountTopicsGroupByVerdictDynamicV2(ctx context.Context, arg CountTopicsGroupByVerdictDynamicV2Params) ([]CountTopicsGroupByVerdictDynamicV2Row, error) {
+	rows, err := q.db.Query(ctx, countTopicsGroupByVerdictDynamicV2, arg.Column1, arg.Column2, arg.Column3)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTopicsGroupByVerdictDynamicV2Row
+	for rows.Next() {
+		var i CountTopicsGroupByVerdictDynamicV2Row
+		if err := rows.Scan(&i.ResultStatus, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countTopicsGroupedByStatus = `-- name: CountTopicsGroupedByStatus :many
 SELECT status, COUNT(*) as count
 FROM topics
@@ -197,27 +338,39 @@ func (q *Queries) CountTopicsGroupedByStatus(ctx context.Context) ([]CountTopics
 
 const createAnswer = `-- name: CreateAnswer :one
 INSERT INTO answers (
-    id, topic_id, text, created_at, updated_at
+    id, topic_id, text, verdict, created_at, updated_at, text_tokens, status, user_id, corrects_id, revision
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, topic_id, text, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+    (SELECT COALESCE(MAX(revision), 0) + 1 FROM answers WHERE topic_id = $2)
+) RETURNING id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id
 `
 
 type CreateAnswerParams struct {
-	ID        pgtype.UUID        `json:"id"`
-	TopicID   pgtype.UUID        `json:"topic_id"`
-	Text      string             `json:"text"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID         pgtype.UUID        `json:"id"`
+	TopicID    pgtype.UUID        `json:"topic_id"`
+	Text       string             `json:"text"`
+	Verdict    pgtype.Text        `json:"verdict"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	TextTokens string             `json:"text_tokens"`
+	Status     string             `json:"status"`
+	UserID     string             `json:"user_id"`
+	CorrectsID pgtype.UUID        `json:"corrects_id"`
 }
 
+// CreateAnswer creates answer as the next revision of its topic.
 func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Answer, error) {
 	row := q.db.QueryRow(ctx, createAnswer,
 		arg.ID,
 		arg.TopicID,
 		arg.Text,
+		arg.Verdict,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.TextTokens,
+		arg.Status,
+		arg.UserID,
+		arg.CorrectsID,
 	)
 	var i Answer
 	err := row.Scan(
@@ -226,27 +379,223 @@ func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Ans
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
 	)
 	return i, err
 }
 
-const createMessageGroup = `-- name: CreateMessageGroup :one
-INSERT INTO message_groups (
-    id, topic_id, name, text, text_sha1, language, created_at, updated_at
+const createAnswerSource = `-- name: CreateAnswerSource :one
+INSERT INTO answer_sources (
+    id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at
+    $1,
+    $2,
+    $3,
+    COALESCE(
+        NULLIF($4::integer, 0),
+        (SELECT COALESCE(MAX(position), 0) + 1 FROM answer_sources WHERE answer_id = $2)
+    ),
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+) RETURNING id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at, updated_at
 `
 
-type CreateMessageGroupParams struct {
+type CreateAnswerSourceParams struct {
+	ID            pgtype.UUID        `json:"id"`
+	AnswerID      pgtype.UUID        `json:"answer_id"`
+	Type          string             `json:"type"`
+	Position      int32              `json:"position"`
+	Url           string             `json:"url"`
+	Publisher     string             `json:"publisher"`
+	ArchiveUrl    string             `json:"archive_url"`
+	Quote         string             `json:"quote"`
+	AttachmentUrl string             `json:"attachment_url"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+// CreateAnswerSource appends the source after existing sources of the answer if position is 0.
+func (q *Queries) CreateAnswerSource(ctx context.Context, arg CreateAnswerSourceParams) (AnswerSource, error) {
+	row := q.db.QueryRow(ctx, createAnswerSource,
+		arg.ID,
+		arg.AnswerID,
+		arg.Type,
+		arg.Position,
+		arg.Url,
+		arg.Publisher,
+		arg.ArchiveUrl,
+		arg.Quote,
+		arg.AttachmentUrl,
+		arg.CreatedAt,
+	)
+	var i AnswerSource
+	err := row.Scan(
+		&i.ID,
+		&i.AnswerID,
+		&i.Type,
+		&i.Position,
+		&i.Url,
+		&i.Publisher,
+		&i.ArchiveUrl,
+		&i.Quote,
+		&i.AttachmentUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    id, actor_type, actor_id, action, target_type, target_ids, data_before, data_after, request_id, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, actor_type, actor_id, action, target_type, target_ids, data_before, data_after, request_id, created_at
+`
+
+type CreateAuditEventParams struct {
+	ID         pgtype.UUID        `json:"id"`
+	ActorType  string             `json:"actor_type"`
+	ActorID    string             `json:"actor_id"`
+	Action     string             `json:"action"`
+	TargetType string             `json:"target_type"`
+	TargetIds  []string           `json:"target_ids"`
+	DataBefore []byte             `json:"data_before"`
+	DataAfter  []byte             `json:"data_after"`
+	RequestID  pgtype.Text        `json:"request_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRow(ctx, createAuditEvent,
+		arg.ID,
+		arg.ActorType,
+		arg.ActorID,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetIds,
+		arg.DataBefore,
+		arg.DataAfter,
+		arg.RequestID,
+		arg.CreatedAt,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ActorType,
+		&i.ActorID,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetIds,
+		&i.DataBefore,
+		&i.DataAfter,
+		&i.RequestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createDelivery = `-- name: CreateDelivery :one
+INSERT INTO deliveries (
+    id, topic_id, answer_id, user_id, type_user, chat_id, sender, status, text, error, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, topic_id, answer_id, user_id, type_user, chat_id, sender, status, text, error, created_at
+`
+
+type CreateDeliveryParams struct {
 	ID        pgtype.UUID        `json:"id"`
 	TopicID   pgtype.UUID        `json:"topic_id"`
-	Name      string             `json:"name"`
+	AnswerID  pgtype.UUID        `json:"answer_id"`
+	UserID    string             `json:"user_id"`
+	TypeUser  string             `json:"type_user"`
+	ChatID    string             `json:"chat_id"`
+	Sender    string             `json:"sender"`
+	Status    string             `json:"status"`
 	Text      string             `json:"text"`
-	TextSha1  string             `json:"text_sha1"`
-	Language  pgtype.Text        `json:"language"`
+	Error     pgtype.Text        `json:"error"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) CreateDelivery(ctx context.Context, arg CreateDeliveryParams) (Delivery, error) {
+	row := q.db.QueryRow(ctx, createDelivery,
+		arg.ID,
+		arg.TopicID,
+		arg.AnswerID,
+		arg.UserID,
+		arg.TypeUser,
+		arg.ChatID,
+		arg.Sender,
+		arg.Status,
+		arg.Text,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	var i Delivery
+	err := row.Scan(
+		&i.ID,
+		&i.TopicID,
+		&i.AnswerID,
+		&i.UserID,
+		&i.TypeUser,
+		&i.ChatID,
+		&i.Sender,
+		&i.Status,
+		&i.Text,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLINEWebhookEvent = `-- name: CreateLINEWebhookEvent :execrows
+INSERT INTO line_webhook_events (webhook_event_id, received_at) VALUES ($1, $2)
+ON CONFLICT (webhook_event_id) DO NOTHING
+`
+
+type CreateLINEWebhookEventParams struct {
+	WebhookEventID string             `json:"webhook_event_id"`
+	ReceivedAt     pgtype.Timestamptz `json:"received_at"`
+}
+
+// CreateLINEWebhookEvent records webhook event, and affects no rows if it was already recorded.
+func (q *Queries) CreateLINEWebhookEvent(ctx context.Context, arg CreateLINEWebhookEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createLINEWebhookEvent, arg.WebhookEventID, arg.ReceivedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createMessageGroup = `-- name: CreateMessageGroup :one
+INSERT INTO message_groups (
+    id, topic_id, name, text, text_sha1, text_simhash, language, status, preview, created_at, updated_at, text_tokens
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens
+`
+
+type CreateMessageGroupParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	TopicID     pgtype.UUID        `json:"topic_id"`
+	Name        string             `json:"name"`
+	Text        string             `json:"text"`
+	TextSha1    string             `json:"text_sha1"`
+	TextSimhash pgtype.Int8        `json:"text_simhash"`
+	Language    pgtype.Text        `json:"language"`
+	Status      string             `json:"status"`
+	Preview     []byte             `json:"preview"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	TextTokens  string             `json:"text_tokens"`
 }
 
 func (q *Queries) CreateMessageGroup(ctx context.Context, arg CreateMessageGroupParams) (MessageGroup, error) {
@@ -256,9 +605,13 @@ func (q *Queries) CreateMessageGroup(ctx context.Context, arg CreateMessageGroup
 		arg.Name,
 		arg.Text,
 		arg.TextSha1,
+		arg.TextSimhash,
 		arg.Language,
+		arg.Status,
+		arg.Preview,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.TextTokens,
 	)
 	var i MessageGroup
 	err := row.Scan(
@@ -270,6 +623,11 @@ func (q *Queries) CreateMessageGroup(ctx context.Context, arg CreateMessageGroup
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
 	)
 	return i, err
 }
@@ -327,23 +685,67 @@ func (q *Queries) CreateMessageV2(ctx context.Context, arg CreateMessageV2Params
 	return i, err
 }
 
+const createOutboxEvent = `-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    id, type, subject_id, payload, created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING seq, id, type, subject_id, payload, attempts, last_error, created_at, published_at, claimed_until, next_attempt_at, parked_at
+`
+
+type CreateOutboxEventParams struct {
+	ID        pgtype.UUID        `json:"id"`
+	Type      string             `json:"type"`
+	SubjectID string             `json:"subject_id"`
+	Payload   []byte             `json:"payload"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error) {
+	row := q.db.QueryRow(ctx, createOutboxEvent,
+		arg.ID,
+		arg.Type,
+		arg.SubjectID,
+		arg.Payload,
+		arg.CreatedAt,
+	)
+	var i Outbox
+	err := row.Scan(
+		&i.Seq,
+		&i.ID,
+		&i.Type,
+		&i.SubjectID,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.PublishedAt,
+		&i.ClaimedUntil,
+		&i.NextAttemptAt,
+		&i.ParkedAt,
+	)
+	return i, err
+}
+
 const createTopic = `-- name: CreateTopic :one
 INSERT INTO topics (
-    id, name, description, status, result, result_status, created_at, updated_at
+    id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING id, name, description, status, result, result_status, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
 `
 
 type CreateTopicParams struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Status       string             `json:"status"`
-	Result       pgtype.Text        `json:"result"`
-	ResultStatus pgtype.Text        `json:"result_status"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ID                pgtype.UUID        `json:"id"`
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	Status            string             `json:"status"`
+	Result            pgtype.Text        `json:"result"`
+	ResultStatus      pgtype.Text        `json:"result_status"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	NameTokens        string             `json:"name_tokens"`
+	DescriptionTokens string             `json:"description_tokens"`
 }
 
 func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic, error) {
@@ -356,6 +758,8 @@ func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic
 		arg.ResultStatus,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.NameTokens,
+		arg.DescriptionTokens,
 	)
 	var i Topic
 	err := row.Scan(
@@ -367,10 +771,34 @@ func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
 	)
 	return i, err
 }
 
+const createTopicRedirect = `-- name: CreateTopicRedirect :one
+INSERT INTO topic_redirects (
+    from_id, to_id, created_at
+) VALUES (
+    $1, $2, $3
+) RETURNING from_id, to_id, created_at
+`
+
+type CreateTopicRedirectParams struct {
+	FromID    pgtype.UUID        `json:"from_id"`
+	ToID      pgtype.UUID        `json:"to_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) CreateTopicRedirect(ctx context.Context, arg CreateTopicRedirectParams) (TopicRedirect, error) {
+	row := q.db.QueryRow(ctx, createTopicRedirect, arg.FromID, arg.ToID, arg.CreatedAt)
+	var i TopicRedirect
+	err := row.Scan(&i.FromID, &i.ToID, &i.CreatedAt)
+	return i, err
+}
+
 const deleteAnswer = `-- name: DeleteAnswer :exec
 DELETE FROM answers WHERE id = $1
 `
@@ -380,6 +808,52 @@ func (q *Queries) DeleteAnswer(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteAnswerSource = `-- name: DeleteAnswerSource :execrows
+DELETE FROM answer_sources WHERE id = $1
+`
+
+func (q *Queries) DeleteAnswerSource(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAnswerSource, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDuplicateMessageGroups = `-- name: DeleteDuplicateMessageGroups :execrows
+DELETE FROM message_groups source
+USING message_groups target
+WHERE source.topic_id = $1
+    AND target.topic_id = $2
+    AND target.text_sha1 = source.text_sha1
+`
+
+type DeleteDuplicateMessageGroupsParams struct {
+	FromID pgtype.UUID `json:"from_id"`
+	ToID   pgtype.UUID `json:"to_id"`
+}
+
+// DeleteDuplicateMessageGroups deletes groups of topic from_id if topic to_id has groups with identical text.
+func (q *Queries) DeleteDuplicateMessageGroups(ctx context.Context, arg DeleteDuplicateMessageGroupsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDuplicateMessageGroups, arg.FromID, arg.ToID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLINEWebhookEventsBefore = `-- name: DeleteLINEWebhookEventsBefore :execrows
+DELETE FROM line_webhook_events WHERE received_at < $1
+`
+
+func (q *Queries) DeleteLINEWebhookEventsBefore(ctx context.Context, receivedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLINEWebhookEventsBefore, receivedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteMessageGroup = `-- name: DeleteMessageGroup :exec
 DELETE FROM message_groups WHERE id = $1
 `
@@ -398,6 +872,20 @@ func (q *Queries) DeleteMessageV2(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteRateLimitsBefore = `-- name: DeleteRateLimitsBefore :execrows
+DELETE FROM rate_limits WHERE updated_at < $1
+`
+
+// DeleteRateLimitsBefore deletes buckets last updated before updated_at.
+// Buckets being taken from are locked and updated, so they are never deleted.
+func (q *Queries) DeleteRateLimitsBefore(ctx context.Context, updatedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRateLimitsBefore, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteTopic = `-- name: DeleteTopic :exec
 DELETE FROM topics WHERE id = $1
 `
@@ -407,8 +895,20 @@ func (q *Queries) DeleteTopic(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteUserRole = `-- name: DeleteUserRole :execrows
+DELETE FROM user_roles WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserRole(ctx context.Context, userID string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserRole, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getAnswerByID = `-- name: GetAnswerByID :one
-SELECT id, topic_id, text, created_at, updated_at FROM answers WHERE id = $1
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers WHERE id = $1
 `
 
 func (q *Queries) GetAnswerByID(ctx context.Context, id pgtype.UUID) (Answer, error) {
@@ -420,16 +920,25 @@ func (q *Queries) GetAnswerByID(ctx context.Context, id pgtype.UUID) (Answer, er
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
 	)
 	return i, err
 }
 
 const getAnswerByTopicID = `-- name: GetAnswerByTopicID :one
-SELECT id, topic_id, text, created_at, updated_at FROM answers WHERE topic_id = $1 ORDER BY created_at DESC LIMIT 1
+SELECT a.id, a.topic_id, a.text, a.created_at, a.updated_at, a.verdict, a.text_tokens, a.revision, a.status, a.user_id, a.corrects_id FROM answers a
+JOIN topics t ON t.answer_id = a.id
+WHERE t.id = $1
 `
 
-func (q *Queries) GetAnswerByTopicID(ctx context.Context, topicID pgtype.UUID) (Answer, error) {
-	row := q.db.QueryRow(ctx, getAnswerByTopicID, topicID)
+// GetAnswerByTopicID gets the published answer of topic.
+func (q *Queries) GetAnswerByTopicID(ctx context.Context, id pgtype.UUID) (Answer, error) {
+	row := q.db.QueryRow(ctx, getAnswerByTopicID, id)
 	var i Answer
 	err := row.Scan(
 		&i.ID,
@@ -437,35 +946,97 @@ func (q *Queries) GetAnswerByTopicID(ctx context.Context, topicID pgtype.UUID) (
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
 	)
 	return i, err
 }
 
-const getMessageGroup = `-- name: GetMessageGroup :one
-SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at FROM message_groups WHERE id = $1
+const getAnswerRevision = `-- name: GetAnswerRevision :one
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers WHERE topic_id = $1 AND revision = $2
 `
 
-func (q *Queries) GetMessageGroup(ctx context.Context, id pgtype.UUID) (MessageGroup, error) {
-	row := q.db.QueryRow(ctx, getMessageGroup, id)
-	var i MessageGroup
+type GetAnswerRevisionParams struct {
+	TopicID  pgtype.UUID `json:"topic_id"`
+	Revision int32       `json:"revision"`
+}
+
+func (q *Queries) GetAnswerRevision(ctx context.Context, arg GetAnswerRevisionParams) (Answer, error) {
+	row := q.db.QueryRow(ctx, getAnswerRevision, arg.TopicID, arg.Revision)
+	var i Answer
 	err := row.Scan(
 		&i.ID,
 		&i.TopicID,
-		&i.Name,
 		&i.Text,
-		&i.TextSha1,
-		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
 	)
 	return i, err
 }
 
-const getMessageGroupBySHA1 = `-- name: GetMessageGroupBySHA1 :one
-SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at FROM message_groups WHERE text_sha1 = $1
+const getAnswerSource = `-- name: GetAnswerSource :one
+SELECT id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at, updated_at FROM answer_sources WHERE id = $1
 `
 
-func (q *Queries) GetMessageGroupBySHA1(ctx context.Context, textSha1 string) (MessageGroup, error) {
+func (q *Queries) GetAnswerSource(ctx context.Context, id pgtype.UUID) (AnswerSource, error) {
+	row := q.db.QueryRow(ctx, getAnswerSource, id)
+	var i AnswerSource
+	err := row.Scan(
+		&i.ID,
+		&i.AnswerID,
+		&i.Type,
+		&i.Position,
+		&i.Url,
+		&i.Publisher,
+		&i.ArchiveUrl,
+		&i.Quote,
+		&i.AttachmentUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMessageGroup = `-- name: GetMessageGroup :one
+SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens FROM message_groups WHERE id = $1
+`
+
+func (q *Queries) GetMessageGroup(ctx context.Context, id pgtype.UUID) (MessageGroup, error) {
+	row := q.db.QueryRow(ctx, getMessageGroup, id)
+	var i MessageGroup
+	err := row.Scan(
+		&i.ID,
+		&i.TopicID,
+		&i.Name,
+		&i.Text,
+		&i.TextSha1,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
+	)
+	return i, err
+}
+
+const getMessageGroupBySHA1 = `-- name: GetMessageGroupBySHA1 :one
+SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens FROM message_groups WHERE text_sha1 = $1
+`
+
+func (q *Queries) GetMessageGroupBySHA1(ctx context.Context, textSha1 string) (MessageGroup, error) {
 	row := q.db.QueryRow(ctx, getMessageGroupBySHA1, textSha1)
 	var i MessageGroup
 	err := row.Scan(
@@ -477,6 +1048,11 @@ func (q *Queries) GetMessageGroupBySHA1(ctx context.Context, textSha1 string) (M
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
 	)
 	return i, err
 }
@@ -504,8 +1080,19 @@ func (q *Queries) GetMessageV2(ctx context.Context, id pgtype.UUID) (MessagesV2,
 	return i, err
 }
 
+const getOutboxLastSeq = `-- name: GetOutboxLastSeq :one
+SELECT COALESCE(MAX(seq), 0)::bigint AS seq FROM outbox
+`
+
+func (q *Queries) GetOutboxLastSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getOutboxLastSeq)
+	var seq int64
+	err := row.Scan(&seq)
+	return seq, err
+}
+
 const getTopic = `-- name: GetTopic :one
-SELECT id, name, description, status, result, result_status, created_at, updated_at FROM topics WHERE id = $1
+SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id FROM topics WHERE id = $1
 `
 
 func (q *Queries) GetTopic(ctx context.Context, id pgtype.UUID) (Topic, error) {
@@ -520,6 +1107,34 @@ func (q *Queries) GetTopic(ctx context.Context, id pgtype.UUID) (Topic, error) {
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
+	)
+	return i, err
+}
+
+const getTopicFollowRedirect = `-- name: GetTopicFollowRedirect :one
+SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id FROM topics
+WHERE id = COALESCE((SELECT to_id FROM topic_redirects WHERE from_id = $1), $1)
+`
+
+// GetTopicFollowRedirect gets topic by ID, or the topic it was merged into.
+func (q *Queries) GetTopicFollowRedirect(ctx context.Context, id pgtype.UUID) (Topic, error) {
+	row := q.db.QueryRow(ctx, getTopicFollowRedirect, id)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.Result,
+		&i.ResultStatus,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
 	)
 	return i, err
 }
@@ -535,12 +1150,29 @@ func (q *Queries) GetTopicStatus(ctx context.Context, id pgtype.UUID) (string, e
 	return status, err
 }
 
-const listAnswersByTopicID = `-- name: ListAnswersByTopicID :many
-SELECT id, topic_id, text, created_at, updated_at FROM answers WHERE topic_id = $1 ORDER BY created_at DESC
+const getUserRole = `-- name: GetUserRole :one
+SELECT user_id, role, created_at, updated_at FROM user_roles WHERE user_id = $1
 `
 
-func (q *Queries) ListAnswersByTopicID(ctx context.Context, topicID pgtype.UUID) ([]Answer, error) {
-	rows, err := q.db.Query(ctx, listAnswersByTopicID, topicID)
+func (q *Queries) GetUserRole(ctx context.Context, userID string) (UserRole, error) {
+	row := q.db.QueryRow(ctx, getUserRole, userID)
+	var i UserRole
+	err := row.Scan(
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAnswerRevisions = `-- name: ListAnswerRevisions :many
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers WHERE topic_id = $1 ORDER BY revision DESC
+`
+
+// ListAnswerRevisions lists all revisions of answers to topic, including drafts.
+func (q *Queries) ListAnswerRevisions(ctx context.Context, topicID pgtype.UUID) ([]Answer, error) {
+	rows, err := q.db.Query(ctx, listAnswerRevisions, topicID)
 	if err != nil {
 		return nil, err
 	}
@@ -554,6 +1186,12 @@ func (q *Queries) ListAnswersByTopicID(ctx context.Context, topicID pgtype.UUID)
 			&i.Text,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Verdict,
+			&i.TextTokens,
+			&i.Revision,
+			&i.Status,
+			&i.UserID,
+			&i.CorrectsID,
 		); err != nil {
 			return nil, err
 		}
@@ -565,57 +1203,29 @@ func (q *Queries) ListAnswersByTopicID(ctx context.Context, topicID pgtype.UUID)
 	return items, nil
 }
 
-const listMessageGroupDynamic = `-- name: ListMessageGroupDynamic :many
-SELECT  mg.id, mg.topic_id, mg.name, mg.text, mg.text_sha1, mg.language, mg.created_at, mg.updated_at
-FROM message_groups mg
-WHERE 1=1
-    AND CASE
-        WHEN $1::text != '' THEN mg.text::text LIKE $1::text
-        ELSE true
-    END
-    AND CASE
-        WHEN array_length($2::text[], 1) > 0 THEN mg.id = ANY(($2::text[])::uuid[])
-        ELSE true
-    END
-    AND CASE
-        WHEN array_length($3::text[], 1) > 0 THEN NOT (mg.id = ANY(($3::text[])::uuid[]))
-        ELSE true
-    END
-ORDER BY mg.created_at DESC
-LIMIT CASE WHEN $5::integer = 0 THEN NULL ELSE $5::integer END
-OFFSET CASE WHEN $4::integer = 0 THEN 0 ELSE $4::integer END
+const listAnswerSourcesByAnswerID = `-- name: ListAnswerSourcesByAnswerID :many
+SELECT id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at, updated_at FROM answer_sources WHERE answer_id = $1 ORDER BY position ASC, created_at ASC
 `
 
-type ListMessageGroupDynamicParams struct {
-	Text    string   `json:"text"`
-	IDIn    []string `json:"id_in"`
-	IDNotIn []string `json:"id_not_in"`
-	Offset  int32    `json:"offset"`
-	Limit   int32    `json:"limit"`
-}
-
-func (q *Queries) ListMessageGroupDynamic(ctx context.Context, arg ListMessageGroupDynamicParams) ([]MessageGroup, error) {
-	rows, err := q.db.Query(ctx, listMessageGroupDynamic,
-		arg.Text,
-		arg.IDIn,
-		arg.IDNotIn,
-		arg.Offset,
-		arg.Limit,
-	)
+func (q *Queries) ListAnswerSourcesByAnswerID(ctx context.Context, answerID pgtype.UUID) ([]AnswerSource, error) {
+	rows, err := q.db.Query(ctx, listAnswerSourcesByAnswerID, answerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []MessageGroup
+	var items []AnswerSource
 	for rows.Next() {
-		var i MessageGroup
+		var i AnswerSource
 		if err := rows.Scan(
 			&i.ID,
-			&i.TopicID,
-			&i.Name,
-			&i.Text,
-			&i.TextSha1,
-			&i.Language,
+			&i.AnswerID,
+			&i.Type,
+			&i.Position,
+			&i.Url,
+			&i.Publisher,
+			&i.ArchiveUrl,
+			&i.Quote,
+			&i.AttachmentUrl,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -629,28 +1239,37 @@ func (q *Queries) ListMessageGroupDynamic(ctx context.Context, arg ListMessageGr
 	return items, nil
 }
 
-const listMessageGroupsByTopic = `-- name: ListMessageGroupsByTopic :many
-SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at FROM message_groups WHERE topic_id = $1 ORDER BY created_at ASC
+const listAnswersAfterID = `-- name: ListAnswersAfterID :many
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers WHERE id > $1 ORDER BY id LIMIT $2
 `
 
-func (q *Queries) ListMessageGroupsByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessageGroup, error) {
-	rows, err := q.db.Query(ctx, listMessageGroupsByTopic, topicID)
+type ListAnswersAfterIDParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Limit int32       `json:"limit"`
+}
+
+// ListAnswersAfterID lists answers in order of IDs, for batch jobs over all answers.
+func (q *Queries) ListAnswersAfterID(ctx context.Context, arg ListAnswersAfterIDParams) ([]Answer, error) {
+	rows, err := q.db.Query(ctx, listAnswersAfterID, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []MessageGroup
+	var items []Answer
 	for rows.Next() {
-		var i MessageGroup
+		var i Answer
 		if err := rows.Scan(
 			&i.ID,
 			&i.TopicID,
-			&i.Name,
 			&i.Text,
-			&i.TextSha1,
-			&i.Language,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Verdict,
+			&i.TextTokens,
+			&i.Revision,
+			&i.Status,
+			&i.UserID,
+			&i.CorrectsID,
 		); err != nil {
 			return nil, err
 		}
@@ -662,31 +1281,31 @@ func (q *Queries) ListMessageGroupsByTopic(ctx context.Context, topicID pgtype.U
 	return items, nil
 }
 
-const listMessagesV2ByGroup = `-- name: ListMessagesV2ByGroup :many
-SELECT id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at FROM messages_v2 WHERE group_id = $1 ORDER BY created_at ASC
+const listAnswersByTopicID = `-- name: ListAnswersByTopicID :many
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers WHERE topic_id = $1 AND status = 'ANSWER_PUBLISHED' ORDER BY revision DESC
 `
 
-func (q *Queries) ListMessagesV2ByGroup(ctx context.Context, groupID pgtype.UUID) ([]MessagesV2, error) {
-	rows, err := q.db.Query(ctx, listMessagesV2ByGroup, groupID)
+func (q *Queries) ListAnswersByTopicID(ctx context.Context, topicID pgtype.UUID) ([]Answer, error) {
+	rows, err := q.db.Query(ctx, listAnswersByTopicID, topicID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []MessagesV2
+	var items []Answer
 	for rows.Next() {
-		var i MessagesV2
+		var i Answer
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.TopicID,
-			&i.GroupID,
-			&i.TypeUser,
-			&i.Type,
 			&i.Text,
-			&i.Language,
-			&i.Metadata,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Verdict,
+			&i.TextTokens,
+			&i.Revision,
+			&i.Status,
+			&i.UserID,
+			&i.CorrectsID,
 		); err != nil {
 			return nil, err
 		}
@@ -698,31 +1317,60 @@ func (q *Queries) ListMessagesV2ByGroup(ctx context.Context, groupID pgtype.UUID
 	return items, nil
 }
 
-const listMessagesV2ByTopic = `-- name: ListMessagesV2ByTopic :many
-SELECT id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at FROM messages_v2 WHERE topic_id = $1 ORDER BY created_at ASC
+const listAnswersByTopicIDPage = `-- name: ListAnswersByTopicIDPage :many
+SELECT id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id FROM answers
+WHERE topic_id = $1
+    AND status = 'ANSWER_PUBLISHED'
+    AND CASE
+        WHEN $2::timestamptz IS NULL THEN true
+        WHEN $3::boolean THEN (created_at, id) > ($2::timestamptz, $4::uuid)
+        ELSE (created_at, id) < ($2::timestamptz, $4::uuid)
+    END
+ORDER BY
+    CASE WHEN $3::boolean THEN created_at END ASC,
+    CASE WHEN $3::boolean THEN id END ASC,
+    created_at DESC,
+    id DESC
+LIMIT $5::integer
 `
 
-func (q *Queries) ListMessagesV2ByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessagesV2, error) {
-	rows, err := q.db.Query(ctx, listMessagesV2ByTopic, topicID)
+type ListAnswersByTopicIDPageParams struct {
+	TopicID         pgtype.UUID        `json:"topic_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorPrev      bool               `json:"cursor_prev"`
+	CursorID        pgtype.UUID        `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// ListAnswersByTopicIDPage lists answers after the cursor, latest first,
+// or answers before the cursor in reverse order if cursor_prev is true.
+func (q *Queries) ListAnswersByTopicIDPage(ctx context.Context, arg ListAnswersByTopicIDPageParams) ([]Answer, error) {
+	rows, err := q.db.Query(ctx, listAnswersByTopicIDPage,
+		arg.TopicID,
+		arg.CursorCreatedAt,
+		arg.CursorPrev,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []MessagesV2
+	var items []Answer
 	for rows.Next() {
-		var i MessagesV2
+		var i Answer
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.TopicID,
-			&i.GroupID,
-			&i.TypeUser,
-			&i.Type,
 			&i.Text,
-			&i.Language,
-			&i.Metadata,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Verdict,
+			&i.TextTokens,
+			&i.Revision,
+			&i.Status,
+			&i.UserID,
+			&i.CorrectsID,
 		); err != nil {
 			return nil, err
 		}
@@ -734,57 +1382,72 @@ func (q *Queries) ListMessagesV2ByTopic(ctx context.Context, topicID pgtype.UUID
 	return items, nil
 }
 
-const listTopics = `-- name: ListTopics :many
-WITH numbered_topics AS (
-    SELECT id, name, description, status, result, result_status, created_at, updated_at,
-           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
-           COUNT(*) OVER () as total_count
-    FROM topics
-)
-SELECT id, name, description, status, result, result_status, created_at, updated_at
-FROM numbered_topics
-WHERE CASE
-    WHEN $1 = 0 THEN true  -- No pagination
-    WHEN $1 > 0 THEN rn BETWEEN $2 + 1 AND $2 + $1  -- Normal pagination
-    WHEN $1 < 0 THEN rn BETWEEN total_count + $1 + 1 AND total_count + $2  -- Negative pagination
-END
-ORDER BY created_at DESC
+const listAuditEventsDynamic = `-- name: ListAuditEventsDynamic :many
+SELECT id, actor_type, actor_id, action, target_type, target_ids, data_before, data_after, request_id, created_at FROM audit_events
+WHERE 1=1
+    AND CASE
+        WHEN $1::text != '' THEN actor_id = $1::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $2::text != '' THEN target_ids @> ARRAY[$2::text]
+        ELSE true
+    END
+    AND CASE
+        WHEN $3::text != '' THEN action = $3::text
+        ELSE true
+    END
+    AND CASE
+        WHEN $4::timestamptz IS NOT NULL THEN created_at >= $4::timestamptz
+        ELSE true
+    END
+    AND CASE
+        WHEN $5::timestamptz IS NOT NULL THEN created_at < $5::timestamptz
+        ELSE true
+    END
+ORDER BY created_at DESC, id ASC
+LIMIT CASE WHEN $6::integer = 0 THEN NULL ELSE $6::integer END
+OFFSET CASE WHEN $6::integer = 0 THEN 0 ELSE $7::integer END
 `
 
-type ListTopicsParams struct {
-	Column1 interface{} `json:"column_1"`
-	Column2 interface{} `json:"column_2"`
-}
-
-type ListTopicsRow struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Status       string             `json:"status"`
-	Result       pgtype.Text        `json:"result"`
-	ResultStatus pgtype.Text        `json:"result_status"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+type ListAuditEventsDynamicParams struct {
+	Column1 string             `json:"column_1"`
+	Column2 string             `json:"column_2"`
+	Column3 string             `json:"column_3"`
+	Column4 pgtype.Timestamptz `json:"column_4"`
+	Column5 pgtype.Timestamptz `json:"column_5"`
+	Column6 int32              `json:"column_6"`
+	Column7 int32              `json:"column_7"`
 }
 
-func (q *Queries) ListTopics(ctx context.Context, arg ListTopicsParams) ([]ListTopicsRow, error) {
-	rows, err := q.db.Query(ctx, listTopics, arg.Column1, arg.Column2)
+func (q *Queries) ListAuditEventsDynamic(ctx context.Context, arg ListAuditEventsDynamicParams) ([]AuditEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditEventsDynamic,
+		arg.Column1,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+		arg.Column5,
+		arg.Column6,
+		arg.Column7,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListTopicsRow
+	var items []AuditEvent
 	for rows.Next() {
-		var i ListTopicsRow
+		var i AuditEvent
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.Status,
-			&i.Result,
-			&i.ResultStatus,
+			&i.ActorType,
+			&i.ActorID,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetIds,
+			&i.DataBefore,
+			&i.DataAfter,
+			&i.RequestID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -796,59 +1459,836 @@ func (q *Queries) ListTopics(ctx context.Context, arg ListTopicsParams) ([]ListT
 	return items, nil
 }
 
-const listTopicsByStatus = `-- name: ListTopicsByStatus :many
-WITH numbered_topics AS (
-    SELECT id, name, description, status, result, result_status, created_at, updated_at,
-           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
-           COUNT(*) OVER () as total_count
-    FROM topics
-    WHERE status = $1
-)
-SELECT id, name, description, status, result, result_status, created_at, updated_at
-FROM numbered_topics
-WHERE CASE
-    WHEN $2 = 0 THEN true  -- No pagination
-    WHEN $2 > 0 THEN rn BETWEEN $3 + 1 AND $3 + $2  -- Normal pagination
-    WHEN $2 < 0 THEN rn BETWEEN total_count + $2 + 1 AND total_count + $3  -- Negative pagination
-END
-ORDER BY created_at DESC
+const listDeliveriesByAnswerID = `-- name: ListDeliveriesByAnswerID :many
+SELECT id, topic_id, answer_id, user_id, type_user, chat_id, sender, status, text, error, created_at FROM deliveries WHERE answer_id = $1 ORDER BY created_at ASC
 `
 
-type ListTopicsByStatusParams struct {
-	Status  string      `json:"status"`
-	Column2 interface{} `json:"column_2"`
-	Column3 interface{} `json:"column_3"`
-}
-
-type ListTopicsByStatusRow struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Status       string             `json:"status"`
-	Result       pgtype.Text        `json:"result"`
-	ResultStatus pgtype.Text        `json:"result_status"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
-}
-
-func (q *Queries) ListTopicsByStatus(ctx context.Context, arg ListTopicsByStatusParams) ([]ListTopicsByStatusRow, error) {
-	rows, err := q.db.Query(ctx, listTopicsByStatus, arg.Status, arg.Column2, arg.Column3)
+func (q *Queries) ListDeliveriesByAnswerID(ctx context.Context, answerID pgtype.UUID) ([]Delivery, error) {
+	rows, err := q.db.Query(ctx, listDeliveriesByAnswerID, answerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListTopicsByStatusRow
+	var items []Delivery
 	for rows.Next() {
-		var i ListTopicsByStatusRow
+		var i Delivery
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.Status,
+			&i.TopicID,
+			&i.AnswerID,
+			&i.UserID,
+			&i.TypeUser,
+			&i.ChatID,
+			&i.Sender,
+			&i.Status,
+			&i.Text,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeliveriesByTopicID = `-- name: ListDeliveriesByTopicID :many
+SELECT id, topic_id, answer_id, user_id, type_user, chat_id, sender, status, text, error, created_at FROM deliveries WHERE topic_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListDeliveriesByTopicID(ctx context.Context, topicID pgtype.UUID) ([]Delivery, error) {
+	rows, err := q.db.Query(ctx, listDeliveriesByTopicID, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Delivery
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.TopicID,
+			&i.AnswerID,
+			&i.UserID,
+			&i.TypeUser,
+			&i.ChatID,
+			&i.Sender,
+			&i.Status,
+			&i.Text,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageGroupDynamic = `-- name: ListMessageGroupDynamic :many
+SELECT  mg.id, mg.topic_id, mg.name, mg.text, mg.text_sha1, mg.language, mg.created_at, mg.updated_at, mg.status, mg.status_reason, mg.text_simhash, mg.preview, mg.text_tokens
+FROM message_groups mg
+WHERE 1=1
+    AND CASE
+        WHEN $1::text != '' THEN mg.text::text LIKE $1::text
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($2::text[], 1) > 0 THEN mg.id = ANY(($2::text[])::uuid[])
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($3::text[], 1) > 0 THEN NOT (mg.id = ANY(($3::text[])::uuid[]))
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($4::text[], 1) > 0 THEN mg.status = ANY($4::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($5::text[], 1) > 0 THEN mg.language = ANY($5::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN $6::timestamptz IS NULL THEN true
+        WHEN $7::boolean THEN (mg.created_at, mg.id) > ($6::timestamptz, $8::uuid)
+        ELSE (mg.created_at, mg.id) < ($6::timestamptz, $8::uuid)
+    END
+ORDER BY
+    CASE WHEN $7::boolean THEN mg.created_at END ASC,
+    CASE WHEN $7::boolean THEN mg.id END ASC,
+    mg.created_at DESC,
+    mg.id DESC
+LIMIT CASE WHEN $10::integer = 0 THEN NULL ELSE $10::integer END
+OFFSET CASE WHEN $9::integer = 0 THEN 0 ELSE $9::integer END
+`
+
+type ListMessageGroupDynamicParams struct {
+	Text            string             `json:"text"`
+	IDIn            []string           `json:"id_in"`
+	IDNotIn         []string           `json:"id_not_in"`
+	Statuses        []string           `json:"statuses"`
+	Languages       []string           `json:"languages"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorPrev      bool               `json:"cursor_prev"`
+	CursorID        pgtype.UUID        `json:"cursor_id"`
+	Offset          int32              `json:"offset"`
+	Limit           int32              `json:"limit"`
+}
+
+func (q *Queries) ListMessageGroupDynamic(ctx context.Context, arg ListMessageGroupDynamicParams) ([]MessageGroup, error) {
+	rows, err := q.db.Query(ctx, listMessageGroupDynamic,
+		arg.Text,
+		arg.IDIn,
+		arg.IDNotIn,
+		arg.Statuses,
+		arg.Languages,
+		arg.CursorCreatedAt,
+		arg.CursorPrev,
+		arg.CursorID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageGroup
+	for rows.Next() {
+		var i MessageGroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.TopicID,
+			&i.Name,
+			&i.Text,
+			&i.TextSha1,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.TextSimhash,
+			&i.Preview,
+			&i.TextTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageGroupsAfterID = `-- name: ListMessageGroupsAfterID :many
+SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens FROM message_groups WHERE id > $1 ORDER BY id LIMIT $2
+`
+
+type ListMessageGroupsAfterIDParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Limit int32       `json:"limit"`
+}
+
+// ListMessageGroupsAfterID lists message groups in order of IDs, for batch jobs over all groups.
+func (q *Queries) ListMessageGroupsAfterID(ctx context.Context, arg ListMessageGroupsAfterIDParams) ([]MessageGroup, error) {
+	rows, err := q.db.Query(ctx, listMessageGroupsAfterID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageGroup
+	for rows.Next() {
+		var i MessageGroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.TopicID,
+			&i.Name,
+			&i.Text,
+			&i.TextSha1,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.TextSimhash,
+			&i.Preview,
+			&i.TextTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessageGroupsByTopic = `-- name: ListMessageGroupsByTopic :many
+SELECT id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens FROM message_groups WHERE topic_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListMessageGroupsByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessageGroup, error) {
+	rows, err := q.db.Query(ctx, listMessageGroupsByTopic, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageGroup
+	for rows.Next() {
+		var i MessageGroup
+		if err := rows.Scan(
+			&i.ID,
+			&i.TopicID,
+			&i.Name,
+			&i.Text,
+			&i.TextSha1,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.TextSimhash,
+			&i.Preview,
+			&i.TextTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesV2ByGroup = `-- name: ListMessagesV2ByGroup :many
+SELECT id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at FROM messages_v2 WHERE group_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListMessagesV2ByGroup(ctx context.Context, groupID pgtype.UUID) ([]MessagesV2, error) {
+	rows, err := q.db.Query(ctx, listMessagesV2ByGroup, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessagesV2
+	for rows.Next() {
+		var i MessagesV2
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TopicID,
+			&i.GroupID,
+			&i.TypeUser,
+			&i.Type,
+			&i.Text,
+			&i.Language,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesV2ByTopic = `-- name: ListMessagesV2ByTopic :many
+SELECT id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at FROM messages_v2 WHERE topic_id = $1 ORDER BY created_at ASC
+`
+
+func (q *Queries) ListMessagesV2ByTopic(ctx context.Context, topicID pgtype.UUID) ([]MessagesV2, error) {
+	rows, err := q.db.Query(ctx, listMessagesV2ByTopic, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessagesV2
+	for rows.Next() {
+		var i MessagesV2
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TopicID,
+			&i.GroupID,
+			&i.TypeUser,
+			&i.Type,
+			&i.Text,
+			&i.Language,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesV2ByTopicPage = `-- name: ListMessagesV2ByTopicPage :many
+SELECT id, user_id, topic_id, group_id, type_user, type, text, language, metadata, created_at, updated_at FROM messages_v2
+WHERE topic_id = $1
+    AND CASE
+        WHEN $2::timestamptz IS NULL THEN true
+        WHEN $3::boolean THEN (created_at, id) < ($2::timestamptz, $4::uuid)
+        ELSE (created_at, id) > ($2::timestamptz, $4::uuid)
+    END
+ORDER BY
+    CASE WHEN $3::boolean THEN created_at END DESC,
+    CASE WHEN $3::boolean THEN id END DESC,
+    created_at ASC,
+    id ASC
+LIMIT $5::integer
+`
+
+type ListMessagesV2ByTopicPageParams struct {
+	TopicID         pgtype.UUID        `json:"topic_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorPrev      bool               `json:"cursor_prev"`
+	CursorID        pgtype.UUID        `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// ListMessagesV2ByTopicPage lists messages after the cursor, oldest first,
+// or messages before the cursor in reverse order if cursor_prev is true.
+func (q *Queries) ListMessagesV2ByTopicPage(ctx context.Context, arg ListMessagesV2ByTopicPageParams) ([]MessagesV2, error) {
+	rows, err := q.db.Query(ctx, listMessagesV2ByTopicPage,
+		arg.TopicID,
+		arg.CursorCreatedAt,
+		arg.CursorPrev,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessagesV2
+	for rows.Next() {
+		var i MessagesV2
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TopicID,
+			&i.GroupID,
+			&i.TypeUser,
+			&i.Type,
+			&i.Text,
+			&i.Language,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxAfter = `-- name: ListOutboxAfter :many
+SELECT seq, id, type, subject_id, payload, attempts, last_error, created_at, published_at, claimed_until, next_attempt_at, parked_at FROM outbox
+WHERE seq > $1
+ORDER BY seq ASC
+LIMIT $2
+`
+
+type ListOutboxAfterParams struct {
+	Seq   int64 `json:"seq"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListOutboxAfter(ctx context.Context, arg ListOutboxAfterParams) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listOutboxAfter, arg.Seq, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.Type,
+			&i.SubjectID,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+			&i.ClaimedUntil,
+			&i.NextAttemptAt,
+			&i.ParkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxBySeqs = `-- name: ListOutboxBySeqs :many
+SELECT seq, id, type, subject_id, payload, attempts, last_error, created_at, published_at, claimed_until, next_attempt_at, parked_at FROM outbox
+WHERE seq = ANY($1::bigint[])
+ORDER BY seq ASC
+`
+
+func (q *Queries) ListOutboxBySeqs(ctx context.Context, seqs []int64) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listOutboxBySeqs, seqs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.Type,
+			&i.SubjectID,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+			&i.ClaimedUntil,
+			&i.NextAttemptAt,
+			&i.ParkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxSinksPublished = `-- name: ListOutboxSinksPublished :many
+SELECT sink FROM outbox_sinks WHERE event_id = $1 AND published_at IS NOT NULL
+`
+
+func (q *Queries) ListOutboxSinksPublished(ctx context.Context, eventID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, listOutboxSinksPublished, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var sink string
+		if err := rows.Scan(&sink); err != nil {
+			return nil, err
+		}
+		items = append(items, sink)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSearchResults = `-- name: ListSearchResults :many
+SELECT results.type, results.id, results.topic_id, results.title, results.text, results.rank, results.created_at
+FROM (
+    SELECT
+        'SEARCH_TOPIC'::text AS type,
+        t.id,
+        t.id AS topic_id,
+        t.name AS title,
+        t.description AS text,
+        ts_rank(
+            setweight(array_to_tsvector(string_to_array(t.name_tokens, ' ')), 'A') ||
+            setweight(array_to_tsvector(string_to_array(t.description_tokens, ' ')), 'B'),
+            $1::text::tsquery
+        ) AS rank,
+        t.created_at
+    FROM topics t
+    WHERE 'SEARCH_TOPIC' = ANY($2::text[])
+        AND (
+            setweight(array_to_tsvector(string_to_array(t.name_tokens, ' ')), 'A') ||
+            setweight(array_to_tsvector(string_to_array(t.description_tokens, ' ')), 'B')
+        ) @@ $1::text::tsquery
+
+    UNION ALL
+
+    SELECT
+        'SEARCH_MESSAGE_GROUP'::text AS type,
+        mg.id,
+        mg.topic_id,
+        mg.name AS title,
+        mg.text,
+        ts_rank(array_to_tsvector(string_to_array(mg.text_tokens, ' ')), $1::text::tsquery) AS rank,
+        mg.created_at
+    FROM message_groups mg
+    WHERE 'SEARCH_MESSAGE_GROUP' = ANY($2::text[])
+        AND mg.status = 'MGROUP_APPROVED'
+        AND array_to_tsvector(string_to_array(mg.text_tokens, ' ')) @@ $1::text::tsquery
+
+    UNION ALL
+
+    SELECT
+        'SEARCH_ANSWER'::text AS type,
+        a.id,
+        a.topic_id,
+        ''::text AS title,
+        a.text,
+        ts_rank(array_to_tsvector(string_to_array(a.text_tokens, ' ')), $1::text::tsquery) AS rank,
+        a.created_at
+    FROM answers a
+    WHERE 'SEARCH_ANSWER' = ANY($2::text[])
+        AND a.status = 'ANSWER_PUBLISHED'
+        AND array_to_tsvector(string_to_array(a.text_tokens, ' ')) @@ $1::text::tsquery
+) AS results
+ORDER BY results.rank DESC, results.created_at DESC, results.id
+LIMIT CASE WHEN $4::integer = 0 THEN NULL ELSE $4::integer END
+OFFSET CASE WHEN $3::integer = 0 THEN 0 ELSE $3::integer END
+`
+
+type ListSearchResultsParams struct {
+	Query  string   `json:"query"`
+	Types  []string `json:"types"`
+	Offset int32    `json:"offset"`
+	Limit  int32    `json:"limit"`
+}
+
+type ListSearchResultsRow struct {
+	Type      string             `json:"type"`
+	ID        pgtype.UUID        `json:"id"`
+	TopicID   pgtype.UUID        `json:"topic_id"`
+	Title     string             `json:"title"`
+	Text      string             `json:"text"`
+	Rank      float32            `json:"rank"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// ListSearchResults ranks topics, approved message groups and published answers matching tsquery from package search.
+// The tsvector expressions must match the GIN indexes in migrations.
+func (q *Queries) ListSearchResults(ctx context.Context, arg ListSearchResultsParams) ([]ListSearchResultsRow, error) {
+	rows, err := q.db.Query(ctx, listSearchResults,
+		arg.Query,
+		arg.Types,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSearchResultsRow
+	for rows.Next() {
+		var i ListSearchResultsRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.ID,
+			&i.TopicID,
+			&i.Title,
+			&i.Text,
+			&i.Rank,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSimilarMessageGroups = `-- name: ListSimilarMessageGroups :many
+SELECT mg.id, mg.topic_id, mg.name, mg.text, mg.text_sha1, mg.language, mg.created_at, mg.updated_at, mg.status, mg.status_reason, mg.text_simhash, mg.preview, mg.text_tokens, bit_count((mg.text_simhash # $1::bigint)::bit(64))::integer AS distance
+FROM message_groups mg
+WHERE mg.created_at > $2::timestamptz
+    AND mg.status != 'MGROUP_REJECTED'
+    AND mg.text_simhash IS NOT NULL
+    AND bit_count((mg.text_simhash # $1::bigint)::bit(64)) <= $3::integer
+ORDER BY distance ASC, mg.created_at ASC
+LIMIT $4::integer
+`
+
+type ListSimilarMessageGroupsParams struct {
+	Simhash      int64              `json:"simhash"`
+	CreatedAfter pgtype.Timestamptz `json:"created_after"`
+	MaxDistance  int32              `json:"max_distance"`
+	Limit        int32              `json:"limit"`
+}
+
+type ListSimilarMessageGroupsRow struct {
+	MessageGroup MessageGroup `json:"message_group"`
+	Distance     int32        `json:"distance"`
+}
+
+// Lists groups created after created_after whose SimHash is within max_distance bits of simhash, closest first.
+// Rejected groups are never similar. Hamming distance can't use indexes, so this scans all groups
+// created after created_after, and callers should not hold transactions.
+func (q *Queries) ListSimilarMessageGroups(ctx context.Context, arg ListSimilarMessageGroupsParams) ([]ListSimilarMessageGroupsRow, error) {
+	rows, err := q.db.Query(ctx, listSimilarMessageGroups,
+		arg.Simhash,
+		arg.CreatedAfter,
+		arg.MaxDistance,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSimilarMessageGroupsRow
+	for rows.Next() {
+		var i ListSimilarMessageGroupsRow
+		if err := rows.Scan(
+			&i.MessageGroup.ID,
+			&i.MessageGroup.TopicID,
+			&i.MessageGroup.Name,
+			&i.MessageGroup.Text,
+			&i.MessageGroup.TextSha1,
+			&i.MessageGroup.Language,
+			&i.MessageGroup.CreatedAt,
+			&i.MessageGroup.UpdatedAt,
+			&i.MessageGroup.Status,
+			&i.MessageGroup.StatusReason,
+			&i.MessageGroup.TextSimhash,
+			&i.MessageGroup.Preview,
+			&i.MessageGroup.TextTokens,
+			&i.Distance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopics = `-- name: ListTopics :many
+WITH numbered_topics AS (
+    SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id,
+           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
+           COUNT(*) OVER () as total_count
+    FROM topics
+)
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
+FROM numbered_topics
+WHERE CASE
+    WHEN $1 = 0 THEN true  -- No pagination
+    WHEN $1 > 0 THEN rn BETWEEN $2 + 1 AND $2 + $1  -- Normal pagination
+    WHEN $1 < 0 THEN rn BETWEEN total_count + $1 + 1 AND total_count + $2  -- Negative pagination
+END
+ORDER BY created_at DESC
+`
+
+type ListTopicsParams struct {
+	Column1 interface{} `json:"column_1"`
+	Column2 interface{} `json:"column_2"`
+}
+
+type ListTopicsRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description"`
+	Status       string             `json:"status"`
+	Result       pgtype.Text        `json:"result"`
+	ResultStatus pgtype.Text        `json:"result_status"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	AnswerID     pgtype.UUID        `json:"answer_id"`
+}
+
+func (q *Queries) ListTopics(ctx context.Context, arg ListTopicsParams) ([]ListTopicsRow, error) {
+	rows, err := q.db.Query(ctx, listTopics, arg.Column1, arg.Column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopicsRow
+	for rows.Next() {
+		var i ListTopicsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.Result,
+			&i.ResultStatus,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AnswerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicsAfterID = `-- name: ListTopicsAfterID :many
+SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id FROM topics WHERE id > $1 ORDER BY id LIMIT $2
+`
+
+type ListTopicsAfterIDParams struct {
+	ID    pgtype.UUID `json:"id"`
+	Limit int32       `json:"limit"`
+}
+
+// ListTopicsAfterID lists topics in order of IDs, for batch jobs over all topics.
+func (q *Queries) ListTopicsAfterID(ctx context.Context, arg ListTopicsAfterIDParams) ([]Topic, error) {
+	rows, err := q.db.Query(ctx, listTopicsAfterID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Topic
+	for rows.Next() {
+		var i Topic
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.Result,
+			&i.ResultStatus,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NameTokens,
+			&i.DescriptionTokens,
+			&i.AnswerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicsByStatus = `-- name: ListTopicsByStatus :many
+WITH numbered_topics AS (
+    SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id,
+           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
+           COUNT(*) OVER () as total_count
+    FROM topics
+    WHERE status = $1
+)
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
+FROM numbered_topics
+WHERE CASE
+    WHEN $2 = 0 THEN true  -- No pagination
+    WHEN $2 > 0 THEN rn BETWEEN $3 + 1 AND $3 + $2  -- Normal pagination
+    WHEN $2 < 0 THEN rn BETWEEN total_count + $2 + 1 AND total_count + $3  -- Negative pagination
+END
+ORDER BY created_at DESC
+`
+
+type ListTopicsByStatusParams struct {
+	Status  string      `json:"status"`
+	Column2 interface{} `json:"column_2"`
+	Column3 interface{} `json:"column_3"`
+}
+
+type ListTopicsByStatusRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description"`
+	Status       string             `json:"status"`
+	Result       pgtype.Text        `json:"result"`
+	ResultStatus pgtype.Text        `json:"result_status"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	AnswerID     pgtype.UUID        `json:"answer_id"`
+}
+
+func (q *Queries) ListTopicsByStatus(ctx context.Context, arg ListTopicsByStatusParams) ([]ListTopicsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, listTopicsByStatus, arg.Status, arg.Column2, arg.Column3)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopicsByStatusRow
+	for rows.Next() {
+		var i ListTopicsByStatusRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
 			&i.Result,
 			&i.ResultStatus,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AnswerID,
 		); err != nil {
 			return nil, err
 		}
@@ -861,7 +2301,8 @@ func (q *Queries) ListTopicsByStatus(ctx context.Context, arg ListTopicsByStatus
 }
 
 const listTopicsDynamicV2 = `-- name: ListTopicsDynamicV2 :many
-SELECT DISTINCT t.id, t.name, t.description, t.status, t.result, t.result_status, t.created_at, t.updated_at
+SELECT t.id, t.name, t.description, t.status, t.result, t.result_status, t.created_at, t.updated_at, t.name_tokens, t.description_tokens, t.answer_id FROM (
+SELECT DISTINCT t.id, t.name, t.description, t.status, t.result, t.result_status, t.created_at, t.updated_at, t.name_tokens, t.description_tokens, t.answer_id
 FROM topics t
 LEFT JOIN message_groups m ON t.id = m.topic_id
 WHERE 1=1
@@ -883,19 +2324,44 @@ WHERE 1=1
         )
         ELSE true
     END
-ORDER BY t.created_at DESC
+    AND CASE
+        WHEN array_length($6::text[], 1) > 0 THEN t.result_status = ANY($6::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN array_length($7::text[], 1) > 0 THEN m.language = ANY($7::text[])
+        ELSE true
+    END
+    AND CASE
+        WHEN $8::timestamptz IS NULL THEN true
+        WHEN $10::boolean THEN (t.created_at, t.id) > ($8::timestamptz, $9::uuid)
+        ELSE (t.created_at, t.id) < ($8::timestamptz, $9::uuid)
+    END
+) t
+ORDER BY
+    CASE WHEN $10::boolean THEN t.created_at END ASC,
+    CASE WHEN $10::boolean THEN t.id END ASC,
+    t.created_at DESC,
+    t.id DESC
 LIMIT CASE WHEN $4::integer = 0 THEN NULL ELSE $4::integer END
 OFFSET CASE WHEN $4::integer = 0 THEN 0 ELSE $5::integer END
 `
 
 type ListTopicsDynamicV2Params struct {
-	Column1 string   `json:"column_1"`
-	Column2 []string `json:"column_2"`
-	Column3 string   `json:"column_3"`
-	Column4 int32    `json:"column_4"`
-	Column5 int32    `json:"column_5"`
+	Column1  string             `json:"column_1"`
+	Column2  []string           `json:"column_2"`
+	Column3  string             `json:"column_3"`
+	Column4  int32              `json:"column_4"`
+	Column5  int32              `json:"column_5"`
+	Column6  []string           `json:"column_6"`
+	Column7  []string           `json:"column_7"`
+	Column8  pgtype.Timestamptz `json:"column_8"`
+	Column9  pgtype.UUID        `json:"column_9"`
+	Column10 bool               `json:"column_10"`
 }
 
+// With cursor ($8, $9), only topics after the cursor are listed,
+// or topics before the cursor in reverse order if $10 is true.
 func (q *Queries) ListTopicsDynamicV2(ctx context.Context, arg ListTopicsDynamicV2Params) ([]Topic, error) {
 	rows, err := q.db.Query(ctx, listTopicsDynamicV2,
 		arg.Column1,
@@ -903,6 +2369,11 @@ func (q *Queries) ListTopicsDynamicV2(ctx context.Context, arg ListTopicsDynamic
 		arg.Column3,
 		arg.Column4,
 		arg.Column5,
+		arg.Column6,
+		arg.Column7,
+		arg.Column8,
+		arg.Column9,
+		arg.Column10,
 	)
 	if err != nil {
 		return nil, err
@@ -920,6 +2391,9 @@ func (q *Queries) ListTopicsDynamicV2(ctx context.Context, arg ListTopicsDynamic
 			&i.ResultStatus,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NameTokens,
+			&i.DescriptionTokens,
+			&i.AnswerID,
 		); err != nil {
 			return nil, err
 		}
@@ -932,7 +2406,7 @@ func (q *Queries) ListTopicsDynamicV2(ctx context.Context, arg ListTopicsDynamic
 }
 
 const listTopicsInIDs = `-- name: ListTopicsInIDs :many
-SELECT DISTINCT t.id, t.name, t.description, t.status, t.result, t.result_status, t.created_at, t.updated_at FROM topics t
+SELECT DISTINCT t.id, t.name, t.description, t.status, t.result, t.result_status, t.created_at, t.updated_at, t.name_tokens, t.description_tokens, t.answer_id FROM topics t
 WHERE t.id = ANY($1::uuid[])
 ORDER BY t.created_at DESC
 `
@@ -955,6 +2429,104 @@ func (q *Queries) ListTopicsInIDs(ctx context.Context, dollar_1 []pgtype.UUID) (
 			&i.ResultStatus,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NameTokens,
+			&i.DescriptionTokens,
+			&i.AnswerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicsLikeID = `-- name: ListTopicsLikeID :many
+WITH numbered_topics AS (
+    SELECT id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id,
+           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
+           COUNT(*) OVER () as total_count
+    FROM topics t
+    WHERE t.id::text LIKE $1::text
+)
+SELECT id, name, description, status, result, result_status, created_at, updated_at, answer_id
+FROM numbered_topics
+WHERE CASE
+    WHEN $2 = 0 THEN true  -- No pagination
+    WHEN $2 > 0 THEN rn BETWEEN $3 + 1 AND $3 + $2  -- Normal pagination
+    WHEN $2 < 0 THEN rn BETWEEN total_count + $2 + 1 AND total_count + $3  -- Negative pagination
+END
+ORDER BY created_at DESC
+`
+
+type ListTopicsLikeIDParams struct {
+	Column1 string      `json:"column_1"`
+	Column2 interface{} `json:"column_2"`
+	Column3 interface{} `json:"column_3"`
+}
+
+type ListTopicsLikeIDRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description"`
+	Status       string             `json:"status"`
+	Result       pgtype.Text        `json:"result"`
+	ResultStatus pgtype.Text        `json:"result_status"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	AnswerID     pgtype.UUID        `json:"answer_id"`
+}
+
+func (q *Queries) ListTopicsLikeID(ctx context.Context, arg ListTopicsLikeIDParams) ([]ListTopicsLikeIDRow, error) {
+	rows, err := q.db.Query(ctx, listTopicsLikeID, arg.Column1, arg.Column2, arg.Column3)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopicsLikeIDRow
+	for rows.Next() {
+		var i ListTopicsLikeIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Status,
+			&i.Result,
+			&i.ResultStatus,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AnswerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRoles = `-- name: ListUserRoles :many
+SELECT user_id, role, created_at, updated_at FROM user_roles ORDER BY user_id ASC
+`
+
+func (q *Queries) ListUserRoles(ctx context.Context) ([]UserRole, error) {
+	rows, err := q.db.Query(ctx, listUserRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserRole
+	for rows.Next() {
+		var i UserRole
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -963,89 +2535,314 @@ func (q *Queries) ListTopicsInIDs(ctx context.Context, dollar_1 []pgtype.UUID) (
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return items, nil
+	return items, nil
+}
+
+const lockRateLimit = `-- name: LockRateLimit :one
+INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+RETURNING key, tokens, updated_at
+`
+
+type LockRateLimitParams struct {
+	Key       string             `json:"key"`
+	Tokens    float64            `json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// LockRateLimit creates the bucket if missing, and returns it locked until the transaction ends.
+func (q *Queries) LockRateLimit(ctx context.Context, arg LockRateLimitParams) (RateLimit, error) {
+	row := q.db.QueryRow(ctx, lockRateLimit, arg.Key, arg.Tokens, arg.UpdatedAt)
+	var i RateLimit
+	err := row.Scan(&i.Key, &i.Tokens, &i.UpdatedAt)
+	return i, err
+}
+
+const markOutboxFailed = `-- name: MarkOutboxFailed :exec
+UPDATE outbox SET
+    attempts = attempts + 1,
+    last_error = $1,
+    claimed_until = NULL,
+    next_attempt_at = $2::timestamptz
+        + make_interval(secs => LEAST($3::float8 * power(2, attempts), $4::float8)),
+    parked_at = CASE WHEN attempts + 1 >= $5::int THEN $2::timestamptz END
+WHERE id = $6
+`
+
+type MarkOutboxFailedParams struct {
+	LastError      pgtype.Text        `json:"last_error"`
+	Now            pgtype.Timestamptz `json:"now"`
+	BackoffSecs    float64            `json:"backoff_secs"`
+	BackoffMaxSecs float64            `json:"backoff_max_secs"`
+	MaxAttempts    int32              `json:"max_attempts"`
+	ID             pgtype.UUID        `json:"id"`
+}
+
+// MarkOutboxFailed schedules the next attempt after backoff_secs doubled for every earlier attempt,
+// up to backoff_max_secs, or parks the event if this was attempt max_attempts.
+func (q *Queries) MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) error {
+	_, err := q.db.Exec(ctx, markOutboxFailed,
+		arg.LastError,
+		arg.Now,
+		arg.BackoffSecs,
+		arg.BackoffMaxSecs,
+		arg.MaxAttempts,
+		arg.ID,
+	)
+	return err
+}
+
+const markOutboxPublished = `-- name: MarkOutboxPublished :exec
+UPDATE outbox SET
+    published_at = $2,
+    attempts = attempts + 1,
+    last_error = NULL,
+    claimed_until = NULL
+WHERE id = $1
+`
+
+type MarkOutboxPublishedParams struct {
+	ID          pgtype.UUID        `json:"id"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+func (q *Queries) MarkOutboxPublished(ctx context.Context, arg MarkOutboxPublishedParams) error {
+	_, err := q.db.Exec(ctx, markOutboxPublished, arg.ID, arg.PublishedAt)
+	return err
+}
+
+const mergeMessagesV2IntoDuplicateGroups = `-- name: MergeMessagesV2IntoDuplicateGroups :execrows
+UPDATE messages_v2 m SET
+    group_id = target.id,
+    updated_at = NOW()
+FROM message_groups source
+JOIN message_groups target ON target.text_sha1 = source.text_sha1
+WHERE source.topic_id = $1
+    AND target.topic_id = $2
+    AND m.group_id = source.id
+`
+
+type MergeMessagesV2IntoDuplicateGroupsParams struct {
+	FromID pgtype.UUID `json:"from_id"`
+	ToID   pgtype.UUID `json:"to_id"`
+}
+
+// MergeMessagesV2IntoDuplicateGroups moves messages in groups of topic from_id into groups of topic to_id with identical text.
+func (q *Queries) MergeMessagesV2IntoDuplicateGroups(ctx context.Context, arg MergeMessagesV2IntoDuplicateGroupsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, mergeMessagesV2IntoDuplicateGroups, arg.FromID, arg.ToID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveAnswersTopic = `-- name: MoveAnswersTopic :execrows
+UPDATE answers source SET
+    topic_id = $1,
+    revision = source.revision + (SELECT COALESCE(MAX(target.revision), 0) FROM answers target WHERE target.topic_id = $1)
+WHERE source.topic_id = $2
+`
+
+type MoveAnswersTopicParams struct {
+	ToID   pgtype.UUID `json:"to_id"`
+	FromID pgtype.UUID `json:"from_id"`
+}
+
+// MoveAnswersTopic moves answers, renumbering them as revisions after the target topic's answers.
+func (q *Queries) MoveAnswersTopic(ctx context.Context, arg MoveAnswersTopicParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveAnswersTopic, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveDeliveriesTopic = `-- name: MoveDeliveriesTopic :execrows
+UPDATE deliveries SET topic_id = $1 WHERE topic_id = $2
+`
+
+type MoveDeliveriesTopicParams struct {
+	ToID   pgtype.UUID `json:"to_id"`
+	FromID pgtype.UUID `json:"from_id"`
+}
+
+func (q *Queries) MoveDeliveriesTopic(ctx context.Context, arg MoveDeliveriesTopicParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveDeliveriesTopic, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveMessageGroupsTopic = `-- name: MoveMessageGroupsTopic :execrows
+UPDATE message_groups SET
+    topic_id = $1,
+    updated_at = NOW()
+WHERE topic_id = $2
+`
+
+type MoveMessageGroupsTopicParams struct {
+	ToID   pgtype.UUID `json:"to_id"`
+	FromID pgtype.UUID `json:"from_id"`
+}
+
+func (q *Queries) MoveMessageGroupsTopic(ctx context.Context, arg MoveMessageGroupsTopicParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveMessageGroupsTopic, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveMessageGroupsTopicInIDs = `-- name: MoveMessageGroupsTopicInIDs :execrows
+UPDATE message_groups SET
+    topic_id = $1,
+    updated_at = NOW()
+WHERE topic_id = $2 AND id = ANY($3::uuid[])
+`
+
+type MoveMessageGroupsTopicInIDsParams struct {
+	ToID   pgtype.UUID   `json:"to_id"`
+	FromID pgtype.UUID   `json:"from_id"`
+	Ids    []pgtype.UUID `json:"ids"`
+}
+
+func (q *Queries) MoveMessageGroupsTopicInIDs(ctx context.Context, arg MoveMessageGroupsTopicInIDsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveMessageGroupsTopicInIDs, arg.ToID, arg.FromID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const listTopicsLikeID = `-- name: ListTopicsLikeID :many
-WITH numbered_topics AS (
-    SELECT id, name, description, status, result, result_status, created_at, updated_at,
-           ROW_NUMBER() OVER (ORDER BY created_at DESC) as rn,
-           COUNT(*) OVER () as total_count
-    FROM topics t
-    WHERE t.id::text LIKE $1::text
-)
-SELECT id, name, description, status, result, result_status, created_at, updated_at
-FROM numbered_topics
-WHERE CASE
-    WHEN $2 = 0 THEN true  -- No pagination
-    WHEN $2 > 0 THEN rn BETWEEN $3 + 1 AND $3 + $2  -- Normal pagination
-    WHEN $2 < 0 THEN rn BETWEEN total_count + $2 + 1 AND total_count + $3  -- Negative pagination
-END
-ORDER BY created_at DESC
+const moveMessagesV2Topic = `-- name: MoveMessagesV2Topic :execrows
+UPDATE messages_v2 SET
+    topic_id = $1,
+    updated_at = NOW()
+WHERE topic_id = $2
 `
 
-type ListTopicsLikeIDParams struct {
-	Column1 string      `json:"column_1"`
-	Column2 interface{} `json:"column_2"`
-	Column3 interface{} `json:"column_3"`
+type MoveMessagesV2TopicParams struct {
+	ToID   pgtype.UUID `json:"to_id"`
+	FromID pgtype.UUID `json:"from_id"`
 }
 
-type ListTopicsLikeIDRow struct {
-	ID           pgtype.UUID        `json:"id"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	Status       string             `json:"status"`
-	Result       pgtype.Text        `json:"result"`
-	ResultStatus pgtype.Text        `json:"result_status"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+func (q *Queries) MoveMessagesV2Topic(ctx context.Context, arg MoveMessagesV2TopicParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveMessagesV2Topic, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) ListTopicsLikeID(ctx context.Context, arg ListTopicsLikeIDParams) ([]ListTopicsLikeIDRow, error) {
-	rows, err := q.db.Query(ctx, listTopicsLikeID, arg.Column1, arg.Column2, arg.Column3)
+const moveMessagesV2TopicInGroupIDs = `-- name: MoveMessagesV2TopicInGroupIDs :execrows
+UPDATE messages_v2 SET
+    topic_id = $1,
+    updated_at = NOW()
+WHERE group_id = ANY($2::uuid[])
+`
+
+type MoveMessagesV2TopicInGroupIDsParams struct {
+	ToID     pgtype.UUID   `json:"to_id"`
+	GroupIds []pgtype.UUID `json:"group_ids"`
+}
+
+// MoveMessagesV2TopicInGroupIDs moves all messages of the groups, including messages not yet assigned to topics.
+func (q *Queries) MoveMessagesV2TopicInGroupIDs(ctx context.Context, arg MoveMessagesV2TopicInGroupIDsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveMessagesV2TopicInGroupIDs, arg.ToID, arg.GroupIds)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ListTopicsLikeIDRow
-	for rows.Next() {
-		var i ListTopicsLikeIDRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.Status,
-			&i.Result,
-			&i.ResultStatus,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
+}
+
+const publishAnswer = `-- name: PublishAnswer :one
+UPDATE answers SET
+    status = 'ANSWER_PUBLISHED',
+    corrects_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'ANSWER_DRAFT' RETURNING id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id
+`
+
+type PublishAnswerParams struct {
+	ID         pgtype.UUID `json:"id"`
+	CorrectsID pgtype.UUID `json:"corrects_id"`
+}
+
+func (q *Queries) PublishAnswer(ctx context.Context, arg PublishAnswerParams) (Answer, error) {
+	row := q.db.QueryRow(ctx, publishAnswer, arg.ID, arg.CorrectsID)
+	var i Answer
+	err := row.Scan(
+		&i.ID,
+		&i.TopicID,
+		&i.Text,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
+	)
+	return i, err
+}
+
+const reopenTopic = `-- name: ReopenTopic :one
+UPDATE topics SET
+    result = NULL,
+    status = 'TOPIC_PENDING',
+    result_status = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'TOPIC_RESOLVED' RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
+`
+
+// ReopenTopic moves resolved topic back to pending, keeping its published answer until corrected.
+func (q *Queries) ReopenTopic(ctx context.Context, id pgtype.UUID) (Topic, error) {
+	row := q.db.QueryRow(ctx, reopenTopic, id)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Status,
+		&i.Result,
+		&i.ResultStatus,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
+	)
+	return i, err
 }
 
 const resolveTopic = `-- name: ResolveTopic :one
 UPDATE topics SET
     result = $2,
-    result_status = $3,
+    status = $3,
+    result_status = $4,
+    answer_id = $5,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at
+WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
 `
 
 type ResolveTopicParams struct {
 	ID           pgtype.UUID `json:"id"`
 	Result       pgtype.Text `json:"result"`
+	Status       string      `json:"status"`
 	ResultStatus pgtype.Text `json:"result_status"`
+	AnswerID     pgtype.UUID `json:"answer_id"`
 }
 
 func (q *Queries) ResolveTopic(ctx context.Context, arg ResolveTopicParams) (Topic, error) {
-	row := q.db.QueryRow(ctx, resolveTopic, arg.ID, arg.Result, arg.ResultStatus)
+	row := q.db.QueryRow(ctx, resolveTopic,
+		arg.ID,
+		arg.Result,
+		arg.Status,
+		arg.ResultStatus,
+		arg.AnswerID,
+	)
 	var i Topic
 	err := row.Scan(
 		&i.ID,
@@ -1056,10 +2853,24 @@ func (q *Queries) ResolveTopic(ctx context.Context, arg ResolveTopicParams) (Top
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
 	)
 	return i, err
 }
 
+const resolveTopicID = `-- name: ResolveTopicID :one
+SELECT COALESCE((SELECT to_id FROM topic_redirects WHERE from_id = $1::uuid), $1::uuid)::uuid AS id
+`
+
+// ResolveTopicID returns ID of the topic id was merged into, or id itself.
+func (q *Queries) ResolveTopicID(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, resolveTopicID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const topicExists = `-- name: TopicExists :one
 SELECT EXISTS (SELECT 1 from topics where id = $1)
 `
@@ -1075,7 +2886,7 @@ const unassignMessageGroupFromTopic = `-- name: UnassignMessageGroupFromTopic :o
 UPDATE message_groups SET
     topic_id = NULL,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at
+WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens
 `
 
 func (q *Queries) UnassignMessageGroupFromTopic(ctx context.Context, id pgtype.UUID) (MessageGroup, error) {
@@ -1090,6 +2901,11 @@ func (q *Queries) UnassignMessageGroupFromTopic(ctx context.Context, id pgtype.U
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
 	)
 	return i, err
 }
@@ -1120,11 +2936,151 @@ func (q *Queries) UnassignMessageV2FromTopic(ctx context.Context, id pgtype.UUID
 	return i, err
 }
 
+const unassignMessagesV2FromTopicInGroup = `-- name: UnassignMessagesV2FromTopicInGroup :execrows
+UPDATE messages_v2 SET
+    topic_id = NULL,
+    updated_at = NOW()
+WHERE group_id = $1 AND topic_id IS NOT NULL
+`
+
+// UnassignMessagesV2FromTopicInGroup unassigns all messages of group from their topics.
+func (q *Queries) UnassignMessagesV2FromTopicInGroup(ctx context.Context, groupID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, unassignMessagesV2FromTopicInGroup, groupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateAnswerDraft = `-- name: UpdateAnswerDraft :one
+UPDATE answers SET
+    text = $2,
+    verdict = $3,
+    text_tokens = $4,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'ANSWER_DRAFT' RETURNING id, topic_id, text, created_at, updated_at, verdict, text_tokens, revision, status, user_id, corrects_id
+`
+
+type UpdateAnswerDraftParams struct {
+	ID         pgtype.UUID `json:"id"`
+	Text       string      `json:"text"`
+	Verdict    pgtype.Text `json:"verdict"`
+	TextTokens string      `json:"text_tokens"`
+}
+
+func (q *Queries) UpdateAnswerDraft(ctx context.Context, arg UpdateAnswerDraftParams) (Answer, error) {
+	row := q.db.QueryRow(ctx, updateAnswerDraft,
+		arg.ID,
+		arg.Text,
+		arg.Verdict,
+		arg.TextTokens,
+	)
+	var i Answer
+	err := row.Scan(
+		&i.ID,
+		&i.TopicID,
+		&i.Text,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Verdict,
+		&i.TextTokens,
+		&i.Revision,
+		&i.Status,
+		&i.UserID,
+		&i.CorrectsID,
+	)
+	return i, err
+}
+
+const updateAnswerSource = `-- name: UpdateAnswerSource :one
+UPDATE answer_sources SET
+    type = $1,
+    position = COALESCE(NULLIF($2::integer, 0), position),
+    url = $3,
+    publisher = $4,
+    archive_url = $5,
+    quote = $6,
+    attachment_url = $7,
+    updated_at = NOW()
+WHERE id = $8 RETURNING id, answer_id, type, position, url, publisher, archive_url, quote, attachment_url, created_at, updated_at
+`
+
+type UpdateAnswerSourceParams struct {
+	Type          string      `json:"type"`
+	Position      int32       `json:"position"`
+	Url           string      `json:"url"`
+	Publisher     string      `json:"publisher"`
+	ArchiveUrl    string      `json:"archive_url"`
+	Quote         string      `json:"quote"`
+	AttachmentUrl string      `json:"attachment_url"`
+	ID            pgtype.UUID `json:"id"`
+}
+
+// UpdateAnswerSource keeps the position of the source if position is 0.
+func (q *Queries) UpdateAnswerSource(ctx context.Context, arg UpdateAnswerSourceParams) (AnswerSource, error) {
+	row := q.db.QueryRow(ctx, updateAnswerSource,
+		arg.Type,
+		arg.Position,
+		arg.Url,
+		arg.Publisher,
+		arg.ArchiveUrl,
+		arg.Quote,
+		arg.AttachmentUrl,
+		arg.ID,
+	)
+	var i AnswerSource
+	err := row.Scan(
+		&i.ID,
+		&i.AnswerID,
+		&i.Type,
+		&i.Position,
+		&i.Url,
+		&i.Publisher,
+		&i.ArchiveUrl,
+		&i.Quote,
+		&i.AttachmentUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateAnswerTokens = `-- name: UpdateAnswerTokens :exec
+UPDATE answers SET text_tokens = $2 WHERE id = $1
+`
+
+type UpdateAnswerTokensParams struct {
+	ID         pgtype.UUID `json:"id"`
+	TextTokens string      `json:"text_tokens"`
+}
+
+// UpdateAnswerTokens sets search tokens of answer, leaving updated_at alone.
+func (q *Queries) UpdateAnswerTokens(ctx context.Context, arg UpdateAnswerTokensParams) error {
+	_, err := q.db.Exec(ctx, updateAnswerTokens, arg.ID, arg.TextTokens)
+	return err
+}
+
+const updateMessageGroupKey = `-- name: UpdateMessageGroupKey :exec
+UPDATE message_groups SET text_sha1 = $2, text_simhash = $3 WHERE id = $1
+`
+
+type UpdateMessageGroupKeyParams struct {
+	ID          pgtype.UUID `json:"id"`
+	TextSha1    string      `json:"text_sha1"`
+	TextSimhash pgtype.Int8 `json:"text_simhash"`
+}
+
+// UpdateMessageGroupKey sets hashes by which submissions join message group, leaving updated_at alone.
+func (q *Queries) UpdateMessageGroupKey(ctx context.Context, arg UpdateMessageGroupKeyParams) error {
+	_, err := q.db.Exec(ctx, updateMessageGroupKey, arg.ID, arg.TextSha1, arg.TextSimhash)
+	return err
+}
+
 const updateMessageGroupName = `-- name: UpdateMessageGroupName :one
 UPDATE message_groups SET
     name = $2,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at
+WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens
 `
 
 type UpdateMessageGroupNameParams struct {
@@ -1144,24 +3100,96 @@ func (q *Queries) UpdateMessageGroupName(ctx context.Context, arg UpdateMessageG
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
+	)
+	return i, err
+}
+
+const updateMessageGroupStatus = `-- name: UpdateMessageGroupStatus :one
+UPDATE message_groups SET
+    status = $2,
+    status_reason = $3,
+    updated_at = NOW()
+WHERE id = $1 RETURNING id, topic_id, name, text, text_sha1, language, created_at, updated_at, status, status_reason, text_simhash, preview, text_tokens
+`
+
+type UpdateMessageGroupStatusParams struct {
+	ID           pgtype.UUID `json:"id"`
+	Status       string      `json:"status"`
+	StatusReason pgtype.Text `json:"status_reason"`
+}
+
+func (q *Queries) UpdateMessageGroupStatus(ctx context.Context, arg UpdateMessageGroupStatusParams) (MessageGroup, error) {
+	row := q.db.QueryRow(ctx, updateMessageGroupStatus, arg.ID, arg.Status, arg.StatusReason)
+	var i MessageGroup
+	err := row.Scan(
+		&i.ID,
+		&i.TopicID,
+		&i.Name,
+		&i.Text,
+		&i.TextSha1,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.TextSimhash,
+		&i.Preview,
+		&i.TextTokens,
 	)
 	return i, err
 }
 
+const updateMessageGroupTokens = `-- name: UpdateMessageGroupTokens :exec
+UPDATE message_groups SET text_tokens = $2 WHERE id = $1
+`
+
+type UpdateMessageGroupTokensParams struct {
+	ID         pgtype.UUID `json:"id"`
+	TextTokens string      `json:"text_tokens"`
+}
+
+// UpdateMessageGroupTokens sets search tokens of message group, leaving updated_at alone.
+func (q *Queries) UpdateMessageGroupTokens(ctx context.Context, arg UpdateMessageGroupTokensParams) error {
+	_, err := q.db.Exec(ctx, updateMessageGroupTokens, arg.ID, arg.TextTokens)
+	return err
+}
+
+const updateRateLimit = `-- name: UpdateRateLimit :exec
+UPDATE rate_limits SET tokens = $2, updated_at = $3 WHERE key = $1
+`
+
+type UpdateRateLimitParams struct {
+	Key       string             `json:"key"`
+	Tokens    float64            `json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) UpdateRateLimit(ctx context.Context, arg UpdateRateLimitParams) error {
+	_, err := q.db.Exec(ctx, updateRateLimit, arg.Key, arg.Tokens, arg.UpdatedAt)
+	return err
+}
+
 const updateTopicDescription = `-- name: UpdateTopicDescription :one
 UPDATE topics SET
     description = $2,
+    description_tokens = $3,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at
+WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
 `
 
 type UpdateTopicDescriptionParams struct {
-	ID          pgtype.UUID `json:"id"`
-	Description string      `json:"description"`
+	ID                pgtype.UUID `json:"id"`
+	Description       string      `json:"description"`
+	DescriptionTokens string      `json:"description_tokens"`
 }
 
 func (q *Queries) UpdateTopicDescription(ctx context.Context, arg UpdateTopicDescriptionParams) (Topic, error) {
-	row := q.db.QueryRow(ctx, updateTopicDescription, arg.ID, arg.Description)
+	row := q.db.QueryRow(ctx, updateTopicDescription, arg.ID, arg.Description, arg.DescriptionTokens)
 	var i Topic
 	err := row.Scan(
 		&i.ID,
@@ -1172,6 +3200,9 @@ func (q *Queries) UpdateTopicDescription(ctx context.Context, arg UpdateTopicDes
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
 	)
 	return i, err
 }
@@ -1179,17 +3210,19 @@ func (q *Queries) UpdateTopicDescription(ctx context.Context, arg UpdateTopicDes
 const updateTopicName = `-- name: UpdateTopicName :one
 UPDATE topics SET
     name = $2,
+    name_tokens = $3,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at
+WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
 `
 
 type UpdateTopicNameParams struct {
-	ID   pgtype.UUID `json:"id"`
-	Name string      `json:"name"`
+	ID         pgtype.UUID `json:"id"`
+	Name       string      `json:"name"`
+	NameTokens string      `json:"name_tokens"`
 }
 
 func (q *Queries) UpdateTopicName(ctx context.Context, arg UpdateTopicNameParams) (Topic, error) {
-	row := q.db.QueryRow(ctx, updateTopicName, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateTopicName, arg.ID, arg.Name, arg.NameTokens)
 	var i Topic
 	err := row.Scan(
 		&i.ID,
@@ -1200,15 +3233,36 @@ func (q *Queries) UpdateTopicName(ctx context.Context, arg UpdateTopicNameParams
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
 	)
 	return i, err
 }
 
+const updateTopicRedirectsTo = `-- name: UpdateTopicRedirectsTo :execrows
+UPDATE topic_redirects SET to_id = $1 WHERE to_id = $2
+`
+
+type UpdateTopicRedirectsToParams struct {
+	ToID   pgtype.UUID `json:"to_id"`
+	FromID pgtype.UUID `json:"from_id"`
+}
+
+// UpdateTopicRedirectsTo repoints redirects into a merged topic to its target, so that chains are never followed.
+func (q *Queries) UpdateTopicRedirectsTo(ctx context.Context, arg UpdateTopicRedirectsToParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateTopicRedirectsTo, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateTopicStatus = `-- name: UpdateTopicStatus :one
 UPDATE topics SET
     status = $2,
     updated_at = NOW()
-WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at
+WHERE id = $1 RETURNING id, name, description, status, result, result_status, created_at, updated_at, name_tokens, description_tokens, answer_id
 `
 
 type UpdateTopicStatusParams struct {
@@ -1228,6 +3282,85 @@ func (q *Queries) UpdateTopicStatus(ctx context.Context, arg UpdateTopicStatusPa
 		&i.ResultStatus,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NameTokens,
+		&i.DescriptionTokens,
+		&i.AnswerID,
+	)
+	return i, err
+}
+
+const updateTopicTokens = `-- name: UpdateTopicTokens :exec
+UPDATE topics SET name_tokens = $2, description_tokens = $3 WHERE id = $1
+`
+
+type UpdateTopicTokensParams struct {
+	ID                pgtype.UUID `json:"id"`
+	NameTokens        string      `json:"name_tokens"`
+	DescriptionTokens string      `json:"description_tokens"`
+}
+
+// UpdateTopicTokens sets search tokens of topic, leaving updated_at alone.
+func (q *Queries) UpdateTopicTokens(ctx context.Context, arg UpdateTopicTokensParams) error {
+	_, err := q.db.Exec(ctx, updateTopicTokens, arg.ID, arg.NameTokens, arg.DescriptionTokens)
+	return err
+}
+
+const upsertOutboxSinkAttempt = `-- name: UpsertOutboxSinkAttempt :exec
+INSERT INTO outbox_sinks (
+    event_id, sink, attempts, last_error, published_at
+) VALUES (
+    $1, $2, 1, $3, $4
+)
+ON CONFLICT (event_id, sink) DO UPDATE SET
+    attempts = outbox_sinks.attempts + 1,
+    last_error = EXCLUDED.last_error,
+    published_at = EXCLUDED.published_at
+`
+
+type UpsertOutboxSinkAttemptParams struct {
+	EventID     pgtype.UUID        `json:"event_id"`
+	Sink        string             `json:"sink"`
+	LastError   pgtype.Text        `json:"last_error"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+// UpsertOutboxSinkAttempt records an attempt of sink publishing event, which succeeded if published_at is set.
+func (q *Queries) UpsertOutboxSinkAttempt(ctx context.Context, arg UpsertOutboxSinkAttemptParams) error {
+	_, err := q.db.Exec(ctx, upsertOutboxSinkAttempt,
+		arg.EventID,
+		arg.Sink,
+		arg.LastError,
+		arg.PublishedAt,
+	)
+	return err
+}
+
+const upsertUserRole = `-- name: UpsertUserRole :one
+INSERT INTO user_roles (
+    user_id, role, created_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    role = EXCLUDED.role,
+    updated_at = EXCLUDED.created_at
+RETURNING user_id, role, created_at, updated_at
+`
+
+type UpsertUserRoleParams struct {
+	UserID    string             `json:"user_id"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) UpsertUserRole(ctx context.Context, arg UpsertUserRoleParams) (UserRole, error) {
+	row := q.db.QueryRow(ctx, upsertUserRole, arg.UserID, arg.Role, arg.CreatedAt)
+	var i UserRole
+	err := row.Scan(
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,93 @@
+package dedup_test
+
+import (
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+)
+
+func TestNormalize(t *testing.T) {
+	type testCase struct {
+		a, b string
+	}
+	same := []testCase{
+		{"Lemon  soda\ncures cancer", "lemon soda cures cancer"},
+		{"ｌｅｍｏｎ　ｓｏｄａ", "lemon soda"},             // Full-width characters (NFKC)
+		{"น้ำมะนาว", "น\u0e49\u0e4d\u0e32มะนาว"}, // Sara am as nikhahit and sara aa
+		{"น้ำมะนาว", "น\u0e4d\u0e49\u0e32มะนาว"}, // Tone mark typed after nikhahit
+		{"น้ำมะนาว", "น\u0e49\u0e49ำมะนาว"},      // Double tone mark
+		{"มะนาว\u200bโซดา", "มะนาวโซดา"},         // Zero width space
+		{"see https://WWW.Example.com/a/?utm_source=line#top", "see https://example.com/a"},
+	}
+	for _, tc := range same {
+		a, b := dedup.Normalize(tc.a), dedup.Normalize(tc.b)
+		if a != b {
+			t.Fatalf("unexpected different normalized texts: '%s' vs '%s'", a, b)
+		}
+	}
+	different := []testCase{
+		{"มะนาว", "มะนาวโซดา"},
+		{"น่า", "น้า"},
+	}
+	for _, tc := range different {
+		a, b := dedup.Normalize(tc.a), dedup.Normalize(tc.b)
+		if a == b {
+			t.Fatalf("unexpected same normalized texts: '%s'", a)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com":                                   "https://example.com",
+		"HTTPS://Example.COM:443/news/":                         "https://example.com/news",
+		"http://www.example.com:80/news?id=1&utm_medium=social": "http://example.com/news?id=1",
+		"https://example.com/news?b=2&a=1&fbclid=abc#comments":  "https://example.com/news?a=1&b=2",
+		"https://example.com:8443/news":                         "https://example.com:8443/news",
+		"https://youtu.be/dQw4w9WgXcQ?si=tracking&t=42":         "https://youtu.be/dQw4w9WgXcQ?t=42",
+	}
+	for raw, expected := range tests {
+		actual, err := dedup.CanonicalURL(raw)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", raw, err)
+		}
+		if actual != expected {
+			t.Fatalf("unexpected canonical url for %s: expected %s, got %s", raw, expected, actual)
+		}
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	threshold := 0.9
+	similar := func(a, b string) bool {
+		distance := dedup.Distance(dedup.SimHash(dedup.Normalize(a)), dedup.SimHash(dedup.Normalize(b)))
+		return distance <= dedup.MaxDistance(threshold)
+	}
+
+	nearDuplicates := [][2]string{
+		{"Lemon soda cures cancer, share with your family!", "lemon soda cures cancer!!! share with your family 🙏"},
+		{"ข่าวด่วน!! น้ำมะนาวโซดา รักษามะเร็งได้ 🍋", "ข่าวด่วน น้ำมะนาวโซดา   รักษามะเร็งได้ 🥤🥤"},
+	}
+	for _, pair := range nearDuplicates {
+		if !similar(pair[0], pair[1]) {
+			t.Fatalf("unexpected dissimilar texts: '%s' vs '%s'", pair[0], pair[1])
+		}
+	}
+
+	unrelated := [][2]string{
+		{"Lemon soda cures cancer, share with your family!", "วัคซีนโควิดทำให้เกิดโรคหัวใจในเด็ก"},
+		{"ข่าวด่วน!! น้ำมะนาวโซดา รักษามะเร็งได้", "วัคซีนโควิดทำให้เกิดโรคหัวใจในเด็ก"},
+	}
+	for _, pair := range unrelated {
+		if similar(pair[0], pair[1]) {
+			t.Fatalf("unexpected similar texts: '%s' vs '%s'", pair[0], pair[1])
+		}
+	}
+
+	if dedup.MaxDistance(1) != 0 {
+		t.Fatalf("unexpected max distance for threshold 1: %d", dedup.MaxDistance(1))
+	}
+	if dedup.Similarity(0) != 1 {
+		t.Fatalf("unexpected similarity for distance 0: %f", dedup.Similarity(0))
+	}
+}
@@ -0,0 +1,142 @@
+// Package dedup finds duplicate and near-duplicate message texts.
+//
+// Texts are first normalized with Normalize, so that exact duplicates
+// can be found by hashing the normalized text. Near-duplicates, e.g. the same rumor
+// with a different emoji or punctuation, are found by comparing SimHash of normalized texts.
+package dedup
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	reURL = regexp.MustCompile(`https?://[^\s]+`)
+
+	// reThaiSaraAm matches sara am spelled as nikhahit and sara aa,
+	// optionally with a tone mark typed before or after nikhahit
+	reThaiSaraAm = regexp.MustCompile(`([\x{0E48}-\x{0E4B}]?)\x{0E4D}([\x{0E48}-\x{0E4B}]?)\x{0E32}`)
+
+	// invisible are zero-width characters often inserted by copy-pasting Thai text
+	invisible = strings.NewReplacer(
+		"\u200b", "", // Zero width space
+		"\u200c", "", // Zero width non-joiner
+		"\u200d", "", // Zero width joiner
+		"\u2060", "", // Word joiner
+		"\ufeff", "", // Zero width no-break space (BOM)
+		"\u00ad", "", // Soft hyphen
+	)
+
+	// trackingParams are URL query parameters which do not change the linked content
+	trackingParams = map[string]struct{}{
+		"fbclid":  {},
+		"gclid":   {},
+		"igshid":  {},
+		"mc_cid":  {},
+		"mc_eid":  {},
+		"si":      {},
+		"ref_src": {},
+	}
+)
+
+// Normalize normalizes text so that texts only differing in
+// Unicode representation, letter case, whitespace, invisible characters,
+// Thai mark typing order, and URL tracking parameters become identical.
+func Normalize(text string) string {
+	text = norm.NFKC.String(text)
+	text = invisible.Replace(text)
+	text = normalizeThai(text)
+	text = reURL.ReplaceAllStringFunc(text, func(raw string) string {
+		canonical, err := CanonicalURL(raw)
+		if err != nil {
+			return raw
+		}
+		return canonical
+	})
+	text = strings.ToLower(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// RemoveURLs removes http and https URLs from text
+func RemoveURLs(text string) string {
+	return reURL.ReplaceAllString(text, "")
+}
+
+// CanonicalURL returns canonical form of raw URL, with lower-case scheme and host,
+// and without www prefix, default port, fragment, tracking parameters and trailing slash.
+// The remaining query parameters are sorted.
+func CanonicalURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	switch {
+	case u.Scheme == "http" && u.Port() == "80",
+		u.Scheme == "https" && u.Port() == "443":
+		u.Host = u.Hostname()
+	}
+	u.Host = strings.TrimPrefix(u.Host, "www.")
+	u.Fragment, u.RawFragment = "", ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	// url.Values.Encode sorts by key
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "utm_") {
+		return true
+	}
+	_, ok := trackingParams[key]
+	return ok
+}
+
+// normalizeThai recomposes sara am decomposed by NFKC,
+// and removes repeated Thai combining marks, e.g. double tone marks.
+func normalizeThai(text string) string {
+	if !strings.ContainsFunc(text, isThai) {
+		return text
+	}
+	text = reThaiSaraAm.ReplaceAllString(text, "${1}${2}\u0e33")
+
+	var b strings.Builder
+	b.Grow(len(text))
+	var prev rune
+	for _, r := range text {
+		if r == prev && isThaiMark(r) {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+func isThai(r rune) bool {
+	return r >= '\u0e00' && r <= '\u0e7f'
+}
+
+// isThaiMark returns whether r is a Thai above or below vowel, or a tone mark
+func isThaiMark(r rune) bool {
+	switch {
+	case r == '\u0e31', // Mai han-akat
+		r >= '\u0e34' && r <= '\u0e3a', // Above and below vowels
+		r >= '\u0e47' && r <= '\u0e4e': // Tone marks and other signs
+		return true
+	}
+	return false
+}
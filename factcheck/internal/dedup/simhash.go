@@ -0,0 +1,72 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"unicode"
+)
+
+// shingleSize is the number of characters in each shingle.
+// Thai is written without spaces between words, so we use characters instead of words.
+const shingleSize = 3
+
+// SimHash returns 64-bit SimHash of character shingles of normalized text.
+// Punctuation, symbols (including emojis) and spaces are ignored,
+// so texts only differing in those have identical SimHash.
+func SimHash(normalized string) uint64 {
+	runes := make([]rune, 0, len(normalized))
+	for _, r := range normalized {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle []rune) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(string(shingle)))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	if len(runes) < shingleSize {
+		add(runes)
+	}
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		add(runes[i : i+shingleSize])
+	}
+
+	var simhash uint64
+	for i, w := range weights {
+		if w > 0 {
+			simhash |= 1 << i
+		}
+	}
+	return simhash
+}
+
+// Distance returns Hamming distance between 2 SimHashes
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity converts Hamming distance between 2 SimHashes into similarity,
+// from 0 (opposite) to 1 (identical)
+func Similarity(distance int) float64 {
+	return 1 - float64(distance)/64
+}
+
+// MaxDistance returns the largest Hamming distance
+// between SimHashes with at least threshold similarity
+func MaxDistance(threshold float64) int {
+	return int(math.Floor((1 - threshold) * 64))
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -13,4 +15,6 @@ type Container struct {
 	PostgresQuerier postgres.Querier
 	Repository      repo.Repository
 	Service         core.Service
+	Notifier        notify.Notifier
+	Relay           outbox.Relay
 }
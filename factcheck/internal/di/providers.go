@@ -4,11 +4,15 @@ package di
 
 import (
 	"github.com/google/wire"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -18,6 +22,9 @@ var ProviderSet = wire.NewSet(
 	ProviderSetDatabase,
 	ProviderSetRepo,
 	ProviderSetCore,
+	ProviderSetLinks,
+	ProviderSetNotify,
+	ProviderSetOutbox,
 	wire.Struct(new(Container), "*"),
 )
 
@@ -27,14 +34,16 @@ var ProviderSetTest = wire.NewSet(
 	ProviderSetDatabase,
 	ProviderSetRepo,
 	ProviderSetCore,
+	ProviderSetLinksTest,
+	ProviderSetNotifyTest,
+	ProviderSetOutbox,
 	NewTest,
 )
 
 // ProviderSetDatabase provides all database-related dependencies
 var ProviderSetDatabase = wire.NewSet(
-	wire.Bind(new(postgres.DBTX), new(*pgxpool.Pool)),
 	wire.Bind(new(postgres.Querier), new(*postgres.Queries)),
-	postgres.New,
+	postgres.NewQueries,
 	postgres.NewConn,
 )
 
@@ -46,5 +55,41 @@ var ProviderSetRepo = wire.NewSet(
 // ProviderSetCore provides business logic layer
 var ProviderSetCore = wire.NewSet(
 	wire.Bind(new(core.Service), new(core.ServiceFactcheck)),
+	wire.Bind(new(language.Detector), new(language.DetectorScript)),
+	language.NewDetectorScript,
+	ratelimit.New,
 	core.New,
 )
+
+// ProviderSetLinks provides link resolver and fetcher from config
+var ProviderSetLinks = wire.NewSet(
+	links.NewResolver,
+	links.NewFetcher,
+)
+
+// ProviderSetLinksTest provides offline link resolver and fetcher
+var ProviderSetLinksTest = wire.NewSet(
+	wire.Bind(new(links.Resolver), new(*links.Stub)),
+	wire.Bind(new(links.Fetcher), new(*links.Stub)),
+	links.NewStub,
+)
+
+// ProviderSetNotify provides notifier that pushes answers to LINE
+var ProviderSetNotify = wire.NewSet(
+	wire.Bind(new(notify.Sender), new(notify.SenderLINE)),
+	notify.NewSenderLINE,
+	notify.New,
+)
+
+// ProviderSetNotifyTest provides notifier that records answers in memory
+var ProviderSetNotifyTest = wire.NewSet(
+	wire.Bind(new(notify.Sender), new(*notify.Recorder)),
+	notify.NewRecorder,
+	notify.New,
+)
+
+// ProviderSetOutbox provides outbox relay with sinks from config
+var ProviderSetOutbox = wire.NewSet(
+	outbox.NewSinks,
+	outbox.NewRelay,
+)
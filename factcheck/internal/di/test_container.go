@@ -8,6 +8,8 @@ import (
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -17,6 +19,8 @@ func NewTest(
 	querier postgres.Querier,
 	repo repo.Repository,
 	service core.Service,
+	notifier notify.Notifier,
+	relay outbox.Relay,
 ) (
 	Container,
 	func(),
@@ -31,15 +35,22 @@ func NewTest(
 		PostgresQuerier: querier,
 		Repository:      repo,
 		Service:         service,
+		Notifier:        notifier,
+		Relay:           relay,
 	}, cleanup
 }
 
 func clearData(conn postgres.DBTX, stage string) {
-	tables := [4]string{
+	tables := [9]string{
 		"topics",
 		"messages_v2",
 		"message_groups",
 		"answers",
+		"deliveries",
+		"outbox",
+		"user_roles",
+		"rate_limits",
+		"line_webhook_events",
 	}
 	ctx := context.Background()
 	slog.WarnContext(ctx, "Clearing all data from database", "stage", stage)
@@ -50,6 +61,17 @@ func clearData(conn postgres.DBTX, stage string) {
 			panic(err)
 		}
 	}
+	// Append-only tables reject DELETE, but not TRUNCATE
+	appendOnly := [1]string{
+		"audit_events",
+	}
+	for i, t := range appendOnly {
+		_, err := conn.Exec(ctx, fmt.Sprintf("TRUNCATE %s", t))
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to truncate table", "i", i, "table", t)
+			panic(err)
+		}
+	}
 	slog.WarnContext(ctx, "Cleared all data from database", "stage", stage)
 }
 
@@ -10,6 +10,11 @@ import (
 	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
 )
 
@@ -24,10 +29,21 @@ func InitializeContainerTest() (Container, func(), error) {
 	if err != nil {
 		return Container{}, nil, err
 	}
-	queries := postgres.New(pool)
+	queries := postgres.NewQueries(pool)
 	repository := repo.New(queries, pool)
-	serviceFactcheck := core.New(repository)
-	container, cleanup2 := NewTest(configConfig, pool, queries, repository, serviceFactcheck)
+	stub := links.NewStub()
+	detectorScript := language.NewDetectorScript()
+	policy, err := ratelimit.New(configConfig, repository)
+	if err != nil {
+		cleanup()
+		return Container{}, nil, err
+	}
+	serviceFactcheck := core.New(configConfig, repository, stub, stub, detectorScript, policy)
+	recorder := notify.NewRecorder()
+	notifier := notify.New(repository, recorder)
+	v := outbox.NewSinks(configConfig, notifier)
+	relay := outbox.NewRelay(configConfig, repository, v, policy)
+	container, cleanup2 := NewTest(configConfig, pool, queries, repository, serviceFactcheck, notifier, relay)
 	return container, func() {
 		cleanup2()
 		cleanup()
@@ -0,0 +1,203 @@
+// Package health checks whether dependencies of factcheck programs are ready,
+// like Postgres, its schema and enabled integrations.
+//
+// Integrations are optional: their failures only degrade reports,
+// so that instances are not taken out of service because LINE or webhooks are down.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded" // Only optional checks failed
+	StatusFail     Status = "fail"
+)
+
+// intervalIntegrations is how long results of integration checks are reused,
+// so that frequent probes do not call their APIs every time
+const intervalIntegrations = 5 * time.Minute
+
+// Names of checks from New
+const (
+	CheckPostgres      = "postgres"
+	CheckMigrations    = "migrations"
+	CheckLINE          = "line"
+	CheckOutboxWebhook = "outbox_webhook"
+)
+
+// Check returns nil if a dependency is ready
+type Check func(ctx context.Context) error
+
+// Result is result of a check
+type Result struct {
+	Status     Status `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is results of all checks by their names.
+// It is ok only if all checks are ok, and fails if any check that is not optional fails.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs named checks concurrently, each with a timeout
+type Checker struct {
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration, checks map[string]Check) Checker {
+	return Checker{checks: checks, timeout: timeout}
+}
+
+// New returns checker of Postgres, its migrations and integrations enabled in conf.
+// Checks time out at half the HTTP write timeout, so that readiness endpoints
+// can still report which checks timed out.
+func New(conf config.Config, pool *pgxpool.Pool) Checker {
+	checks := map[string]Check{
+		CheckPostgres: pool.Ping,
+		CheckMigrations: func(ctx context.Context) error {
+			return migrate.Check(ctx, pool)
+		},
+	}
+	if conf.LINE.ChannelAccessToken != "" {
+		ping := line.NewClient(conf.LINE.Endpoint, conf.LINE.ChannelAccessToken).Ping
+		checks[CheckLINE] = Optional(Cached(ping, intervalIntegrations))
+	}
+	if conf.Outbox.WebhookURL != "" {
+		checks[CheckOutboxWebhook] = Optional(Cached(CheckDial(conf.Outbox.WebhookURL), intervalIntegrations))
+	}
+	timeout := utils.DefaultIfZero(time.Duration(conf.HTTP.TimeoutMsWrite)*time.Millisecond, time.Second) / 2
+	return NewChecker(timeout, checks)
+}
+
+// Check runs all checks and reports their results
+func (c Checker) Check(ctx context.Context) Report {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			switch {
+			case result.Status == StatusFail:
+				report.Status = StatusFail
+			case result.Status == StatusDegraded && report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func run(ctx context.Context, check Check) Result {
+	start := utils.TimeNow()
+	err := check(ctx)
+	result := Result{Status: StatusOK, DurationMs: utils.TimeSince(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+		if errors.As(err, new(errOptional)) {
+			result.Status = StatusDegraded
+		}
+	}
+	return result
+}
+
+// errOptional is error of optional checks
+type errOptional struct {
+	err error
+}
+
+func (e errOptional) Error() string {
+	return e.err.Error()
+}
+
+func (e errOptional) Unwrap() error {
+	return e.err
+}
+
+// Optional returns check whose failures only degrade reports
+func Optional(check Check) Check {
+	return func(ctx context.Context) error {
+		err := check(ctx)
+		if err != nil {
+			return errOptional{err: err}
+		}
+		return nil
+	}
+}
+
+// Cached returns check that reuses the last result of check for interval.
+// Concurrent checks wait for the same result.
+func Cached(check Check, interval time.Duration) Check {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		last    error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checked.IsZero() && utils.TimeSince(checked) < interval {
+			return last
+		}
+		last = check(ctx)
+		checked = utils.TimeNow()
+		return last
+	}
+}
+
+// CheckDial returns check that connects to host of rawURL over TCP,
+// for integrations whose endpoints should not be sent requests just for checks.
+func CheckDial(rawURL string) Check {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return err
+		}
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+		if err != nil {
+			return fmt.Errorf("cannot connect to %s: %w", u.Host, err)
+		}
+		return conn.Close()
+	}
+}
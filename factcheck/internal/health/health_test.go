@@ -0,0 +1,119 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/health"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		report := health.NewChecker(time.Second, map[string]health.Check{
+			"a": func(context.Context) error { return nil },
+			"b": func(context.Context) error { return nil },
+		}).Check(t.Context())
+		if report.Status != health.StatusOK || len(report.Checks) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("no checks", func(t *testing.T) {
+		report := health.Checker{}.Check(t.Context())
+		if report.Status != health.StatusOK {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		report := health.NewChecker(time.Second, map[string]health.Check{
+			"ok":   func(context.Context) error { return nil },
+			"fail": func(context.Context) error { return errors.New("some error") },
+		}).Check(t.Context())
+		if report.Status != health.StatusFail {
+			t.Fatalf("unexpected report status: %+v", report)
+		}
+		if report.Checks["ok"].Status != health.StatusOK {
+			t.Fatalf("unexpected result of ok check: %+v", report.Checks["ok"])
+		}
+		if report.Checks["fail"].Status != health.StatusFail || report.Checks["fail"].Error != "some error" {
+			t.Fatalf("unexpected result of failed check: %+v", report.Checks["fail"])
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		report := health.NewChecker(10*time.Millisecond, map[string]health.Check{
+			"slow": func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}).Check(t.Context())
+		if report.Status != health.StatusFail || report.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+	})
+}
+
+func TestCheckDial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	defer l.Close()
+
+	err = health.CheckDial("http://" + addr + "/webhook")(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Close()
+	err = health.CheckDial("http://" + addr + "/webhook")(t.Context())
+	if err == nil {
+		t.Fatal("unexpected nil error after listener closed")
+	}
+}
+
+func TestOptional(t *testing.T) {
+	report := health.NewChecker(time.Second, map[string]health.Check{
+		"ok":       func(context.Context) error { return nil },
+		"optional": health.Optional(func(context.Context) error { return errors.New("some error") }),
+	}).Check(t.Context())
+	if report.Status != health.StatusDegraded || report.Checks["optional"].Status != health.StatusDegraded || report.Checks["optional"].Error != "some error" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	report = health.NewChecker(time.Second, map[string]health.Check{
+		"fail":     func(context.Context) error { return errors.New("some error") },
+		"optional": health.Optional(func(context.Context) error { return errors.New("some error") }),
+	}).Check(t.Context())
+	if report.Status != health.StatusFail {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestCached(t *testing.T) {
+	now := utils.TimeNow()
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	calls := 0
+	check := health.Cached(func(context.Context) error {
+		calls++
+		return nil
+	}, time.Minute)
+	for range 3 {
+		if err := check(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	utils.TimeFreeze(now.Add(time.Minute))
+	if err := check(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("unexpected %d calls", calls)
+	}
+}
@@ -0,0 +1,58 @@
+// Package language detects languages of message texts
+package language
+
+import (
+	"unicode"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+)
+
+// Detector detects language of text, and returns empty language if unsure.
+// Implementations could use better models than DetectorScript.
+type Detector interface {
+	Detect(text string) factcheck.Language
+}
+
+// DetectorScript detects Thai and English by counting letters in Thai and Latin scripts.
+// Thai messages often mix in English words, so text is Thai
+// if at least ThresholdThai of its letters are Thai.
+type DetectorScript struct {
+	ThresholdThai  float64
+	ThresholdLatin float64
+}
+
+func NewDetectorScript() DetectorScript {
+	return DetectorScript{
+		ThresholdThai:  0.3,
+		ThresholdLatin: 0.5,
+	}
+}
+
+func (d DetectorScript) Detect(text string) factcheck.Language {
+	// URLs are Latin regardless of the language around them
+	text = dedup.RemoveURLs(text)
+	var thai, latin, letters int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	switch {
+	case float64(thai)/float64(letters) >= d.ThresholdThai:
+		return factcheck.LanguageThai
+	case float64(latin)/float64(letters) >= d.ThresholdLatin:
+		return factcheck.LanguageEnglish
+	}
+	return ""
+}
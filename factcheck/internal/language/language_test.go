@@ -0,0 +1,28 @@
+package language_test
+
+import (
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+)
+
+func TestDetectorScript(t *testing.T) {
+	tests := map[string]factcheck.Language{
+		"น้ำมะนาวโซดารักษามะเร็งได้":                  factcheck.LanguageThai,
+		"ข่าวด่วน COVID-19 vaccine ทำให้เป็นโรคหัวใจ": factcheck.LanguageThai,
+		"Lemon soda cures cancer":                                             factcheck.LanguageEnglish,
+		"Lemon soda cures cancer https://example.com/ข่าว":                    factcheck.LanguageEnglish,
+		"ดูนี่ https://example.com/lemon-soda-cures-cancer-share-with-family": factcheck.LanguageThai,
+		"柠檬苏打水可以治愈癌症":                                                         "",
+		"🍋🥤 12345 !!!":                                                        "",
+		"":                                                                    "",
+	}
+	detector := language.NewDetectorScript()
+	for text, expected := range tests {
+		actual := detector.Detect(text)
+		if actual != expected {
+			t.Fatalf("unexpected language for '%s': expected '%s', got '%s'", text, expected, actual)
+		}
+	}
+}
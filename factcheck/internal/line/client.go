@@ -0,0 +1,131 @@
+package line
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+const (
+	// EndpointAPI is the default base URL of LINE Messaging API
+	EndpointAPI = "https://api.line.me"
+
+	pathPush    = "/v2/bot/message/push"
+	pathReply   = "/v2/bot/message/reply"
+	pathBotInfo = "/v2/bot/info"
+
+	// headerRetryKey lets LINE deduplicate our retried push requests
+	headerRetryKey = "X-Line-Retry-Key"
+	// headerAcceptedRequestID is set on 409 responses to retry keys LINE already accepted
+	headerAcceptedRequestID = "X-Line-Accepted-Request-Id"
+)
+
+// TextMessage is an outgoing LINE text message
+type TextMessage struct {
+	Type TypeMessage `json:"type"`
+	Text string      `json:"text"`
+}
+
+// Client is a minimal LINE Messaging API client for sending messages
+type Client struct {
+	endpoint    string
+	accessToken string
+	http        *http.Client
+}
+
+// NewClient returns a new client for channel with accessToken.
+// If endpoint is empty, EndpointAPI is used.
+func NewClient(endpoint string, accessToken string) *Client {
+	if endpoint == "" {
+		endpoint = EndpointAPI
+	}
+	return &Client{
+		endpoint:    endpoint,
+		accessToken: accessToken,
+		http:        &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+	}
+}
+
+func Text(text string) TextMessage {
+	return TextMessage{Type: TypeMessageText, Text: text}
+}
+
+// Push pushes messages to a user, group, or room.
+// retryKey should be a UUID, and must stay the same across retries of the same push.
+// Retries of pushes LINE already accepted succeed without sending again.
+func (c *Client) Push(ctx context.Context, to string, retryKey string, messages ...TextMessage) error {
+	body := struct {
+		To       string        `json:"to"`
+		Messages []TextMessage `json:"messages"`
+	}{
+		To:       to,
+		Messages: messages,
+	}
+	header := http.Header{}
+	if retryKey != "" {
+		header.Set(headerRetryKey, retryKey)
+	}
+	return c.post(ctx, pathPush, header, body)
+}
+
+// Reply replies to a webhook event with its replyToken.
+// Reply tokens expire shortly after the event is sent to the webhook.
+func (c *Client) Reply(ctx context.Context, replyToken string, messages ...TextMessage) error {
+	body := struct {
+		ReplyToken string        `json:"replyToken"`
+		Messages   []TextMessage `json:"messages"`
+	}{
+		ReplyToken: replyToken,
+		Messages:   messages,
+	}
+	return c.post(ctx, pathReply, http.Header{}, body)
+}
+
+// Ping checks that the API is reachable and accepts the channel access token,
+// by getting the bot info without using any message quota.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, pathBotInfo, http.Header{}, nil)
+}
+
+func (c *Client) post(ctx context.Context, path string, header http.Header, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal line request error: %w", err)
+	}
+	header.Set("Content-Type", "application/json")
+	return c.do(ctx, http.MethodPost, path, header, b)
+}
+
+// do sends request with body, which may be nil, and discards the response
+func (c *Client) do(ctx context.Context, method string, path string, header http.Header, body []byte) error {
+	if c.accessToken == "" {
+		return errors.New("empty line channel access token")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("line request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode == http.StatusConflict && header.Get(headerRetryKey) != "" && resp.Header.Get(headerAcceptedRequestID) != "" {
+		// Retried push was already sent
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:errcheck
+	return fmt.Errorf("line api %s returned %d: %s", path, resp.StatusCode, msg)
+}
@@ -0,0 +1,103 @@
+package line_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+)
+
+func TestClientPush(t *testing.T) {
+	type pushed struct {
+		To       string             `json:"to"`
+		Messages []line.TextMessage `json:"messages"`
+	}
+	var got pushed
+	var auth, retryKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/bot/message/push" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		retryKey = r.Header.Get("X-Line-Retry-Key")
+		err := json.NewDecoder(r.Body).Decode(&got)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}")) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	client := line.NewClient(srv.URL, "some-token")
+	err := client.Push(t.Context(), "C1", "some-retry-key", line.Text("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer some-token" {
+		t.Fatalf("unexpected auth header: '%s'", auth)
+	}
+	if retryKey != "some-retry-key" {
+		t.Fatalf("unexpected retry key: '%s'", retryKey)
+	}
+	if got.To != "C1" || len(got.Messages) != 1 || got.Messages[0] != line.Text("hello") {
+		t.Fatalf("unexpected push body: %+v", got)
+	}
+
+	err = line.NewClient(srv.URL, "").Push(t.Context(), "C1", "", line.Text("hello"))
+	if err == nil {
+		t.Fatal("unexpected nil error with empty access token")
+	}
+	err = client.Reply(t.Context(), "some-reply-token", line.Text("hello"))
+	if err == nil {
+		t.Fatal("unexpected nil error from server returning 404")
+	}
+}
+
+func TestClientPush_Accepted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Line-Retry-Key") == "accepted-retry-key" {
+			w.Header().Set("X-Line-Accepted-Request-Id", "some-request-id")
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	client := line.NewClient(srv.URL, "some-token")
+	err := client.Push(t.Context(), "C1", "accepted-retry-key", line.Text("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error retrying accepted push: %v", err)
+	}
+	err = client.Push(t.Context(), "C1", "", line.Text("hello"))
+	if err == nil {
+		t.Fatal("unexpected nil error from server returning 409")
+	}
+}
+
+func TestClientPing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/bot/info" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer some-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"userId":"U1"}`)) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	err := line.NewClient(srv.URL, "some-token").Ping(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = line.NewClient(srv.URL, "bad-token").Ping(t.Context())
+	if err == nil {
+		t.Fatal("unexpected nil error with rejected access token")
+	}
+}
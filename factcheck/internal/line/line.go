@@ -0,0 +1,161 @@
+// Package line provides types and helpers for LINE Messaging API.
+// It only knows about LINE, and maps LINE concepts into types defined in package factcheck.
+package line
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+// HeaderSignature is the HTTP header in which LINE sends webhook body signature
+const HeaderSignature = "X-Line-Signature"
+
+type (
+	TypeEvent   string
+	TypeSource  string
+	TypeMessage string
+)
+
+const (
+	TypeEventMessage  TypeEvent = "message"
+	TypeEventFollow   TypeEvent = "follow"
+	TypeEventUnfollow TypeEvent = "unfollow"
+	TypeEventJoin     TypeEvent = "join"
+	TypeEventLeave    TypeEvent = "leave"
+
+	TypeSourceUser  TypeSource = "user"
+	TypeSourceGroup TypeSource = "group"
+	TypeSourceRoom  TypeSource = "room"
+
+	TypeMessageText    TypeMessage = "text"
+	TypeMessageImage   TypeMessage = "image"
+	TypeMessageSticker TypeMessage = "sticker"
+)
+
+// Webhook is the request body sent by LINE platform to our webhook URL.
+// LINE sends a webhook with empty events when verifying the webhook URL.
+type Webhook struct {
+	Destination string  `json:"destination"`
+	Events      []Event `json:"events"`
+}
+
+type Event struct {
+	Type            TypeEvent       `json:"type"`
+	Mode            string          `json:"mode"`
+	Timestamp       int64           `json:"timestamp"`
+	Source          Source          `json:"source"`
+	ReplyToken      string          `json:"replyToken"`
+	WebhookEventID  string          `json:"webhookEventId"`
+	DeliveryContext DeliveryContext `json:"deliveryContext"`
+	Message         *Message        `json:"message,omitempty"`
+}
+
+type Source struct {
+	Type    TypeSource `json:"type"`
+	UserID  string     `json:"userId"`
+	GroupID string     `json:"groupId,omitempty"`
+	RoomID  string     `json:"roomId,omitempty"`
+}
+
+type Message struct {
+	ID   string      `json:"id"`
+	Type TypeMessage `json:"type"`
+	Text string      `json:"text,omitempty"`
+}
+
+type DeliveryContext struct {
+	IsRedelivery bool `json:"isRedelivery"`
+}
+
+// VerifySignature verifies that body was signed by LINE with our channel secret.
+// The signature is base64-encoded HMAC-SHA256 digest of the request body.
+func VerifySignature(channelSecret string, body []byte, signature string) error {
+	if channelSecret == "" {
+		return errors.New("empty channel secret")
+	}
+	if signature == "" {
+		return errors.New("empty signature")
+	}
+	expected, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("bad signature encoding: %w", err)
+	}
+	if !hmac.Equal(Sign(channelSecret, body), expected) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// Sign returns raw HMAC-SHA256 digest of body, keyed with channelSecret
+func Sign(channelSecret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(channelSecret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func ParseWebhook(body []byte) (Webhook, error) {
+	var w Webhook
+	err := json.Unmarshal(body, &w)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("bad webhook body: %w", err)
+	}
+	return w, nil
+}
+
+// Text returns message text if e is a text message event
+func (e Event) Text() (string, bool) {
+	if e.Type != TypeEventMessage || e.Message == nil {
+		return "", false
+	}
+	if e.Message.Type != TypeMessageText || e.Message.Text == "" {
+		return "", false
+	}
+	return e.Message.Text, true
+}
+
+// ChatID returns ID of the chat the event came from,
+// which is also the push destination for replies.
+func (s Source) ChatID() string {
+	switch s.Type {
+	case TypeSourceGroup:
+		return s.GroupID
+	case TypeSourceRoom:
+		return s.RoomID
+	}
+	return s.UserID
+}
+
+// UserInfo maps event source to factcheck.UserInfo.
+// 1:1 chats map to TypeUserMessageLINEChat, while groups and multi-person rooms
+// map to TypeUserMessageLINEGroupChat.
+func (s Source) UserInfo() (factcheck.UserInfo, error) {
+	var userType factcheck.TypeUser
+	switch s.Type {
+	case TypeSourceUser:
+		userType = factcheck.TypeUserMessageLINEChat
+	case TypeSourceGroup, TypeSourceRoom:
+		userType = factcheck.TypeUserMessageLINEGroupChat
+	default:
+		return factcheck.UserInfo{}, fmt.Errorf("unexpected source type '%s'", s.Type)
+	}
+	chatID := s.ChatID()
+	if chatID == "" {
+		return factcheck.UserInfo{}, fmt.Errorf("empty chat id for source type '%s'", s.Type)
+	}
+	userID := s.UserID
+	if userID == "" {
+		// LINE omits userId in groups when the user has not consented to share it
+		userID = chatID
+	}
+	return factcheck.UserInfo{
+		UserType: userType,
+		UserID:   userID,
+		ChatID:   chatID,
+	}, nil
+}
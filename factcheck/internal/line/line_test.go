@@ -0,0 +1,91 @@
+package line_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := "some-channel-secret"
+	body := []byte(`{"destination":"U123","events":[]}`)
+	signature := base64.StdEncoding.EncodeToString(line.Sign(secret, body))
+
+	err := line.VerifySignature(secret, body, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+	}{
+		{name: "empty secret", secret: "", body: body, signature: signature},
+		{name: "empty signature", secret: secret, body: body, signature: ""},
+		{name: "wrong secret", secret: "other-secret", body: body, signature: signature},
+		{name: "tampered body", secret: secret, body: []byte(`{"destination":"U124","events":[]}`), signature: signature},
+		{name: "bad encoding", secret: secret, body: body, signature: "%%%"},
+	}
+	for _, b := range bad {
+		err := line.VerifySignature(b.secret, b.body, b.signature)
+		if err == nil {
+			t.Fatalf("unexpected nil error for case '%s'", b.name)
+		}
+	}
+}
+
+func TestSourceUserInfo(t *testing.T) {
+	ok := []struct {
+		source   line.Source
+		expected factcheck.UserInfo
+	}{
+		{
+			source: line.Source{Type: line.TypeSourceUser, UserID: "U1"},
+			expected: factcheck.UserInfo{
+				UserType: factcheck.TypeUserMessageLINEChat,
+				UserID:   "U1",
+				ChatID:   "U1",
+			},
+		},
+		{
+			source: line.Source{Type: line.TypeSourceGroup, UserID: "U1", GroupID: "C1"},
+			expected: factcheck.UserInfo{
+				UserType: factcheck.TypeUserMessageLINEGroupChat,
+				UserID:   "U1",
+				ChatID:   "C1",
+			},
+		},
+		{
+			source: line.Source{Type: line.TypeSourceRoom, RoomID: "R1"},
+			expected: factcheck.UserInfo{
+				UserType: factcheck.TypeUserMessageLINEGroupChat,
+				UserID:   "R1",
+				ChatID:   "R1",
+			},
+		},
+	}
+	for i := range ok {
+		actual, err := ok[i].source.UserInfo()
+		if err != nil {
+			t.Fatalf("unexpected error for source %+v: %v", ok[i].source, err)
+		}
+		if actual != ok[i].expected {
+			t.Fatalf("unexpected user info %+v, expected %+v", actual, ok[i].expected)
+		}
+	}
+
+	bad := []line.Source{
+		{Type: "unknown", UserID: "U1"},
+		{Type: line.TypeSourceUser},
+		{Type: line.TypeSourceGroup, UserID: "U1"},
+	}
+	for i := range bad {
+		_, err := bad[i].UserInfo()
+		if err == nil {
+			t.Fatalf("unexpected nil error for source %+v", bad[i])
+		}
+	}
+}
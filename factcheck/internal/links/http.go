@@ -0,0 +1,195 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// maxPreviewBytes limits how much of a page FetcherHTTP reads.
+// Title and meta tags are expected in the head.
+const maxPreviewBytes = 512 << 10
+
+var (
+	reTitle     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	reMeta      = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	reAttribute = regexp.MustCompile(`(?is)([a-z][a-z:_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ResolverHTTP resolves known short links by following their redirects
+type ResolverHTTP struct {
+	http *http.Client
+}
+
+// ResolverNoop never resolves short links.
+// It is used when resolving is disabled, and in tests.
+type ResolverNoop struct{}
+
+// FetcherHTTP fetches previews from HTML title and meta tags
+type FetcherHTTP struct {
+	http *http.Client
+}
+
+// FetcherNoop never fetches previews, and is used when fetching is disabled
+type FetcherNoop struct{}
+
+// NewResolver returns ResolverHTTP, or ResolverNoop if disabled by conf
+func NewResolver(conf config.Config) Resolver {
+	if !conf.Links.ResolveShorteners {
+		return ResolverNoop{}
+	}
+	return ResolverHTTP{http: newClient(conf)}
+}
+
+// NewFetcher returns FetcherHTTP, or FetcherNoop if disabled by conf
+func NewFetcher(conf config.Config) Fetcher {
+	if !conf.Links.FetchPreview {
+		return FetcherNoop{}
+	}
+	return FetcherHTTP{http: newClient(conf)}
+}
+
+// Timeout returns timeout of each link request of conf.
+// Submissions may resolve and then fetch a link before responding,
+// so each request is limited to a quarter of the write timeout.
+func Timeout(conf config.Config) time.Duration {
+	write := utils.DefaultIfZero(time.Duration(conf.HTTP.TimeoutMsWrite)*time.Millisecond, time.Second)
+	return min(time.Duration(conf.Links.TimeoutMs)*time.Millisecond, write/4)
+}
+
+// newClient returns HTTP client that refuses to connect to private networks,
+// because links come from untrusted users
+func newClient(conf config.Config) *http.Client {
+	timeout := Timeout(conf)
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracing.TransportUntrusted(transport),
+	}
+}
+
+func (r ResolverHTTP) Resolve(ctx context.Context, u string) (string, error) {
+	if !IsShortener(u) {
+		return u, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("unexpected status %d resolving %s", resp.StatusCode, u)
+	}
+	// The client follows redirects, so the last request is the destination
+	return resp.Request.URL.String(), nil
+}
+
+func (ResolverNoop) Resolve(_ context.Context, u string) (string, error) {
+	return u, nil
+}
+
+func (f FetcherHTTP) Fetch(ctx context.Context, u string) (factcheck.LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return factcheck.LinkPreview{}, err
+	}
+	req.Header.Set("Accept", "text/html")
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return factcheck.LinkPreview{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return factcheck.LinkPreview{}, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, u)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "html") {
+		return factcheck.LinkPreview{}, fmt.Errorf("unexpected content type '%s' fetching %s", contentType, u)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewBytes))
+	if err != nil {
+		return factcheck.LinkPreview{}, err
+	}
+	return ParsePreview(u, string(body))
+}
+
+func (FetcherNoop) Fetch(_ context.Context, u string) (factcheck.LinkPreview, error) {
+	return factcheck.LinkPreview{URL: u}, nil
+}
+
+// ParsePreview parses preview from HTML page,
+// preferring Open Graph tags over title and description tags
+func ParsePreview(u string, page string) (factcheck.LinkPreview, error) {
+	meta := make(map[string]string)
+	for _, tag := range reMeta.FindAllString(page, -1) {
+		var key, content string
+		for _, attr := range reAttribute.FindAllStringSubmatch(tag, -1) {
+			value := attr[2] + attr[3]
+			switch strings.ToLower(attr[1]) {
+			case "property", "name":
+				key = strings.ToLower(value)
+			case "content":
+				content = value
+			}
+		}
+		if key != "" && content != "" {
+			if _, ok := meta[key]; !ok {
+				meta[key] = clean(content)
+			}
+		}
+	}
+	preview := factcheck.LinkPreview{
+		URL:         u,
+		Title:       meta["og:title"],
+		Description: meta["og:description"],
+		SiteName:    meta["og:site_name"],
+	}
+	if preview.Title == "" {
+		if m := reTitle.FindStringSubmatch(page); m != nil {
+			preview.Title = clean(m[1])
+		}
+	}
+	if preview.Description == "" {
+		preview.Description = meta["description"]
+	}
+	if preview.Title == "" && preview.Description == "" {
+		return preview, errors.New("no title or description found")
+	}
+	return preview, nil
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
@@ -0,0 +1,80 @@
+// Package links handles URL messages: detecting them, canonicalizing them
+// for grouping, and fetching previews of linked pages.
+//
+// Network access is abstracted by Resolver and Fetcher,
+// so that tests and deployments without internet access could use offline implementations.
+package links
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+)
+
+// Resolver resolves short links to their destinations.
+// Implementations should return u unchanged if u is not a short link.
+type Resolver interface {
+	Resolve(ctx context.Context, u string) (string, error)
+}
+
+// Fetcher fetches preview of linked page
+type Fetcher interface {
+	Fetch(ctx context.Context, u string) (factcheck.LinkPreview, error)
+}
+
+// shorteners are hosts of known URL shorteners
+var shorteners = map[string]struct{}{
+	"bit.ly":      {},
+	"buff.ly":     {},
+	"cutt.ly":     {},
+	"goo.gl":      {},
+	"is.gd":       {},
+	"lin.ee":      {},
+	"ow.ly":       {},
+	"rebrand.ly":  {},
+	"s.id":        {},
+	"shorturl.at": {},
+	"t.co":        {},
+	"t.ly":        {},
+	"tiny.cc":     {},
+	"tinyurl.com": {},
+}
+
+// IsURL reports whether the whole text is a single http or https URL
+func IsURL(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// IsShortener reports whether u is hosted on a known URL shortener
+func IsShortener(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	_, ok := shorteners[strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")]
+	return ok
+}
+
+// Canonical returns canonical form of u, after resolving short links with resolver.
+// Failure to resolve is only logged, and the unresolved link is canonicalized instead.
+func Canonical(ctx context.Context, resolver Resolver, u string) (string, error) {
+	u = strings.TrimSpace(u)
+	resolved, err := resolver.Resolve(ctx, u)
+	if err != nil {
+		slog.WarnContext(ctx, "error resolving link", "url", u, "err", err)
+		resolved = u
+	}
+	return dedup.CanonicalURL(resolved)
+}
@@ -0,0 +1,120 @@
+package links_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+)
+
+func TestIsURL(t *testing.T) {
+	urls := []string{
+		"https://example.com",
+		" http://example.com/news?id=1 ",
+		"https://bit.ly/abc",
+	}
+	for _, u := range urls {
+		if !links.IsURL(u) {
+			t.Fatalf("unexpected non-URL: '%s'", u)
+		}
+	}
+	notURLs := []string{
+		"",
+		"example.com",
+		"ftp://example.com/file",
+		"see https://example.com",
+		"https://example.com is fake",
+		"lemon soda cures cancer",
+	}
+	for _, u := range notURLs {
+		if links.IsURL(u) {
+			t.Fatalf("unexpected URL: '%s'", u)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	stub := links.NewStub()
+	stub.Redirect("https://bit.ly/abc", "https://www.example.com/news/?utm_campaign=x")
+	tests := map[string]string{
+		"https://bit.ly/abc":                    "https://example.com/news",
+		"https://example.com/news?fbclid=1#top": "https://example.com/news",
+		"https://bit.ly/unknown":                "https://bit.ly/unknown",
+	}
+	for u, expected := range tests {
+		actual, err := links.Canonical(t.Context(), stub, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Fatalf("unexpected canonical url for %s: expected %s, got %s", u, expected, actual)
+		}
+	}
+	if !links.IsShortener("https://BIT.LY/abc") || links.IsShortener("https://example.com/abc") {
+		t.Fatal("unexpected shortener detection")
+	}
+}
+
+func TestParsePreview(t *testing.T) {
+	page := `<html><head>
+		<title>Fallback &amp; title</title>
+		<meta name="description" content="Fallback description">
+		<meta content="Lemon soda   cures cancer" property="og:title" />
+		<meta property='og:site_name' content='Example News'>
+	</head><body></body></html>`
+	preview, err := links.ParsePreview("https://example.com/news", page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Title != "Lemon soda cures cancer" {
+		t.Fatalf("unexpected title: '%s'", preview.Title)
+	}
+	if preview.Description != "Fallback description" {
+		t.Fatalf("unexpected description: '%s'", preview.Description)
+	}
+	if preview.SiteName != "Example News" {
+		t.Fatalf("unexpected site name: '%s'", preview.SiteName)
+	}
+
+	_, err = links.ParsePreview("https://example.com/empty", "<html></html>")
+	if err == nil {
+		t.Fatal("unexpected nil error for page without title")
+	}
+}
+
+func TestFetcherHTTP_PrivateNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<title>internal</title>"))
+	}))
+	defer server.Close()
+
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Links.FetchPreview = true
+	_, err = links.NewFetcher(conf).Fetch(t.Context(), server.URL)
+	if err == nil {
+		t.Fatal("unexpected nil error fetching from loopback")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.HTTP.TimeoutMsWrite = 1000
+	conf.Links.TimeoutMs = 3000
+	if timeout := links.Timeout(conf); timeout != 250*time.Millisecond {
+		t.Fatalf("unexpected timeout capped by write timeout: %v", timeout)
+	}
+	conf.Links.TimeoutMs = 100
+	if timeout := links.Timeout(conf); timeout != 100*time.Millisecond {
+		t.Fatalf("unexpected timeout: %v", timeout)
+	}
+}
@@ -0,0 +1,68 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+// Stub is an offline Resolver and Fetcher backed by maps.
+// It is meant for tests.
+type Stub struct {
+	mut       sync.Mutex
+	redirects map[string]string
+	previews  map[string]factcheck.LinkPreview
+	fetched   []string
+}
+
+func NewStub() *Stub {
+	return &Stub{
+		redirects: make(map[string]string),
+		previews:  make(map[string]factcheck.LinkPreview),
+	}
+}
+
+// Redirect makes short resolve to destination
+func (s *Stub) Redirect(short string, destination string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.redirects[short] = destination
+}
+
+// Preview makes u fetch preview
+func (s *Stub) Preview(u string, preview factcheck.LinkPreview) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.previews[u] = preview
+}
+
+// Fetched returns a copy of every URL fetched so far
+func (s *Stub) Fetched() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	fetched := make([]string, len(s.fetched))
+	copy(fetched, s.fetched)
+	return fetched
+}
+
+func (s *Stub) Resolve(_ context.Context, u string) (string, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if destination, ok := s.redirects[u]; ok {
+		return destination, nil
+	}
+	return u, nil
+}
+
+func (s *Stub) Fetch(_ context.Context, u string) (factcheck.LinkPreview, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.fetched = append(s.fetched, u)
+	preview, ok := s.previews[u]
+	if !ok {
+		return factcheck.LinkPreview{}, fmt.Errorf("no preview for %s", u)
+	}
+	return preview, nil
+}
@@ -0,0 +1,33 @@
+// Package metrics provides the Prometheus registry of our metrics.
+// Metrics are registered to Registry, usually with promauto.With(Registry),
+// and are served by Handler for Prometheus to scrape.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// BucketsLatency are histogram buckets in seconds for latencies of HTTP requests and queries
+var BucketsLatency = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry is the registry served by Handler.
+// Besides our metrics, it has go_* metrics of the Go runtime and process_* metrics of the process.
+var Registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}
+
+// Handler serves metrics of Registry
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,39 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "factcheck_test_total",
+		Help: "Some counter",
+	}, []string{"label"}).WithLabelValues(`say "hi"`).Inc()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("unexpected content type: %s", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`factcheck_test_total{label="say \"hi\""} 1` + "\n",
+		"# TYPE go_goroutines gauge\n",
+		"# TYPE process_start_time_seconds gauge\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("unexpected body without '%s':\n%s", expected, body)
+		}
+	}
+}
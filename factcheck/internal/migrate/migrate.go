@@ -0,0 +1,322 @@
+// Package migrate applies numbered schema migrations from postgres.Migrations,
+// and records applied versions in table schema_migrations.
+//
+// Each migration is applied in its own transaction, while holding an advisory lock
+// so that concurrent migrators never apply the same migration twice.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// ErrOutdated is returned by Check when some migrations are not yet applied
+var ErrOutdated = errors.New("outdated schema")
+
+// lockID is the key of the advisory lock held while migrating
+const lockID = 8_254_716_001
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // Empty for irreversible migrations
+}
+
+// Status is a migration, and when it was applied
+type Status struct {
+	Migration
+	AppliedAt *time.Time // Nil for pending migrations
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New returns Migrator of migrations embedded in postgres.Migrations
+func New(pool *pgxpool.Pool) (Migrator, error) {
+	migrations, err := Embedded()
+	if err != nil {
+		return Migrator{}, err
+	}
+	return Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Embedded loads migrations embedded in postgres.Migrations
+func Embedded() ([]Migration, error) {
+	fsys, err := fs.Sub(postgres.Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return Load(fsys)
+}
+
+// Load loads migrations from SQL files in fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		if !ok || direction != "up" && direction != "down" {
+			return nil, fmt.Errorf("bad migration file name '%s': expecting <version>_<name>.up.sql or .down.sql", file)
+		}
+		v, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(v)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("bad migration file name '%s': expecting positive version", file)
+		}
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration '%s': %w", file, err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("duplicate migration version %d: '%s' and '%s'", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+	return migrations, nil
+}
+
+// Up applies all pending migrations in order, and returns the applied migrations
+func (m Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	for {
+		migration, ok, err := m.step(ctx, func(versions map[int]time.Time) (Migration, bool) {
+			for _, migration := range m.migrations {
+				if _, ok := versions[migration.Version]; !ok {
+					return migration, true
+				}
+			}
+			return Migration{}, false
+		}, true)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			return applied, nil
+		}
+		applied = append(applied, migration)
+	}
+}
+
+// Down reverts the latest steps applied migrations, and returns the reverted migrations
+func (m Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	for range steps {
+		migration, ok, err := m.step(ctx, func(versions map[int]time.Time) (Migration, bool) {
+			for i := len(m.migrations) - 1; i >= 0; i-- {
+				if _, ok := versions[m.migrations[i].Version]; ok {
+					return m.migrations[i], true
+				}
+			}
+			return Migration{}, false
+		}, false)
+		if err != nil {
+			return reverted, err
+		}
+		if !ok {
+			break
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+// Status returns status of all migrations, ordered by version
+func (m Migrator) Status(ctx context.Context) ([]Status, error) {
+	return status(ctx, m.pool, m.migrations)
+}
+
+// Check returns ErrOutdated if migrations embedded in postgres.Migrations are not all applied
+func Check(ctx context.Context, db postgres.DBTX) error {
+	migrations, err := Embedded()
+	if err != nil {
+		return err
+	}
+	statuses, err := status(ctx, db, migrations)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) != 0 {
+		return fmt.Errorf("%w: pending migrations %v, run 'factcheck migrate up'", ErrOutdated, pending)
+	}
+	return nil
+}
+
+// step applies (or reverts if !up) the migration chosen by next from applied versions in a transaction.
+// It returns false if next chose nothing.
+func (m Migrator) step(
+	ctx context.Context,
+	next func(versions map[int]time.Time) (Migration, bool),
+	up bool,
+) (
+	Migration,
+	bool,
+	error,
+) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return Migration{}, false, err
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.ErrorContext(ctx, "error rolling back migration", "err", err)
+		}
+	}()
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockID)
+	if err != nil {
+		return Migration{}, false, fmt.Errorf("error locking migrations: %w", err)
+	}
+	err = ensureTable(ctx, tx)
+	if err != nil {
+		return Migration{}, false, err
+	}
+	versions, err := appliedVersions(ctx, tx)
+	if err != nil {
+		return Migration{}, false, err
+	}
+	migration, ok := next(versions)
+	if !ok {
+		return Migration{}, false, tx.Commit(ctx)
+	}
+	if up {
+		_, err = tx.Exec(ctx, migration.Up)
+		if err != nil {
+			return Migration{}, false, fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, utils.TimeNow())
+	} else {
+		if migration.Down == "" {
+			return Migration{}, false, fmt.Errorf("migration %d_%s is irreversible", migration.Version, migration.Name)
+		}
+		_, err = tx.Exec(ctx, migration.Down)
+		if err != nil {
+			return Migration{}, false, fmt.Errorf("error reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		_, err = tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	}
+	if err != nil {
+		return Migration{}, false, fmt.Errorf("error recording migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return migration, true, tx.Commit(ctx)
+}
+
+// ensureTable creates table schema_migrations if it does not exist.
+// Databases created from schema.sql before we had migrations are baselined at version 1.
+func ensureTable(ctx context.Context, tx pgx.Tx) error {
+	exists, err := tableExists(ctx, tx, "schema_migrations")
+	if err != nil || exists {
+		return err
+	}
+	_, err = tx.Exec(ctx, `CREATE TABLE schema_migrations (
+    version    bigint NOT NULL PRIMARY KEY,
+    name       text NOT NULL,
+    applied_at timestamptz NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+	baseline, err := tableExists(ctx, tx, "topics")
+	if err != nil || !baseline {
+		return err
+	}
+	slog.WarnContext(ctx, "baselining schema created before migrations at version 1")
+	_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (1, 'init', $1)", utils.TimeNow())
+	return err
+}
+
+func tableExists(ctx context.Context, db postgres.DBTX, table string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking table %s: %w", table, err)
+	}
+	return exists, nil
+}
+
+func appliedVersions(ctx context.Context, db postgres.DBTX) (map[int]time.Time, error) {
+	rows, err := db.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error listing applied migrations: %w", err)
+	}
+	defer rows.Close()
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		err := rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+func status(ctx context.Context, db postgres.DBTX, migrations []Migration) ([]Status, error) {
+	versions := map[int]time.Time{}
+	exists, err := tableExists(ctx, db, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		versions, err = appliedVersions(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
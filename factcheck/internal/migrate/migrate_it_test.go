@@ -0,0 +1,24 @@
+//go:build integration_test
+// +build integration_test
+
+package migrate_test
+
+import (
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+)
+
+func TestCheck(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+
+	err = migrate.Check(t.Context(), app.PostgresConn)
+	if err != nil {
+		t.Fatalf("expecting migrated test database: %v", err)
+	}
+}
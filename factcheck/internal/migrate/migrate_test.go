@@ -0,0 +1,99 @@
+package migrate_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":    {Data: []byte("CREATE INDEX")},
+		"0002_add_column.up.sql":   {Data: []byte("ALTER TABLE")},
+		"0001_init.up.sql":         {Data: []byte("CREATE TABLE")},
+		"0001_init.down.sql":       {Data: []byte("DROP TABLE")},
+		"0002_add_column.down.sql": {Data: []byte("ALTER TABLE DROP")},
+		"README.md":                {Data: []byte("ignored")},
+	}
+	migrations, err := migrate.Load(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []migrate.Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 2, Name: "add_column", Up: "ALTER TABLE", Down: "ALTER TABLE DROP"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX"},
+	}
+	if len(migrations) != len(expected) {
+		t.Fatalf("unexpected migrations: %+v", migrations)
+	}
+	for i := range expected {
+		if migrations[i] != expected[i] {
+			t.Fatalf("unexpected migration %d: expected=%+v, actual=%+v", i, expected[i], migrations[i])
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"no direction":      {"0001_init.sql": {Data: []byte("x")}},
+		"bad direction":     {"0001_init.sideways.sql": {Data: []byte("x")}},
+		"no version":        {"init.up.sql": {Data: []byte("x")}},
+		"zero version":      {"0000_init.up.sql": {Data: []byte("x")}},
+		"duplicate version": {"0001_init.up.sql": {Data: []byte("x")}, "0001_other.up.sql": {Data: []byte("x")}},
+		"no up":             {"0001_init.down.sql": {Data: []byte("x")}},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := migrate.Load(fsys)
+			if err == nil {
+				t.Fatalf("expecting error")
+			}
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := migrate.Embedded()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("no embedded migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration versions must be contiguous from 1: got %d at %d", m.Version, i)
+		}
+		if m.Down == "" {
+			t.Fatalf("migration %d_%s has no down migration", m.Version, m.Name)
+		}
+	}
+}
+
+// TestEmbedded_Baseline checks that migration 1 is schema.sql of before we had migrations,
+// which ensureTable baselines at version 1, only without its transaction
+func TestEmbedded_Baseline(t *testing.T) {
+	migrations, err := migrate.Embedded()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	schema, err := os.ReadFile("testdata/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl, ok := strings.CutPrefix(string(schema), "BEGIN;\n\n")
+	if !ok {
+		t.Fatalf("schema.sql does not begin transaction")
+	}
+	ddl, ok = strings.CutSuffix(ddl, "\nCOMMIT; \n")
+	if !ok {
+		t.Fatalf("schema.sql does not commit transaction")
+	}
+	if migrations[0].Up != ddl {
+		t.Fatalf("migration 1 is not schema.sql:\n%s", migrations[0].Up)
+	}
+}
@@ -0,0 +1,226 @@
+//go:build integration_test
+// +build integration_test
+
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/core"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/language"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/links"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/migrate"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+// upgradeSchema is where TestUpgrade creates its database from schema.sql,
+// so that it does not touch the migrated test database
+const upgradeSchema = "upgrade_test"
+
+// TestUpgrade applies schema.sql of before we had migrations (testdata/schema.sql),
+// with some data, then migrates it up and runs core flows on the migrated database.
+func TestUpgrade(t *testing.T) {
+	ctx := t.Context()
+	conf, err := config.NewTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := upgradePool(t, conf)
+
+	schema, err := os.ReadFile("testdata/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pool.Exec(ctx, string(schema))
+	if err != nil {
+		t.Fatalf("error applying schema.sql: %v", err)
+	}
+	const (
+		resolvedID = "550e8400-e29b-41d4-a716-446655440001"
+		pendingID  = "550e8400-e29b-41d4-a716-446655440002"
+		groupID    = "550e8400-e29b-41d4-a716-446655440003"
+		messageID  = "550e8400-e29b-41d4-a716-446655440004"
+		answerID   = "550e8400-e29b-41d4-a716-446655440005"
+	)
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	inserts := []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO topics (id, name, description, status, result, result_status, created_at) VALUES
+    ($1, 'Lemon soda', 'Lemon soda cures cancer', 'TOPIC_RESOLVED', 'It does not', 'VERDICT_FALSE', $3),
+    ($2, 'Hot water', 'Hot water kills viruses', 'TOPIC_PENDING', NULL, NULL, $3)`,
+			[]any{resolvedID, pendingID, createdAt}},
+		{`INSERT INTO message_groups (id, topic_id, name, text, text_sha1, language, created_at) VALUES
+    ($1, $2, 'Lemon soda', 'lemon soda cures cancer', 'sha1', 'en', $3)`,
+			[]any{groupID, resolvedID, createdAt}},
+		{`INSERT INTO messages_v2 (id, user_id, topic_id, group_id, type_user, type, text, language, created_at) VALUES
+    ($1, 'U1', $2, $3, 'USER_CHAT', 'MSG_TEXT', 'lemon soda cures cancer', 'en', $4)`,
+			[]any{messageID, resolvedID, groupID, createdAt}},
+		{`INSERT INTO answers (id, topic_id, text, created_at) VALUES ($1, $2, 'It does not', $3)`,
+			[]any{answerID, resolvedID, createdAt}},
+	}
+	for _, insert := range inserts {
+		_, err = pool.Exec(ctx, insert.sql, insert.args...)
+		if err != nil {
+			t.Fatalf("error inserting data of schema.sql: %v", err)
+		}
+	}
+
+	migrator, err := migrate.New(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("error migrating up from schema.sql: %v", err)
+	}
+	// schema.sql is baselined as version 1
+	if len(applied) == 0 || applied[0].Version != 2 {
+		t.Fatalf("unexpected applied migrations: %+v", applied)
+	}
+	err = migrate.Check(ctx, pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := repo.New(postgres.NewQueries(pool), pool)
+	stub := links.NewStub()
+	service := core.New(conf, repository, stub, stub, language.NewDetectorScript(), ratelimit.Policy{})
+	admin := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin"}
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+
+	t.Run("existing answers are published revisions", func(t *testing.T) {
+		topic, err := repository.Topics.GetByID(ctx, resolvedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if topic.AnswerID != answerID || topic.Result != "It does not" {
+			t.Fatalf("unexpected resolved topic: %+v", topic)
+		}
+		answer, err := repository.Answers.GetByID(ctx, answerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if answer.Revision != 1 || answer.Status != factcheck.StatusAnswerPublished {
+			t.Fatalf("unexpected answer: %+v", answer)
+		}
+	})
+
+	t.Run("existing groups are moderated", func(t *testing.T) {
+		group, err := repository.MessageGroups.GetByID(ctx, groupID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group.Status != factcheck.StatusMGroupPending {
+			t.Fatalf("unexpected status of existing group: %s", group.Status)
+		}
+		group, err = service.ApproveGroup(ctx, admin, groupID, "checked")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group.Status != factcheck.StatusMGroupApproved {
+			t.Fatalf("unexpected status of approved group: %s", group.Status)
+		}
+	})
+
+	t.Run("rekey existing groups", func(t *testing.T) {
+		afterID := ""
+		for {
+			lastID, err := service.RekeyGroups(ctx, afterID, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lastID == "" {
+				break
+			}
+			afterID = lastID
+		}
+		group, err := repository.MessageGroups.GetByID(ctx, groupID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		normalized := dedup.Normalize("lemon soda cures cancer")
+		if group.TextSHA1 != factcheck.SHA1(normalized) || group.TextSimHash != dedup.SimHash(normalized) {
+			t.Fatalf("unexpected keys of rekeyed group: %+v", group)
+		}
+		_, joined, _, err := service.Submit(ctx, user, "lemon soda cures cancer", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if joined.ID != groupID {
+			t.Fatalf("unexpected group %s of submission, expecting existing group %s", joined.ID, groupID)
+		}
+	})
+
+	t.Run("submit", func(t *testing.T) {
+		message, group, topic, err := service.Submit(ctx, user, "hot water kills viruses", pendingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if topic == nil || topic.ID != pendingID || group.TopicID != pendingID || message.GroupID != group.ID {
+			t.Fatalf("unexpected submission: message %+v, group %+v, topic %+v", message, group, topic)
+		}
+	})
+
+	t.Run("correct existing answer", func(t *testing.T) {
+		_, err := service.ReopenTopic(ctx, admin, resolvedID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		answer, topic, _, err := service.Resolve(ctx, admin, resolvedID, "It does not, and never did", factcheck.VerdictFalse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if answer.Revision != 2 || answer.CorrectsID != answerID || topic.AnswerID != answer.ID {
+			t.Fatalf("unexpected correction: answer %+v, topic %+v", answer, topic)
+		}
+	})
+}
+
+// upgradePool returns pool whose connections use upgradeSchema, which is created empty and dropped on cleanup
+func upgradePool(t *testing.T, conf config.Config) *pgxpool.Pool {
+	t.Helper()
+	ctx := t.Context()
+	pgConf, err := pgxpool.ParseConfig(fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		conf.Postgres.Host, conf.Postgres.Port, conf.Postgres.User, conf.Postgres.Password, conf.Postgres.DB,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := pgxpool.NewWithConfig(ctx, pgConf.Copy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(admin.Close)
+	_, err = admin.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %[1]s CASCADE; CREATE SCHEMA %[1]s", upgradeSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, err := admin.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %s CASCADE", upgradeSchema))
+		if err != nil {
+			t.Errorf("error dropping schema %s: %v", upgradeSchema, err)
+		}
+	})
+
+	pgConf.ConnConfig.RuntimeParams["search_path"] = upgradeSchema
+	pool, err := pgxpool.NewWithConfig(ctx, pgConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
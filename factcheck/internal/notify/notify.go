@@ -0,0 +1,214 @@
+// Package notify tells original submitters about answers to their messages.
+// How the notification reaches the submitter is abstracted by Sender.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Sender sends text to a recipient
+type Sender interface {
+	// Name identifies the sender in recorded deliveries
+	Name() string
+	// Supports reports whether the sender can deliver to user
+	Supports(user factcheck.UserInfo) bool
+	// Send sends text to user. key identifies the notification, and stays the same
+	// when it is sent again after failures, so that senders can deduplicate retries.
+	Send(ctx context.Context, user factcheck.UserInfo, text string, key string) error
+}
+
+// Notifier fans out answers to submitters via its sender,
+// and records every delivery attempt.
+type Notifier struct {
+	repo   repo.Repository
+	sender Sender
+}
+
+func New(repo repo.Repository, sender Sender) Notifier {
+	return Notifier{repo: repo, sender: sender}
+}
+
+// Name identifies the notifier as outbox sink
+func (n Notifier) Name() string { return "notify" }
+
+// Publish notifies submitters of the topic whose answer was published by event,
+// so that notifications are only sent after the answer is committed, and survive restarts.
+// Other events are ignored.
+func (n Notifier) Publish(ctx context.Context, event factcheck.Event) error {
+	if event.Type != factcheck.TypeEventAnswerPublished {
+		return nil
+	}
+	// The answer could have been moved to another topic by merges since
+	answer, err := n.repo.Answers.GetByID(ctx, event.SubjectID)
+	if repo.IsNotFound(err) {
+		slog.WarnContext(ctx, "skipping notification of deleted answer", "answer_id", event.SubjectID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error getting answer '%s': %w", event.SubjectID, err)
+	}
+	topic, err := n.repo.Topics.GetByID(ctx, answer.TopicID)
+	if err != nil {
+		return fmt.Errorf("error getting topic '%s' of answer '%s': %w", answer.TopicID, answer.ID, err)
+	}
+	messages, err := n.repo.MessagesV2.ListByTopic(ctx, topic.ID)
+	if err != nil {
+		return fmt.Errorf("error listing messages of topic '%s': %w", topic.ID, err)
+	}
+	deliveries, err := n.NotifyResolved(ctx, topic, answer, messages)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "notified submitters",
+		"topic_id", topic.ID,
+		"answer_id", answer.ID,
+		"deliveries", len(deliveries),
+	)
+	return nil
+}
+
+// NotifyResolved sends answer of the resolved topic to each distinct submitter of messages.
+// Failure to send to one recipient does not stop the others, and is recorded
+// as a failed delivery, but an error is still returned so that the outbox relay retries.
+// Recipients with sent deliveries of answer are skipped, so that retries
+// only send to recipients who have not got the answer yet.
+func (n Notifier) NotifyResolved(
+	ctx context.Context,
+	topic factcheck.Topic,
+	answer factcheck.Answer,
+	messages []factcheck.MessageV2,
+) (
+	[]factcheck.Delivery,
+	error,
+) {
+	delivered, err := n.repo.Deliveries.ListByAnswerID(ctx, answer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing deliveries of answer '%s': %w", answer.ID, err)
+	}
+	type key struct {
+		userID string
+		chatID string
+	}
+	skip := make(map[key]struct{}, len(delivered))
+	for i := range delivered {
+		if delivered[i].Status != factcheck.StatusDeliverySent {
+			continue
+		}
+		skip[key{userID: delivered[i].UserID, chatID: delivered[i].ChatID}] = struct{}{}
+	}
+
+	text := Text(topic, answer)
+	recipients := Recipients(messages)
+	deliveries := make([]factcheck.Delivery, 0, len(recipients))
+	failed := 0
+	for i := range recipients {
+		user := recipients[i]
+		if _, ok := skip[key{userID: user.UserID, chatID: user.ChatID}]; ok {
+			continue
+		}
+		if !n.sender.Supports(user) {
+			slog.DebugContext(ctx, "skipping unsupported recipient",
+				"sender", n.sender.Name(),
+				"user", user,
+			)
+			continue
+		}
+		delivery := factcheck.Delivery{
+			ID:       utils.NewID().String(),
+			TopicID:  topic.ID,
+			AnswerID: answer.ID,
+			UserID:   user.UserID,
+			TypeUser: user.UserType,
+			ChatID:   user.ChatID,
+			Sender:   n.sender.Name(),
+			Status:   factcheck.StatusDeliverySent,
+			Text:     text,
+		}
+		err := n.sender.Send(ctx, user, text, answer.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "error sending answer",
+				"err", err,
+				"topic_id", topic.ID,
+				"answer_id", answer.ID,
+				"user", user,
+			)
+			delivery.Status = factcheck.StatusDeliveryFailed
+			delivery.Error = err.Error()
+			failed++
+		}
+		delivery.CreatedAt = utils.TimeNow()
+		created, err := n.repo.Deliveries.Create(ctx, delivery)
+		if err != nil {
+			return deliveries, fmt.Errorf("error recording delivery to '%s' for topic '%s': %w", user.UserID, topic.ID, err)
+		}
+		deliveries = append(deliveries, created)
+	}
+	if failed != 0 {
+		return deliveries, fmt.Errorf("failed to send %d of %d deliveries of answer '%s'", failed, len(deliveries), answer.ID)
+	}
+	return deliveries, nil
+}
+
+// PrefixCorrection is prepended to notification text of answers correcting earlier ones
+const PrefixCorrection = "[Correction]"
+
+// Text formats the notification text for a resolved topic.
+// Corrections to previously published answers are prefixed with PrefixCorrection.
+func Text(topic factcheck.Topic, answer factcheck.Answer) string {
+	text := answer.Text
+	if topic.Name != "" {
+		text = fmt.Sprintf("%s\n\n%s", topic.Name, text)
+	}
+	if answer.CorrectsID != "" {
+		text = fmt.Sprintf("%s %s", PrefixCorrection, text)
+	}
+	return text
+}
+
+// Recipients returns distinct submitters of messages,
+// deduplicated by user ID and chat. The order of first appearance is kept.
+func Recipients(messages []factcheck.MessageV2) []factcheck.UserInfo {
+	type key struct {
+		userID string
+		chatID string
+	}
+	seen := make(map[key]struct{})
+	var recipients []factcheck.UserInfo
+	for i := range messages {
+		user := UserInfo(messages[i])
+		k := key{userID: user.UserID, chatID: user.ChatID}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		recipients = append(recipients, user)
+	}
+	return recipients
+}
+
+// UserInfo returns submitter of m, preferring user info saved in message metadata.
+// Messages without chat ID in metadata (1:1 chats) fall back to using user ID as chat ID.
+func UserInfo(m factcheck.MessageV2) factcheck.UserInfo {
+	user := factcheck.UserInfo{
+		UserType: m.TypeUser,
+		UserID:   m.UserID,
+	}
+	var meta factcheck.Metadata[factcheck.UserInfo]
+	if len(m.Metadata) != 0 {
+		err := json.Unmarshal(m.Metadata, &meta)
+		if err == nil && meta.Type == factcheck.TypeMetadataUserInfo && meta.Data.UserID != "" {
+			user = meta.Data
+		}
+	}
+	if user.ChatID == "" && user.UserType == factcheck.TypeUserMessageLINEChat {
+		user.ChatID = user.UserID
+	}
+	return user
+}
@@ -0,0 +1,107 @@
+//go:build integration_test
+// +build integration_test
+
+package notify_test
+
+import (
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestNotifier_Publish(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	topic, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+		ID:          "550e8400-e29b-41d4-a716-446655440001",
+		Name:        "Lemon soda cures cancer",
+		Description: "Lemon soda",
+		Status:      factcheck.StatusTopicPending,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []factcheck.UserInfo{
+		{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"},
+		{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U1", ChatID: "C1"},
+		{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}, // Duplicate
+		{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin"},               // Not notified
+	}
+	var groupID string
+	for i := range users {
+		_, group, _, err := app.Service.Submit(ctx, users[i], "lemon soda cures cancer", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		groupID = group.ID
+	}
+	_, err = app.Repository.MessageGroups.AssignTopic(ctx, groupID, topic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := app.Repository.MessagesV2.ListByGroup(ctx, groupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range messages {
+		_, err = app.Repository.MessagesV2.AssignTopic(ctx, messages[i].ID, topic.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	answer, _, messages, err := app.Service.Resolve(ctx, users[3], topic.ID, "False", factcheck.VerdictFalse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != len(users) {
+		t.Fatalf("unexpected messages: %d", len(messages))
+	}
+	// Submitters are notified when the outbox relay publishes answer.published
+	for {
+		n, err := app.Relay.RelayOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			break
+		}
+	}
+	deliveries, err := app.Repository.Deliveries.ListByTopicID(ctx, topic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+	for i := range deliveries {
+		if deliveries[i].AnswerID != answer.ID || deliveries[i].Status != factcheck.StatusDeliverySent {
+			t.Fatalf("unexpected delivery: %+v", deliveries[i])
+		}
+	}
+
+	// Publishing the event again does not notify anyone twice
+	err = app.Notifier.Publish(ctx, factcheck.Event{Type: factcheck.TypeEventAnswerPublished, SubjectID: answer.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := app.Repository.Deliveries.ListByTopicID(ctx, topic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(deliveries) {
+		t.Fatalf("unexpected deliveries after publishing again: %+v", actual)
+	}
+}
@@ -0,0 +1,221 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+type deliveriesMemory struct {
+	repo.Deliveries
+	created []factcheck.Delivery
+}
+
+func (d *deliveriesMemory) Create(_ context.Context, delivery factcheck.Delivery, _ ...repo.Option) (factcheck.Delivery, error) {
+	d.created = append(d.created, delivery)
+	return delivery, nil
+}
+
+func (d *deliveriesMemory) ListByAnswerID(_ context.Context, answerID string, _ ...repo.Option) ([]factcheck.Delivery, error) {
+	var deliveries []factcheck.Delivery
+	for _, delivery := range d.created {
+		if delivery.AnswerID == answerID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func message(t *testing.T, user factcheck.UserInfo) factcheck.MessageV2 {
+	t.Helper()
+	meta, err := json.Marshal(factcheck.Metadata[factcheck.UserInfo]{
+		Type: factcheck.TypeMetadataUserInfo,
+		Data: user,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return factcheck.MessageV2{
+		UserID:   user.UserID,
+		TypeUser: user.UserType,
+		Text:     "some text",
+		Metadata: meta,
+	}
+}
+
+func TestRecipients(t *testing.T) {
+	chat := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	group1 := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U1", ChatID: "C1"}
+	group2 := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U2", ChatID: "C1"}
+	admin := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin"}
+
+	messages := []factcheck.MessageV2{
+		message(t, chat),
+		message(t, group1),
+		message(t, chat),
+		message(t, group2),
+		message(t, group1),
+		message(t, admin),
+		// No metadata, e.g. legacy message
+		{UserID: "U3", TypeUser: factcheck.TypeUserMessageLINEChat, Text: "legacy"},
+	}
+	actual := notify.Recipients(messages)
+	expected := []factcheck.UserInfo{
+		chat,
+		group1,
+		group2,
+		admin,
+		{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U3", ChatID: "U3"},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("unexpected recipients: %+v", actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("unexpected recipient %d: %+v, expected %+v", i, actual[i], expected[i])
+		}
+	}
+}
+
+func TestNotifyResolved(t *testing.T) {
+	topic := factcheck.Topic{ID: "550e8400-e29b-41d4-a716-446655440001", Name: "Lemon soda cures cancer"}
+	answer := factcheck.Answer{ID: "550e8400-e29b-41d4-a716-446655440002", TopicID: topic.ID, Text: "False"}
+	chat := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	group := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U2", ChatID: "C1"}
+	admin := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin"}
+	messages := []factcheck.MessageV2{
+		message(t, chat),
+		message(t, chat),
+		message(t, group),
+		message(t, admin),
+	}
+
+	t.Run("sent", func(t *testing.T) {
+		recorder := notify.NewRecorder()
+		deliveries := &deliveriesMemory{}
+		notifier := notify.New(repo.Repository{Deliveries: deliveries}, recorder)
+		result, err := notifier.NotifyResolved(t.Context(), topic, answer, messages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 2 || len(deliveries.created) != 2 {
+			t.Fatalf("unexpected deliveries: %+v", result)
+		}
+		sent := recorder.Sent()
+		if len(sent) != 2 {
+			t.Fatalf("unexpected sent: %+v", sent)
+		}
+		if sent[0].User != chat || sent[1].User != group {
+			t.Fatalf("unexpected sent: %+v", sent)
+		}
+		if sent[0].Key != answer.ID || sent[1].Key != answer.ID {
+			t.Fatalf("unexpected keys of sent: %+v", sent)
+		}
+		for i := range result {
+			d := &result[i]
+			if d.Status != factcheck.StatusDeliverySent {
+				t.Fatalf("unexpected status %s", d.Status)
+			}
+			if d.TopicID != topic.ID || d.AnswerID != answer.ID {
+				t.Fatalf("unexpected delivery: %+v", d)
+			}
+			if d.Text != notify.Text(topic, answer) {
+				t.Fatalf("unexpected text: %s", d.Text)
+			}
+		}
+
+		// Notifying again, e.g. after the outbox relay failed, skips recipients already delivered to
+		result, err = notifier.NotifyResolved(t.Context(), topic, answer, messages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 0 || len(recorder.Sent()) != 2 || len(deliveries.created) != 2 {
+			t.Fatalf("unexpected deliveries notifying again: %+v", result)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		recorder := notify.NewRecorder()
+		recorder.Fail(errors.New("some error"))
+		deliveries := &deliveriesMemory{}
+		notifier := notify.New(repo.Repository{Deliveries: deliveries}, recorder)
+		result, err := notifier.NotifyResolved(t.Context(), topic, answer, messages)
+		if err == nil {
+			t.Fatal("unexpected nil error of failed deliveries")
+		}
+		if len(result) != 2 {
+			t.Fatalf("unexpected deliveries: %+v", result)
+		}
+		for i := range result {
+			if result[i].Status != factcheck.StatusDeliveryFailed || result[i].Error != "some error" {
+				t.Fatalf("unexpected delivery: %+v", result[i])
+			}
+		}
+		if len(recorder.Sent()) != 0 {
+			t.Fatalf("unexpected sent: %+v", recorder.Sent())
+		}
+
+		// Recipients of failed deliveries are retried
+		recorder.Fail(nil)
+		result, err = notifier.NotifyResolved(t.Context(), topic, answer, messages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 2 || len(recorder.Sent()) != 2 || len(deliveries.created) != 4 {
+			t.Fatalf("unexpected deliveries retrying: %+v", result)
+		}
+	})
+}
+
+func TestText(t *testing.T) {
+	type testCase struct {
+		topic    factcheck.Topic
+		answer   factcheck.Answer
+		expected string
+	}
+	tests := []testCase{
+		{
+			topic:    factcheck.Topic{},
+			answer:   factcheck.Answer{Text: "False"},
+			expected: "False",
+		},
+		{
+			topic:    factcheck.Topic{Name: "some topic"},
+			answer:   factcheck.Answer{Text: "False"},
+			expected: "some topic\n\nFalse",
+		},
+		{
+			topic:    factcheck.Topic{Name: "some topic"},
+			answer:   factcheck.Answer{Text: "True", CorrectsID: "550e8400-e29b-41d4-a716-446655440002"},
+			expected: notify.PrefixCorrection + " some topic\n\nTrue",
+		},
+	}
+	for i := range tests {
+		tc := &tests[i]
+		actual := notify.Text(tc.topic, tc.answer)
+		if actual != tc.expected {
+			t.Fatalf("[case %d] unexpected text: expected=%q, actual=%q", i, tc.expected, actual)
+		}
+	}
+}
+
+func TestRetryKey(t *testing.T) {
+	key := notify.RetryKey("550e8400-e29b-41d4-a716-446655440002", "C1")
+	if _, err := uuid.Parse(key); err != nil {
+		t.Fatalf("unexpected retry key '%s': %v", key, err)
+	}
+	if notify.RetryKey("550e8400-e29b-41d4-a716-446655440002", "C1") != key {
+		t.Fatal("unexpected different retry keys of the same notification")
+	}
+	if notify.RetryKey("550e8400-e29b-41d4-a716-446655440002", "C2") == key ||
+		notify.RetryKey("550e8400-e29b-41d4-a716-446655440003", "C1") == key {
+		t.Fatal("unexpected same retry keys of different notifications")
+	}
+}
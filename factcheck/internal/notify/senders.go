@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/line"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// SenderLINE pushes notifications to LINE chats
+type SenderLINE struct {
+	client *line.Client
+}
+
+// Recorder is an in-memory sender that records sent texts.
+// It is meant for tests.
+type Recorder struct {
+	mut  sync.Mutex
+	sent []Sent
+	err  error
+}
+
+// Sent is a notification recorded by Recorder
+type Sent struct {
+	User   factcheck.UserInfo
+	Text   string
+	Key    string
+	SentAt time.Time
+}
+
+func NewSenderLINE(conf config.Config) SenderLINE {
+	return SenderLINE{client: line.NewClient(conf.LINE.Endpoint, conf.LINE.ChannelAccessToken)}
+}
+
+func (s SenderLINE) Name() string { return "line" }
+
+func (s SenderLINE) Supports(user factcheck.UserInfo) bool {
+	switch user.UserType {
+	case
+		factcheck.TypeUserMessageLINEChat,
+		factcheck.TypeUserMessageLINEGroupChat:
+		return user.ChatID != ""
+	}
+	return false
+}
+
+func (s SenderLINE) Send(ctx context.Context, user factcheck.UserInfo, text string, key string) error {
+	if !s.Supports(user) {
+		return errors.New("unsupported recipient for line sender")
+	}
+	return s.client.Push(ctx, user.ChatID, RetryKey(key, user.ChatID), line.Text(text))
+}
+
+// RetryKey returns LINE retry key of pushing notification key to chatID.
+// Keys are UUIDv5 derived from both, so that LINE drops pushes already accepted,
+// e.g. when recording the delivery failed after the push succeeded.
+func RetryKey(key string, chatID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("line-fact-check:notify:"+key+":"+chatID)).String()
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Name() string { return "recorder" }
+
+// Supports accepts any users except admins, mirroring SenderLINE
+func (r *Recorder) Supports(user factcheck.UserInfo) bool {
+	return user.UserType != factcheck.TypeUserMessageAdmin
+}
+
+func (r *Recorder) Send(_ context.Context, user factcheck.UserInfo, text string, key string) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.sent = append(r.sent, Sent{User: user, Text: text, Key: key, SentAt: utils.TimeNow()})
+	return nil
+}
+
+// Sent returns a copy of everything sent so far
+func (r *Recorder) Sent() []Sent {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	sent := make([]Sent, len(r.sent))
+	copy(sent, r.sent)
+	return sent
+}
+
+// Fail makes subsequent sends fail with err, or succeed again if err is nil
+func (r *Recorder) Fail(err error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.err = err
+}
@@ -0,0 +1,269 @@
+// Package openapi describes HTTP APIs with OpenAPI 3 documents.
+//
+// Only the subset of OpenAPI used by our APIs is modeled. Schemas of Go types
+// are generated by reflection with Components.SchemaOf, so that documented
+// request and response types never drift from the types handlers actually use.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const Version = "3.0.3"
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
+	ContentTypeText    = "text/plain"
+	ContentTypeEvents  = "text/event-stream"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+	Security   []Requirement        `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps lowercase HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Security    []Requirement        `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Requirement maps security scheme names to scopes
+type Requirement map[string][]string
+
+type SecurityScheme struct {
+	Type         string `json:"type"` // apiKey or http
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"` // Header name of apiKey
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"` // e.g. bearer
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+
+	types map[reflect.Type]string // Names of schemas of Go types
+}
+
+func NewComponents() *Components {
+	return &Components{
+		Schemas:         make(map[string]*Schema),
+		SecuritySchemes: make(map[string]SecurityScheme),
+		types:           make(map[reflect.Type]string),
+	}
+}
+
+// Define defines schema s as the component schema of v's type.
+// It is used for types whose schemas cannot be reflected, e.g. enums.
+func (c *Components) Define(v any, s *Schema) *Schema {
+	t := reflect.TypeOf(v)
+	name := typeName(t)
+	c.types[t] = name
+	c.Schemas[name] = s
+	return Ref(name)
+}
+
+// SchemaOf returns schema of v's type. Named struct types and types from Define
+// are added to the component schemas, and referenced from the returned schema.
+func (c *Components) SchemaOf(v any) *Schema {
+	return c.schemaOf(reflect.TypeOf(v))
+}
+
+func (c *Components) schemaOf(t reflect.Type) *Schema {
+	if name, ok := c.types[t]; ok {
+		return Ref(name)
+	}
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeFor[json.RawMessage]():
+		return &Schema{} // Any JSON value
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := c.schemaOf(t.Elem())
+		if s.Ref != "" {
+			// Siblings of $ref are ignored in OpenAPI 3.0
+			return &Schema{OneOf: []*Schema{s}, Nullable: true}
+		}
+		nullable := *s
+		nullable.Nullable = true
+		return &nullable
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Integer()
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return Array(c.schemaOf(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.object(t)
+		}
+		name := typeName(t)
+		c.types[t] = name
+		c.Schemas[name] = c.object(t)
+		return Ref(name)
+	}
+	return &Schema{}
+}
+
+// object returns schema of struct type t, with fields of embedded structs inlined
+// like encoding/json does. Fields without omitempty are required.
+func (c *Components) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// Exported fields of unexported embedded structs are still marshaled
+			embedded := c.object(field.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = c.schemaOf(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// Resolve returns the component schema referenced by s, or s itself if it is not a reference
+func (c *Components) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = c.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	return s
+}
+
+const refPrefix = "#/components/schemas/"
+
+// typeName returns component name of t, e.g. Topic for factcheck.Topic,
+// and PageTopic for repo.Page[factcheck.Topic]
+func typeName(t reflect.Type) string {
+	name, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return name
+	}
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		if i := strings.LastIndex(arg, "."); i != -1 {
+			arg = arg[i+1:]
+		}
+		name += arg
+	}
+	return name
+}
+
+func Ref(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// Enum returns string schema of values
+func Enum[S ~string](values ...S) *Schema {
+	s := String()
+	for _, v := range values {
+		s.Enum = append(s.Enum, string(v))
+	}
+	return s
+}
+
+// Object returns object schema with properties, of which required are required
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
@@ -0,0 +1,158 @@
+package openapi_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/openapi"
+)
+
+type (
+	status string
+
+	item struct {
+		ID        string     `json:"id"`
+		Status    status     `json:"status"`
+		Note      string     `json:"note,omitempty"`
+		Count     int        `json:"count"`
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+		Internal  uint64     `json:"-"`
+	}
+
+	itemScored struct {
+		item
+		Score float64 `json:"score"`
+	}
+
+	page[T any] struct {
+		Data []T `json:"data"`
+	}
+)
+
+func TestSchemaOf(t *testing.T) {
+	c := openapi.NewComponents()
+	c.Define(status(""), openapi.Enum("OK", "BAD"))
+	s := c.SchemaOf(page[itemScored]{})
+	if s.Ref != "#/components/schemas/pageitemScored" {
+		t.Fatalf("unexpected ref '%s'", s.Ref)
+	}
+	scored := c.Schemas["itemScored"]
+	if scored == nil {
+		t.Fatalf("missing schema itemScored, got %v", c.Schemas)
+	}
+	expected := []string{"id", "status", "count", "created_at", "updated_at", "score"}
+	if !reflect.DeepEqual(scored.Required, expected) {
+		t.Fatalf("unexpected required: expected %v, got %v", expected, scored.Required)
+	}
+	if _, ok := scored.Properties["Internal"]; ok {
+		t.Fatalf("unexpected ignored field")
+	}
+	if scored.Properties["status"].Ref != "#/components/schemas/status" {
+		t.Fatalf("unexpected status schema %+v", scored.Properties["status"])
+	}
+	if p := scored.Properties["updated_at"]; !p.Nullable || p.Format != "date-time" {
+		t.Fatalf("unexpected updated_at schema %+v", p)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	c := openapi.NewComponents()
+	c.Define(status(""), openapi.Enum("OK", "BAD"))
+	item := c.SchemaOf(item{})
+	doc := &openapi.Document{
+		Components: c,
+		Paths: map[string]*openapi.PathItem{
+			"/items/{id}": {
+				"get": {OperationID: "GetItem"},
+				"put": {
+					OperationID: "PutItem",
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  map[string]openapi.MediaType{openapi.ContentTypeJSON: {Schema: item}},
+					},
+				},
+			},
+			"/items/count": {
+				"get": {
+					OperationID: "CountItems",
+					Parameters: []openapi.Parameter{
+						{Name: "limit", In: "query", Schema: openapi.Integer()},
+						{Name: "in_statuses", In: "query", Required: true, Schema: openapi.Array(c.SchemaOf(status("")))},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("operation", func(t *testing.T) {
+		type testCase struct {
+			method, path string
+			expected     string
+		}
+		tests := []testCase{
+			{http.MethodGet, "/items/some-id", "GetItem"},
+			{http.MethodGet, "/items/count", "CountItems"},
+			{http.MethodPut, "/items/count", "PutItem"},
+			{http.MethodPost, "/items/some-id", ""},
+			{http.MethodGet, "/items/", ""},
+			{http.MethodGet, "/items/some-id/more", ""},
+		}
+		for _, tc := range tests {
+			op, _, ok := doc.Operation(tc.method, tc.path)
+			actual := ""
+			if ok {
+				actual = op.OperationID
+			}
+			if actual != tc.expected {
+				t.Fatalf("unexpected operation for %s %s: expected '%s', got '%s'", tc.method, tc.path, tc.expected, actual)
+			}
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		type testCase struct {
+			method, target, body string
+			valid                bool
+		}
+		now := time.Now().Format(time.RFC3339)
+		tests := []testCase{
+			{http.MethodGet, "/items/count?in_statuses=OK,BAD&limit=10", "", true},
+			{http.MethodGet, "/items/count?in_statuses=OK&limit=ten", "", false},
+			{http.MethodGet, "/items/count?in_statuses=OK,MAYBE", "", false},
+			{http.MethodGet, "/items/count", "", false},
+			{http.MethodPut, "/items/1", `{"id":"1","status":"OK","count":1,"created_at":"` + now + `","updated_at":null}`, true},
+			{http.MethodPut, "/items/1", `{"id":"1","status":"OK","count":1.5,"created_at":"` + now + `","updated_at":null}`, false},
+			{http.MethodPut, "/items/1", `{"id":"1","status":"MAYBE","count":1,"created_at":"` + now + `","updated_at":null}`, false},
+			{http.MethodPut, "/items/1", `{"id":"1","status":"OK","count":1}`, false},
+			{http.MethodPut, "/items/1", `[]`, false},
+			{http.MethodPut, "/items/1", ``, false},
+		}
+		for _, tc := range tests {
+			req := httptest.NewRequestWithContext(t.Context(), tc.method, tc.target, strings.NewReader(tc.body))
+			err := doc.Validate(req)
+			if (err == nil) != tc.valid {
+				t.Fatalf("unexpected validation of %s %s %s: %v", tc.method, tc.target, tc.body, err)
+			}
+		}
+	})
+
+	t.Run("validated body can be read again", func(t *testing.T) {
+		body := `{"id":"1","status":"OK","count":1,"created_at":"2025-01-01T00:00:00Z","updated_at":null}`
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/items/1", strings.NewReader(body))
+		err := doc.Validate(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := new(strings.Builder)
+		_, err = io.Copy(b, req.Body)
+		if err != nil || b.String() != body {
+			t.Fatalf("unexpected body after validation: '%s', %v", b.String(), err)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNoOperation is returned by Validate for requests not described by the document
+var ErrNoOperation = errors.New("no operation")
+
+// Operation returns operation of method and path, matching path parameters in path templates.
+// Templates with more literal segments win, e.g. /topics/count over /topics/{id}.
+func (d *Document) Operation(method string, path string) (*Operation, map[string]string, bool) {
+	segments := strings.Split(path, "/")
+	var (
+		found  *Operation
+		params map[string]string
+		best   = -1
+	)
+	for template, item := range d.Paths {
+		op, ok := (*item)[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		matched, literals, ok := match(strings.Split(template, "/"), segments)
+		if ok && literals > best {
+			found, params, best = op, matched, literals
+		}
+	}
+	return found, params, found != nil
+}
+
+func match(template []string, segments []string) (map[string]string, int, bool) {
+	if len(template) != len(segments) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	literals := 0
+	for i := range template {
+		if strings.HasPrefix(template[i], "{") && strings.HasSuffix(template[i], "}") {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			params[strings.Trim(template[i], "{}")] = segments[i]
+			continue
+		}
+		if template[i] != segments[i] {
+			return nil, 0, false
+		}
+		literals++
+	}
+	return params, literals, true
+}
+
+// Validate validates r against its operation in d. JSON bodies are read,
+// and replaced so that handlers can still read them.
+// Callers limit body size, e.g. with http.MaxBytesReader, whose errors are wrapped.
+func (d *Document) Validate(r *http.Request) error {
+	op, params, ok := d.Operation(r.Method, r.URL.Path)
+	if !ok {
+		return fmt.Errorf("%w for %s %s", ErrNoOperation, r.Method, r.URL.Path)
+	}
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var (
+			value   string
+			present bool
+		)
+		switch p.In {
+		case "path":
+			value, present = params[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			value = query.Get(p.Name)
+		case "header":
+			value = r.Header.Get(p.Name)
+			present = value != ""
+		}
+		if !present {
+			if p.Required {
+				return fmt.Errorf("missing %s parameter %s", p.In, p.Name)
+			}
+			continue
+		}
+		err := d.validateParam(p.Schema, value)
+		if err != nil {
+			return fmt.Errorf("bad %s parameter %s: %w", p.In, p.Name, err)
+		}
+	}
+	if op.RequestBody == nil {
+		return nil
+	}
+	media, ok := op.RequestBody.Content[ContentTypeJSON]
+	if !ok {
+		return nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return errors.New("missing body")
+		}
+		return nil
+	}
+	var v any
+	err = json.Unmarshal(body, &v)
+	if err != nil {
+		return fmt.Errorf("bad json body: %w", err)
+	}
+	err = d.validate(media.Schema, v)
+	if err != nil {
+		return fmt.Errorf("bad body: %w", err)
+	}
+	return nil
+}
+
+func (d *Document) validateParam(s *Schema, value string) error {
+	s = d.Components.Resolve(s)
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+		return checkRange(s, float64(n))
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a number", value)
+		}
+		return checkRange(s, n)
+	case "boolean":
+		_, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a boolean", value)
+		}
+	case "array":
+		// Arrays in query are comma-separated, i.e. style form without explode
+		for _, item := range strings.Split(value, ",") {
+			err := d.validateParam(s.Items, item)
+			if err != nil {
+				return err
+			}
+		}
+	case "string":
+		return checkEnum(s, value)
+	}
+	return nil
+}
+
+// validate validates JSON value v decoded into any against s
+func (d *Document) validate(s *Schema, v any) error {
+	s = d.Components.Resolve(s)
+	if s == nil {
+		return nil
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return errors.New("unexpected null")
+	}
+	if len(s.OneOf) != 0 {
+		var errs []error
+		for _, option := range s.OneOf {
+			err := d.validate(option, v)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expecting object, got %T", v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("missing property %s", name)
+			}
+		}
+		for name, value := range obj {
+			property, ok := s.Properties[name]
+			if !ok {
+				property = s.AdditionalProperties
+			}
+			err := d.validate(property, value)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", name, err)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("expecting array, got %T", v)
+		}
+		for i := range arr {
+			err := d.validate(s.Items, arr[i])
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expecting string, got %T", v)
+		}
+		return checkEnum(s, str)
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("expecting %s, got %T", s.Type, v)
+		}
+		if s.Type == "integer" && n != float64(int64(n)) {
+			return fmt.Errorf("expecting integer, got %v", n)
+		}
+		return checkRange(s, n)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("expecting boolean, got %T", v)
+		}
+	}
+	return nil
+}
+
+func checkEnum(s *Schema, value string) error {
+	if len(s.Enum) != 0 && !slices.Contains(s.Enum, value) {
+		return fmt.Errorf("'%s' is not one of %s", value, strings.Join(s.Enum, ", "))
+	}
+	return nil
+}
+
+func checkRange(s *Schema, n float64) error {
+	if s.Minimum != nil && n < *s.Minimum {
+		return fmt.Errorf("%v is less than %v", n, *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return fmt.Errorf("%v is greater than %v", n, *s.Maximum)
+	}
+	return nil
+}
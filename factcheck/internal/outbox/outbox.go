@@ -0,0 +1,168 @@
+// Package outbox relays domain events from the outbox table to sinks.
+//
+// Events are written to outbox by package core in the same transaction as the change,
+// and Relay publishes them afterwards. Delivery is at-least-once: an event whose sinks
+// failed will be retried, and sinks may see the same event more than once.
+// Failed events are retried with exponential backoff without blocking later events,
+// so sinks may also see events out of order. Events failing too many times are parked.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Sink receives published events. Publish must be idempotent,
+// as the same event could be published again after failures.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event factcheck.Event) error
+}
+
+// Relay periodically publishes unpublished events to all of its sinks, claiming them in order of Seq.
+// Less often, it also sweeps expired state of other packages, see [Relay.Sweep].
+type Relay struct {
+	repo      repo.Repository
+	sinks     []Sink
+	interval  time.Duration
+	batch     int
+	lease     time.Duration
+	backoff   repo.Backoff
+	sweep     time.Duration
+	retention time.Duration // Retention of webhook event IDs
+	limits    ratelimit.Policy
+}
+
+func NewRelay(conf config.Config, repository repo.Repository, sinks []Sink, limits ratelimit.Policy) Relay {
+	return Relay{
+		repo:     repository,
+		limits:   limits,
+		sinks:    sinks,
+		interval: utils.DefaultIfZero(time.Duration(conf.Outbox.RelayIntervalMs)*time.Millisecond, time.Second),
+		batch:    utils.DefaultIfZero(conf.Outbox.BatchSize, 100),
+		lease:    utils.DefaultIfZero(time.Duration(conf.Outbox.LeaseMs)*time.Millisecond, 5*time.Minute),
+		backoff: repo.Backoff{
+			Base:        utils.DefaultIfZero(time.Duration(conf.Outbox.BackoffMs)*time.Millisecond, time.Second),
+			Max:         utils.DefaultIfZero(time.Duration(conf.Outbox.BackoffMaxMs)*time.Millisecond, time.Hour),
+			MaxAttempts: utils.DefaultIfZero(conf.Outbox.MaxAttempts, 20),
+		},
+		sweep:     utils.DefaultIfZero(time.Duration(conf.Outbox.SweepIntervalMs)*time.Millisecond, time.Hour),
+		retention: utils.DefaultIfZero(time.Duration(conf.LINE.WebhookRetentionMs)*time.Millisecond, 7*24*time.Hour),
+	}
+}
+
+// Run relays events and sweeps until ctx is done
+func (r Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	sweeper := time.NewTicker(r.sweep)
+	defer sweeper.Stop()
+	slog.InfoContext(ctx, "outbox relay started", "interval", r.interval, "batch", r.batch, "sinks", len(r.sinks))
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "outbox relay stopped")
+			return
+		case <-sweeper.C:
+			err := r.Sweep(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox relay sweep error", "err", err)
+			}
+			continue
+		case <-ticker.C:
+		}
+		for {
+			n, err := r.RelayOnce(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox relay error", "err", err, "published", n)
+				break
+			}
+			if n < r.batch {
+				break
+			}
+			// Full batch: there could be more, so we go again without waiting
+		}
+	}
+}
+
+// Sweep deletes state which is no longer needed: IDs of webhook events too old to be redelivered,
+// and rate limit buckets idle long enough to be full again
+func (r Relay) Sweep(ctx context.Context) error {
+	events, err := r.repo.LINEWebhookEvents.DeleteBefore(ctx, utils.TimeNow().Add(-r.retention))
+	if err != nil {
+		return fmt.Errorf("error sweeping webhook events: %w", err)
+	}
+	buckets, err := r.limits.Sweep(ctx)
+	if err != nil {
+		return fmt.Errorf("error sweeping rate limits: %w", err)
+	}
+	slog.InfoContext(ctx, "outbox relay swept", "webhook_events", events, "rate_limits", buckets)
+	return nil
+}
+
+// RelayOnce claims one batch of events and publishes them, returning the number of events published.
+// Events are published outside of transactions, so that slow sinks do not hold locks.
+// Failed events are released for their next attempt after backoff, and the error is returned
+// after the other events of the batch are published.
+func (r Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.repo.Outbox.Claim(ctx, r.batch, r.lease)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	var errs []error
+	for i := range events {
+		event := events[i]
+		errPublish := r.publish(ctx, event)
+		if errPublish != nil {
+			errs = append(errs, errPublish)
+			err = r.repo.Outbox.MarkFailed(ctx, event.ID, errPublish.Error(), r.backoff)
+			if err != nil {
+				return published, err
+			}
+			continue
+		}
+		err = r.repo.Outbox.MarkPublished(ctx, event.ID)
+		if err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, errors.Join(errs...)
+}
+
+// publish publishes event to sinks which have not published it in earlier attempts
+func (r Relay) publish(ctx context.Context, event factcheck.Event) error {
+	done, err := r.repo.Outbox.ListSinksPublished(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("error listing sinks of event %s: %w", event.ID, err)
+	}
+	var errs []error
+	for _, sink := range r.sinks {
+		if slices.Contains(done, sink.Name()) {
+			continue
+		}
+		reason := ""
+		errSink := sink.Publish(ctx, event)
+		if errSink != nil {
+			errSink = fmt.Errorf("sink %s failed to publish event %s (seq %d): %w", sink.Name(), event.ID, event.Seq, errSink)
+			errs = append(errs, errSink)
+			reason = errSink.Error()
+		}
+		err = r.repo.Outbox.RecordSinkAttempt(ctx, event.ID, sink.Name(), reason)
+		if err != nil {
+			return fmt.Errorf("error recording attempt of sink %s to publish event %s: %w", sink.Name(), event.ID, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,153 @@
+//go:build integration_test
+// +build integration_test
+
+package outbox_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestRelay_RelayOnce(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	_, _, _, err = app.Service.Submit(ctx, user, "lemon soda cures cancer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = app.Service.Submit(ctx, user, "lemon soda cures cancer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 group.created + 2 message.submitted
+	expected := []factcheck.TypeEvent{
+		factcheck.TypeEventGroupCreated,
+		factcheck.TypeEventMessageSubmitted,
+		factcheck.TypeEventMessageSubmitted,
+	}
+
+	sink := outbox.NewMemory()
+	sink.Fail(errors.New("sink unavailable"))
+	relay := outbox.NewRelay(app.Config, app.Repository, []outbox.Sink{sink}, ratelimit.Policy{})
+	n, err := relay.RelayOnce(ctx)
+	if err == nil {
+		t.Fatal("expecting error from failed sink")
+	}
+	if n != 0 {
+		t.Fatalf("unexpected published count after failure: %d", n)
+	}
+
+	// Failed events wait for backoff
+	sink.Fail(nil)
+	n, err = relay.RelayOnce(ctx)
+	if err != nil || n != 0 {
+		t.Fatalf("unexpected result before backoff: %d, %v", n, err)
+	}
+	utils.TimeFreeze(now.Add(time.Minute))
+	n, err = relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(expected) {
+		t.Fatalf("unexpected published count: %d", n)
+	}
+	events := sink.Events()
+	if len(events) != len(expected) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	for i := range events {
+		if events[i].Type != expected[i] {
+			t.Fatalf("unexpected event type at %d: %s, expected %s", i, events[i].Type, expected[i])
+		}
+		if i > 0 && events[i].Seq <= events[i-1].Seq {
+			t.Fatalf("events out of order: %+v", events)
+		}
+	}
+
+	// Published events are not relayed again
+	n, err = relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("unexpected published count on empty outbox: %d", n)
+	}
+	if len(sink.Events()) != len(expected) {
+		t.Fatalf("events published twice: %+v", sink.Events())
+	}
+}
+
+func TestRelay_Retries(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	_, _, _, err = app.Service.Submit(ctx, user, "lemon soda cures cancer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := app.Config
+	conf.Outbox.BackoffMs = 1000
+	conf.Outbox.BackoffMaxMs = 4000
+	conf.Outbox.MaxAttempts = 3
+	ok, failing := outbox.NewMemory(), &memoryNamed{Memory: outbox.NewMemory(), name: "failing"}
+	failing.Fail(errors.New("sink unavailable"))
+	relay := outbox.NewRelay(conf, app.Repository, []outbox.Sink{ok, failing}, ratelimit.Policy{})
+
+	// Attempts are 1s, 2s, then parked
+	for i, wait := range []time.Duration{0, time.Second, 2 * time.Second} {
+		now = now.Add(wait)
+		utils.TimeFreeze(now)
+		_, err = relay.RelayOnce(ctx)
+		if err == nil {
+			t.Fatalf("expecting error from failed sink at attempt %d", i+1)
+		}
+	}
+	utils.TimeFreeze(now.Add(time.Hour))
+	failing.Fail(nil)
+	n, err := relay.RelayOnce(ctx)
+	if err != nil || n != 0 {
+		t.Fatalf("unexpected result after parking: %d, %v", n, err)
+	}
+	// Sinks which published are not retried
+	if len(ok.Events()) != 2 {
+		t.Fatalf("unexpected events of working sink: %+v", ok.Events())
+	}
+	if len(failing.Events()) != 0 {
+		t.Fatalf("unexpected events of failing sink: %+v", failing.Events())
+	}
+}
+
+// memoryNamed is outbox.Memory with another name, so that relays tell them apart
+type memoryNamed struct {
+	*outbox.Memory
+	name string
+}
+
+func (m *memoryNamed) Name() string { return m.name }
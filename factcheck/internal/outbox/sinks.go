@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/notify"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// HeaderEventID is set on webhook requests, so that receivers could deduplicate events
+const HeaderEventID = "X-Factcheck-Event-Id"
+
+// SinkLog logs events with slog
+type SinkLog struct{}
+
+// SinkWebhook POSTs events as JSON to a URL
+type SinkWebhook struct {
+	url  string
+	http *http.Client
+}
+
+// Memory is an in-memory sink that records published events.
+// It is meant for tests.
+type Memory struct {
+	mut    sync.Mutex
+	events []factcheck.Event
+	err    error
+}
+
+// NewSinks returns sinks enabled by conf, and notifier,
+// which notifies submitters of published answers
+func NewSinks(conf config.Config, notifier notify.Notifier) []Sink {
+	sinks := []Sink{SinkLog{}, notifier}
+	if conf.Outbox.WebhookURL != "" {
+		sinks = append(sinks, NewSinkWebhook(conf.Outbox.WebhookURL))
+	}
+	return sinks
+}
+
+func (SinkLog) Name() string { return "log" }
+
+func (SinkLog) Publish(ctx context.Context, event factcheck.Event) error {
+	slog.InfoContext(ctx, "outbox event",
+		"id", event.ID,
+		"seq", event.Seq,
+		"type", event.Type,
+		"subject_id", event.SubjectID,
+	)
+	return nil
+}
+
+func NewSinkWebhook(url string) SinkWebhook {
+	return SinkWebhook{
+		url:  url,
+		http: &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+	}
+}
+
+func (s SinkWebhook) Name() string { return "webhook" }
+
+func (s SinkWebhook) Publish(ctx context.Context, event factcheck.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID)
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Name() string { return "memory" }
+
+func (m *Memory) Publish(_ context.Context, event factcheck.Event) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns a copy of everything published so far
+func (m *Memory) Events() []factcheck.Event {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	events := make([]factcheck.Event, len(m.events))
+	copy(events, m.events)
+	return events
+}
+
+// Fail makes subsequent publishes fail with err, or succeed again if err is nil
+func (m *Memory) Fail(err error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.err = err
+}
@@ -0,0 +1,51 @@
+package outbox_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/outbox"
+)
+
+func TestSinkWebhook(t *testing.T) {
+	event := factcheck.Event{
+		ID:        "550e8400-e29b-41d4-a716-446655440001",
+		Seq:       7,
+		Type:      factcheck.TypeEventTopicResolved,
+		SubjectID: "550e8400-e29b-41d4-a716-446655440002",
+		Payload:   json.RawMessage(`{"id":"550e8400-e29b-41d4-a716-446655440002"}`),
+	}
+	var received factcheck.Event
+	var header string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(outbox.HeaderEventID)
+		err := json.NewDecoder(r.Body).Decode(&received)
+		if err != nil {
+			t.Errorf("unexpected body: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := outbox.NewSinkWebhook(srv.URL)
+	err := sink.Publish(t.Context(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != event.ID {
+		t.Fatalf("unexpected event id header: '%s'", header)
+	}
+	if received.ID != event.ID || received.Seq != event.Seq || received.Type != event.Type {
+		t.Fatalf("unexpected event received: %+v", received)
+	}
+
+	status = http.StatusInternalServerError
+	err = sink.Publish(t.Context(), event)
+	if err == nil {
+		t.Fatal("expecting error from non-2xx status")
+	}
+}
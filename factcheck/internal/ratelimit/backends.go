@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// sweepEvery is how many takes Memory waits between removing full buckets,
+// which are the same as missing buckets
+const sweepEvery = 1024
+
+// Memory keeps buckets in memory of this instance
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]entry
+	takes   int
+}
+
+type entry struct {
+	bucket
+	full time.Time // When the bucket will be full again
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]entry)}
+}
+
+func (m *Memory) Take(_ context.Context, requests ...Request) (Result, error) {
+	now := utils.TimeNow()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buckets := make([]bucket, len(requests))
+	for i, r := range requests {
+		e, ok := m.buckets[r.Key]
+		if !ok {
+			e.bucket = bucket{tokens: float64(r.Limit.Burst), updated: now}
+		}
+		buckets[i] = e.bucket
+	}
+	buckets, result := take(buckets, now, requests)
+	for i, r := range requests {
+		missing := float64(r.Limit.Burst) - buckets[i].tokens
+		m.buckets[r.Key] = entry{
+			bucket: buckets[i],
+			full:   now.Add(time.Duration(missing / r.Limit.rate() * float64(time.Second))),
+		}
+	}
+	m.takes++
+	if m.takes%sweepEvery == 0 {
+		for k, e := range m.buckets {
+			if !e.full.After(now) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Postgres keeps buckets in Postgres, so that instances share limits.
+// Each take locks its buckets in a short transaction, in order of keys so that takes never deadlock.
+// Buckets are kept until swept, see [Policy.Sweep].
+type Postgres struct {
+	repo repo.Repository
+}
+
+func NewPostgres(repository repo.Repository) Postgres {
+	return Postgres{repo: repository}
+}
+
+func (p Postgres) Take(ctx context.Context, requests ...Request) (Result, error) {
+	now := utils.TimeNow()
+	requests = slices.Clone(requests)
+	slices.SortFunc(requests, func(a, b Request) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	tx, err := p.repo.BeginTx(ctx, repo.ReadCommitted)
+	if err != nil {
+		return Result{}, err
+	}
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err == nil || errors.Is(err, context.Canceled) {
+			return
+		}
+		slog.DebugContext(ctx, "rate limit rollback", "err", err)
+	}()
+
+	withTx := repo.WithTx(tx)
+	buckets := make([]bucket, len(requests))
+	for i, r := range requests {
+		locked, err := p.repo.RateLimits.Lock(ctx, repo.Bucket{Key: r.Key, Tokens: float64(r.Limit.Burst), UpdatedAt: now}, withTx)
+		if err != nil {
+			return Result{}, err
+		}
+		buckets[i] = bucket{tokens: locked.Tokens, updated: locked.UpdatedAt}
+	}
+	buckets, result := take(buckets, now, requests)
+	for i, r := range requests {
+		err = p.repo.RateLimits.Update(ctx, repo.Bucket{Key: r.Key, Tokens: buckets[i].tokens, UpdatedAt: buckets[i].updated}, withTx)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// Sweep deletes buckets not taken from for idle
+func (p Postgres) Sweep(ctx context.Context, idle time.Duration) (int64, error) {
+	return p.repo.RateLimits.DeleteBefore(ctx, utils.TimeNow().Add(-idle))
+}
@@ -0,0 +1,288 @@
+// Package ratelimit limits message submissions of users and their chats with token buckets.
+// Buckets are kept in memory of a single instance by Memory,
+// or in Postgres by Postgres to be shared by multiple instances.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/metrics"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Scopes of limits, which prefix bucket keys
+const (
+	scopeUser = "user"
+	scopeChat = "chat"
+)
+
+const (
+	BackendNone     = "none"
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+var metricLimited = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+	Name: "factcheck_rate_limited_total",
+	Help: "Submissions rejected by rate limits, by user type and scope: user or chat",
+}, []string{"type_user", "scope"})
+
+// Limit allows bursts of Burst tokens, refilled at Burst tokens per Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// ParseLimit parses limit formatted "burst/period", e.g. "20/1m"
+func ParseLimit(s string) (Limit, error) {
+	burst, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("bad limit '%s', expecting burst/period like 20/1m", s)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b <= 0 {
+		return Limit{}, fmt.Errorf("bad burst '%s' of limit '%s'", burst, s)
+	}
+	p, err := time.ParseDuration(period)
+	if err != nil || p <= 0 {
+		return Limit{}, fmt.Errorf("bad period '%s' of limit '%s'", period, s)
+	}
+	return Limit{Burst: b, Period: p}, nil
+}
+
+// rate returns tokens refilled per second
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// Request requests a token from bucket of Key, limited by Limit
+type Request struct {
+	Key   string
+	Limit Limit
+}
+
+// Result is result of taking tokens
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left in the emptiest bucket
+	Key        string        // Bucket that rejected the take if not allowed, the last to refill
+	RetryAfter time.Duration // Time until every bucket has a token if not allowed
+}
+
+// Limiter takes tokens from buckets identified by keys
+type Limiter interface {
+	// Take takes a token from every requested bucket if all of them have one, and none otherwise,
+	// so that buckets are never debited for takes rejected by other buckets
+	Take(ctx context.Context, requests ...Request) (Result, error)
+}
+
+// bucket is a token bucket, as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills buckets of requests until now, and takes a token from each if all of them have one
+func take(buckets []bucket, now time.Time, requests []Request) ([]bucket, Result) {
+	refilled := make([]bucket, len(buckets))
+	result := Result{Allowed: true, Remaining: math.MaxInt}
+	for i, b := range buckets {
+		limit := requests[i].Limit
+		elapsed := max(now.Sub(b.updated).Seconds(), 0)
+		tokens := math.Min(float64(limit.Burst), b.tokens+elapsed*limit.rate())
+		refilled[i] = bucket{tokens: tokens, updated: now}
+		if tokens >= 1 {
+			continue
+		}
+		wait := time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+		if result.Allowed || wait > result.RetryAfter {
+			result = Result{Key: requests[i].Key, RetryAfter: wait}
+		}
+	}
+	if !result.Allowed {
+		return refilled, result
+	}
+	for i := range refilled {
+		refilled[i].tokens--
+		result.Remaining = min(result.Remaining, int(refilled[i].tokens))
+	}
+	return refilled, result
+}
+
+// ErrLimited is returned when users or their chats submitted more than their limits allow
+type ErrLimited struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *ErrLimited) Error() string {
+	return fmt.Sprintf("rate limited %s, retry after %s", e.Key, e.RetryAfter.Round(time.Second))
+}
+
+// IsLimited checks if err is *ErrLimited, and returns it
+func IsLimited(err error) (*ErrLimited, bool) {
+	var limited *ErrLimited
+	ok := errors.As(err, &limited)
+	return limited, ok
+}
+
+// Notices remembers limited keys whose users were told to wait, until they can retry,
+// so that floods of limited submissions get a single notice per key
+type Notices struct {
+	mu     sync.Mutex
+	until  map[string]time.Time
+	notice int
+}
+
+func NewNotices() *Notices {
+	return &Notices{until: make(map[string]time.Time)}
+}
+
+// First returns true if limited is the first of its key since the key could last retry,
+// i.e. if its users should be told to wait
+func (n *Notices) First(limited *ErrLimited) bool {
+	now := utils.TimeNow()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if until, ok := n.until[limited.Key]; ok && now.Before(until) {
+		return false
+	}
+	n.until[limited.Key] = now.Add(limited.RetryAfter)
+	n.notice++
+	if n.notice%sweepEvery == 0 {
+		for k, until := range n.until {
+			if !now.Before(until) {
+				delete(n.until, k)
+			}
+		}
+	}
+	return true
+}
+
+// Policy limits each user and each group chat by limits of their user types.
+// The zero Policy allows everything.
+type Policy struct {
+	limiter Limiter
+	users   map[factcheck.TypeUser]Limit
+	chats   map[factcheck.TypeUser]Limit
+}
+
+func NewPolicy(limiter Limiter, users map[factcheck.TypeUser]Limit, chats map[factcheck.TypeUser]Limit) Policy {
+	return Policy{limiter: limiter, users: users, chats: chats}
+}
+
+// New returns policy with backend and limits from conf
+func New(conf config.Config, repository repo.Repository) (Policy, error) {
+	var limiter Limiter
+	switch conf.RateLimit.Backend {
+	case BackendNone:
+		return Policy{}, nil
+	case BackendMemory:
+		limiter = NewMemory()
+	case BackendPostgres:
+		limiter = NewPostgres(repository)
+	default:
+		return Policy{}, fmt.Errorf("unknown rate limit backend '%s'", conf.RateLimit.Backend)
+	}
+	users, err := parseLimits(conf.RateLimit.Users)
+	if err != nil {
+		return Policy{}, fmt.Errorf("bad user rate limits: %w", err)
+	}
+	chats, err := parseLimits(conf.RateLimit.Chats)
+	if err != nil {
+		return Policy{}, fmt.Errorf("bad chat rate limits: %w", err)
+	}
+	return NewPolicy(limiter, users, chats), nil
+}
+
+func parseLimits(limits map[string]string) (map[factcheck.TypeUser]Limit, error) {
+	parsed := make(map[factcheck.TypeUser]Limit, len(limits))
+	for typeUser, s := range limits {
+		t := factcheck.TypeUser(typeUser)
+		if !t.IsValid() {
+			return nil, fmt.Errorf("invalid user type '%s'", typeUser)
+		}
+		limit, err := ParseLimit(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[t] = limit
+	}
+	return parsed, nil
+}
+
+// Allow takes a token of user, and of their chat if it is not their own 1:1 chat.
+// It returns *ErrLimited if either has no tokens left, and then takes neither.
+//
+// Errors of the limiter are logged and the submission is allowed,
+// so that a broken limiter does not stop users from submitting.
+func (p Policy) Allow(ctx context.Context, user factcheck.UserInfo) error {
+	if p.limiter == nil {
+		return nil
+	}
+	var requests []Request
+	if limit, ok := p.users[user.UserType]; ok {
+		requests = append(requests, Request{Key: key(user, scopeUser, user.UserID), Limit: limit})
+	}
+	if limit, ok := p.chats[user.UserType]; ok && user.ChatID != "" && user.ChatID != user.UserID {
+		requests = append(requests, Request{Key: key(user, scopeChat, user.ChatID), Limit: limit})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	result, err := p.limiter.Take(ctx, requests...)
+	if err != nil {
+		slog.ErrorContext(ctx, "rate limiter error, allowing submission", "requests", requests, "err", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	scope, _, _ := strings.Cut(result.Key, ":")
+	metricLimited.WithLabelValues(string(user.UserType), scope).Inc()
+	return &ErrLimited{Key: result.Key, RetryAfter: result.RetryAfter}
+}
+
+// Sweeper is implemented by limiters which keep buckets until swept,
+// unlike Memory which sweeps its own buckets
+type Sweeper interface {
+	Sweep(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+// Sweep deletes buckets idle for the longest period of limits of p, and returns how many were deleted.
+// Such buckets are full again, which is the same as missing, so users and chats which come back lose nothing.
+func (p Policy) Sweep(ctx context.Context) (int64, error) {
+	sweeper, ok := p.limiter.(Sweeper)
+	if !ok {
+		return 0, nil
+	}
+	var horizon time.Duration
+	for _, limits := range []map[factcheck.TypeUser]Limit{p.users, p.chats} {
+		for _, limit := range limits {
+			horizon = max(horizon, limit.Period)
+		}
+	}
+	if horizon == 0 {
+		return 0, nil
+	}
+	return sweeper.Sweep(ctx, horizon)
+}
+
+// key returns bucket key of id in scope, e.g. user:USER_CHAT:U123
+func key(user factcheck.UserInfo, scope string, id string) string {
+	return scope + ":" + string(user.UserType) + ":" + id
+}
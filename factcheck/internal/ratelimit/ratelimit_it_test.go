@@ -0,0 +1,117 @@
+//go:build integration_test
+// +build integration_test
+
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestPostgres(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(time.Microsecond)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	// Instances share buckets in Postgres
+	user := ratelimit.Request{Key: "user:USER_CHAT:U1", Limit: ratelimit.Limit{Burst: 2, Period: 2 * time.Second}}
+	a, b := ratelimit.NewPostgres(app.Repository), ratelimit.NewPostgres(app.Repository)
+	for i, limiter := range []ratelimit.Postgres{a, b} {
+		result, err := limiter.Take(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != 1-i {
+			t.Fatalf("unexpected result of take %d: %+v", i, result)
+		}
+	}
+	result, err := a.Take(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("unexpected result of exhausted bucket: %+v", result)
+	}
+
+	utils.TimeFreeze(now.Add(time.Second))
+	result, err = b.Take(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("unexpected result of refilled bucket: %+v", result)
+	}
+
+	// Buckets are only debited if all of them have tokens
+	chat := ratelimit.Request{Key: "chat:USER_GROUPCHAT:C1", Limit: ratelimit.Limit{Burst: 1, Period: time.Minute}}
+	_, err = a.Take(ctx, chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.TimeFreeze(now.Add(2 * time.Second))
+	result, err = a.Take(ctx, user, chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Key != chat.Key {
+		t.Fatalf("unexpected result of exhausted chat: %+v", result)
+	}
+	result, err = b.Take(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("unexpected result of user after rejected take: %+v", result)
+	}
+}
+
+func TestPolicy_Sweep(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(time.Microsecond)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	policy := ratelimit.NewPolicy(ratelimit.NewPostgres(app.Repository),
+		map[factcheck.TypeUser]ratelimit.Limit{factcheck.TypeUserMessageLINEGroupChat: {Burst: 2, Period: 2 * time.Second}},
+		map[factcheck.TypeUser]ratelimit.Limit{factcheck.TypeUserMessageLINEGroupChat: {Burst: 1, Period: time.Minute}},
+	)
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U1", ChatID: "C1"}
+	err = policy.Allow(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Buckets are swept only after the longest period, when all of them are full again
+	utils.TimeFreeze(now.Add(30 * time.Second))
+	deleted, err := policy.Sweep(ctx)
+	if err != nil || deleted != 0 {
+		t.Fatalf("unexpected sweep of buckets not yet full: %d, %v", deleted, err)
+	}
+	utils.TimeFreeze(now.Add(time.Minute + time.Second))
+	deleted, err = policy.Sweep(ctx)
+	if err != nil || deleted != 2 {
+		t.Fatalf("unexpected sweep of full buckets: %d, %v", deleted, err)
+	}
+	err = policy.Allow(ctx, user)
+	if err != nil {
+		t.Fatalf("unexpected error after sweep: %v", err)
+	}
+}
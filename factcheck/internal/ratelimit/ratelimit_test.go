@@ -0,0 +1,274 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/ratelimit"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("20/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit.Burst != 20 || limit.Period != time.Minute {
+		t.Fatalf("unexpected limit: %+v", limit)
+	}
+	for _, s := range []string{"", "20", "20/", "/1m", "0/1m", "-1/1m", "20/0s", "20/1x", "a/1m"} {
+		_, err := ratelimit.ParseLimit(s)
+		if err == nil {
+			t.Fatalf("expected error for limit '%s'", s)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	limit := ratelimit.Limit{Burst: 3, Period: 3 * time.Second}
+	a, b := ratelimit.Request{Key: "a", Limit: limit}, ratelimit.Request{Key: "b", Limit: limit}
+	m := ratelimit.NewMemory()
+	for i := range 3 {
+		result, err := m.Take(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("unexpected result of take %d: %+v", i, result)
+		}
+	}
+	result, err := m.Take(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Key != "a" || result.RetryAfter != time.Second {
+		t.Fatalf("unexpected result of exhausted bucket: %+v", result)
+	}
+
+	// Other buckets are not affected
+	result, err = m.Take(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("unexpected result of other bucket: %+v", result)
+	}
+
+	// Refills 1 token per second
+	utils.TimeFreeze(now.Add(500 * time.Millisecond))
+	result, err = m.Take(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("unexpected result of partly refilled bucket: %+v", result)
+	}
+	utils.TimeFreeze(now.Add(time.Second))
+	result, err = m.Take(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("unexpected result of refilled bucket: %+v", result)
+	}
+
+	// Never refills beyond burst
+	utils.TimeFreeze(now.Add(time.Hour))
+	for i := range 4 {
+		result, err = m.Take(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != (i < 3) {
+			t.Fatalf("unexpected result of take %d after an hour: %+v", i, result)
+		}
+	}
+}
+
+func TestMemory_Buckets(t *testing.T) {
+	utils.TimeFreeze(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	m := ratelimit.NewMemory()
+	user := ratelimit.Request{Key: "user", Limit: ratelimit.Limit{Burst: 2, Period: 2 * time.Second}}
+	chat := ratelimit.Request{Key: "chat", Limit: ratelimit.Limit{Burst: 1, Period: 4 * time.Second}}
+	result, err := m.Take(ctx, user, chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("unexpected result of first take: %+v", result)
+	}
+	// Rejected by chat, which refills last
+	result, err = m.Take(ctx, user, chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.Key != "chat" || result.RetryAfter != 4*time.Second {
+		t.Fatalf("unexpected result of exhausted chat: %+v", result)
+	}
+	// User was not debited by the rejected take
+	result, err = m.Take(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("unexpected result of user alone: %+v", result)
+	}
+}
+
+func TestNotices(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	notices := ratelimit.NewNotices()
+	limited := &ratelimit.ErrLimited{Key: "user:USER_CHAT:U1", RetryAfter: 10 * time.Second}
+	if !notices.First(limited) {
+		t.Fatalf("expected first notice")
+	}
+	for _, d := range []time.Duration{0, time.Second, 9 * time.Second} {
+		utils.TimeFreeze(now.Add(d))
+		if notices.First(limited) {
+			t.Fatalf("unexpected notice after %s", d)
+		}
+	}
+	if !notices.First(&ratelimit.ErrLimited{Key: "chat:USER_GROUPCHAT:C1", RetryAfter: time.Second}) {
+		t.Fatalf("expected first notice of other key")
+	}
+	// Limited again once the key could retry
+	utils.TimeFreeze(now.Add(10 * time.Second))
+	if !notices.First(limited) {
+		t.Fatalf("expected notice after retry time")
+	}
+}
+
+// limiterErr fails every take
+type limiterErr struct{}
+
+func (limiterErr) Take(context.Context, ...ratelimit.Request) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestPolicy(t *testing.T) {
+	utils.TimeFreeze(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	policy := ratelimit.NewPolicy(
+		ratelimit.NewMemory(),
+		map[factcheck.TypeUser]ratelimit.Limit{
+			factcheck.TypeUserMessageLINEChat:      {Burst: 2, Period: time.Minute},
+			factcheck.TypeUserMessageLINEGroupChat: {Burst: 2, Period: time.Minute},
+		},
+		map[factcheck.TypeUser]ratelimit.Limit{
+			factcheck.TypeUserMessageLINEGroupChat: {Burst: 3, Period: time.Minute},
+		},
+	)
+
+	t.Run("users", func(t *testing.T) {
+		// 1:1 chats are limited only as users
+		user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+		for i := range 2 {
+			err := policy.Allow(ctx, user)
+			if err != nil {
+				t.Fatalf("unexpected error of submission %d: %v", i, err)
+			}
+		}
+		err := policy.Allow(ctx, user)
+		limited, ok := ratelimit.IsLimited(err)
+		if !ok {
+			t.Fatalf("expected ErrLimited, got %v", err)
+		}
+		if limited.Key != "user:USER_CHAT:U1" || limited.RetryAfter != 30*time.Second {
+			t.Fatalf("unexpected error: %+v", limited)
+		}
+	})
+
+	t.Run("group chats", func(t *testing.T) {
+		// Each user is within their limit, but the chat is not
+		for i, userID := range []string{"U2", "U3", "U2"} {
+			err := policy.Allow(ctx, factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: userID, ChatID: "C1"})
+			if err != nil {
+				t.Fatalf("unexpected error of submission %d: %v", i, err)
+			}
+		}
+		err := policy.Allow(ctx, factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U4", ChatID: "C1"})
+		limited, ok := ratelimit.IsLimited(err)
+		if !ok {
+			t.Fatalf("expected ErrLimited, got %v", err)
+		}
+		if limited.Key != "chat:USER_GROUPCHAT:C1" {
+			t.Fatalf("unexpected error: %+v", limited)
+		}
+		// Other chats are not affected, and U4 was not debited when C1 was limited
+		for i := range 2 {
+			err = policy.Allow(ctx, factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEGroupChat, UserID: "U4", ChatID: "C2"})
+			if err != nil {
+				t.Fatalf("unexpected error of submission %d to other chat: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("user types without limits", func(t *testing.T) {
+		admin := factcheck.UserInfo{UserType: factcheck.TypeUserMessageAdmin, UserID: "admin"}
+		for i := range 10 {
+			err := policy.Allow(ctx, admin)
+			if err != nil {
+				t.Fatalf("unexpected error of submission %d: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("zero policy", func(t *testing.T) {
+		err := ratelimit.Policy{}.Allow(ctx, factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("limiter errors allow submissions", func(t *testing.T) {
+		policy := ratelimit.NewPolicy(limiterErr{}, map[factcheck.TypeUser]ratelimit.Limit{
+			factcheck.TypeUserMessageLINEChat: {Burst: 1, Period: time.Minute},
+		}, nil)
+		err := policy.Allow(ctx, factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	conf := config.Config{RateLimit: config.RateLimit{
+		Backend: ratelimit.BackendMemory,
+		Users:   map[string]string{"USER_CHAT": "20/1m"},
+		Chats:   map[string]string{"USER_GROUPCHAT": "60/1m"},
+	}}
+	_, err := ratelimit.New(conf, repo.Repository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []config.RateLimit{
+		{Backend: "redis"},
+		{Backend: ratelimit.BackendMemory, Users: map[string]string{"USER_UNKNOWN": "20/1m"}},
+		{Backend: ratelimit.BackendMemory, Chats: map[string]string{"USER_GROUPCHAT": "60"}},
+	}
+	for _, c := range bad {
+		_, err := ratelimit.New(config.Config{RateLimit: c}, repo.Repository{})
+		if err == nil {
+			t.Fatalf("expected error for config %+v", c)
+		}
+	}
+}
@@ -3,16 +3,31 @@ package repo
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/search"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 )
 
+// Answers stores revisions of answers to topics.
+// Only published revisions are listed, except by ListRevisions.
 type Answers interface {
+	// Create creates answer as the next revision of its topic
 	Create(context.Context, factcheck.Answer, ...Option) (factcheck.Answer, error)
 	GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Answer, error)
+	// GetByTopicID gets the published answer of topic
 	GetByTopicID(ctx context.Context, topicID string, opts ...Option) (factcheck.Answer, error)
+	GetRevision(ctx context.Context, topicID string, revision int, opts ...Option) (factcheck.Answer, error)
 	ListByTopicID(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Answer, error)
+	ListByTopicIDPage(ctx context.Context, topicID string, limit int, cursor Cursor, opts ...Option) (Page[factcheck.Answer], error)
+	ListRevisions(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Answer, error)
+	// UpdateDraft updates text and verdict of draft. Published answers are not found.
+	UpdateDraft(ctx context.Context, id string, text string, verdict factcheck.Verdict, opts ...Option) (factcheck.Answer, error)
+	// Publish publishes draft, which corrects published answer correctsID if not empty.
+	// Published answers are not found.
+	Publish(ctx context.Context, id string, correctsID string, opts ...Option) (factcheck.Answer, error)
 	Delete(ctx context.Context, id string, opts ...Option) error
 }
 
@@ -25,6 +40,8 @@ type answers struct {
 }
 
 func (a *answers) Create(ctx context.Context, answer factcheck.Answer, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.Create")
+	defer span.End()
 	queries := queries(a.queries, options(opts...))
 	if answer.Text == "" {
 		slog.WarnContext(ctx, "empty answer.text", "answer_id", answer.ID)
@@ -41,6 +58,8 @@ func (a *answers) Create(ctx context.Context, answer factcheck.Answer, opts ...O
 }
 
 func (a *answers) GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.GetByID")
+	defer span.End()
 	queries := queries(a.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -54,6 +73,8 @@ func (a *answers) GetByID(ctx context.Context, id string, opts ...Option) (factc
 }
 
 func (a *answers) GetByTopicID(ctx context.Context, topicID string, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.GetByTopicID")
+	defer span.End()
 	queries := queries(a.queries, options(opts...))
 	topicUUID, err := postgres.UUID(topicID)
 	if err != nil {
@@ -67,6 +88,8 @@ func (a *answers) GetByTopicID(ctx context.Context, topicID string, opts ...Opti
 }
 
 func (a *answers) ListByTopicID(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.ListByTopicID")
+	defer span.End()
 	queries := queries(a.queries, options(opts...))
 	topicUUID, err := postgres.UUID(topicID)
 	if err != nil {
@@ -79,7 +102,114 @@ func (a *answers) ListByTopicID(ctx context.Context, topicID string, opts ...Opt
 	return postgres.ToAnswers(result)
 }
 
+func (a *answers) GetRevision(ctx context.Context, topicID string, revision int, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.GetRevision")
+	defer span.End()
+	queries := queries(a.queries, options(opts...))
+	topicUUID, err := postgres.UUID(topicID)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	result, err := queries.GetAnswerRevision(ctx, postgres.GetAnswerRevisionParams{
+		TopicID:  topicUUID,
+		Revision: int32(revision), //nolint:gosec
+	})
+	if err != nil {
+		return factcheck.Answer{}, handleNotFound(err, filter{"topic_id": topicID, "revision": revision})
+	}
+	return postgres.ToAnswer(result)
+}
+
+// ListRevisions lists all revisions of answers to topic including drafts, latest first
+func (a *answers) ListRevisions(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.ListRevisions")
+	defer span.End()
+	queries := queries(a.queries, options(opts...))
+	topicUUID, err := postgres.UUID(topicID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := queries.ListAnswerRevisions(ctx, topicUUID)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToAnswers(result)
+}
+
+func (a *answers) UpdateDraft(ctx context.Context, id string, text string, verdict factcheck.Verdict, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.UpdateDraft")
+	defer span.End()
+	queries := queries(a.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	updated, err := queries.UpdateAnswerDraft(ctx, postgres.UpdateAnswerDraftParams{
+		ID:         uuid,
+		Text:       text,
+		Verdict:    postgres.TextNullable(verdict),
+		TextTokens: search.Tokens(text),
+	})
+	if err != nil {
+		return factcheck.Answer{}, handleNotFound(err, filter{"id": id, "status": string(factcheck.StatusAnswerDraft)})
+	}
+	return postgres.ToAnswer(updated)
+}
+
+func (a *answers) Publish(ctx context.Context, id string, correctsID string, opts ...Option) (factcheck.Answer, error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.Publish")
+	defer span.End()
+	queries := queries(a.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.Answer{}, err
+	}
+	published, err := queries.PublishAnswer(ctx, postgres.PublishAnswerParams{
+		ID:         uuid,
+		CorrectsID: postgres.UUIDNullable(correctsID),
+	})
+	if err != nil {
+		return factcheck.Answer{}, handleNotFound(err, filter{"id": id, "status": string(factcheck.StatusAnswerDraft)})
+	}
+	return postgres.ToAnswer(published)
+}
+
+// ListByTopicIDPage lists a page of answers to topic from cursor, latest first
+func (a *answers) ListByTopicIDPage(ctx context.Context, topicID string, limit int, cursor Cursor, opts ...Option) (Page[factcheck.Answer], error) {
+	ctx, span := tracing.Start(ctx, "repo.Answers.ListByTopicIDPage")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	queries := queries(a.queries, options(opts...))
+	topicUUID, err := postgres.UUID(topicID)
+	if err != nil {
+		return Page[factcheck.Answer]{}, err
+	}
+	cursorCreatedAt, cursorID, err := cursor.params()
+	if err != nil {
+		return Page[factcheck.Answer]{}, err
+	}
+	rows, err := queries.ListAnswersByTopicIDPage(ctx, postgres.ListAnswersByTopicIDPageParams{
+		TopicID:         topicUUID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		CursorPrev:      cursor.Prev,
+		Limit:           int32(limit + 1), //nolint:gosec
+	})
+	if err != nil {
+		return Page[factcheck.Answer]{}, err
+	}
+	list, err := postgres.ToAnswers(rows)
+	if err != nil {
+		return Page[factcheck.Answer]{}, err
+	}
+	return paginate(list, limit, cursor, func(answer factcheck.Answer) (time.Time, string) {
+		return answer.CreatedAt, answer.ID
+	}), nil
+}
+
 func (a *answers) Delete(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Answers.Delete")
+	defer span.End()
 	queries := queries(a.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
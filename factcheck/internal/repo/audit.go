@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// Audit is an append-only log of admin actions
+type Audit interface {
+	Create(ctx context.Context, event factcheck.AuditEvent, opts ...Option) (factcheck.AuditEvent, error)
+	ListDynamic(ctx context.Context, limit, offset int, opts ...OptionAudit) ([]factcheck.AuditEvent, error)
+}
+
+func NewAudit(queries *postgres.Queries) Audit {
+	return &audit{queries: queries}
+}
+
+type audit struct {
+	queries *postgres.Queries
+}
+
+func (a *audit) Create(ctx context.Context, event factcheck.AuditEvent, opts ...Option) (factcheck.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "repo.Audit.Create")
+	defer span.End()
+	queries := queries(a.queries, options(opts...))
+	params, err := postgres.AuditEventCreator(event)
+	if err != nil {
+		return factcheck.AuditEvent{}, err
+	}
+	created, err := queries.CreateAuditEvent(ctx, params)
+	if err != nil {
+		return factcheck.AuditEvent{}, err
+	}
+	return postgres.ToAuditEvent(created)
+}
+
+func (a *audit) ListDynamic(ctx context.Context, limit, offset int, opts ...OptionAudit) ([]factcheck.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "repo.Audit.ListDynamic")
+	defer span.End()
+	limit, offset = sanitize(limit, offset)
+	options := options(opts...)
+	queries := queries(a.queries, options.Options)
+	var since, until pgtype.Timestamptz
+	if !options.Since.IsZero() {
+		since = pgtype.Timestamptz{Time: options.Since, Valid: true}
+	}
+	if !options.Until.IsZero() {
+		until = pgtype.Timestamptz{Time: options.Until, Valid: true}
+	}
+	rows, err := queries.ListAuditEventsDynamic(ctx, postgres.ListAuditEventsDynamicParams{
+		Column1: options.ActorID,
+		Column2: options.TargetID,
+		Column3: string(options.Action),
+		Column4: since,
+		Column5: until,
+		Column6: int32(limit),  //nolint:gosec
+		Column7: int32(offset), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToAuditEvents(rows)
+}
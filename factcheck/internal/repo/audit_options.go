@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+type OptionAudit func(*OptionsAudit)
+
+type OptionsAudit struct {
+	Options
+	ActorID  string
+	TargetID string
+	Action   factcheck.ActionAudit
+	Since    time.Time // Inclusive
+	Until    time.Time // Exclusive
+}
+
+func AuditActorID(actorID string) OptionAudit {
+	return func(opts *OptionsAudit) {
+		opts.ActorID = actorID
+	}
+}
+
+// AuditTargetID filters events affecting targetID, as either the main or secondary target
+func AuditTargetID(targetID string) OptionAudit {
+	return func(opts *OptionsAudit) {
+		opts.TargetID = targetID
+	}
+}
+
+func AuditAction(action factcheck.ActionAudit) OptionAudit {
+	return func(opts *OptionsAudit) {
+		opts.Action = action
+	}
+}
+
+func AuditSince(since time.Time) OptionAudit {
+	return func(opts *OptionsAudit) {
+		opts.Since = since
+	}
+}
+
+func AuditUntil(until time.Time) OptionAudit {
+	return func(opts *OptionsAudit) {
+		opts.Until = until
+	}
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+)
+
+// Cursor is a keyset position in lists ordered by (created_at, id).
+// Unlike offsets, cursors do not shift when new items are created while paginating.
+// Zero Cursor starts from the first page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+	Prev      bool // Whether to list the page before the position, instead of after
+}
+
+// Page is a page of list, with opaque cursors to its adjacent pages
+type Page[T any] struct {
+	Data []T    `json:"data"`
+	Next string `json:"next"` // Empty on the last page
+	Prev string `json:"prev"` // Empty on the first page
+}
+
+// cursorJSON is the JSON form of Cursor, before encoded as an opaque string
+type cursorJSON struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Prev      bool      `json:"p,omitempty"`
+}
+
+// ParseCursor parses cursor from Cursor.String.
+// Empty string is parsed as zero Cursor.
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("bad cursor '%s': %w", s, err)
+	}
+	var c cursorJSON
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("bad cursor '%s': %w", s, err)
+	}
+	if c.CreatedAt.IsZero() || c.ID == "" {
+		return Cursor{}, fmt.Errorf("bad cursor '%s': %w", s, errors.New("missing position"))
+	}
+	return Cursor(c), nil
+}
+
+// String returns cursor as an opaque string for clients
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	b, err := json.Marshal(cursorJSON(c))
+	if err != nil {
+		panic(err) // Marshaling time and strings never fails
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c Cursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == ""
+}
+
+// params returns cursor as query parameters, with NULL timestamp for zero Cursor
+func (c Cursor) params() (pgtype.Timestamptz, pgtype.UUID, error) {
+	if c.IsZero() {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, nil
+	}
+	id, err := postgres.UUID(c.ID)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	createdAt, err := postgres.Timestamptz(c.CreatedAt)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	return createdAt, id, nil
+}
+
+// paginate builds page from items queried from cursor with limit+1,
+// which are in reverse list order if cursor.Prev.
+// The extra item only tells whether there are more items beyond the page.
+func paginate[T any](items []T, limit int, cursor Cursor, position func(T) (time.Time, string)) Page[T] {
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if cursor.Prev {
+		slices.Reverse(items)
+	}
+	if items == nil {
+		items = []T{}
+	}
+	page := Page[T]{Data: items}
+	if len(items) == 0 {
+		// Past either end of the list, so we can only go back the way we came
+		if !cursor.IsZero() {
+			back := Cursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Prev: !cursor.Prev}.String()
+			if cursor.Prev {
+				page.Next = back
+			} else {
+				page.Prev = back
+			}
+		}
+		return page
+	}
+	cursorAt := func(item T, prev bool) string {
+		createdAt, id := position(item)
+		return Cursor{CreatedAt: createdAt, ID: id, Prev: prev}.String()
+	}
+	// Listing backward means we came from the next page, and vice versa
+	if more || cursor.Prev {
+		page.Next = cursorAt(items[len(items)-1], false)
+	}
+	if more && cursor.Prev || !cursor.Prev && !cursor.IsZero() {
+		page.Prev = cursorAt(items[0], true)
+	}
+	return page
+}
@@ -0,0 +1,37 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := repo.Cursor{
+		CreatedAt: time.Date(2025, 7, 1, 12, 30, 0, 123456000, time.UTC),
+		ID:        "880e8400-e29b-41d4-a716-446655440001",
+		Prev:      true,
+	}
+	parsed, err := repo.ParseCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !parsed.CreatedAt.Equal(cursor.CreatedAt) || parsed.ID != cursor.ID || parsed.Prev != cursor.Prev {
+		t.Fatalf("unexpected parsed cursor: expected %+v, got %+v", cursor, parsed)
+	}
+
+	zero, err := repo.ParseCursor("")
+	if err != nil || !zero.IsZero() {
+		t.Fatalf("unexpected cursor from empty string: %+v, err %v", zero, err)
+	}
+	if s := (repo.Cursor{}).String(); s != "" {
+		t.Fatalf("unexpected string for zero cursor: '%s'", s)
+	}
+
+	for _, bad := range []string{"not-a-cursor", "e30"} { // e30 is {}
+		if _, err := repo.ParseCursor(bad); err == nil {
+			t.Fatalf("unexpected nil error for bad cursor '%s'", bad)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+type Deliveries interface {
+	Create(ctx context.Context, delivery factcheck.Delivery, opts ...Option) (factcheck.Delivery, error)
+	ListByTopicID(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Delivery, error)
+	ListByAnswerID(ctx context.Context, answerID string, opts ...Option) ([]factcheck.Delivery, error)
+}
+
+func NewDeliveries(queries *postgres.Queries) Deliveries {
+	return &deliveries{queries: queries}
+}
+
+type deliveries struct {
+	queries *postgres.Queries
+}
+
+func (d *deliveries) Create(ctx context.Context, delivery factcheck.Delivery, opts ...Option) (factcheck.Delivery, error) {
+	ctx, span := tracing.Start(ctx, "repo.Deliveries.Create")
+	defer span.End()
+	queries := queries(d.queries, options(opts...))
+	params, err := postgres.DeliveryCreator(delivery)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	created, err := queries.CreateDelivery(ctx, params)
+	if err != nil {
+		return factcheck.Delivery{}, err
+	}
+	return postgres.ToDelivery(created)
+}
+
+func (d *deliveries) ListByTopicID(ctx context.Context, topicID string, opts ...Option) ([]factcheck.Delivery, error) {
+	ctx, span := tracing.Start(ctx, "repo.Deliveries.ListByTopicID")
+	defer span.End()
+	queries := queries(d.queries, options(opts...))
+	topicUUID, err := postgres.UUID(topicID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := queries.ListDeliveriesByTopicID(ctx, topicUUID)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToDeliveries(result)
+}
+
+func (d *deliveries) ListByAnswerID(ctx context.Context, answerID string, opts ...Option) ([]factcheck.Delivery, error) {
+	ctx, span := tracing.Start(ctx, "repo.Deliveries.ListByAnswerID")
+	defer span.End()
+	queries := queries(d.queries, options(opts...))
+	answerUUID, err := postgres.UUID(answerID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := queries.ListDeliveriesByAnswerID(ctx, answerUUID)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToDeliveries(result)
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// LINEWebhookEvents stores IDs of LINE webhook events submitted,
+// so that events redelivered by LINE are skipped
+type LINEWebhookEvents interface {
+	// Create records webhook event id received at receivedAt,
+	// and returns false if it was already recorded
+	Create(ctx context.Context, id string, receivedAt time.Time, opts ...Option) (bool, error)
+	// DeleteBefore deletes events received before t, and returns how many were deleted
+	DeleteBefore(ctx context.Context, t time.Time, opts ...Option) (int64, error)
+}
+
+func NewLINEWebhookEvents(queries *postgres.Queries) LINEWebhookEvents {
+	return &lineWebhookEvents{queries: queries}
+}
+
+type lineWebhookEvents struct {
+	queries *postgres.Queries
+}
+
+func (l *lineWebhookEvents) Create(ctx context.Context, id string, receivedAt time.Time, opts ...Option) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.LINEWebhookEvents.Create")
+	defer span.End()
+	queries := queries(l.queries, options(opts...))
+	at, err := postgres.Timestamptz(receivedAt)
+	if err != nil {
+		return false, err
+	}
+	rows, err := queries.CreateLINEWebhookEvent(ctx, postgres.CreateLINEWebhookEventParams{
+		WebhookEventID: id,
+		ReceivedAt:     at,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (l *lineWebhookEvents) DeleteBefore(ctx context.Context, t time.Time, opts ...Option) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.LINEWebhookEvents.DeleteBefore")
+	defer span.End()
+	queries := queries(l.queries, options(opts...))
+	before, err := postgres.Timestamptz(t)
+	if err != nil {
+		return 0, err
+	}
+	return queries.DeleteLINEWebhookEventsBefore(ctx, before)
+}
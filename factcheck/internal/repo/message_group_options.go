@@ -1,5 +1,7 @@
 package repo
 
+import "github.com/kaogeek/line-fact-check/factcheck"
+
 type OptionMessageGroup func(*OptionsMessageGroup)
 
 type OptionsMessageGroup struct {
@@ -7,6 +9,8 @@ type OptionsMessageGroup struct {
 	LikeMessageText string
 	IDIn            []string
 	IDNotIn         []string
+	Statuses        []factcheck.StatusMGroup
+	Languages       []factcheck.Language
 }
 
 func MessageGroupLikeMessageText(text string) OptionMessageGroup {
@@ -26,3 +30,15 @@ func MessageGroupIDNotIn(idNotIn []string) OptionMessageGroup {
 		opts.IDNotIn = idNotIn
 	}
 }
+
+func MessageGroupInStatuses(statuses []factcheck.StatusMGroup) OptionMessageGroup {
+	return func(opts *OptionsMessageGroup) {
+		opts.Statuses = statuses
+	}
+}
+
+func MessageGroupInLanguages(languages []factcheck.Language) OptionMessageGroup {
+	return func(opts *OptionsMessageGroup) {
+		opts.Languages = languages
+	}
+}
@@ -3,9 +3,15 @@ package repo
 import (
 	"context"
 	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
 type MessageGroups interface {
@@ -13,9 +19,17 @@ type MessageGroups interface {
 	GetByID(ctx context.Context, id string, opts ...Option) (factcheck.MessageGroup, error)
 	GetBySHA1(ctx context.Context, sha1 string, opts ...Option) (factcheck.MessageGroup, error)
 	ListDynamic(ctx context.Context, limit int, offset int, opts ...OptionMessageGroup) ([]factcheck.MessageGroup, error)
+	ListDynamicPage(ctx context.Context, limit int, cursor Cursor, opts ...OptionMessageGroup) (Page[factcheck.MessageGroup], error)
 	ListByTopic(ctx context.Context, topicID string, opts ...Option) ([]factcheck.MessageGroup, error)
 	AssignTopic(ctx context.Context, id string, topicID string, opts ...Option) (factcheck.MessageGroup, error)
 	UnassignTopic(ctx context.Context, id string, opts ...Option) (factcheck.MessageGroup, error)
+	ListSimilar(ctx context.Context, simhash uint64, threshold float64, createdAfter time.Time, limit int, opts ...Option) ([]factcheck.MessageGroupSimilar, error)
+	// ListAfterID lists up to limit groups with IDs after afterID in order of IDs, for batch jobs over all groups.
+	// Empty afterID starts from the first group.
+	ListAfterID(ctx context.Context, afterID string, limit int, opts ...Option) ([]factcheck.MessageGroup, error)
+	// UpdateKey sets TextSHA1 and TextSimHash of group id, without changing UpdatedAt
+	UpdateKey(ctx context.Context, id string, sha1 string, simhash uint64, opts ...Option) error
+	UpdateStatus(ctx context.Context, id string, status factcheck.StatusMGroup, reason string, opts ...Option) (factcheck.MessageGroup, error)
 	Delete(ctx context.Context, id string, opts ...Option) error
 }
 
@@ -28,6 +42,8 @@ type messageGroups struct {
 }
 
 func (m *messageGroups) Create(ctx context.Context, group factcheck.MessageGroup, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.Create")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	if group.Text == "" {
 		slog.WarnContext(ctx, "empty group.text", "group_id", group.ID)
@@ -47,6 +63,8 @@ func (m *messageGroups) Create(ctx context.Context, group factcheck.MessageGroup
 }
 
 func (m *messageGroups) GetByID(ctx context.Context, id string, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.GetByID")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -60,14 +78,43 @@ func (m *messageGroups) GetByID(ctx context.Context, id string, opts ...Option)
 }
 
 func (m *messageGroups) ListDynamic(ctx context.Context, limit int, offset int, opts ...OptionMessageGroup) ([]factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.ListDynamic")
+	defer span.End()
+	return m.listDynamic(ctx, limit, offset, Cursor{}, opts...)
+}
+
+// ListDynamicPage lists a page of groups from cursor, latest first
+func (m *messageGroups) ListDynamicPage(ctx context.Context, limit int, cursor Cursor, opts ...OptionMessageGroup) (Page[factcheck.MessageGroup], error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.ListDynamicPage")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	list, err := m.listDynamic(ctx, limit+1, 0, cursor, opts...)
+	if err != nil {
+		return Page[factcheck.MessageGroup]{}, err
+	}
+	return paginate(list, limit, cursor, func(g factcheck.MessageGroup) (time.Time, string) {
+		return g.CreatedAt, g.ID
+	}), nil
+}
+
+func (m *messageGroups) listDynamic(ctx context.Context, limit int, offset int, cursor Cursor, opts ...OptionMessageGroup) ([]factcheck.MessageGroup, error) {
 	options := options(opts...)
 	queries := queries(m.queries, options.Options)
+	cursorCreatedAt, cursorID, err := cursor.params()
+	if err != nil {
+		return nil, err
+	}
 	result, err := queries.ListMessageGroupDynamic(ctx, postgres.ListMessageGroupDynamicParams{
-		Text:    options.LikeMessageText,
-		IDIn:    options.IDIn,
-		IDNotIn: options.IDNotIn,
-		Offset:  int32(offset), //nolint:gosec
-		Limit:   int32(limit),  //nolint:gosec
+		Text:            options.LikeMessageText,
+		IDIn:            options.IDIn,
+		IDNotIn:         options.IDNotIn,
+		Statuses:        utils.MapNoError(options.Statuses, utils.String[factcheck.StatusMGroup, string]),
+		Languages:       utils.MapNoError(options.Languages, utils.String[factcheck.Language, string]),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		CursorPrev:      cursor.Prev,
+		Offset:          int32(offset), //nolint:gosec
+		Limit:           int32(limit),  //nolint:gosec
 	})
 	if err != nil {
 		return nil, err
@@ -75,7 +122,78 @@ func (m *messageGroups) ListDynamic(ctx context.Context, limit int, offset int,
 	return postgres.ToMessageGroups(result)
 }
 
+// ListSimilar lists groups created after createdAfter with SimHash at least threshold similar to simhash,
+// most similar first. Rejected groups are never listed.
+func (m *messageGroups) ListSimilar(ctx context.Context, simhash uint64, threshold float64, createdAfter time.Time, limit int, opts ...Option) ([]factcheck.MessageGroupSimilar, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.ListSimilar")
+	defer span.End()
+	queries := queries(m.queries, options(opts...))
+	after, err := postgres.Timestamptz(createdAfter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := queries.ListSimilarMessageGroups(ctx, postgres.ListSimilarMessageGroupsParams{
+		CreatedAfter: after,
+		Simhash:      int64(simhash),                      //nolint:gosec
+		MaxDistance:  int32(dedup.MaxDistance(threshold)), //nolint:gosec
+		Limit:        int32(limit),                        //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	return utils.Map(rows, func(row postgres.ListSimilarMessageGroupsRow) (factcheck.MessageGroupSimilar, error) {
+		group, err := postgres.ToMessageGroup(row.MessageGroup)
+		if err != nil {
+			return factcheck.MessageGroupSimilar{}, err
+		}
+		return factcheck.MessageGroupSimilar{
+			MessageGroup: group,
+			Similarity:   dedup.Similarity(int(row.Distance)),
+		}, nil
+	})
+}
+
+func (m *messageGroups) ListAfterID(ctx context.Context, afterID string, limit int, opts ...Option) ([]factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.ListAfterID")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	queries := queries(m.queries, options(opts...))
+	after := pgtype.UUID{Valid: true} // Nil UUID sorts first
+	if afterID != "" {
+		var err error
+		after, err = postgres.UUID(afterID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rows, err := queries.ListMessageGroupsAfterID(ctx, postgres.ListMessageGroupsAfterIDParams{
+		ID:    after,
+		Limit: int32(limit), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToMessageGroups(rows)
+}
+
+func (m *messageGroups) UpdateKey(ctx context.Context, id string, sha1 string, simhash uint64, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.UpdateKey")
+	defer span.End()
+	queries := queries(m.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return err
+	}
+	return queries.UpdateMessageGroupKey(ctx, postgres.UpdateMessageGroupKeyParams{
+		ID:          uuid,
+		TextSha1:    sha1,
+		TextSimhash: postgres.Int8Nullable(simhash),
+	})
+}
+
 func (m *messageGroups) ListByTopic(ctx context.Context, topicID string, opts ...Option) ([]factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.ListByTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	topicUUID, err := postgres.UUID(topicID)
 	if err != nil {
@@ -89,6 +207,8 @@ func (m *messageGroups) ListByTopic(ctx context.Context, topicID string, opts ..
 }
 
 func (m *messageGroups) AssignTopic(ctx context.Context, id string, topicID string, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.AssignTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -112,6 +232,8 @@ func (m *messageGroups) AssignTopic(ctx context.Context, id string, topicID stri
 }
 
 func (m *messageGroups) UnassignTopic(ctx context.Context, id string, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.UnassignTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -124,7 +246,28 @@ func (m *messageGroups) UnassignTopic(ctx context.Context, id string, opts ...Op
 	return postgres.ToMessageGroup(result)
 }
 
+func (m *messageGroups) UpdateStatus(ctx context.Context, id string, status factcheck.StatusMGroup, reason string, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.UpdateStatus")
+	defer span.End()
+	queries := queries(m.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.MessageGroup{}, err
+	}
+	result, err := queries.UpdateMessageGroupStatus(ctx, postgres.UpdateMessageGroupStatusParams{
+		ID:           uuid,
+		Status:       string(status),
+		StatusReason: postgres.TextNullable(reason),
+	})
+	if err != nil {
+		return factcheck.MessageGroup{}, handleNotFound(err, filter{"id": id})
+	}
+	return postgres.ToMessageGroup(result)
+}
+
 func (m *messageGroups) GetBySHA1(ctx context.Context, sha1 string, opts ...Option) (factcheck.MessageGroup, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.GetBySHA1")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	result, err := queries.GetMessageGroupBySHA1(ctx, sha1)
 	if err != nil {
@@ -134,6 +277,8 @@ func (m *messageGroups) GetBySHA1(ctx context.Context, sha1 string, opts ...Opti
 }
 
 func (m *messageGroups) Delete(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.MessageGroups.Delete")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
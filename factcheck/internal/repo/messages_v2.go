@@ -2,9 +2,11 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
@@ -12,8 +14,10 @@ type MessagesV2 interface {
 	Create(ctx context.Context, message factcheck.MessageV2, opts ...Option) (factcheck.MessageV2, error)
 	GetByID(ctx context.Context, id string, opts ...Option) (factcheck.MessageV2, error)
 	ListByTopic(ctx context.Context, topicID string, opts ...Option) ([]factcheck.MessageV2, error)
+	ListByTopicPage(ctx context.Context, topicID string, limit int, cursor Cursor, opts ...Option) (Page[factcheck.MessageV2], error)
 	AssignTopic(ctx context.Context, messageID string, topicID string, opts ...Option) (factcheck.MessageV2, error)
 	UnassignTopic(ctx context.Context, messageID string, opts ...Option) (factcheck.MessageV2, error)
+	UnassignTopicByGroup(ctx context.Context, groupID string, opts ...Option) (int64, error)
 	ListByGroup(ctx context.Context, groupID string, opts ...Option) ([]factcheck.MessageV2, error)
 	AssignGroup(ctx context.Context, messageID string, groupID string, opts ...Option) (factcheck.MessageV2, error)
 	Delete(ctx context.Context, id string, opts ...Option) error
@@ -28,6 +32,8 @@ type messagesV2 struct {
 }
 
 func (m *messagesV2) Create(ctx context.Context, msg factcheck.MessageV2, opts ...Option) (factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.Create")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	params, err := postgres.MessageV2Creator(msg)
 	if err != nil {
@@ -41,6 +47,8 @@ func (m *messagesV2) Create(ctx context.Context, msg factcheck.MessageV2, opts .
 }
 
 func (m *messagesV2) GetByID(ctx context.Context, id string, opts ...Option) (factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.GetByID")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -54,6 +62,8 @@ func (m *messagesV2) GetByID(ctx context.Context, id string, opts ...Option) (fa
 }
 
 func (m *messagesV2) ListByTopic(ctx context.Context, topicID string, opts ...Option) ([]factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.ListByTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	topicUUID, err := postgres.UUID(topicID)
 	if err != nil {
@@ -66,7 +76,42 @@ func (m *messagesV2) ListByTopic(ctx context.Context, topicID string, opts ...Op
 	return utils.Map(list, postgres.ToMessageV2)
 }
 
+// ListByTopicPage lists a page of messages in topic from cursor, oldest first
+func (m *messagesV2) ListByTopicPage(ctx context.Context, topicID string, limit int, cursor Cursor, opts ...Option) (Page[factcheck.MessageV2], error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.ListByTopicPage")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	queries := queries(m.queries, options(opts...))
+	topicUUID, err := postgres.UUID(topicID)
+	if err != nil {
+		return Page[factcheck.MessageV2]{}, err
+	}
+	cursorCreatedAt, cursorID, err := cursor.params()
+	if err != nil {
+		return Page[factcheck.MessageV2]{}, err
+	}
+	rows, err := queries.ListMessagesV2ByTopicPage(ctx, postgres.ListMessagesV2ByTopicPageParams{
+		TopicID:         topicUUID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		CursorPrev:      cursor.Prev,
+		Limit:           int32(limit + 1), //nolint:gosec
+	})
+	if err != nil {
+		return Page[factcheck.MessageV2]{}, err
+	}
+	list, err := utils.Map(rows, postgres.ToMessageV2)
+	if err != nil {
+		return Page[factcheck.MessageV2]{}, err
+	}
+	return paginate(list, limit, cursor, func(m factcheck.MessageV2) (time.Time, string) {
+		return m.CreatedAt, m.ID
+	}), nil
+}
+
 func (m *messagesV2) ListByGroup(ctx context.Context, groupID string, opts ...Option) ([]factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.ListByGroup")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	groupUUID, err := postgres.UUID(groupID)
 	if err != nil {
@@ -80,6 +125,8 @@ func (m *messagesV2) ListByGroup(ctx context.Context, groupID string, opts ...Op
 }
 
 func (m *messagesV2) Delete(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.Delete")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -93,6 +140,8 @@ func (m *messagesV2) Delete(ctx context.Context, id string, opts ...Option) erro
 }
 
 func (m *messagesV2) AssignTopic(ctx context.Context, messageID string, topicID string, opts ...Option) (factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.AssignTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(messageID)
 	if err != nil {
@@ -113,6 +162,8 @@ func (m *messagesV2) AssignTopic(ctx context.Context, messageID string, topicID
 }
 
 func (m *messagesV2) UnassignTopic(ctx context.Context, messageID string, opts ...Option) (factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.UnassignTopic")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(messageID)
 	if err != nil {
@@ -125,7 +176,22 @@ func (m *messagesV2) UnassignTopic(ctx context.Context, messageID string, opts .
 	return postgres.ToMessageV2(msg)
 }
 
+// UnassignTopicByGroup unassigns all messages of group from their topics,
+// returning the number of messages unassigned
+func (m *messagesV2) UnassignTopicByGroup(ctx context.Context, groupID string, opts ...Option) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.UnassignTopicByGroup")
+	defer span.End()
+	queries := queries(m.queries, options(opts...))
+	uuid, err := postgres.UUID(groupID)
+	if err != nil {
+		return 0, err
+	}
+	return queries.UnassignMessagesV2FromTopicInGroup(ctx, uuid)
+}
+
 func (m *messagesV2) AssignGroup(ctx context.Context, messageID string, groupID string, opts ...Option) (factcheck.MessageV2, error) {
+	ctx, span := tracing.Start(ctx, "repo.MessagesV2.AssignGroup")
+	defer span.End()
 	queries := queries(m.queries, options(opts...))
 	uuid, err := postgres.UUID(messageID)
 	if err != nil {
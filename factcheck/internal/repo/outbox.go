@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Outbox stores domain events. Events should be created with the same
+// transaction as the change they describe, and are later published by a relay.
+type Outbox interface {
+	Create(ctx context.Context, event factcheck.Event, opts ...Option) (factcheck.Event, error)
+	// Claim leases oldest unpublished events due for publishing for lease, in order of Seq.
+	// Claimed events are skipped by other relays until the lease expires, or they are marked.
+	// Parked events and events waiting for their next attempt are not claimed.
+	Claim(ctx context.Context, limit int, lease time.Duration, opts ...Option) ([]factcheck.Event, error)
+	// ListAfter returns oldest events with Seq greater than seq, published or not
+	ListAfter(ctx context.Context, seq int64, limit int, opts ...Option) ([]factcheck.Event, error)
+	// ListBySeqs returns events of seqs which are visible, in order of Seq
+	ListBySeqs(ctx context.Context, seqs []int64, opts ...Option) ([]factcheck.Event, error)
+	// LastSeq returns Seq of the latest event, or 0 if there are no events
+	LastSeq(ctx context.Context, opts ...Option) (int64, error)
+	MarkPublished(ctx context.Context, id string, opts ...Option) error
+	// MarkFailed releases claimed event id for its next attempt after backoff,
+	// or parks it if it has failed backoff.MaxAttempts times.
+	MarkFailed(ctx context.Context, id string, reason string, backoff Backoff, opts ...Option) error
+	// ListSinksPublished returns names of sinks which published event id
+	ListSinksPublished(ctx context.Context, id string, opts ...Option) ([]string, error)
+	// RecordSinkAttempt records an attempt of sink publishing event id.
+	// reason is empty if the sink published the event.
+	RecordSinkAttempt(ctx context.Context, id string, sink string, reason string, opts ...Option) error
+}
+
+// Backoff schedules attempts of failed outbox events
+type Backoff struct {
+	Base        time.Duration // Delay after the first failure, doubled after every later failure
+	Max         time.Duration // Maximum delay
+	MaxAttempts int           // Events are parked after failing this many times
+}
+
+func NewOutbox(queries *postgres.Queries) Outbox {
+	return &outbox{queries: queries}
+}
+
+type outbox struct {
+	queries *postgres.Queries
+}
+
+func (o *outbox) Create(ctx context.Context, event factcheck.Event, opts ...Option) (factcheck.Event, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.Create")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	params, err := postgres.EventCreator(event)
+	if err != nil {
+		return factcheck.Event{}, err
+	}
+	created, err := queries.CreateOutboxEvent(ctx, params)
+	if err != nil {
+		return factcheck.Event{}, err
+	}
+	return postgres.ToEvent(created)
+}
+
+func (o *outbox) Claim(ctx context.Context, limit int, lease time.Duration, opts ...Option) ([]factcheck.Event, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.Claim")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	limit, _ = sanitize(limit, 0)
+	now := utils.TimeNow()
+	nowDB, err := postgres.Timestamptz(now)
+	if err != nil {
+		return nil, err
+	}
+	claimedUntil, err := postgres.Timestamptz(now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := queries.ClaimOutboxEvents(ctx, postgres.ClaimOutboxEventsParams{
+		ClaimedUntil: claimedUntil,
+		Now:          nowDB,
+		Limit:        int32(limit), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(rows, func(a, b postgres.Outbox) int { return cmp.Compare(a.Seq, b.Seq) })
+	return postgres.ToEvents(rows)
+}
+
+func (o *outbox) ListAfter(ctx context.Context, seq int64, limit int, opts ...Option) ([]factcheck.Event, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.ListAfter")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	limit, _ = sanitize(limit, 0)
+	rows, err := queries.ListOutboxAfter(ctx, postgres.ListOutboxAfterParams{
+		Seq:   seq,
+		Limit: int32(limit), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToEvents(rows)
+}
+
+func (o *outbox) ListBySeqs(ctx context.Context, seqs []int64, opts ...Option) ([]factcheck.Event, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.ListBySeqs")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	rows, err := queries.ListOutboxBySeqs(ctx, seqs)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToEvents(rows)
+}
+
+func (o *outbox) LastSeq(ctx context.Context, opts ...Option) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.LastSeq")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	return queries.GetOutboxLastSeq(ctx)
+}
+
+func (o *outbox) MarkPublished(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.MarkPublished")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return err
+	}
+	publishedAt, err := postgres.Timestamptz(utils.TimeNow())
+	if err != nil {
+		return err
+	}
+	return queries.MarkOutboxPublished(ctx, postgres.MarkOutboxPublishedParams{
+		ID:          uuid,
+		PublishedAt: publishedAt,
+	})
+}
+
+func (o *outbox) MarkFailed(ctx context.Context, id string, reason string, backoff Backoff, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.MarkFailed")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return err
+	}
+	now, err := postgres.Timestamptz(utils.TimeNow())
+	if err != nil {
+		return err
+	}
+	return queries.MarkOutboxFailed(ctx, postgres.MarkOutboxFailedParams{
+		ID:             uuid,
+		LastError:      postgres.TextNullable(reason),
+		Now:            now,
+		BackoffSecs:    backoff.Base.Seconds(),
+		BackoffMaxSecs: backoff.Max.Seconds(),
+		MaxAttempts:    int32(backoff.MaxAttempts), //nolint:gosec
+	})
+}
+
+func (o *outbox) ListSinksPublished(ctx context.Context, id string, opts ...Option) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.ListSinksPublished")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return nil, err
+	}
+	return queries.ListOutboxSinksPublished(ctx, uuid)
+}
+
+func (o *outbox) RecordSinkAttempt(ctx context.Context, id string, sink string, reason string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Outbox.RecordSinkAttempt")
+	defer span.End()
+	queries := queries(o.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return err
+	}
+	var publishedAt *time.Time
+	if reason == "" {
+		now := utils.TimeNow()
+		publishedAt = &now
+	}
+	publishedAtDB, err := postgres.TimestamptzNullable(publishedAt)
+	if err != nil {
+		return err
+	}
+	return queries.UpsertOutboxSinkAttempt(ctx, postgres.UpsertOutboxSinkAttemptParams{
+		EventID:     uuid,
+		Sink:        sink,
+		LastError:   postgres.TextNullable(reason),
+		PublishedAt: publishedAtDB,
+	})
+}
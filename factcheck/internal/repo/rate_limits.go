@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// Bucket is state of a token bucket of rate limiters, as of UpdatedAt
+type Bucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateLimits stores token buckets of rate limiters shared by multiple instances.
+// Buckets should be locked and updated in the same transaction.
+type RateLimits interface {
+	// Lock returns bucket of key, creating it from bucket if missing,
+	// and locks it until the transaction ends
+	Lock(ctx context.Context, bucket Bucket, opts ...Option) (Bucket, error)
+	Update(ctx context.Context, bucket Bucket, opts ...Option) error
+	// DeleteBefore deletes buckets last updated before t, and returns how many were deleted
+	DeleteBefore(ctx context.Context, t time.Time, opts ...Option) (int64, error)
+}
+
+func NewRateLimits(queries *postgres.Queries) RateLimits {
+	return &rateLimits{queries: queries}
+}
+
+type rateLimits struct {
+	queries *postgres.Queries
+}
+
+func (r *rateLimits) Lock(ctx context.Context, bucket Bucket, opts ...Option) (Bucket, error) {
+	ctx, span := tracing.Start(ctx, "repo.RateLimits.Lock")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	updatedAt, err := postgres.Timestamptz(bucket.UpdatedAt)
+	if err != nil {
+		return Bucket{}, err
+	}
+	locked, err := queries.LockRateLimit(ctx, postgres.LockRateLimitParams{
+		Key:       bucket.Key,
+		Tokens:    bucket.Tokens,
+		UpdatedAt: updatedAt,
+	})
+	if err != nil {
+		return Bucket{}, err
+	}
+	lockedAt, err := postgres.Time(locked.UpdatedAt)
+	if err != nil {
+		return Bucket{}, err
+	}
+	return Bucket{Key: locked.Key, Tokens: locked.Tokens, UpdatedAt: lockedAt}, nil
+}
+
+func (r *rateLimits) Update(ctx context.Context, bucket Bucket, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.RateLimits.Update")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	updatedAt, err := postgres.Timestamptz(bucket.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return queries.UpdateRateLimit(ctx, postgres.UpdateRateLimitParams{
+		Key:       bucket.Key,
+		Tokens:    bucket.Tokens,
+		UpdatedAt: updatedAt,
+	})
+}
+
+func (r *rateLimits) DeleteBefore(ctx context.Context, t time.Time, opts ...Option) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.RateLimits.DeleteBefore")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	before, err := postgres.Timestamptz(t)
+	if err != nil {
+		return 0, err
+	}
+	return queries.DeleteRateLimitsBefore(ctx, before)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
@@ -17,10 +18,18 @@ import (
 // Repository combines all repository interfaces
 // and provides a transaction manager for beginning a transaction
 type Repository struct {
-	Topics        Topics
-	MessagesV2    MessagesV2
-	MessageGroups MessageGroups
-	Answers       Answers
+	Topics            Topics
+	MessagesV2        MessagesV2
+	MessageGroups     MessageGroups
+	Answers           Answers
+	Sources           Sources
+	Deliveries        Deliveries
+	Outbox            Outbox
+	Roles             Roles
+	Audit             Audit
+	Search            Search
+	RateLimits        RateLimits
+	LINEWebhookEvents LINEWebhookEvents
 
 	TxnManager postgres.TxnManager
 }
@@ -34,11 +43,19 @@ type ErrNotFound struct {
 // New creates a new repository with all implementations
 func New(queries *postgres.Queries, pool *pgxpool.Pool) Repository {
 	return Repository{
-		Topics:        NewTopics(queries),
-		MessagesV2:    NewMessagesV2(queries),
-		MessageGroups: NewMessageGroups(queries),
-		Answers:       NewAnswers(queries),
-		TxnManager:    postgres.NewTxnManager(pool),
+		Topics:            NewTopics(queries),
+		MessagesV2:        NewMessagesV2(queries),
+		MessageGroups:     NewMessageGroups(queries),
+		Answers:           NewAnswers(queries),
+		Sources:           NewSources(queries),
+		Deliveries:        NewDeliveries(queries),
+		Outbox:            NewOutbox(queries),
+		Roles:             NewRoles(queries),
+		Audit:             NewAudit(queries),
+		Search:            NewSearch(queries),
+		RateLimits:        NewRateLimits(queries),
+		LINEWebhookEvents: NewLINEWebhookEvents(queries),
+		TxnManager:        postgres.NewTxnManager(pool),
 	}
 }
 
@@ -61,6 +78,40 @@ func (e *ErrNotFound) Is(target error) bool {
 	return ok
 }
 
+// ErrInvalidID is returned when a resource ID is not a valid UUID
+var ErrInvalidID = postgres.ErrInvalidUUID
+
+// SQLSTATE codes of errors callers may want to tell apart,
+// see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// IsUniqueViolation checks if the error is from inserting a duplicate of an existing row
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, codeUniqueViolation)
+}
+
+// IsForeignKeyViolation checks if the error is from referencing a row that does not exist,
+// or deleting a row that is still referenced
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, codeForeignKeyViolation)
+}
+
+// IsTxConflict checks if the error is from a transaction aborted by concurrent transactions,
+// which may succeed if retried
+func IsTxConflict(err error) bool {
+	return hasCode(err, codeSerializationFailure) || hasCode(err, codeDeadlockDetected)
+}
+
+func hasCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
 type filter map[string]any
 
 func handleNotFound(err error, filter any) error {
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+type Roles interface {
+	// Upsert grants role.Role to role.UserID, replacing the user's previous role
+	Upsert(ctx context.Context, role factcheck.UserRole, opts ...Option) (factcheck.UserRole, error)
+	GetByUserID(ctx context.Context, userID string, opts ...Option) (factcheck.UserRole, error)
+	List(ctx context.Context, opts ...Option) ([]factcheck.UserRole, error)
+	Delete(ctx context.Context, userID string, opts ...Option) error
+}
+
+func NewRoles(queries *postgres.Queries) Roles {
+	return &roles{queries: queries}
+}
+
+type roles struct {
+	queries *postgres.Queries
+}
+
+func (r *roles) Upsert(ctx context.Context, role factcheck.UserRole, opts ...Option) (factcheck.UserRole, error) {
+	ctx, span := tracing.Start(ctx, "repo.Roles.Upsert")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	params, err := postgres.UserRoleUpserter(role)
+	if err != nil {
+		return factcheck.UserRole{}, err
+	}
+	upserted, err := queries.UpsertUserRole(ctx, params)
+	if err != nil {
+		return factcheck.UserRole{}, err
+	}
+	return postgres.ToUserRole(upserted)
+}
+
+func (r *roles) GetByUserID(ctx context.Context, userID string, opts ...Option) (factcheck.UserRole, error) {
+	ctx, span := tracing.Start(ctx, "repo.Roles.GetByUserID")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	result, err := queries.GetUserRole(ctx, userID)
+	if err != nil {
+		return factcheck.UserRole{}, handleNotFound(err, map[string]string{"user_id": userID})
+	}
+	return postgres.ToUserRole(result)
+}
+
+func (r *roles) List(ctx context.Context, opts ...Option) ([]factcheck.UserRole, error) {
+	ctx, span := tracing.Start(ctx, "repo.Roles.List")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	result, err := queries.ListUserRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToUserRoles(result)
+}
+
+func (r *roles) Delete(ctx context.Context, userID string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Roles.Delete")
+	defer span.End()
+	queries := queries(r.queries, options(opts...))
+	deleted, err := queries.DeleteUserRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrNotFound{Filter: map[string]string{"user_id": userID}}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/search"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Search is full-text search across topics, approved message groups and published answers
+type Search interface {
+	// Search returns results matching all words in q, highest rank first.
+	// Results of all types are returned unless filtered with SearchInTypes.
+	Search(ctx context.Context, q string, limit, offset int, opts ...OptionSearch) ([]factcheck.SearchResult, error)
+	// Retokenize re-computes search tokens of up to limit rows of typ with IDs after afterID, in order of IDs.
+	// Empty afterID starts from the first row. It returns ID of the last retokenized row,
+	// and empty ID when there are no more rows, so callers can backfill all rows in batches.
+	Retokenize(ctx context.Context, typ factcheck.TypeSearch, afterID string, limit int, opts ...Option) (string, error)
+}
+
+func NewSearch(queries *postgres.Queries) Search {
+	return &searcher{queries: queries}
+}
+
+type searcher struct {
+	queries *postgres.Queries
+}
+
+func (s *searcher) Search(ctx context.Context, q string, limit, offset int, opts ...OptionSearch) ([]factcheck.SearchResult, error) {
+	ctx, span := tracing.Start(ctx, "repo.Searcher.Search")
+	defer span.End()
+	limit, offset = sanitize(limit, offset)
+	options := options(opts...)
+	queries := queries(s.queries, options.Options)
+	query := search.Query(q)
+	if query == "" {
+		return nil, nil
+	}
+	types := options.Types
+	if len(types) == 0 {
+		types = factcheck.TypesSearch()
+	}
+	rows, err := queries.ListSearchResults(ctx, postgres.ListSearchResultsParams{
+		Query:  query,
+		Types:  utils.MapNoError(types, utils.String[factcheck.TypeSearch, string]),
+		Limit:  int32(limit),  //nolint:gosec
+		Offset: int32(offset), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	return utils.Map(rows, postgres.ToSearchResult)
+}
+
+func (s *searcher) Retokenize(ctx context.Context, typ factcheck.TypeSearch, afterID string, limit int, opts ...Option) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.Searcher.Retokenize")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	queries := queries(s.queries, options(opts...))
+	after := pgtype.UUID{Valid: true} // Nil UUID sorts first
+	if afterID != "" {
+		var err error
+		after, err = postgres.UUID(afterID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var last pgtype.UUID
+	switch typ {
+	case factcheck.TypeSearchTopic:
+		rows, err := queries.ListTopicsAfterID(ctx, postgres.ListTopicsAfterIDParams{ID: after, Limit: int32(limit)}) //nolint:gosec
+		if err != nil {
+			return "", err
+		}
+		for _, row := range rows {
+			err = queries.UpdateTopicTokens(ctx, postgres.UpdateTopicTokensParams{
+				ID:                row.ID,
+				NameTokens:        search.Tokens(row.Name),
+				DescriptionTokens: search.Tokens(row.Description),
+			})
+			if err != nil {
+				return "", err
+			}
+			last = row.ID
+		}
+
+	case factcheck.TypeSearchGroup:
+		rows, err := queries.ListMessageGroupsAfterID(ctx, postgres.ListMessageGroupsAfterIDParams{ID: after, Limit: int32(limit)}) //nolint:gosec
+		if err != nil {
+			return "", err
+		}
+		for _, row := range rows {
+			group, err := postgres.ToMessageGroup(row)
+			if err != nil {
+				return "", err
+			}
+			err = queries.UpdateMessageGroupTokens(ctx, postgres.UpdateMessageGroupTokensParams{
+				ID:         row.ID,
+				TextTokens: postgres.MessageGroupTokens(group),
+			})
+			if err != nil {
+				return "", err
+			}
+			last = row.ID
+		}
+
+	case factcheck.TypeSearchAnswer:
+		rows, err := queries.ListAnswersAfterID(ctx, postgres.ListAnswersAfterIDParams{ID: after, Limit: int32(limit)}) //nolint:gosec
+		if err != nil {
+			return "", err
+		}
+		for _, row := range rows {
+			err = queries.UpdateAnswerTokens(ctx, postgres.UpdateAnswerTokensParams{
+				ID:         row.ID,
+				TextTokens: search.Tokens(row.Text),
+			})
+			if err != nil {
+				return "", err
+			}
+			last = row.ID
+		}
+
+	default:
+		return "", fmt.Errorf("unexpected search type '%s'", typ)
+	}
+	if !last.Valid {
+		return "", nil
+	}
+	return postgres.FromUUID(last)
+}
@@ -0,0 +1,16 @@
+package repo
+
+import "github.com/kaogeek/line-fact-check/factcheck"
+
+type OptionSearch func(*OptionsSearch)
+
+type OptionsSearch struct {
+	Options
+	Types []factcheck.TypeSearch
+}
+
+func SearchInTypes(types []factcheck.TypeSearch) OptionSearch {
+	return func(opts *OptionsSearch) {
+		opts.Types = types
+	}
+}
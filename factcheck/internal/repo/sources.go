@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+// Sources is repository for evidence cited by answers.
+// Sources are listed by their position in the answer.
+type Sources interface {
+	// Create creates source. If source.Position is 0, the source is appended after existing sources of its answer.
+	Create(ctx context.Context, source factcheck.Source, opts ...Option) (factcheck.Source, error)
+	GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Source, error)
+	ListByAnswerID(ctx context.Context, answerID string, opts ...Option) ([]factcheck.Source, error)
+	// Update updates every field of source except its answer. If source.Position is 0, the position is kept.
+	Update(ctx context.Context, source factcheck.Source, opts ...Option) (factcheck.Source, error)
+	Delete(ctx context.Context, id string, opts ...Option) error
+}
+
+func NewSources(queries *postgres.Queries) Sources {
+	return &sources{queries: queries}
+}
+
+type sources struct {
+	queries *postgres.Queries
+}
+
+func (s *sources) Create(ctx context.Context, source factcheck.Source, opts ...Option) (factcheck.Source, error) {
+	ctx, span := tracing.Start(ctx, "repo.Sources.Create")
+	defer span.End()
+	queries := queries(s.queries, options(opts...))
+	params, err := postgres.SourceCreator(source)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	created, err := queries.CreateAnswerSource(ctx, params)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	return postgres.ToSource(created)
+}
+
+func (s *sources) GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Source, error) {
+	ctx, span := tracing.Start(ctx, "repo.Sources.GetByID")
+	defer span.End()
+	queries := queries(s.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	result, err := queries.GetAnswerSource(ctx, uuid)
+	if err != nil {
+		return factcheck.Source{}, handleNotFound(err, map[string]string{"id": id})
+	}
+	return postgres.ToSource(result)
+}
+
+func (s *sources) ListByAnswerID(ctx context.Context, answerID string, opts ...Option) ([]factcheck.Source, error) {
+	ctx, span := tracing.Start(ctx, "repo.Sources.ListByAnswerID")
+	defer span.End()
+	queries := queries(s.queries, options(opts...))
+	answerUUID, err := postgres.UUID(answerID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := queries.ListAnswerSourcesByAnswerID(ctx, answerUUID)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.ToSources(result)
+}
+
+func (s *sources) Update(ctx context.Context, source factcheck.Source, opts ...Option) (factcheck.Source, error) {
+	ctx, span := tracing.Start(ctx, "repo.Sources.Update")
+	defer span.End()
+	queries := queries(s.queries, options(opts...))
+	params, err := postgres.SourceUpdater(source)
+	if err != nil {
+		return factcheck.Source{}, err
+	}
+	updated, err := queries.UpdateAnswerSource(ctx, params)
+	if err != nil {
+		return factcheck.Source{}, handleNotFound(err, map[string]string{"id": source.ID})
+	}
+	return postgres.ToSource(updated)
+}
+
+func (s *sources) Delete(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Sources.Delete")
+	defer span.End()
+	queries := queries(s.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return err
+	}
+	deleted, err := queries.DeleteAnswerSource(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrNotFound{Filter: map[string]string{"id": id}}
+	}
+	return nil
+}
@@ -4,29 +4,41 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/kaogeek/line-fact-check/factcheck"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/data/postgres"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/search"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
 	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
 )
 
 // Topics defines the interface for topic data operations
 type Topics interface {
 	Create(ctx context.Context, topic factcheck.Topic, opts ...Option) (factcheck.Topic, error)
-	Resolve(ctx context.Context, id string, answerText string, opts ...Option) (factcheck.Topic, error)
+	Resolve(ctx context.Context, id string, answer factcheck.Answer, opts ...Option) (factcheck.Topic, error)
+	Reopen(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error)
 	GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error)
+	GetByIDFollowRedirect(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error)
+	// ResolveID returns ID of the topic id was merged into, or id itself if it was not merged.
+	// It does not check that the topic exists.
+	ResolveID(ctx context.Context, id string, opts ...Option) (string, error)
 	GetStatus(ctx context.Context, id string, opts ...Option) (factcheck.StatusTopic, error)
 	Exists(ctx context.Context, id string, opts ...Option) (bool, error)
 	List(ctx context.Context, limit, offset int, opts ...Option) ([]factcheck.Topic, error)
 	ListDynamicV2(ctx context.Context, limit, offset int, opts ...OptionTopic) ([]factcheck.Topic, error)
+	ListDynamicV2Page(ctx context.Context, limit int, cursor Cursor, opts ...OptionTopic) (Page[factcheck.Topic], error)
 	ListInIDs(ctx context.Context, ids []string, opts ...Option) ([]factcheck.Topic, error)
 	ListByStatus(ctx context.Context, status factcheck.StatusTopic, limit, offset int, opts ...Option) ([]factcheck.Topic, error)
 	CountByStatus(ctx context.Context, opts ...Option) (map[factcheck.StatusTopic]int64, error)
 	CountByStatusDynamicV2(ctx context.Context, opts ...OptionTopic) (map[factcheck.StatusTopic]int64, error)
+	CountByVerdictDynamicV2(ctx context.Context, opts ...OptionTopic) (map[factcheck.Verdict]int64, error)
 	Delete(ctx context.Context, id string, opts ...Option) error
 	UpdateStatus(ctx context.Context, id string, status factcheck.StatusTopic, opts ...Option) (factcheck.Topic, error)
 	UpdateDescription(ctx context.Context, id string, description string, opts ...Option) (factcheck.Topic, error)
 	UpdateName(ctx context.Context, id string, name string, opts ...Option) (factcheck.Topic, error)
+	Merge(ctx context.Context, sourceID string, targetID string, opts ...Option) (factcheck.TopicMerge, error)
+	Split(ctx context.Context, sourceID string, topic factcheck.Topic, groupIDs []string, opts ...Option) (factcheck.TopicSplit, error)
 }
 
 type topics struct {
@@ -38,6 +50,8 @@ func NewTopics(queries *postgres.Queries) Topics {
 }
 
 func (t *topics) List(ctx context.Context, limit, offset int, opts ...Option) ([]factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.List")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	rows, err := queries.ListTopics(ctx, postgres.ListTopicsParams{
 		Column1: limit,
@@ -55,6 +69,8 @@ func (t *topics) ListAll(ctx context.Context) ([]factcheck.Topic, error) {
 }
 
 func (t *topics) Exists(ctx context.Context, id string, opts ...Option) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Exists")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -68,6 +84,8 @@ func (t *topics) Exists(ctx context.Context, id string, opts ...Option) (bool, e
 }
 
 func (t *topics) GetStatus(ctx context.Context, id string, opts ...Option) (factcheck.StatusTopic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.GetStatus")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -80,24 +98,33 @@ func (t *topics) GetStatus(ctx context.Context, id string, opts ...Option) (fact
 	return factcheck.StatusTopic(row), nil
 }
 
-func (t *topics) Resolve(ctx context.Context, id string, answerText string, opts ...Option) (factcheck.Topic, error) {
+// Resolve resolves topic with answer, which becomes the topic's published answer
+func (t *topics) Resolve(ctx context.Context, id string, answer factcheck.Answer, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Resolve")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
 		return factcheck.Topic{}, err
 	}
-	result, err := postgres.Text(answerText)
+	answerID, err := postgres.UUID(answer.ID)
 	if err != nil {
 		return factcheck.Topic{}, err
 	}
-	status, err := postgres.Text(factcheck.StatusTopicResolved)
+	result, err := postgres.Text(answer.Text)
+	if err != nil {
+		return factcheck.Topic{}, err
+	}
+	resultStatus, err := postgres.Text(answer.Verdict)
 	if err != nil {
 		return factcheck.Topic{}, err
 	}
 	resolved, err := queries.ResolveTopic(ctx, postgres.ResolveTopicParams{
 		ID:           uuid,
 		Result:       result,
-		ResultStatus: status,
+		Status:       string(factcheck.StatusTopicResolved),
+		ResultStatus: resultStatus,
+		AnswerID:     answerID,
 	})
 	if err != nil {
 		return factcheck.Topic{}, handleNotFound(err, filter{"id": id})
@@ -105,16 +132,62 @@ func (t *topics) Resolve(ctx context.Context, id string, answerText string, opts
 	return postgres.ToTopic(resolved), nil
 }
 
+// Reopen moves resolved topic back to pending. Its published answer is kept until corrected.
+// Topics that are not resolved are not found.
+func (t *topics) Reopen(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Reopen")
+	defer span.End()
+	queries := queries(t.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.Topic{}, err
+	}
+	reopened, err := queries.ReopenTopic(ctx, uuid)
+	if err != nil {
+		return factcheck.Topic{}, handleNotFound(err, filter{"id": id, "status": string(factcheck.StatusTopicResolved)})
+	}
+	return postgres.ToTopic(reopened), nil
+}
+
 func (t *topics) ListDynamicV2(ctx context.Context, limit, offset int, opts ...OptionTopic) ([]factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ListDynamicV2")
+	defer span.End()
 	limit, offset = sanitize(limit, offset)
+	return t.listDynamicV2(ctx, limit, offset, Cursor{}, opts...)
+}
+
+// ListDynamicV2Page lists a page of topics from cursor, latest first
+func (t *topics) ListDynamicV2Page(ctx context.Context, limit int, cursor Cursor, opts ...OptionTopic) (Page[factcheck.Topic], error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ListDynamicV2Page")
+	defer span.End()
+	limit, _ = sanitize(limit, 0)
+	list, err := t.listDynamicV2(ctx, limit+1, 0, cursor, opts...)
+	if err != nil {
+		return Page[factcheck.Topic]{}, err
+	}
+	return paginate(list, limit, cursor, func(topic factcheck.Topic) (time.Time, string) {
+		return topic.CreatedAt, topic.ID
+	}), nil
+}
+
+func (t *topics) listDynamicV2(ctx context.Context, limit, offset int, cursor Cursor, opts ...OptionTopic) ([]factcheck.Topic, error) {
 	options := options(opts...)
 	queries := queries(t.queries, options.Options)
+	cursorCreatedAt, cursorID, err := cursor.params()
+	if err != nil {
+		return nil, err
+	}
 	rows, err := queries.ListTopicsDynamicV2(ctx, postgres.ListTopicsDynamicV2Params{
-		Column1: options.LikeID,
-		Column2: utils.MapNoError(options.Statuses, utils.String[factcheck.StatusTopic, string]),
-		Column3: options.LikeMessageText,
-		Column4: int32(limit),  //nolint:gosec
-		Column5: int32(offset), //nolint:gosec
+		Column1:  options.LikeID,
+		Column2:  utils.MapNoError(options.Statuses, utils.String[factcheck.StatusTopic, string]),
+		Column3:  options.LikeMessageText,
+		Column4:  int32(limit),  //nolint:gosec
+		Column5:  int32(offset), //nolint:gosec
+		Column6:  utils.MapNoError(options.Verdicts, utils.String[factcheck.Verdict, string]),
+		Column7:  utils.MapNoError(options.Languages, utils.String[factcheck.Language, string]),
+		Column8:  cursorCreatedAt,
+		Column9:  cursorID,
+		Column10: cursor.Prev,
 	})
 	if err != nil {
 		return nil, err
@@ -123,6 +196,8 @@ func (t *topics) ListDynamicV2(ctx context.Context, limit, offset int, opts ...O
 }
 
 func (t *topics) CountByStatusDynamicV2(ctx context.Context, opts ...OptionTopic) (map[factcheck.StatusTopic]int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.CountByStatusDynamicV2")
+	defer span.End()
 	options := options(opts...)
 	queries := queries(t.queries, options.Options)
 	if len(options.Statuses) != 0 {
@@ -131,6 +206,7 @@ func (t *topics) CountByStatusDynamicV2(ctx context.Context, opts ...OptionTopic
 	rows, err := queries.CountTopicsGroupByStatusDynamicV2(ctx, postgres.CountTopicsGroupByStatusDynamicV2Params{
 		Column1: options.LikeID,
 		Column2: options.LikeMessageText,
+		Column3: utils.MapNoError(options.Languages, utils.String[factcheck.Language, string]),
 	})
 	if err != nil {
 		return nil, err
@@ -147,7 +223,37 @@ func (t *topics) CountByStatusDynamicV2(ctx context.Context, opts ...OptionTopic
 	return result, nil
 }
 
+func (t *topics) CountByVerdictDynamicV2(ctx context.Context, opts ...OptionTopic) (map[factcheck.Verdict]int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.CountByVerdictDynamicV2")
+	defer span.End()
+	options := options(opts...)
+	queries := queries(t.queries, options.Options)
+	if len(options.Verdicts) != 0 {
+		slog.WarnContext(ctx, "Verdicts is not supported in CountByVerdictDynamic", "verdicts", options.Verdicts)
+	}
+	rows, err := queries.CountTopicsGroupByVerdictDynamicV2(ctx, postgres.CountTopicsGroupByVerdictDynamicV2Params{
+		Column1: options.LikeID,
+		Column2: options.LikeMessageText,
+		Column3: utils.MapNoError(options.Languages, utils.String[factcheck.Language, string]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[factcheck.Verdict]int64)
+	for i := range rows {
+		r := &rows[i]
+		v := factcheck.Verdict(r.ResultStatus.String)
+		if !v.IsValid() {
+			return nil, fmt.Errorf("unexpected invalid verdict '%s' with %d count", v, r.Count)
+		}
+		result[v] = r.Count
+	}
+	return result, nil
+}
+
 func (t *topics) ListByStatus(ctx context.Context, status factcheck.StatusTopic, limit, offset int, opts ...Option) ([]factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ListByStatus")
+	defer span.End()
 	limit, offset = sanitize(limit, offset)
 	queries := queries(t.queries, options(opts...))
 	rows, err := queries.ListTopicsByStatus(ctx, postgres.ListTopicsByStatusParams{
@@ -162,6 +268,8 @@ func (t *topics) ListByStatus(ctx context.Context, status factcheck.StatusTopic,
 }
 
 func (t *topics) ListLikeID(ctx context.Context, idPattern string, limit, offset int, opts ...Option) ([]factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ListLikeID")
+	defer span.End()
 	limit, offset = sanitize(limit, offset)
 	queries := queries(t.queries, options(opts...))
 	rows, err := queries.ListTopicsLikeID(ctx, postgres.ListTopicsLikeIDParams{
@@ -176,6 +284,8 @@ func (t *topics) ListLikeID(ctx context.Context, idPattern string, limit, offset
 }
 
 func (t *topics) Create(ctx context.Context, top factcheck.Topic, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Create")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	params, err := postgres.TopicCreator(top)
 	if err != nil {
@@ -189,6 +299,8 @@ func (t *topics) Create(ctx context.Context, top factcheck.Topic, opts ...Option
 }
 
 func (t *topics) GetByID(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.GetByID")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -201,8 +313,161 @@ func (t *topics) GetByID(ctx context.Context, id string, opts ...Option) (factch
 	return postgres.ToTopic(result), nil
 }
 
+// GetByIDFollowRedirect gets topic by ID, or the topic it was merged into
+func (t *topics) GetByIDFollowRedirect(ctx context.Context, id string, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.GetByIDFollowRedirect")
+	defer span.End()
+	queries := queries(t.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return factcheck.Topic{}, err
+	}
+	result, err := queries.GetTopicFollowRedirect(ctx, uuid)
+	if err != nil {
+		return factcheck.Topic{}, handleNotFound(err, map[string]string{"id": id})
+	}
+	return postgres.ToTopic(result), nil
+}
+
+func (t *topics) ResolveID(ctx context.Context, id string, opts ...Option) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ResolveID")
+	defer span.End()
+	queries := queries(t.queries, options(opts...))
+	uuid, err := postgres.UUID(id)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := queries.ResolveTopicID(ctx, uuid)
+	if err != nil {
+		return "", err
+	}
+	return postgres.FromUUID(resolved)
+}
+
+// Merge moves message groups, messages, answers and deliveries of topic sourceID into targetID,
+// then deletes the source topic and leaves a redirect to the target.
+// Groups with identical text in both topics are merged into the target's group.
+//
+// Merge runs many statements, so callers should pass a transaction as opts.
+func (t *topics) Merge(ctx context.Context, sourceID string, targetID string, opts ...Option) (factcheck.TopicMerge, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Merge")
+	defer span.End()
+	queries := queries(t.queries, options(opts...))
+	from, err := postgres.UUID(sourceID)
+	if err != nil {
+		return factcheck.TopicMerge{}, err
+	}
+	to, err := postgres.UUID(targetID)
+	if err != nil {
+		return factcheck.TopicMerge{}, err
+	}
+	createdAt, err := postgres.Timestamptz(utils.TimeNow())
+	if err != nil {
+		return factcheck.TopicMerge{}, err
+	}
+	merge := factcheck.TopicMerge{SourceID: sourceID}
+	_, err = queries.MergeMessagesV2IntoDuplicateGroups(ctx, postgres.MergeMessagesV2IntoDuplicateGroupsParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error merging messages into duplicate groups: %w", err)
+	}
+	merge.GroupsMerged, err = queries.DeleteDuplicateMessageGroups(ctx, postgres.DeleteDuplicateMessageGroupsParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error deleting duplicate groups: %w", err)
+	}
+	merge.Groups, err = queries.MoveMessageGroupsTopic(ctx, postgres.MoveMessageGroupsTopicParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error moving groups: %w", err)
+	}
+	merge.Messages, err = queries.MoveMessagesV2Topic(ctx, postgres.MoveMessagesV2TopicParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error moving messages: %w", err)
+	}
+	merge.Answers, err = queries.MoveAnswersTopic(ctx, postgres.MoveAnswersTopicParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error moving answers: %w", err)
+	}
+	_, err = queries.MoveDeliveriesTopic(ctx, postgres.MoveDeliveriesTopicParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error moving deliveries: %w", err)
+	}
+	_, err = queries.UpdateTopicRedirectsTo(ctx, postgres.UpdateTopicRedirectsToParams{FromID: from, ToID: to})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error updating redirects: %w", err)
+	}
+	err = queries.DeleteTopic(ctx, from)
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error deleting merged topic: %w", err)
+	}
+	_, err = queries.CreateTopicRedirect(ctx, postgres.CreateTopicRedirectParams{FromID: from, ToID: to, CreatedAt: createdAt})
+	if err != nil {
+		return factcheck.TopicMerge{}, fmt.Errorf("error creating redirect: %w", err)
+	}
+	target, err := queries.GetTopic(ctx, to)
+	if err != nil {
+		return factcheck.TopicMerge{}, handleNotFound(err, map[string]string{"id": targetID})
+	}
+	merge.Target = postgres.ToTopic(target)
+	return merge, nil
+}
+
+// Split creates topic, and moves message groups groupIDs of topic sourceID and their messages into it.
+// Groups not in the source topic are not moved.
+//
+// Split runs many statements, so callers should pass a transaction as opts.
+func (t *topics) Split(ctx context.Context, sourceID string, topic factcheck.Topic, groupIDs []string, opts ...Option) (factcheck.TopicSplit, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Split")
+	defer span.End()
+	queries := queries(t.queries, options(opts...))
+	from, err := postgres.UUID(sourceID)
+	if err != nil {
+		return factcheck.TopicSplit{}, err
+	}
+	ids, err := postgres.UUIDs(groupIDs)
+	if err != nil {
+		return factcheck.TopicSplit{}, err
+	}
+	created, err := t.Create(ctx, topic, opts...)
+	if err != nil {
+		return factcheck.TopicSplit{}, fmt.Errorf("error creating split topic: %w", err)
+	}
+	to, err := postgres.UUID(created.ID)
+	if err != nil {
+		return factcheck.TopicSplit{}, err
+	}
+	_, err = queries.MoveMessageGroupsTopicInIDs(ctx, postgres.MoveMessageGroupsTopicInIDsParams{FromID: from, ToID: to, Ids: ids})
+	if err != nil {
+		return factcheck.TopicSplit{}, fmt.Errorf("error moving groups: %w", err)
+	}
+	_, err = queries.MoveMessagesV2TopicInGroupIDs(ctx, postgres.MoveMessagesV2TopicInGroupIDsParams{ToID: to, GroupIds: ids})
+	if err != nil {
+		return factcheck.TopicSplit{}, fmt.Errorf("error moving messages: %w", err)
+	}
+	source, err := queries.GetTopic(ctx, from)
+	if err != nil {
+		return factcheck.TopicSplit{}, handleNotFound(err, map[string]string{"id": sourceID})
+	}
+	split := factcheck.TopicSplit{
+		Source: factcheck.TopicCounts{Topic: postgres.ToTopic(source)},
+		Split:  factcheck.TopicCounts{Topic: created},
+	}
+	for _, counts := range []*factcheck.TopicCounts{&split.Source, &split.Split} {
+		id, err := postgres.UUID(counts.Topic.ID)
+		if err != nil {
+			return factcheck.TopicSplit{}, err
+		}
+		row, err := queries.CountTopicGroupsAndMessages(ctx, id)
+		if err != nil {
+			return factcheck.TopicSplit{}, fmt.Errorf("error counting groups and messages of topic '%s': %w", counts.Topic.ID, err)
+		}
+		counts.Groups, counts.Messages = row.Groups, row.Messages
+	}
+	return split, nil
+}
+
 // ListInIDs retrieves topics by IDs using the topicDomain adapter
 func (t *topics) ListInIDs(ctx context.Context, ids []string, opts ...Option) ([]factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.ListInIDs")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	if len(ids) == 0 {
 		return nil, nil
@@ -219,6 +484,8 @@ func (t *topics) ListInIDs(ctx context.Context, ids []string, opts ...Option) ([
 }
 
 func (t *topics) CountByStatus(ctx context.Context, opts ...Option) (map[factcheck.StatusTopic]int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.CountByStatus")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	rows, err := queries.CountTopicsGroupedByStatus(ctx)
 	if err != nil {
@@ -237,6 +504,8 @@ func (t *topics) CountByStatus(ctx context.Context, opts ...Option) (map[factche
 }
 
 func (t *topics) Delete(ctx context.Context, id string, opts ...Option) error {
+	ctx, span := tracing.Start(ctx, "repo.Topics.Delete")
+	defer span.End()
 	options := options(opts...)
 	queries := queries(t.queries, options)
 	uuid, err := postgres.UUID(id)
@@ -251,6 +520,8 @@ func (t *topics) Delete(ctx context.Context, id string, opts ...Option) error {
 }
 
 func (t *topics) UpdateStatus(ctx context.Context, id string, status factcheck.StatusTopic, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.UpdateStatus")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
@@ -267,14 +538,17 @@ func (t *topics) UpdateStatus(ctx context.Context, id string, status factcheck.S
 }
 
 func (t *topics) UpdateDescription(ctx context.Context, id string, description string, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.UpdateDescription")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
 		return factcheck.Topic{}, err
 	}
 	updated, err := queries.UpdateTopicDescription(ctx, postgres.UpdateTopicDescriptionParams{
-		ID:          uuid,
-		Description: description,
+		ID:                uuid,
+		Description:       description,
+		DescriptionTokens: search.Tokens(description),
 	})
 	if err != nil {
 		return factcheck.Topic{}, handleNotFound(err, map[string]string{"id": id})
@@ -283,14 +557,17 @@ func (t *topics) UpdateDescription(ctx context.Context, id string, description s
 }
 
 func (t *topics) UpdateName(ctx context.Context, id string, name string, opts ...Option) (factcheck.Topic, error) {
+	ctx, span := tracing.Start(ctx, "repo.Topics.UpdateName")
+	defer span.End()
 	queries := queries(t.queries, options(opts...))
 	uuid, err := postgres.UUID(id)
 	if err != nil {
 		return factcheck.Topic{}, err
 	}
 	updated, err := queries.UpdateTopicName(ctx, postgres.UpdateTopicNameParams{
-		ID:   uuid,
-		Name: name,
+		ID:         uuid,
+		Name:       name,
+		NameTokens: search.Tokens(name),
 	})
 	if err != nil {
 		return factcheck.Topic{}, handleNotFound(err, map[string]string{"id": id})
@@ -752,3 +752,104 @@ func TestRepository_TopicFiltering(t *testing.T) {
 		}
 	})
 }
+
+func TestRepository_Verdicts(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	now := utils.TimeNow().Round(0)
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	verdicts := []factcheck.Verdict{
+		factcheck.VerdictFalse,
+		factcheck.VerdictFalse,
+		factcheck.VerdictMisleading,
+		"", // Not yet resolved
+	}
+	ids := make([]string, len(verdicts))
+	for i := range verdicts {
+		created, err := app.Repository.Topics.Create(ctx, factcheck.Topic{
+			ID:        utils.NewID().String(),
+			Name:      "topic",
+			Status:    factcheck.StatusTopicPending,
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create topic: %v", err)
+		}
+		ids[i] = created.ID
+		if verdicts[i] == "" {
+			continue
+		}
+		answer, err := app.Repository.Answers.Create(ctx, factcheck.Answer{
+			ID:        utils.NewID().String(),
+			TopicID:   created.ID,
+			Status:    factcheck.StatusAnswerPublished,
+			Text:      "answer",
+			Verdict:   verdicts[i],
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create answer: %v", err)
+		}
+		resolved, err := app.Repository.Topics.Resolve(ctx, created.ID, answer)
+		if err != nil {
+			t.Fatalf("Failed to resolve topic: %v", err)
+		}
+		if resolved.Status != factcheck.StatusTopicResolved {
+			t.Fatalf("unexpected status: %s", resolved.Status)
+		}
+		if resolved.Verdict != verdicts[i] {
+			t.Fatalf("unexpected verdict: %s", resolved.Verdict)
+		}
+	}
+
+	t.Run("ListDynamicV2 - verdict filter", func(t *testing.T) {
+		topics, err := app.Repository.Topics.ListDynamicV2(t.Context(), 0, 0, repo.TopicInVerdicts([]factcheck.Verdict{factcheck.VerdictFalse}))
+		if err != nil {
+			t.Fatalf("ListDynamicV2 failed: %v", err)
+		}
+		if len(topics) != 2 {
+			t.Fatalf("Expected 2 topics, got %d", len(topics))
+		}
+		for i := range topics {
+			if topics[i].Verdict != factcheck.VerdictFalse {
+				t.Fatalf("unexpected verdict: %s", topics[i].Verdict)
+			}
+		}
+	})
+
+	t.Run("CountByVerdictDynamicV2", func(t *testing.T) {
+		counts, err := app.Repository.Topics.CountByVerdictDynamicV2(t.Context())
+		if err != nil {
+			t.Fatalf("CountByVerdictDynamicV2 failed: %v", err)
+		}
+		expected := map[factcheck.Verdict]int64{
+			factcheck.VerdictFalse:      2,
+			factcheck.VerdictMisleading: 1,
+		}
+		if len(counts) != len(expected) {
+			t.Fatalf("unexpected counts: %+v", counts)
+		}
+		for k, v := range expected {
+			if counts[k] != v {
+				t.Fatalf("unexpected count for %s: expected %d, got %d", k, v, counts[k])
+			}
+		}
+	})
+
+	t.Run("CountByVerdictDynamicV2 - ID filter", func(t *testing.T) {
+		counts, err := app.Repository.Topics.CountByVerdictDynamicV2(t.Context(), repo.TopicLikeID(ids[2]))
+		if err != nil {
+			t.Fatalf("CountByVerdictDynamicV2 failed: %v", err)
+		}
+		if len(counts) != 1 || counts[factcheck.VerdictMisleading] != 1 {
+			t.Fatalf("unexpected counts: %+v", counts)
+		}
+	})
+}
@@ -12,6 +12,8 @@ type OptionsTopic struct {
 	LikeID          string
 	LikeMessageText string
 	Statuses        []factcheck.StatusTopic
+	Verdicts        []factcheck.Verdict
+	Languages       []factcheck.Language // Topics with any message group in these languages
 }
 
 func TopicLikeID(id string) OptionTopic {
@@ -31,3 +33,15 @@ func TopicInStatuses(statuses []factcheck.StatusTopic) OptionTopic {
 		opts.Statuses = statuses
 	}
 }
+
+func TopicInVerdicts(verdicts []factcheck.Verdict) OptionTopic {
+	return func(opts *OptionsTopic) {
+		opts.Verdicts = verdicts
+	}
+}
+
+func TopicInLanguages(languages []factcheck.Language) OptionTopic {
+	return func(opts *OptionsTopic) {
+		opts.Languages = languages
+	}
+}
@@ -0,0 +1,215 @@
+// Package search segments texts into words for full-text search.
+//
+// Thai is written without spaces between words, so Postgres text search parsers
+// cannot split it into words. Texts are instead segmented in Go by Tokenizer,
+// and stored as space-separated tokens, which Postgres indexes as-is
+// with array_to_tsvector. Queries are segmented the same way with Query.
+package search
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/dedup"
+)
+
+//go:embed words_th.txt
+var wordsThai string
+
+// tokenizer is the default tokenizer, with the embedded Thai dictionary
+var tokenizer = NewTokenizer(dictionary(wordsThai))
+
+// Tokenizer splits text into words. Thai runs are segmented by maximal matching
+// against a dictionary, while other scripts are split on non-letters.
+type Tokenizer struct {
+	root *node
+}
+
+type node struct {
+	children map[rune]*node
+	word     bool
+}
+
+// NewTokenizer returns Tokenizer with words as its Thai dictionary
+func NewTokenizer(words []string) *Tokenizer {
+	t := &Tokenizer{root: &node{}}
+	for _, word := range words {
+		t.add(dedup.Normalize(word))
+	}
+	return t
+}
+
+// Tokenize tokenizes text with the default tokenizer
+func Tokenize(text string) []string {
+	return tokenizer.Tokenize(text)
+}
+
+// Tokens tokenizes texts with the default tokenizer, and joins all tokens with spaces.
+// Its output is what we store in the search token columns.
+func Tokens(texts ...string) string {
+	var tokens []string
+	for _, text := range texts {
+		tokens = append(tokens, tokenizer.Tokenize(text)...)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Query returns Postgres tsquery matching texts with all tokens of q,
+// with the last token matching as a prefix, so that partially typed words still match.
+// It returns empty string if q has no tokens.
+func Query(q string) string {
+	tokens := tokenizer.Tokenize(q)
+	if len(tokens) == 0 {
+		return ""
+	}
+	lexemes := make([]string, len(tokens))
+	for i, token := range tokens {
+		// Tokens only contain letters, marks and numbers, so quoting never needs escaping
+		lexemes[i] = "'" + token + "'"
+	}
+	lexemes[len(lexemes)-1] += ":*"
+	return strings.Join(lexemes, " & ")
+}
+
+// Tokenize normalizes text with dedup.Normalize and splits it into words
+func (t *Tokenizer) Tokenize(text string) []string {
+	runes := []rune(dedup.Normalize(text))
+	var tokens []string
+	for i := 0; i < len(runes); {
+		switch {
+		case isThai(runes[i]):
+			j := i
+			for j < len(runes) && isThai(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, t.segment(runes[i:j])...)
+			i = j
+
+		case isWord(runes[i]):
+			j := i
+			for j < len(runes) && isWord(runes[j]) && !isThai(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func (t *Tokenizer) add(word string) {
+	if word == "" {
+		return
+	}
+	n := t.root
+	for _, r := range word {
+		if n.children == nil {
+			n.children = make(map[rune]*node)
+		}
+		child, ok := n.children[r]
+		if !ok {
+			child = &node{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	n.word = true
+}
+
+// segment splits a run of Thai text into words by maximal matching:
+// it prefers segmentations with the fewest characters outside dictionary words,
+// and then the fewest words. Consecutive unknown characters are kept as one token.
+func (t *Tokenizer) segment(runes []rune) []string {
+	type state struct {
+		reached bool
+		unknown int
+		words   int
+		prev    int
+		known   bool // Whether runes[prev:i] is a dictionary word
+	}
+	states := make([]state, len(runes)+1)
+	states[0].reached = true
+	better := func(i int, candidate state) {
+		s := states[i]
+		if !s.reached ||
+			candidate.unknown < s.unknown ||
+			candidate.unknown == s.unknown && candidate.words < s.words {
+			states[i] = candidate
+		}
+	}
+	for i := range runes {
+		if !states[i].reached {
+			continue
+		}
+		s := states[i]
+		better(i+1, state{reached: true, unknown: s.unknown + 1, words: s.words + 1, prev: i})
+		n := t.root
+		for j := i; j < len(runes); j++ {
+			n = n.children[runes[j]]
+			if n == nil {
+				break
+			}
+			if n.word {
+				better(j+1, state{reached: true, unknown: s.unknown, words: s.words + 1, prev: i, known: true})
+			}
+		}
+	}
+
+	var tokens []string
+	end, unknownEnd := len(runes), -1
+	for end > 0 {
+		s := states[end]
+		if s.known {
+			if unknownEnd != -1 {
+				tokens = append(tokens, string(runes[end:unknownEnd]))
+				unknownEnd = -1
+			}
+			tokens = append(tokens, string(runes[s.prev:end]))
+		} else if unknownEnd == -1 {
+			unknownEnd = end
+		}
+		end = s.prev
+	}
+	if unknownEnd != -1 {
+		tokens = append(tokens, string(runes[:unknownEnd]))
+	}
+	for i, j := 0, len(tokens)-1; i < j; i, j = i+1, j-1 {
+		tokens[i], tokens[j] = tokens[j], tokens[i]
+	}
+	return tokens
+}
+
+func dictionary(text string) []string {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words
+}
+
+// isThai returns whether r is a Thai letter, vowel or mark
+func isThai(r rune) bool {
+	return r >= '\u0e01' && r <= '\u0e4e' && !isThaiSign(r)
+}
+
+// isThaiSign returns whether r is a Thai abbreviation, currency or repetition sign,
+// which are not part of words
+func isThaiSign(r rune) bool {
+	switch r {
+	case '\u0e2f', '\u0e3f', '\u0e46': // Paiyannoi, baht sign and mai yamok
+		return true
+	}
+	return false
+}
+
+func isWord(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)) && !isThaiSign(r)
+}
@@ -0,0 +1,54 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/search"
+)
+
+func TestTokenize(t *testing.T) {
+	type testCase struct {
+		text     string
+		expected []string
+	}
+	tests := []testCase{
+		{"ข่าวปลอม", []string{"ข่าว", "ปลอม"}},
+		{"ดื่มน้ำมะนาวรักษามะเร็ง", []string{"ดื่ม", "น้ำ", "มะนาว", "รักษา", "มะเร็ง"}},
+		{"วัคซีนโควิดทำให้ตาย", []string{"วัคซีน", "โควิด", "ทำ", "ให้", "ตาย"}},
+		{"Lemon soda cures CANCER!", []string{"lemon", "soda", "cures", "cancer"}},
+		{"ฉีดวัคซีน Pfizer 2 เข็ม", []string{"ฉีด", "วัคซีน", "pfizer", "2", "เข็ม"}},
+		{"กินกะทิกรุงเทพ", []string{"กิน", "กะทิ", "กรุงเทพ"}}, // Unknown word is kept as one token
+		{"น้ํามะนาว", []string{"น้ำ", "มะนาว"}},                // Normalized before segmenting
+		{"ดีๆ", []string{"ดี"}},
+		{"", nil},
+	}
+	for _, tc := range tests {
+		actual := search.Tokenize(tc.text)
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("unexpected tokens for '%s': expected %q, got %q", tc.text, tc.expected, actual)
+		}
+	}
+}
+
+func TestTokenizer(t *testing.T) {
+	tokenizer := search.NewTokenizer([]string{"ไป", "ไปมา", "มาก"})
+	actual := tokenizer.Tokenize("ไปมาก")
+	expected := []string{"ไป", "มาก"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected tokens: expected %q, got %q", expected, actual)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	if q := search.Query("  !!  "); q != "" {
+		t.Fatalf("unexpected query for empty text: '%s'", q)
+	}
+	expected := "'ข่าว' & 'ปลอม' & 'วัคซีน':*"
+	if q := search.Query("ข่าวปลอมวัคซีน"); q != expected {
+		t.Fatalf("unexpected query: expected '%s', got '%s'", expected, q)
+	}
+	if tokens := search.Tokens("ข่าวปลอม", "Vaccine"); tokens != "ข่าว ปลอม vaccine" {
+		t.Fatalf("unexpected tokens: '%s'", tokens)
+	}
+}
@@ -0,0 +1,335 @@
+// Package stream streams domain events from outbox to live subscribers, like backoffice webapps.
+//
+// Every insert into outbox is notified on Postgres channel [Channel] when its transaction commits,
+// so subscribers on every API instance wake up for events written by any instance.
+// Subscribers then read events from outbox after their cursor, which is event Seq,
+// so that they can resume from any event they last saw.
+//
+// Seqs are assigned on insert but become visible on commit, so events can become visible out of Seq order.
+// Subscribers read events after seq gaps right away, and read missing seqs again until gapHorizon,
+// so that events of transactions committing late are still delivered, after the later events.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// Channel is Postgres channel notified by trigger on outbox inserts
+const Channel = "outbox_events"
+
+const (
+	// batch is how many events subscriptions read from outbox at once
+	batch = 100
+	// gapPoll is how often subscriptions with missing seqs read them again.
+	// A missing seq is either an event of a transaction yet to commit, or of a transaction that rolled back.
+	gapPoll = time.Second
+	// gapHorizon is how long subscriptions read missing seqs again before giving them up as rolled back.
+	// It outlasts transactions writing events, which are bounded by statement and request timeouts.
+	gapHorizon = 10 * time.Minute
+	// reconnectWait is how long Listen waits before listening again after errors
+	reconnectWait = time.Second
+)
+
+// ErrClosed is returned by subscriptions of closed brokers
+var ErrClosed = errors.New("stream closed")
+
+// Filter selects events by their types and topics. Empty fields select all.
+type Filter struct {
+	TopicIDs []string
+	Types    []factcheck.TypeEvent
+}
+
+// Match returns if event is selected by f
+func (f Filter) Match(event factcheck.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if len(f.TopicIDs) == 0 {
+		return true
+	}
+	topicIDs, err := TopicIDs(event)
+	if err != nil {
+		slog.Warn("stream cannot get topics of event", "event_id", event.ID, "type", event.Type, "err", err)
+		return false
+	}
+	for _, id := range topicIDs {
+		if slices.Contains(f.TopicIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// TopicIDs returns IDs of topics event is about, from its payload.
+// Messages and groups not yet assigned to topics have no topic IDs.
+func TopicIDs(event factcheck.Event) ([]string, error) {
+	var ids []string
+	var err error
+	switch event.Type {
+	case factcheck.TypeEventMessageSubmitted, factcheck.TypeEventMessageAssigned:
+		var m factcheck.MessageV2
+		err = json.Unmarshal(event.Payload, &m)
+		ids = []string{m.TopicID}
+	case factcheck.TypeEventGroupCreated, factcheck.TypeEventGroupAssigned, factcheck.TypeEventGroupApproved, factcheck.TypeEventGroupRejected:
+		var g factcheck.MessageGroup
+		err = json.Unmarshal(event.Payload, &g)
+		ids = []string{g.TopicID}
+	case factcheck.TypeEventTopicResolved:
+		var t factcheck.Topic
+		err = json.Unmarshal(event.Payload, &t)
+		ids = []string{t.ID}
+	case factcheck.TypeEventTopicMerged:
+		var m factcheck.TopicMerge
+		err = json.Unmarshal(event.Payload, &m)
+		ids = []string{m.SourceID, m.Target.ID}
+	case factcheck.TypeEventTopicSplit:
+		var s factcheck.TopicSplit
+		err = json.Unmarshal(event.Payload, &s)
+		ids = []string{s.Source.Topic.ID, s.Split.Topic.ID}
+	case factcheck.TypeEventAnswerCreated, factcheck.TypeEventAnswerPublished:
+		var a factcheck.Answer
+		err = json.Unmarshal(event.Payload, &a)
+		ids = []string{a.TopicID}
+	default:
+		return nil, fmt.Errorf("unexpected event type '%s'", event.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad payload of event %s: %w", event.Type, err)
+	}
+	return slices.DeleteFunc(ids, func(id string) bool { return id == "" }), nil
+}
+
+// Broker wakes its subscriptions up when they could have new events
+type Broker struct {
+	repo   repo.Repository
+	mu     sync.Mutex
+	subs   map[chan struct{}]struct{}
+	done   chan struct{}
+	closed sync.Once
+}
+
+func NewBroker(repository repo.Repository) *Broker {
+	return &Broker{
+		repo: repository,
+		subs: make(map[chan struct{}]struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Close ends all subscriptions with ErrClosed, e.g. so that servers shutting down
+// do not wait for streams that never end
+func (b *Broker) Close() {
+	b.closed.Do(func() {
+		close(b.done)
+	})
+}
+
+// New returns broker listening for notifications of pool in background,
+// and function to stop listening
+func New(repository repo.Repository, pool *pgxpool.Pool) (*Broker, func()) {
+	b := NewBroker(repository)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Listen(ctx, pool)
+	}()
+	return b, func() {
+		b.Close()
+		cancel()
+		<-done
+	}
+}
+
+// Listen listens on Channel with a connection taken from pool, and wakes subscriptions on notifications,
+// until ctx is done. Connections lost are replaced after reconnectWait.
+func (b *Broker) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	for {
+		err := b.listen(ctx, pool)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(ctx, "stream listener error, listening again", "err", err, "wait", reconnectWait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectWait):
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context, pool *pgxpool.Pool) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Listening connections are never returned to pool, where they would keep buffering notifications
+	pgConn := conn.Hijack()
+	defer func() {
+		_ = pgConn.Close(context.WithoutCancel(ctx))
+	}()
+	_, err = pgConn.Exec(ctx, "LISTEN "+Channel)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "stream listening", "channel", Channel)
+	// Events could have been written while not listening
+	b.Notify()
+	for {
+		_, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		b.Notify()
+	}
+}
+
+// Notify wakes all subscriptions up to read new events.
+// Subscriptions woken up but still busy are woken up only once.
+func (b *Broker) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for wake := range b.subs {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribe returns subscription to events after seq matching f.
+// Negative seq subscribes to only events after the latest event.
+func (b *Broker) Subscribe(ctx context.Context, seq int64, f Filter) (*Subscription, error) {
+	select {
+	case <-b.done:
+		return nil, ErrClosed
+	default:
+	}
+	if seq < 0 {
+		last, err := b.repo.Outbox.LastSeq(ctx)
+		if err != nil {
+			return nil, err
+		}
+		seq = last
+	}
+	wake := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.subs[wake] = struct{}{}
+	b.mu.Unlock()
+	return &Subscription{broker: b, wake: wake, filter: f, cursor: seq, last: seq, missing: make(map[int64]time.Time)}, nil
+}
+
+// Subscription reads events of its filter in order of Seq,
+// except events of missing seqs, which are read when they become visible
+type Subscription struct {
+	broker  *Broker
+	wake    chan struct{}
+	filter  Filter
+	cursor  int64               // Seq up to which all events are read or given up
+	last    int64               // Seq of the last event read
+	missing map[int64]time.Time // Seqs after cursor yet to be read, and when they were first missed
+}
+
+// Close stops waking s
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	delete(s.broker.subs, s.wake)
+}
+
+// Cursor returns Seq up to which s has read all events, matched or not, or given them up.
+// Subscriptions resumed after Cursor miss no events, but may repeat events read after it.
+func (s *Subscription) Cursor() int64 {
+	return s.cursor
+}
+
+// Next waits for new events up to timeout, and returns them.
+// It returns no events and no error on timeout, so that callers can send keep-alives.
+func (s *Subscription) Next(ctx context.Context, timeout time.Duration) ([]factcheck.Event, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		events, waiting, err := s.read(ctx)
+		if err != nil || len(events) > 0 {
+			return events, err
+		}
+		// Polls again for events of transactions committing out of order
+		var retry <-chan time.Time
+		if waiting {
+			retry = time.After(gapPoll)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.broker.done:
+			return nil, ErrClosed
+		case <-deadline.C:
+			return nil, nil
+		case <-s.wake:
+		case <-retry:
+		}
+	}
+}
+
+// read reads events of missing seqs and events after the last event, and returns those matching filter.
+// It returns waiting if some seqs are still missing.
+func (s *Subscription) read(ctx context.Context) ([]factcheck.Event, bool, error) {
+	var matched []factcheck.Event
+	if len(s.missing) > 0 {
+		events, err := s.broker.repo.Outbox.ListBySeqs(ctx, slices.Sorted(maps.Keys(s.missing)))
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range events {
+			delete(s.missing, events[i].Seq)
+			if s.filter.Match(events[i]) {
+				matched = append(matched, events[i])
+			}
+		}
+		for seq, missed := range s.missing {
+			if utils.TimeSince(missed) >= gapHorizon {
+				slog.DebugContext(ctx, "stream giving up missing seq", "seq", seq)
+				delete(s.missing, seq)
+			}
+		}
+	}
+	for {
+		events, err := s.broker.repo.Outbox.ListAfter(ctx, s.last, batch)
+		if err != nil {
+			return nil, false, err
+		}
+		now := utils.TimeNow()
+		for i := range events {
+			event := events[i]
+			for seq := s.last + 1; seq < event.Seq; seq++ {
+				s.missing[seq] = now
+			}
+			s.last = event.Seq
+			if s.filter.Match(event) {
+				matched = append(matched, event)
+			}
+		}
+		if len(events) < batch || len(matched) > 0 {
+			break
+		}
+	}
+	s.cursor = s.last
+	if len(s.missing) > 0 {
+		s.cursor = slices.Min(slices.Collect(maps.Keys(s.missing))) - 1
+	}
+	return matched, len(s.missing) > 0, nil
+}
@@ -0,0 +1,56 @@
+//go:build integration_test
+// +build integration_test
+
+package stream_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/di"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
+)
+
+func TestBroker_Listen(t *testing.T) {
+	app, cleanup, err := di.InitializeContainerTest()
+	if err != nil {
+		t.Fatalf("Failed to initialize test container: %v", err)
+	}
+	defer cleanup()
+	ctx := t.Context()
+
+	pool, ok := app.PostgresConn.(*pgxpool.Pool)
+	if !ok {
+		t.Fatalf("unexpected postgres conn %T", app.PostgresConn)
+	}
+	broker, stop := stream.New(app.Repository, pool)
+	defer stop()
+	sub, err := broker.Subscribe(ctx, -1, stream.Filter{Types: []factcheck.TypeEvent{factcheck.TypeEventMessageSubmitted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	user := factcheck.UserInfo{UserType: factcheck.TypeUserMessageLINEChat, UserID: "U1", ChatID: "U1"}
+	message, _, _, err := app.Service.Submit(ctx, user, "lemon soda cures cancer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Committed events are notified, so subscriptions get them long before heartbeats time out
+	events, err := sub.Next(ctx, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].SubjectID != message.ID {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	stop()
+	_, err = sub.Next(ctx, time.Second)
+	if err != stream.ErrClosed {
+		t.Fatalf("unexpected error after stop: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/repo"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/stream"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/utils"
+)
+
+// outboxMemory keeps events in memory.
+// Calling other methods of repo.Outbox will panic.
+type outboxMemory struct {
+	repo.Outbox
+	mu     sync.Mutex
+	events []factcheck.Event
+}
+
+func (o *outboxMemory) add(events ...factcheck.Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, events...)
+}
+
+func (o *outboxMemory) ListAfter(_ context.Context, seq int64, limit int, _ ...repo.Option) ([]factcheck.Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var events []factcheck.Event
+	for _, e := range o.events {
+		if e.Seq > seq && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (o *outboxMemory) ListBySeqs(_ context.Context, seqs []int64, _ ...repo.Option) ([]factcheck.Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var events []factcheck.Event
+	for _, e := range o.events {
+		if slices.Contains(seqs, e.Seq) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (o *outboxMemory) LastSeq(context.Context, ...repo.Option) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.events) == 0 {
+		return 0, nil
+	}
+	return o.events[len(o.events)-1].Seq, nil
+}
+
+func event(t *testing.T, seq int64, typ factcheck.TypeEvent, payload any) factcheck.Event {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return factcheck.Event{ID: utils.NewID().String(), Seq: seq, Type: typ, Payload: data, CreatedAt: utils.TimeNow()}
+}
+
+func seqs(events []factcheck.Event) []int64 {
+	s := make([]int64, len(events))
+	for i := range events {
+		s[i] = events[i].Seq
+	}
+	return s
+}
+
+func TestFilter(t *testing.T) {
+	submitted := event(t, 1, factcheck.TypeEventMessageSubmitted, factcheck.MessageV2{ID: "m1", TopicID: "t1"})
+	created := event(t, 2, factcheck.TypeEventGroupCreated, factcheck.MessageGroup{ID: "g1"})
+	merged := event(t, 3, factcheck.TypeEventTopicMerged, factcheck.TopicMerge{SourceID: "t2", Target: factcheck.Topic{ID: "t3"}})
+	resolved := event(t, 4, factcheck.TypeEventTopicResolved, factcheck.Topic{ID: "t1"})
+	events := []factcheck.Event{submitted, created, merged, resolved}
+
+	type testCase struct {
+		filter   stream.Filter
+		expected []int64
+	}
+	tests := []testCase{
+		{stream.Filter{}, []int64{1, 2, 3, 4}},
+		{stream.Filter{TopicIDs: []string{"t1"}}, []int64{1, 4}},
+		{stream.Filter{TopicIDs: []string{"t2"}}, []int64{3}},
+		{stream.Filter{TopicIDs: []string{"t3", "t1"}}, []int64{1, 3, 4}},
+		{stream.Filter{Types: []factcheck.TypeEvent{factcheck.TypeEventGroupCreated, factcheck.TypeEventTopicResolved}}, []int64{2, 4}},
+		{stream.Filter{TopicIDs: []string{"t1"}, Types: []factcheck.TypeEvent{factcheck.TypeEventTopicResolved}}, []int64{4}},
+	}
+	for _, tc := range tests {
+		var matched []factcheck.Event
+		for _, e := range events {
+			if tc.filter.Match(e) {
+				matched = append(matched, e)
+			}
+		}
+		if !slices.Equal(seqs(matched), tc.expected) {
+			t.Fatalf("unexpected matches of filter %+v: %v", tc.filter, seqs(matched))
+		}
+	}
+}
+
+func TestSubscription(t *testing.T) {
+	now := utils.TimeNow()
+	utils.TimeFreeze(now)
+	defer utils.TimeUnfreeze()
+
+	ctx := t.Context()
+	outbox := &outboxMemory{}
+	outbox.add(
+		event(t, 1, factcheck.TypeEventGroupCreated, factcheck.MessageGroup{ID: "g1"}),
+		event(t, 2, factcheck.TypeEventMessageSubmitted, factcheck.MessageV2{ID: "m1", GroupID: "g1"}),
+	)
+	broker := stream.NewBroker(repo.Repository{Outbox: outbox})
+
+	t.Run("resume and new events", func(t *testing.T) {
+		resumed, err := broker.Subscribe(ctx, 1, stream.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resumed.Close()
+		latest, err := broker.Subscribe(ctx, -1, stream.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer latest.Close()
+
+		events, err := resumed.Next(ctx, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(seqs(events), []int64{2}) {
+			t.Fatalf("unexpected resumed events: %v", seqs(events))
+		}
+		// Times out without new events
+		events, err = latest.Next(ctx, 10*time.Millisecond)
+		if err != nil || len(events) != 0 {
+			t.Fatalf("unexpected result without new events: %v, %v", seqs(events), err)
+		}
+
+		assigned := event(t, 3, factcheck.TypeEventGroupAssigned, factcheck.MessageGroup{ID: "g1", TopicID: "t1"})
+		go func() {
+			outbox.add(assigned)
+			broker.Notify()
+		}()
+		for _, sub := range []*stream.Subscription{resumed, latest} {
+			events, err = sub.Next(ctx, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(seqs(events), []int64{3}) {
+				t.Fatalf("unexpected new events: %v", seqs(events))
+			}
+		}
+	})
+
+	t.Run("filtered events move cursor", func(t *testing.T) {
+		sub, err := broker.Subscribe(ctx, 0, stream.Filter{Types: []factcheck.TypeEvent{factcheck.TypeEventGroupAssigned}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		events, err := sub.Next(ctx, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(seqs(events), []int64{3}) || sub.Cursor() != 3 {
+			t.Fatalf("unexpected events: %v, cursor %d", seqs(events), sub.Cursor())
+		}
+	})
+
+	t.Run("seq gaps", func(t *testing.T) {
+		sub, err := broker.Subscribe(ctx, -1, stream.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		// Seq 4 could still be committed, and seq 5 is read without waiting for it
+		outbox.add(event(t, 5, factcheck.TypeEventGroupApproved, factcheck.MessageGroup{ID: "g1"}))
+		events, err := sub.Next(ctx, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(seqs(events), []int64{5}) || sub.Cursor() != 3 {
+			t.Fatalf("unexpected events after young gap: %v, cursor %d", seqs(events), sub.Cursor())
+		}
+		// Seq 4 committed late, long after seq 5
+		utils.TimeFreeze(now.Add(time.Minute))
+		outbox.add(event(t, 4, factcheck.TypeEventGroupAssigned, factcheck.MessageGroup{ID: "g1", TopicID: "t1"}))
+		events, err = sub.Next(ctx, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(seqs(events), []int64{4}) || sub.Cursor() != 5 {
+			t.Fatalf("unexpected events of late commit: %v, cursor %d", seqs(events), sub.Cursor())
+		}
+		// Seq 6 rolled back, and is given up after gap horizon
+		outbox.add(event(t, 7, factcheck.TypeEventGroupApproved, factcheck.MessageGroup{ID: "g1"}))
+		events, err = sub.Next(ctx, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(seqs(events), []int64{7}) || sub.Cursor() != 5 {
+			t.Fatalf("unexpected events after young gap: %v, cursor %d", seqs(events), sub.Cursor())
+		}
+		utils.TimeFreeze(now.Add(time.Hour))
+		events, err = sub.Next(ctx, 1500*time.Millisecond)
+		if err != nil || len(events) != 0 || sub.Cursor() != 7 {
+			t.Fatalf("unexpected result after old gap: %v, %v, cursor %d", seqs(events), err, sub.Cursor())
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		sub, err := broker.Subscribe(ctx, -1, stream.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		broker.Close()
+		_, err = sub.Next(ctx, time.Minute)
+		if !errors.Is(err, stream.ErrClosed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = broker.Subscribe(ctx, -1, stream.Filter{})
+		if !errors.Is(err, stream.ErrClosed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
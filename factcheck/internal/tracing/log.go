@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHandler adds trace_id and span_id of the span of context to log records,
+// so that logs of a request can be correlated with its trace.
+type LogHandler struct {
+	slog.Handler
+}
+
+func NewLogHandler(h slog.Handler) LogHandler {
+	return LogHandler{Handler: h}
+}
+
+func (h LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := trace.SpanContextFromContext(ctx)
+	if c.IsValid() {
+		r = r.Clone()
+		r.AddAttrs(
+			slog.String("trace_id", c.TraceID().String()),
+			slog.String("span_id", c.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h LogHandler) WithGroup(name string) slog.Handler {
+	return LogHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,102 @@
+// Package tracing provides distributed tracing with OpenTelemetry.
+// Spans are started with Start, propagated across services in W3C Trace Context headers,
+// and exported by the tracer provider from New to an OTLP/HTTP collector or stdout.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+)
+
+const (
+	NameExporterNone   = ""
+	NameExporterStdout = "stdout"
+	NameExporterOTLP   = "otlp"
+)
+
+const (
+	scopeName     = "github.com/kaogeek/line-fact-check/factcheck"
+	exportTimeout = 10 * time.Second
+)
+
+// Start starts span name with the global tracer provider, as a child of span of ctx if any.
+// Callers must End the returned span, usually with defer.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(scopeName).Start(ctx, name, opts...)
+}
+
+// RecordError records err on span and sets its status to error, if err is not nil
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// New returns tracer provider with exporter and sample ratio from conf,
+// and cleanup function flushing remaining spans. Callers set it with otel.SetTracerProvider.
+// Providers without exporters still start spans, so that trace IDs are propagated and logged.
+func New(conf config.Config) (*sdktrace.TracerProvider, func(), error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(Sampler(conf.Tracing.SampleRatio)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(conf.AppName))),
+	}
+	switch conf.Tracing.Exporter {
+	case NameExporterNone:
+	case NameExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case NameExporterOTLP:
+		exporter, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(conf.Tracing.OTLPEndpoint, "/")+"/v1/traces"),
+			otlptracehttp.WithTimeout(exportTimeout),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter '%s'", conf.Tracing.Exporter)
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+		err := provider.Shutdown(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "tracing shutdown error", "err", err)
+		}
+	}
+	return provider, cleanup, nil
+}
+
+// Sampler samples ratio of traces by their trace IDs, so that every instance decides the same.
+// Spans with remote parents are decided by us too, since the sampled flag of traceparent
+// comes from clients, who could otherwise have every request exported.
+// Spans with local parents follow their parents.
+func Sampler(ratio float64) sdktrace.Sampler {
+	root := sdktrace.TraceIDRatioBased(ratio)
+	return sdktrace.ParentBased(root,
+		sdktrace.WithRemoteParentSampled(root),
+		sdktrace.WithRemoteParentNotSampled(root),
+	)
+}
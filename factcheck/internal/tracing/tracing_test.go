@@ -0,0 +1,209 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kaogeek/line-fact-check/factcheck/internal/config"
+	"github.com/kaogeek/line-fact-check/factcheck/internal/tracing"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID    = "00f067aa0ba902b7"
+	traceParent = "00-" + traceID + "-" + parentID + "-01"
+)
+
+// record sets global tracer provider sampling ratio of traces into the returned recorder,
+// until the test ends
+func record(t *testing.T, ratio float64) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(tracing.Sampler(ratio)),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	global := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(global) })
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	recorder := record(t, 1)
+	ctx, root := tracing.Start(context.Background(), "root")
+	_, child := tracing.Start(ctx, "child")
+	tracing.RecordError(child, io.EOF)
+	child.End()
+	root.End()
+
+	if !root.SpanContext().IsValid() || !root.SpanContext().IsSampled() {
+		t.Fatalf("unexpected root span context: %+v", root.SpanContext())
+	}
+	if child.SpanContext().TraceID() != root.SpanContext().TraceID() {
+		t.Fatalf("unexpected trace of child: %s, root %s", child.SpanContext().TraceID(), root.SpanContext().TraceID())
+	}
+	if trace.SpanFromContext(ctx) != root {
+		t.Fatal("unexpected span from context")
+	}
+	ended := recorder.Ended()
+	if len(ended) != 2 || ended[0].Name() != "child" || ended[0].Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Fatalf("unexpected ended spans: %+v", ended)
+	}
+	if ended[0].Status().Code != codes.Error || ended[0].Status().Description != io.EOF.Error() {
+		t.Fatalf("unexpected status of child: %+v", ended[0].Status())
+	}
+}
+
+func TestNew(t *testing.T) {
+	conf := config.Config{AppName: "factcheck-test", Tracing: config.Tracing{SampleRatio: 1}}
+	provider, cleanup, err := tracing.New(conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+	// Spans are not exported, but still have IDs to propagate and log
+	_, span := provider.Tracer("test").Start(context.Background(), "root")
+	span.End()
+	if !span.SpanContext().IsValid() {
+		t.Fatalf("unexpected span context without exporter: %+v", span.SpanContext())
+	}
+
+	conf.Tracing.Exporter = "zipkin"
+	_, _, err = tracing.New(conf)
+	if err == nil {
+		t.Fatal("unexpected nil error of unknown exporter")
+	}
+}
+
+func TestSampler(t *testing.T) {
+	type testCase struct {
+		ratio    float64
+		flags    string
+		expected bool
+	}
+	tests := []testCase{
+		// Clients cannot force their traces to be exported
+		{ratio: 0, flags: "01", expected: false},
+		// Nor keep ours from being exported
+		{ratio: 1, flags: "00", expected: true},
+	}
+	for _, tc := range tests {
+		recorder := record(t, tc.ratio)
+		in := http.Header{}
+		in.Set("traceparent", "00-"+traceID+"-"+parentID+"-"+tc.flags)
+		ctx, span := tracing.Start(tracing.Extract(context.Background(), in), "server")
+		_, child := tracing.Start(ctx, "child")
+		child.End()
+		span.End()
+
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Fatalf("unexpected trace ID %s, expecting trace of traceparent", span.SpanContext().TraceID())
+		}
+		if span.SpanContext().IsSampled() != tc.expected || child.SpanContext().IsSampled() != tc.expected {
+			t.Fatalf("unexpected sampled flag of ratio %v and flags %s: server %v, child %v",
+				tc.ratio, tc.flags, span.SpanContext().IsSampled(), child.SpanContext().IsSampled())
+		}
+		if tc.expected != (len(recorder.Ended()) == 2) {
+			t.Fatalf("unexpected spans exported of ratio %v and flags %s: %d", tc.ratio, tc.flags, len(recorder.Ended()))
+		}
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	record(t, 1)
+	in := http.Header{}
+	in.Set("traceparent", traceParent)
+	in.Set("tracestate", "vendor=value")
+	ctx, span := tracing.Start(tracing.Extract(context.Background(), in), "server")
+	defer span.End()
+
+	out := http.Header{}
+	tracing.Inject(ctx, out)
+	expected := "00-" + traceID + "-" + span.SpanContext().SpanID().String() + "-01"
+	if out.Get("traceparent") != expected {
+		t.Fatalf("unexpected traceparent: %s, expecting %s", out.Get("traceparent"), expected)
+	}
+	if out.Get("tracestate") != "vendor=value" {
+		t.Fatalf("unexpected tracestate: %s", out.Get("tracestate"))
+	}
+
+	empty := http.Header{}
+	tracing.Inject(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Fatalf("unexpected headers without trace: %v", empty)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := record(t, 1)
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("traceparent"))
+	}))
+	defer srv.Close()
+
+	ctx, span := tracing.Start(context.Background(), "caller")
+	defer span.End()
+	for _, transport := range []http.RoundTripper{tracing.Transport(nil), tracing.TransportUntrusted(nil)} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := http.Client{Transport: transport}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	ended := recorder.Ended()
+	if len(ended) != 2 || ended[0].SpanKind() != trace.SpanKindClient {
+		t.Fatalf("unexpected client spans: %+v", ended)
+	}
+	expected := "00-" + span.SpanContext().TraceID().String() + "-" + ended[0].SpanContext().SpanID().String() + "-01"
+	if got[0] != expected {
+		t.Fatalf("unexpected traceparent '%s', expecting '%s' of client span", got[0], expected)
+	}
+	if got[1] != "" {
+		t.Fatalf("unexpected traceparent '%s' sent to untrusted server", got[1])
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	record(t, 1)
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+	ctx, span := tracing.Start(context.Background(), "request")
+	defer span.End()
+
+	logger.InfoContext(ctx, "traced")
+	logger.InfoContext(context.Background(), "untraced")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var traced, untraced map[string]any
+	_ = json.Unmarshal([]byte(lines[0]), &traced)
+	_ = json.Unmarshal([]byte(lines[1]), &untraced)
+	if traced["trace_id"] != span.SpanContext().TraceID().String() || traced["span_id"] != span.SpanContext().SpanID().String() {
+		t.Fatalf("unexpected traced record: %v", traced)
+	}
+	if traced["component"] != "test" {
+		t.Fatalf("unexpected attrs of traced record: %v", traced)
+	}
+	if _, ok := untraced["trace_id"]; ok {
+		t.Fatalf("unexpected trace_id in untraced record: %v", untraced)
+	}
+}
@@ -14,4 +14,5 @@ type Metadata[T any] struct {
 type UserInfo struct {
 	UserType TypeUser `json:"user_type"`
 	UserID   string   `json:"user_id"`
+	ChatID   string   `json:"chat_id,omitempty"` // Chat the user submitted from, e.g. LINE user, group, or room ID
 }
@@ -0,0 +1,76 @@
+package factcheck
+
+import "time"
+
+type (
+	Role       string
+	Permission string
+)
+
+// Roles are ordered from least to most privileged,
+// and each role has all permissions of the roles before it.
+const (
+	RoleViewer      Role = "ROLE_VIEWER"      // Read-only access to admin data
+	RoleFactChecker Role = "ROLE_FACTCHECKER" // Triages messages and drafts answers
+	RoleEditor      Role = "ROLE_EDITOR"      // Publishes answers and resolves topics
+	RoleAdmin       Role = "ROLE_ADMIN"       // Deletes data and manages roles
+
+	PermissionRead         Permission = "PERM_READ"          // Read admin-only data, e.g. deliveries
+	PermissionSubmit       Permission = "PERM_SUBMIT"        // Submit messages via API
+	PermissionAssign       Permission = "PERM_ASSIGN"        // Assign messages to groups, and groups to topics
+	PermissionModerate     Permission = "PERM_MODERATE"      // Approve or reject message groups
+	PermissionAnswerDraft  Permission = "PERM_ANSWER_DRAFT"  // Draft answers
+	PermissionTopicEdit    Permission = "PERM_TOPIC_EDIT"    // Create topics, edit topic names and descriptions
+	PermissionTopicResolve Permission = "PERM_TOPIC_RESOLVE" // Answer and resolve topics, change topic status
+	PermissionTopicMerge   Permission = "PERM_TOPIC_MERGE"   // Merge duplicate topics, and split topics
+	PermissionDelete       Permission = "PERM_DELETE"        // Delete topics, messages and groups
+	PermissionRolesManage  Permission = "PERM_ROLES_MANAGE"  // Grant and revoke roles
+	PermissionAuditRead    Permission = "PERM_AUDIT_READ"    // Read audit log
+)
+
+// permissions is the permission matrix, with each role only listing
+// permissions it adds on top of the previous role
+var permissions = []struct {
+	role        Role
+	permissions []Permission
+}{
+	{RoleViewer, []Permission{PermissionRead}},
+	{RoleFactChecker, []Permission{PermissionSubmit, PermissionAssign, PermissionModerate, PermissionAnswerDraft, PermissionTopicEdit}},
+	{RoleEditor, []Permission{PermissionTopicResolve, PermissionTopicMerge, PermissionAuditRead}},
+	{RoleAdmin, []Permission{PermissionDelete, PermissionRolesManage}},
+}
+
+// UserRole is the role granted to an authenticated user
+type UserRole struct {
+	UserID    string     `json:"user_id"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func (r Role) IsValid() bool {
+	for i := range permissions {
+		if permissions[i].role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Can returns whether r has permission p
+func (r Role) Can(p Permission) bool {
+	if !r.IsValid() {
+		return false
+	}
+	for i := range permissions {
+		for _, granted := range permissions[i].permissions {
+			if granted == p {
+				return true
+			}
+		}
+		if permissions[i].role == r {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package factcheck_test
+
+import (
+	"testing"
+
+	"github.com/kaogeek/line-fact-check/factcheck"
+)
+
+func TestRoleCan(t *testing.T) {
+	type testCase struct {
+		role       factcheck.Role
+		permission factcheck.Permission
+		expected   bool
+	}
+	tests := []testCase{
+		{factcheck.RoleViewer, factcheck.PermissionRead, true},
+		{factcheck.RoleViewer, factcheck.PermissionAssign, false},
+		{factcheck.RoleFactChecker, factcheck.PermissionRead, true},
+		{factcheck.RoleFactChecker, factcheck.PermissionAssign, true},
+		{factcheck.RoleFactChecker, factcheck.PermissionModerate, true},
+		{factcheck.RoleViewer, factcheck.PermissionModerate, false},
+		{factcheck.RoleFactChecker, factcheck.PermissionAnswerDraft, true},
+		{factcheck.RoleFactChecker, factcheck.PermissionTopicResolve, false},
+		{factcheck.RoleEditor, factcheck.PermissionAssign, true},
+		{factcheck.RoleEditor, factcheck.PermissionTopicResolve, true},
+		{factcheck.RoleFactChecker, factcheck.PermissionTopicMerge, false},
+		{factcheck.RoleEditor, factcheck.PermissionTopicMerge, true},
+		{factcheck.RoleEditor, factcheck.PermissionDelete, false},
+		{factcheck.RoleAdmin, factcheck.PermissionTopicResolve, true},
+		{factcheck.RoleAdmin, factcheck.PermissionDelete, true},
+		{factcheck.RoleAdmin, factcheck.PermissionRolesManage, true},
+		{"ROLE_UNKNOWN", factcheck.PermissionRead, false},
+		{"", factcheck.PermissionRead, false},
+	}
+	for _, tc := range tests {
+		if actual := tc.role.Can(tc.permission); actual != tc.expected {
+			t.Fatalf("unexpected %s.Can(%s): %v", tc.role, tc.permission, actual)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package factcheck
+
+import "time"
+
+type TypeSearch string
+
+const (
+	TypeSearchTopic  TypeSearch = "SEARCH_TOPIC"
+	TypeSearchGroup  TypeSearch = "SEARCH_MESSAGE_GROUP"
+	TypeSearchAnswer TypeSearch = "SEARCH_ANSWER"
+)
+
+// SearchResult is a topic, message group or answer matching search query.
+// TopicID is the topic of the result, and is empty for groups not yet assigned to topics.
+// Title is empty for answers.
+type SearchResult struct {
+	Type      TypeSearch `json:"type"`
+	ID        string     `json:"id"`
+	TopicID   string     `json:"topic_id"`
+	Title     string     `json:"title"`
+	Text      string     `json:"text"`
+	Rank      float32    `json:"rank"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TypesSearch returns all types of search results
+func TypesSearch() []TypeSearch {
+	return []TypeSearch{TypeSearchTopic, TypeSearchGroup, TypeSearchAnswer}
+}
+
+func (t TypeSearch) IsValid() bool {
+	switch t {
+	case
+		TypeSearchTopic,
+		TypeSearchGroup,
+		TypeSearchAnswer:
+		return true
+	}
+	return false
+}
@@ -84,6 +84,8 @@ rec {
           tag = version;
           config = {
             Entrypoint = [ "${self.packages.${pkgs.system}.factcheck}/bin/api" ];
+            # Schema migrations are applied with: factcheck migrate up
+            Env = [ "PATH=${self.packages.${pkgs.system}.factcheck}/bin" ];
             ExposedPorts = {
               "8080/tcp" = {};
             };
@@ -129,10 +131,6 @@ rec {
             finalImageTag = "16";
             sha256 = "sha256-Gj6yGJdD0/eyMkXDrbEPPuPd89018+zyQ6EG7faC83g=";
           };
-          copyToRoot = pkgs.runCommand "postgres-init-schema-factcheck" {} ''
-            mkdir -p $out/docker-entrypoint-initdb.d
-            cp ${./factcheck/internal/data/postgres/schema.sql} $out/docker-entrypoint-initdb.d/01-schema.sql
-          '';
           config = {
             Entrypoint = [ "docker-entrypoint.sh" ];
             Cmd = [ "postgres" ];
@@ -259,6 +257,8 @@ rec {
             echo "PostgreSQL container started on $POSTGRES_HOST:$POSTGRES_PORT"
             echo "Waiting for PostgreSQL to be ready..."
             bash ${./compose/scripts/wait-for-postgres.sh} localhost
+            echo "Applying schema migrations..."
+            ${self.packages.${pkgs.system}.factcheck}/bin/factcheck migrate up
             echo "Use 'docker stop postgres-it-test && docker rm postgres-it-test' to clean up"
           '';
         };